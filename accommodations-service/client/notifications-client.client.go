@@ -0,0 +1,73 @@
+package client
+
+import (
+	"accommodations-service/config"
+	"accommodations-service/errors"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/sony/gobreaker"
+	"net/http"
+	"time"
+)
+
+type NotificationsClient struct {
+	address        string
+	client         *http.Client
+	circuitBreaker *gobreaker.CircuitBreaker
+	logger         *config.Logger
+}
+
+type HostNotification struct {
+	Mail      string `json:"mail"`
+	Text      string `json:"text"`
+	CreatedAt string `json:"createdAt"`
+	IsOpened  bool   `json:"isOpened"`
+}
+
+func NewNotificationsClient(host, port string, client *http.Client, circuitBreaker *gobreaker.CircuitBreaker, logger *config.Logger) *NotificationsClient {
+	return &NotificationsClient{
+		address:        fmt.Sprintf("http://%s:%s", host, port),
+		client:         client,
+		circuitBreaker: circuitBreaker,
+		logger:         logger,
+	}
+}
+
+func (nc NotificationsClient) NotifyHost(ctx context.Context, hostId, email, message string) *errors.ErrorStruct {
+	notification := HostNotification{
+		Mail:      email,
+		Text:      message,
+		CreatedAt: time.Now().String(),
+		IsOpened:  false,
+	}
+	reqBytes, err := json.Marshal(notification)
+	if err != nil {
+		nc.logger.LogError("accommodations-client", fmt.Sprintf("Unable to marshal notification"))
+		return errors.NewError(err.Error(), 500)
+	}
+
+	cbResp, err := nc.circuitBreaker.Execute(func() (interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, nc.address+"/"+hostId, bytes.NewReader(reqBytes))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return nc.client.Do(req)
+	})
+	if err != nil {
+		nc.logger.LogError("accommodations-client", fmt.Sprintf("Unable to send request to notifications service"))
+		nc.logger.LogError("accommodation-client", fmt.Sprintf("Error:"+err.Error()))
+		return errors.NewError("Notifications service is not responding", 500)
+	}
+
+	resp := cbResp.(*http.Response)
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		nc.logger.LogError("accommodations-client", fmt.Sprintf("Notifications service responded with status %d", resp.StatusCode))
+		return errors.NewError("Unable to notify host", resp.StatusCode)
+	}
+	nc.logger.LogInfo("accommodation-client", fmt.Sprintf("Notification sent to host %s", hostId))
+	return nil
+}
@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	neturl "net/url"
 
 	"github.com/sony/gobreaker"
 )
@@ -93,6 +94,9 @@ func (rc ReservationsClient) SendCreatedReservationsAvailabilities(ctx context.C
 
 }
 
+// CheckAvailabilityForAccommodations returns the accommodations that can not host a
+// stay of the given nights: they are reserved, not available or their stay rules do
+// not allow it.
 func (rc ReservationsClient) CheckAvailabilityForAccommodations(ctx context.Context, accommodationIDs []string, dateRange []string) ([]string, *errors.ErrorStruct) {
 	log.Println("Uslo u Check")
 	availabilityCheck := struct {
@@ -179,11 +183,16 @@ func (rc ReservationsClient) CheckAvailabilityForAccommodations(ctx context.Cont
 
 }
 
-func (rc ReservationsClient) GetAccommodationsBelowPrice(ctx context.Context, maxPrice int) ([]string, *errors.ErrorStruct) {
+// GetAccommodationsBelowPrice finds the accommodations with a nightly rate of at
+// most maxPrice whole units of currency, the default currency when empty.
+func (rc ReservationsClient) GetAccommodationsBelowPrice(ctx context.Context, maxPrice int, currency string) ([]string, *errors.ErrorStruct) {
 	log.Println("Entering GetAccommodationsBelowPrice")
 
 	// Build the request URL with the max price
 	url := fmt.Sprintf("%s/price/myPrice/%d", rc.address, maxPrice)
+	if currency != "" {
+		url += "?currency=" + neturl.QueryEscape(currency)
+	}
 
 	cbResp, err := rc.circuitBreaker.Execute(func() (interface{}, error) {
 		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
@@ -256,3 +265,42 @@ func (rc ReservationsClient) GetAccommodationsBelowPrice(ctx context.Context, ma
 		return nil, errors.NewError(resp.Error, resp.Status)
 	}
 }
+
+// SetCancellationPolicy hands a changed cancellation policy to the reservations
+// service, which computes the refunds. authorization is the header of the host.
+func (rc ReservationsClient) SetCancellationPolicy(ctx context.Context, authorization, accommodationID string, policy domain.CancellationPolicy) *errors.ErrorStruct {
+	jsonData, err := json.Marshal(policy)
+	if err != nil {
+		rc.logger.LogError("accommodations-client", fmt.Sprintf("Unable to marshal cancellation policy"))
+		return errors.NewError("Failed to marshal JSON data", http.StatusInternalServerError)
+	}
+
+	cbResp, err := rc.circuitBreaker.Execute(func() (interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, rc.address+"/cancellation-policy/"+accommodationID, bytes.NewReader(jsonData))
+		if err != nil {
+			rc.logger.LogError("accommodations-client", fmt.Sprintf("Unable to send request to reservations service"))
+			rc.logger.LogError("accommodation-client", fmt.Sprintf("Error:"+err.Error()))
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", authorization)
+		return rc.client.Do(req)
+	})
+	if err != nil {
+		rc.logger.LogError("accommodations-client", fmt.Sprintf("Internal server error"))
+		rc.logger.LogError("accommodation-client", fmt.Sprintf("Error:"+err.Error()))
+		return errors.NewError("Internal server error", http.StatusInternalServerError)
+	}
+	response := cbResp.(*http.Response)
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusOK {
+		rc.logger.LogInfo("accommodation-client", fmt.Sprintf("Updated cancellation policy of accommodation %s", accommodationID))
+		return nil
+	}
+	resp := domain.BaseErrorHttpResponse{}
+	if err := json.NewDecoder(response.Body).Decode(&resp); err != nil {
+		rc.logger.LogError("accommodations-client", fmt.Sprintf("Unable to decode json"))
+		return errors.NewError("Error decoding json", http.StatusInternalServerError)
+	}
+	return errors.NewError(resp.Error, resp.Status)
+}
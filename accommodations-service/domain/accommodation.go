@@ -1,25 +1,28 @@
 package domain
 
 import (
+	"time"
+
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Accommodation struct {
-	Id               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserId           string             `json:"userId" bson:"userId"`
-	UserName         string             `json:"username" bson:"username"`
-	Email            string             `json:"email" bson:"email"`
-	Name             string             `json:"name" bson:"name"`
-	Address          string             `json:"address" bson:"address"`
-	City             string             `json:"city" bson:"city"`
-	Country          string             `json:"country" bson:"country"`
-	Conveniences     []string           `json:"conveniences" bson:"conveniences"`
-	MinNumOfVisitors int                `json:"minNumOfVisitors" bson:"minNumOfVisitors"`
-	MaxNumOfVisitors int                `json:"maxNumOfVisitors" bson:"maxNumOfVisitors"`
-	ImageIds         []string           `json:"imageIds"`
-	Rating           float32            `json:"rating" bson:"rating"`
-	Status           string             `json:"status" bson:"status"`
-	Paying           string             `json:"paying" bson:"paying"`
+	Id                 primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserId             string              `json:"userId" bson:"userId"`
+	UserName           string              `json:"username" bson:"username"`
+	Email              string              `json:"email" bson:"email"`
+	Name               string              `json:"name" bson:"name"`
+	Address            string              `json:"address" bson:"address"`
+	City               string              `json:"city" bson:"city"`
+	Country            string              `json:"country" bson:"country"`
+	Conveniences       []string            `json:"conveniences" bson:"conveniences"`
+	MinNumOfVisitors   int                 `json:"minNumOfVisitors" bson:"minNumOfVisitors"`
+	MaxNumOfVisitors   int                 `json:"maxNumOfVisitors" bson:"maxNumOfVisitors"`
+	ImageIds           []string            `json:"imageIds"`
+	Rating             float32             `json:"rating" bson:"rating"`
+	Status             string              `json:"status" bson:"status"`
+	Paying             string              `json:"paying" bson:"paying"`
+	CancellationPolicy *CancellationPolicy `json:"cancellationPolicy,omitempty" bson:"cancellationPolicy,omitempty"`
 }
 
 type CreateAccommodation struct {
@@ -38,37 +41,83 @@ type CreateAccommodation struct {
 	Location                    string                        `json:"location" `
 	Status                      string                        `json:"status" bson:"status"`
 	Paying                      string                        `json:"paying" bson:"paying"`
+	CancellationPolicy          *CancellationPolicy           `json:"cancellationPolicy" bson:"cancellationPolicy"`
 }
 
 type AvailableAccommodationDates struct {
 	AccommodationId string   `json:"accommodationId"`
 	DateRange       []string `json:"dateRange"`
 	Location        string   `json:"location"`
-	Price           int      `json:"price"`
+	// Price is in minor units of Currency, cents for USD.
+	Price    int    `json:"price"`
+	Currency string `json:"currency"`
+	// Rules are checked by the reservations service, which owns them.
+	Rules *StayRules `json:"rules,omitempty"`
+}
+
+// StayRules limit which stays the nights can be booked for. Weekdays are named
+// like Monday, zero values and empty lists do not limit anything.
+type StayRules struct {
+	MinNights      int      `json:"minNights"`
+	MaxNights      int      `json:"maxNights"`
+	CheckInDays    []string `json:"checkInDays"`
+	CheckOutDays   []string `json:"checkOutDays"`
+	MinNoticeDays  int      `json:"minNoticeDays"`
+	MaxHorizonDays int      `json:"maxHorizonDays"`
 }
 
 type AccommodationDTO struct {
-	Id               string   `json:"id"`
-	UserId           string   `json:"userId" `
-	UserName         string   `json:"username" `
-	Email            string   `json:"email" bson:"email"`
-	Name             string   `json:"name" `
-	Address          string   `json:"address" `
-	City             string   `json:"city" `
-	Country          string   `json:"country" `
-	Conveniences     []string `json:"conveniences" `
-	MinNumOfVisitors int      `json:"minNumOfVisitors" `
-	MaxNumOfVisitors int      `json:"maxNumOfVisitors" `
-	ImageIds         []string `json:"imageIds"`
-	Rating           float32  `json:"rating"`
-	Status           string   `json:"status" bson:"status"`
-	Paying           string   `json:"paying" bson:"paying"`
+	Id                 string             `json:"id"`
+	UserId             string             `json:"userId" `
+	UserName           string             `json:"username" `
+	Email              string             `json:"email" bson:"email"`
+	Name               string             `json:"name" `
+	Address            string             `json:"address" `
+	City               string             `json:"city" `
+	Country            string             `json:"country" `
+	Conveniences       []string           `json:"conveniences" `
+	MinNumOfVisitors   int                `json:"minNumOfVisitors" `
+	MaxNumOfVisitors   int                `json:"maxNumOfVisitors" `
+	ImageIds           []string           `json:"imageIds"`
+	Rating             float32            `json:"rating"`
+	Status             string             `json:"status" bson:"status"`
+	Paying             string             `json:"paying" bson:"paying"`
+	CancellationPolicy CancellationPolicy `json:"cancellationPolicy"`
 }
 
 type SendCreateAccommodationAvailability struct {
-	AccommodationID string                        `json:"accommodationId"`
-	Location        string                        `json:"location"`
-	DateRange       []AvailableAccommodationDates `json:"dateRange"`
+	AccommodationID    string                        `json:"accommodationId"`
+	Location           string                        `json:"location"`
+	DateRange          []AvailableAccommodationDates `json:"dateRange"`
+	CancellationPolicy *CancellationPolicy           `json:"cancellationPolicy" bson:"cancellationPolicy"`
+	Paying             string                        `json:"paying" bson:"paying"`
+	HostID             string                        `json:"hostId" bson:"hostId"`
+}
+
+// CancellationPolicy decides how much a guest gets back when cancelling. Flexible,
+// moderate and strict use the tiers of the reservations service, custom policies
+// bring their own.
+type CancellationPolicy struct {
+	Name  string       `json:"name" bson:"name"`
+	Tiers []RefundTier `json:"tiers,omitempty" bson:"tiers,omitempty"`
+}
+
+// RefundTier refunds RefundPercent of the price when the guest cancels at least
+// DaysBeforeCheckIn days before check-in.
+type RefundTier struct {
+	DaysBeforeCheckIn int `json:"daysBeforeCheckIn" bson:"daysBeforeCheckIn"`
+	RefundPercent     int `json:"refundPercent" bson:"refundPercent"`
+}
+
+const DefaultCancellationPolicy = "flexible"
+
+// PolicyOrDefault returns the policy shown for an accommodation, the default one
+// when it has none.
+func PolicyOrDefault(policy *CancellationPolicy) CancellationPolicy {
+	if policy == nil || policy.Name == "" {
+		return CancellationPolicy{Name: DefaultCancellationPolicy}
+	}
+	return *policy
 }
 
 type AccommodationStatus string
@@ -77,3 +126,57 @@ const (
 	Pending AccommodationStatus = "Pending"
 	Created AccommodationStatus = "Created"
 )
+
+type OutboxStatus string
+
+const (
+	OutboxPending OutboxStatus = "Pending"
+	OutboxSent    OutboxStatus = "Sent"
+)
+
+// OutboxMessage is written together with the accommodation and later relayed to
+// the create accommodation saga, so the saga starts exactly when the accommodation exists.
+type OutboxMessage struct {
+	Id          primitive.ObjectID                  `bson:"_id,omitempty" json:"id"`
+	AggregateId string                              `bson:"aggregateId" json:"aggregateId"`
+	Payload     SendCreateAccommodationAvailability `bson:"payload" json:"payload"`
+	Status      OutboxStatus                        `bson:"status" json:"status"`
+	CreatedAt   time.Time                           `bson:"createdAt" json:"createdAt"`
+	SentAt      time.Time                           `bson:"sentAt" json:"sentAt"`
+
+	// TraceContext carries the trace of the create request over to the saga.
+	TraceContext map[string]string `bson:"traceContext,omitempty" json:"traceContext,omitempty"`
+}
+
+type CreationOutcome string
+
+const (
+	CreationQueued      CreationOutcome = "Queued"
+	CreationInProgress  CreationOutcome = "InProgress"
+	CreationRollingBack CreationOutcome = "RollingBack"
+	CreationApproved    CreationOutcome = "Approved"
+	CreationFailed      CreationOutcome = "Failed"
+	CreationTimedOut    CreationOutcome = "TimedOut"
+)
+
+type CreationStepDTO struct {
+	Step   string    `json:"step"`
+	Kind   string    `json:"kind"`
+	Detail string    `json:"detail"`
+	At     time.Time `json:"at"`
+}
+
+// CreationStatusDTO reports how far the create accommodation saga got. Outcome is
+// terminal once it is Approved, Failed or TimedOut.
+type CreationStatusDTO struct {
+	AccommodationId string            `json:"accommodationId"`
+	Outcome         CreationOutcome   `json:"outcome"`
+	SagaState       string            `json:"sagaState"`
+	CurrentStep     string            `json:"currentStep"`
+	History         []CreationStepDTO `json:"history"`
+	Replies         []string          `json:"replies"`
+	Compensations   []string          `json:"compensations"`
+	FailureReason   string            `json:"failureReason"`
+	StartedAt       time.Time         `json:"startedAt"`
+	UpdatedAt       time.Time         `json:"updatedAt"`
+}
@@ -87,6 +87,13 @@ func (a *AccommodationsHandler) CreateAccommodationById(rw http.ResponseWriter,
 		utils.WriteErrorResp(err2.Error(), http.StatusBadRequest, "dates puca", rw)
 		return
 	}
+	var cancellationPolicy *domain.CancellationPolicy
+	if policyJson := h.FormValue("cancellationPolicy"); policyJson != "" {
+		if err := json.Unmarshal([]byte(policyJson), &cancellationPolicy); err != nil {
+			utils.WriteErrorResp(err.Error(), http.StatusBadRequest, "api/accommodations", rw)
+			return
+		}
+	}
 	minVis, err := strconv.Atoi(h.FormValue("minNumOfVisitors"))
 	maxVis, err := strconv.Atoi(h.FormValue("maxNumOfVisitors"))
 
@@ -112,7 +119,7 @@ func (a *AccommodationsHandler) CreateAccommodationById(rw http.ResponseWriter,
 		City:                        h.FormValue("city"),
 		Country:                     h.FormValue("country"),
 		UserName:                    h.FormValue("username"),
-		UserId:                      h.FormValue("userId"),
+		UserId:                      h.Context().Value("userID").(string),
 		Email:                       h.FormValue("email"),
 		Conveniences:                conv,
 		MinNumOfVisitors:            minVis,
@@ -120,6 +127,7 @@ func (a *AccommodationsHandler) CreateAccommodationById(rw http.ResponseWriter,
 		AvailableAccommodationDates: accDates,
 		Location:                    h.FormValue("location"),
 		Paying:                      h.FormValue("paying"),
+		CancellationPolicy:          cancellationPolicy,
 	}
 
 	_, err4 := a.AccommodationService.CreateAccommodation(accomm, images, ctx)
@@ -200,6 +208,27 @@ func (a *AccommodationsHandler) GetAccommodationById(rw http.ResponseWriter, r *
 	utils.WriteResp(accommodation, 201, rw)
 }
 
+func (a *AccommodationsHandler) GetCreationStatus(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := a.Tracer.Start(r.Context(), "AccommodationsHandler.GetCreationStatus")
+	defer span.End()
+	vars := mux.Vars(r)
+	accommodationId := vars["id"]
+
+	hostId := r.Context().Value("userID").(string)
+
+	status, err := a.AccommodationService.GetCreationStatus(ctx, accommodationId, hostId)
+	if err != nil {
+		a.Logger.Error("Error getting creation status", log.Fields{
+			"module": "handler",
+			"error":  err.GetErrorMessage(),
+		})
+		utils.WriteErrorResp(err.GetErrorMessage(), err.GetErrorStatus(), "api/accommodations/"+accommodationId+"/creation-status", rw)
+		return
+	}
+	a.Logger.Infof("Successfully got creation status of accommodation " + accommodationId)
+	utils.WriteResp(status, 200, rw)
+}
+
 func (a *AccommodationsHandler) FindAccommodationsByIds(rw http.ResponseWriter, r *http.Request) {
 	ctx, span := a.Tracer.Start(r.Context(), "AccommodationsHandler.FindAccommodationsByIds")
 	defer span.End()
@@ -269,7 +298,7 @@ func (a *AccommodationsHandler) UpdateAccommodationById(rw http.ResponseWriter,
 	id, _ := primitive.ObjectIDFromHex(accommodationId)
 	updatedAccommodation.Id = id
 
-	accommodation, err := a.AccommodationService.UpdateAccommodation(ctx, updatedAccommodation)
+	accommodation, err := a.AccommodationService.UpdateAccommodation(ctx, updatedAccommodation, r.Header.Get("Authorization"))
 	if err != nil {
 		a.Logger.Error("Error getting response from accommodation service", log.Fields{
 			"module": "handler",
@@ -359,6 +388,9 @@ func (a *AccommodationsHandler) SearchAccommodations(w http.ResponseWriter, r *h
 	}
 
 	maxPrice, err := strconv.Atoi(maxPriceString)
+	// maxPrice is in whole units of currency, the reservations service converts the
+	// nightly rates of other currencies before comparing.
+	currency := r.URL.Query().Get("currency")
 
 	conveniencesCsv := r.URL.Query().Get("conveniences")
 
@@ -372,7 +404,7 @@ func (a *AccommodationsHandler) SearchAccommodations(w http.ResponseWriter, r *h
 
 	// Handle empty dateRange as needed
 
-	accommodations, errS := a.AccommodationService.SearchAccommodations(city, country, numOfVisitors, startDate, endDate, maxPrice, conveniences, isDistinguishedString, ctx)
+	accommodations, errS := a.AccommodationService.SearchAccommodations(city, country, numOfVisitors, startDate, endDate, maxPrice, currency, conveniences, isDistinguishedString, ctx)
 
 	if errS != nil {
 		a.Logger.Error("Error searching accommodations", log.Fields{
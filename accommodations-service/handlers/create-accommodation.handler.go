@@ -3,6 +3,7 @@ package handlers
 import (
 	"accommodations-service/config"
 	"accommodations-service/services"
+	"context"
 	events "example/saga/create_accommodation"
 	saga "example/saga/messaging"
 	"fmt"
@@ -34,7 +35,9 @@ func NewCreateAccommodationCommandHandler(accommodationService *services.Accommo
 	return o, nil
 }
 
-func (handler *CreateAccommodationCommandHandler) handle(command *events.CreateAccommodationCommand) {
+// handle returns an error only when a compensation could not be applied, so the
+// command is redelivered instead of acknowledged.
+func (handler *CreateAccommodationCommandHandler) handle(command *events.CreateAccommodationCommand, envelope *saga.Envelope) error {
 	// ctx, span := handler.tracer.Start(ctx, "CreateAccommodationCommandHandler.handle")
 	// defer span.End()
 	handler.logger.LogInfo("saga-handler", fmt.Sprintf("USLO U CREATE KOD ACCOMMODATION %v", command.Type))
@@ -42,19 +45,30 @@ func (handler *CreateAccommodationCommandHandler) handle(command *events.CreateA
 	switch command.Type {
 	case events.UpdateAccommodation:
 		handler.logger.LogInfo("saga-handler", fmt.Sprintf("USLO U CREATE KOD ACCOMMODATION ZA UPDATE ACCOMMODATION %v", command.Type))
+		reply := events.CreateAccommodationReply{Type: events.AccommodationApproved, Payload: returnedValue}
 		err := handler.accommodationService.ApproveAccommodation(returnedValue.AccommodationID)
 		if err != nil {
-			return
+			reply.Type = events.AccommodationNotApproved
 		}
+		_ = saga.Reply(handler.replyPublisher, reply, envelope)
 		break
 	case events.DenyAccommodation:
 		handler.logger.LogInfo("saga-handler", fmt.Sprintf("USLO U CREATE KOD ACCOMMODATION ZA DENY ACCOMMODATION %v", command.Type))
 		err := handler.accommodationService.DenyAccommodation(returnedValue.AccommodationID)
 		if err != nil {
-			return
+			handler.logger.LogError("saga-handler", fmt.Sprintf("Unable to deny accommodation %s: %v", returnedValue.AccommodationID, err))
+			return err
+		}
+		break
+	case events.RollbackAccommodation:
+		err := handler.accommodationService.RollbackAccommodation(context.Background(), returnedValue.AccommodationID)
+		if err != nil {
+			handler.logger.LogError("saga-handler", fmt.Sprintf("Unable to roll back accommodation %s: %v", returnedValue.AccommodationID, err))
+			return err
 		}
 		break
 	default:
 		break
 	}
+	return nil
 }
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"accommodations-service/config"
+	"accommodations-service/services"
+	"context"
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+)
+
+type DeleteUserCommandHandler struct {
+	accommodationService *services.AccommodationService
+	replyPublisher       saga.Publisher
+	commandSubscriber    saga.Subscriber
+	logger               *config.Logger
+}
+
+func NewDeleteUserCommandHandler(accommodationService *services.AccommodationService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		accommodationService: accommodationService,
+		replyPublisher:       publisher,
+		commandSubscriber:    subscriber,
+		logger:               logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("accommodations-saga-handler", fmt.Sprintf("Unable to subscribe to delete user commands"))
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.DeleteUserReply{Payload: details}
+	switch command.Type {
+	case events.ArchiveUserAccommodations:
+		reply.Type = events.UserAccommodationsArchived
+		if details.Role == "Host" {
+			ids, err := handler.accommodationService.ArchiveAccommodationsByUserId(context.Background(), details.UserID)
+			if err != nil {
+				reply.Type = events.UserAccommodationsNotArchived
+				break
+			}
+			reply.Payload.AccommodationIDs = ids
+		}
+	case events.RestoreUserAccommodations:
+		if err := handler.accommodationService.RestoreAccommodationsByUserId(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
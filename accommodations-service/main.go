@@ -13,6 +13,7 @@ import (
 	"accommodations-service/utils"
 	"context"
 	"example/saga/messaging/nats"
+	"example/saga/store"
 	"log"
 	"net/http"
 	"os"
@@ -49,6 +50,9 @@ func main() {
 	userServicePort := os.Getenv("USER_SERVICE_PORT")
 	log.Println("PORT", userServicePort)
 
+	notificationsServiceHost := os.Getenv("NOTIFICATION_SERVICE_HOST")
+	notificationsServicePort := os.Getenv("NOTIFICATION_SERVICE_PORT")
+
 	//clients
 
 	customReservationsServiceClient := &http.Client{
@@ -67,6 +71,14 @@ func main() {
 		},
 	}
 
+	customNotificationsServiceClient := &http.Client{
+		Transport: &http.Transport{
+			MaxIdleConns:        10,
+			MaxIdleConnsPerHost: 10,
+			MaxConnsPerHost:     10,
+		},
+	}
+
 	reservationsServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
 		gobreaker.Settings{
 			Name:        "reservations-service",
@@ -91,9 +103,22 @@ func main() {
 		},
 	)
 
+	notificationsServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
+		gobreaker.Settings{
+			Name:        "notifications-service",
+			MaxRequests: 1,
+			Timeout:     10 * time.Second,
+			Interval:    0,
+			OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+				log.Printf("Circuit Breaker %v: %v -> %v", name, from, to)
+			},
+		},
+	)
+
 	validator := utils.NewValidator()
 	reservationsClient := client.NewReservationsClient(reservationsServiceHost, reservationsServicePort, customReservationsServiceClient, reservationsServiceCircuitBreaker, loggerW)
 	userClient := client.NewUserClient(userServiceHost, userServicePort, customUserServiceClient, userServiceCircuitBreaker, loggerW)
+	notificationsClient := client.NewNotificationsClient(notificationsServiceHost, notificationsServicePort, customNotificationsServiceClient, notificationsServiceCircuitBreaker, loggerW)
 
 	tracerConfig := tracing.GetConfig()
 	tracerProvider, err := tracing.NewTracerProvider("accommodations-service", tracerConfig.JaegerAddress)
@@ -115,7 +140,7 @@ func main() {
 
 	accommodationRepo := repository.NewAccommodationRepository(
 		mongoService.GetCli(), loggerW, tracer)
-	publisher, err := nats.NewNATSPublisher(
+	publisher, err := nats.NewJetStreamPublisher(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -125,7 +150,7 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	replySubscriber, err := nats.NewNATSSubscriber(
+	replySubscriber, err := nats.NewJetStreamSubscriber(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -135,7 +160,8 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	orch, err := orchestrator.NewCreateAccommodationOrchestrator(publisher, replySubscriber, loggerW)
+	sagaStore := store.NewMongoStore(mongoService.GetCli(), "accommodations-service", "sagas")
+	orch, err := orchestrator.NewCreateAccommodationOrchestrator(publisher, replySubscriber, sagaStore, loggerW)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -143,8 +169,10 @@ func main() {
 	defer fileStorage.Close()
 	_ = fileStorage.CreateDirectories()
 	cache := repository.NewCache(loggerCach, tracer)
-	accommodationService := services.NewAccommodationService(accommodationRepo, validator, reservationsClient, userClient, fileStorage, cache, orch, tracer, loggerW)
-	publisher1, err := nats.NewNATSPublisher(
+	outboxRepo := repository.NewOutboxRepository(mongoService.GetCli(), loggerW)
+	outboxRelay := services.NewOutboxRelay(outboxRepo, orch, loggerW)
+	accommodationService := services.NewAccommodationService(accommodationRepo, validator, reservationsClient, userClient, notificationsClient, fileStorage, cache, outboxRelay, orch, tracer, loggerW)
+	publisher1, err := nats.NewJetStreamPublisher(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -154,7 +182,7 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	replySubscriber2, err := nats.NewNATSSubscriber(
+	replySubscriber2, err := nats.NewJetStreamSubscriber(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -165,6 +193,37 @@ func main() {
 	if err != nil {
 		log.Println(err)
 	}
+	deleteUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deleteUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"accommodations-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handlers.NewDeleteUserCommandHandler(accommodationService, deleteUserReplyPublisher, deleteUserCommandSubscriber, loggerW)
+	if err != nil {
+		log.Println(err)
+	}
+	sagaContext, stopSagas := context.WithCancel(context.Background())
+	defer stopSagas()
+	err = orch.Resume(sagaContext)
+	if err != nil {
+		log.Println(err)
+	}
+	go outboxRelay.Run(sagaContext, 5*time.Second)
 
 	accommodationsHandler := handlers.AccommodationsHandler{
 		AccommodationService: accommodationService,
@@ -197,6 +256,8 @@ func main() {
 
 	router.HandleFunc("/{id}", accommodationsHandler.GetAccommodationById).Methods("GET")
 
+	router.HandleFunc("/{id}/creation-status", middlewares.ValidateJWT(middlewares.RoleValidator("Host", accommodationsHandler.GetCreationStatus))).Methods("GET")
+
 	router.HandleFunc("/images/{id}", accommodationsHandler.MiddlewareCacheHit(accommodationsHandler.GetImage)).Methods("GET")
 
 	router.HandleFunc("/rating/{id}", accommodationsHandler.PutAccommodationRating).Methods("PUT")
@@ -233,6 +294,7 @@ func main() {
 	if server.Shutdown(timeoutContext) != nil {
 		loggerW.Fatalf("Cannot gracefully shutdown...")
 	}
+	nats.Drain()
 	loggerW.Println("Server stopped")
 
 }
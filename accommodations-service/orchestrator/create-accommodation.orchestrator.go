@@ -2,23 +2,36 @@ package orchestrator
 
 import (
 	"accommodations-service/config"
+	"context"
 	events "example/saga/create_accommodation"
+	"example/saga/engine"
 	saga "example/saga/messaging"
 	"fmt"
-	"log"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+)
+
+const (
+	createAccommodationSaga  = "create-accommodation"
+	saveAccommodationStep    = "save-accommodation"
+	createAvailabilityStep   = "create-availability"
+	approveAccommodationStep = "approve-accommodation"
+	stepTimeout              = 30 * time.Second
+	timeoutCheckInterval     = 5 * time.Second
 )
 
 type CreateAccommodationOrchestrator struct {
-	commandPublisher saga.Publisher
-	replySubscriber  saga.Subscriber
-	logger           *config.Logger
+	sagas           *engine.Orchestrator
+	replySubscriber saga.Subscriber
+	logger          *config.Logger
 }
 
-func NewCreateAccommodationOrchestrator(publisher saga.Publisher, replySubscriber saga.Subscriber, logger *config.Logger) (*CreateAccommodationOrchestrator, error) {
+func NewCreateAccommodationOrchestrator(publisher saga.Publisher, replySubscriber saga.Subscriber, store engine.Store, logger *config.Logger) (*CreateAccommodationOrchestrator, error) {
 	orchestrator := &CreateAccommodationOrchestrator{
-		commandPublisher: publisher,
-		replySubscriber:  replySubscriber,
-		logger:           logger,
+		sagas:           engine.NewOrchestrator(createAccommodationDefinition(), publisher, store),
+		replySubscriber: replySubscriber,
+		logger:          logger,
 	}
 	err := orchestrator.replySubscriber.Subscribe(orchestrator.handle)
 	if err != nil {
@@ -28,37 +41,123 @@ func NewCreateAccommodationOrchestrator(publisher saga.Publisher, replySubscribe
 	return orchestrator, nil
 }
 
-func (cao *CreateAccommodationOrchestrator) Start(accommodation *events.SendCreateAccommodationAvailability) error {
-	log.Println(accommodation.AccommodationID)
+// createAccommodationDefinition describes the saga: the accommodation is saved locally
+// before the saga starts, then reservations-service creates the availability and
+// finally the accommodation is approved. A failed step rolls the availability and
+// the accommodation back; both are kept when the accommodation was approved after
+// all.
+func createAccommodationDefinition() engine.Definition {
+	return engine.Definition{
+		Name: createAccommodationSaga,
+		Steps: []engine.Step{
+			{
+				Name:         saveAccommodationStep,
+				Compensation: commandOf(events.RollbackAccommodation),
+			},
+			{
+				Name:         createAvailabilityStep,
+				Command:      commandOf(events.CreateAvailability),
+				Compensation: commandOf(events.RollbackAvailability),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:    approveAccommodationStep,
+				Command: commandOf(events.UpdateAccommodation),
+				Timeout: stepTimeout,
+			},
+		},
+	}
+}
+
+func commandOf(commandType events.CreateAccommodationCommandType) engine.CommandBuilder {
+	return func(instance *engine.Instance) (interface{}, error) {
+		var payload events.SendCreateAccommodationAvailability
+		if err := instance.Decode(&payload); err != nil {
+			return nil, err
+		}
+		return &events.CreateAccommodationCommand{
+			Type:    commandType,
+			Payload: payload,
+		}, nil
+	}
+}
+
+func (cao *CreateAccommodationOrchestrator) Start(ctx context.Context, accommodation *events.SendCreateAccommodationAvailability) error {
 	cao.logger.LogInfo("accommodation-saga-orchestrator", "Entered in start saga with id of accommodation "+accommodation.AccommodationID)
-	event := &events.CreateAccommodationCommand{
-		Type:    events.CreateAvailability,
-		Payload: *accommodation,
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+	return cao.sagas.StartTraced(accommodation.AccommodationID, accommodation, traceContext)
+}
+
+// Status returns the saga of the accommodation, or engine.ErrNotFound when its
+// outbox message has not been relayed yet.
+func (cao *CreateAccommodationOrchestrator) Status(accommodationID string) (*engine.Instance, error) {
+	return cao.sagas.Get(accommodationID)
+}
+
+// HostID returns the host that created the accommodation of the saga, or "" for
+// sagas started before the host was carried.
+func (cao *CreateAccommodationOrchestrator) HostID(instance *engine.Instance) string {
+	var payload events.SendCreateAccommodationAvailability
+	if err := instance.Decode(&payload); err != nil {
+		return ""
 	}
-	cao.logger.LogInfo("accommodation-saga-orchestrator", "Event created ")
-	log.Println("EVENT IZGLEDA OVAKO ", event)
-	return cao.commandPublisher.Publish(event)
+	return payload.HostID
 }
 
-func (cao *CreateAccommodationOrchestrator) handle(reply *events.CreateAccommodationReply) {
-	cao.logger.LogInfo("accommodation-saga-orchestrator", "Entered saga handle func")
-	command := events.CreateAccommodationCommand{Payload: reply.Payload}
-	command.Type = cao.nextCommandType(reply.Type)
-	if command.Type != events.UnknownCommand {
-		cao.logger.LogInfo("accommodation-saga-service", "Entered command publishing")
-		_ = cao.commandPublisher.Publish(command)
+// StepName returns the name of the step the saga is at, or "" once it finished.
+func (cao *CreateAccommodationOrchestrator) StepName(instance *engine.Instance) string {
+	steps := createAccommodationDefinition().Steps
+	switch instance.State {
+	case engine.Running:
+		return steps[instance.CurrentStep].Name
+	case engine.Compensating:
+		if instance.CurrentStep > 0 {
+			return steps[instance.CurrentStep-1].Name
+		}
 	}
+	return ""
 }
 
-func (cao *CreateAccommodationOrchestrator) nextCommandType(reply events.CreateAccommodationReplyType) events.CreateAccommodationCommandType {
-	cao.logger.LogInfo("accommodation-saga-service", "Picking next command")
-	switch reply {
+// AwaitsApproval reports whether the saga of the accommodation is still waiting
+// for it to be approved. An approval arriving after the saga gave up is refused,
+// since the availability is rolled back already.
+func (cao *CreateAccommodationOrchestrator) AwaitsApproval(accommodationID string) bool {
+	instance, err := cao.sagas.Get(accommodationID)
+	if err != nil {
+		return false
+	}
+	return instance.State == engine.Running && cao.StepName(instance) == approveAccommodationStep
+}
+
+// Resume restarts sagas left in flight by a previous run and starts watching step timeouts.
+func (cao *CreateAccommodationOrchestrator) Resume(ctx context.Context) error {
+	err := cao.sagas.Resume()
+	if err != nil {
+		cao.logger.LogError("accommodation-saga-orchestrator", "Unable to resume sagas: "+err.Error())
+		return err
+	}
+	go cao.sagas.WatchTimeouts(ctx, timeoutCheckInterval)
+	return nil
+}
+
+func (cao *CreateAccommodationOrchestrator) handle(reply *events.CreateAccommodationReply) {
+	cao.logger.LogInfo("accommodation-saga-orchestrator", "Entered saga handle func")
+	id := reply.Payload.AccommodationID
+	var err error
+	switch reply.Type {
 	case events.AvailabilityCreated:
-		return events.UpdateAccommodation
+		err = cao.sagas.StepSucceeded(id, createAvailabilityStep, "AvailabilityCreated")
 	case events.AvailabilityNotCreated:
-		return events.RollbackAccommodation
+		err = cao.sagas.StepFailed(id, createAvailabilityStep, "AvailabilityNotCreated")
+	case events.AccommodationApproved:
+		err = cao.sagas.StepSucceeded(id, approveAccommodationStep, "AccommodationApproved")
+	case events.AccommodationNotApproved:
+		err = cao.sagas.StepFailed(id, approveAccommodationStep, "AccommodationNotApproved")
 	default:
-		return events.UnknownCommand
+		return
+	}
+	if err != nil {
+		cao.logger.LogError("accommodation-saga-orchestrator", fmt.Sprintf("Unable to handle reply for accommodation %s: %v", id, err))
 	}
-
 }
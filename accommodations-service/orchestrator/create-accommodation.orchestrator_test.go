@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"accommodations-service/config"
+	"context"
+	events "example/saga/create_accommodation"
+	"example/saga/engine"
+	"example/saga/sagatest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestOrchestrator runs the create accommodation saga on the harness, with
+// every step timing out after timeout.
+func newTestOrchestrator(t *testing.T, harness *sagatest.Harness, timeout time.Duration) *CreateAccommodationOrchestrator {
+	t.Helper()
+	definition := createAccommodationDefinition()
+	for i := range definition.Steps {
+		if definition.Steps[i].Timeout > 0 {
+			definition.Steps[i].Timeout = timeout
+		}
+	}
+	orchestrator := &CreateAccommodationOrchestrator{
+		sagas:           engine.NewOrchestrator(definition, harness.CreateAccommodationCommandPublisher(), harness.Store),
+		replySubscriber: harness.CreateAccommodationReplySubscriber("accommodations-saga-orchestrator"),
+		logger:          config.NewLogger(filepath.Join(t.TempDir(), "orchestrator.log")),
+	}
+	if err := orchestrator.replySubscriber.Subscribe(orchestrator.handle); err != nil {
+		t.Fatal(err)
+	}
+	return orchestrator
+}
+
+// watchTimeouts expires the steps of sagas until the returned func is called.
+func watchTimeouts(sagas *engine.Orchestrator) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		sagas.WatchTimeouts(ctx, time.Millisecond)
+		close(stopped)
+	}()
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+func newFakes(t *testing.T, harness *sagatest.Harness) (*sagatest.FakeReservations, *sagatest.FakeAccommodations) {
+	t.Helper()
+	reservations, err := harness.FakeReservations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	accommodations, err := harness.FakeAccommodations()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reservations, accommodations
+}
+
+func assertState(t *testing.T, harness *sagatest.Harness, id string, want engine.State) {
+	t.Helper()
+	state, err := harness.State(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != want {
+		t.Fatalf("saga is %s, want %s", state, want)
+	}
+}
+
+func TestCreateAccommodationCompletes(t *testing.T) {
+	harness := sagatest.NewHarness()
+	reservations, accommodations := newFakes(t, harness)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	err := orchestrator.Start(context.Background(), &events.SendCreateAccommodationAvailability{AccommodationID: "approved"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertState(t, harness, "approved", engine.Completed)
+	if !reservations.HasAvailability("approved") {
+		t.Fatal("availability was not created")
+	}
+	if status := accommodations.Status("approved"); status != "Approved" {
+		t.Fatalf("accommodation is %s, want Approved", status)
+	}
+}
+
+func TestCreateAccommodationRollsBackWhenAvailabilityFails(t *testing.T) {
+	harness := sagatest.NewHarness()
+	reservations, accommodations := newFakes(t, harness)
+	reservations.FailFor("failing")
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	err := orchestrator.Start(context.Background(), &events.SendCreateAccommodationAvailability{AccommodationID: "failing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertState(t, harness, "failing", engine.Compensated)
+	if status := accommodations.Status("failing"); status != "RolledBack" {
+		t.Fatalf("accommodation is %s, want RolledBack", status)
+	}
+	want := []events.CreateAccommodationCommandType{events.CreateAvailability, events.RollbackAccommodation}
+	if received := reservations.Received(); !reflect.DeepEqual(received, want) {
+		t.Fatalf("reservations-service received %v, want %v", received, want)
+	}
+}
+
+func TestCreateAccommodationRollsBackWhenAvailabilityTimesOut(t *testing.T) {
+	harness := sagatest.NewHarness()
+	reservations, accommodations := newFakes(t, harness)
+	reservations.IgnoreFor("silent")
+	orchestrator := newTestOrchestrator(t, harness, 10*time.Millisecond)
+	defer watchTimeouts(orchestrator.sagas)()
+
+	err := orchestrator.Start(context.Background(), &events.SendCreateAccommodationAvailability{AccommodationID: "silent"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.WaitFor("silent", engine.Compensated, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	if status := accommodations.Status("silent"); status != "RolledBack" {
+		t.Fatalf("accommodation is %s, want RolledBack", status)
+	}
+	if reservations.HasAvailability("silent") {
+		t.Fatal("availability created after the timeout was not rolled back")
+	}
+}
+
+func TestCreateAccommodationKeepsAvailabilityWhenApprovalTimesOutAfterApproving(t *testing.T) {
+	harness := sagatest.NewHarness()
+	reservations, accommodations := newFakes(t, harness)
+	reservations.CheckStatusWith(accommodations.Status)
+	accommodations.IgnoreFor("approved-late")
+	orchestrator := newTestOrchestrator(t, harness, 10*time.Millisecond)
+	defer watchTimeouts(orchestrator.sagas)()
+
+	err := orchestrator.Start(context.Background(), &events.SendCreateAccommodationAvailability{AccommodationID: "approved-late"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.WaitFor("approved-late", engine.Compensated, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	if status := accommodations.Status("approved-late"); status != "Approved" {
+		t.Fatalf("accommodation is %s, want Approved", status)
+	}
+	if !reservations.HasAvailability("approved-late") {
+		t.Fatal("availability of the approved accommodation was rolled back")
+	}
+	want := []events.CreateAccommodationCommandType{events.CreateAvailability, events.UpdateAccommodation, events.RollbackAvailability, events.RollbackAccommodation}
+	if received := reservations.Received(); !reflect.DeepEqual(received, want) {
+		t.Fatalf("reservations-service received %v, want %v", received, want)
+	}
+}
+
+func TestCreateAccommodationAwaitsApprovalUntilItTimesOut(t *testing.T) {
+	harness := sagatest.NewHarness()
+	_, accommodations := newFakes(t, harness)
+	accommodations.IgnoreFor("slow")
+	orchestrator := newTestOrchestrator(t, harness, 10*time.Millisecond)
+
+	if orchestrator.AwaitsApproval("slow") {
+		t.Fatal("an accommodation without a saga awaits approval")
+	}
+	err := orchestrator.Start(context.Background(), &events.SendCreateAccommodationAvailability{AccommodationID: "slow"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+	if !orchestrator.AwaitsApproval("slow") {
+		t.Fatal("accommodation does not await approval")
+	}
+
+	defer watchTimeouts(orchestrator.sagas)()
+	if err := harness.WaitFor("slow", engine.Compensated, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if orchestrator.AwaitsApproval("slow") {
+		t.Fatal("accommodation awaits approval after its saga timed out")
+	}
+}
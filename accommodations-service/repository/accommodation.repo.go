@@ -47,6 +47,39 @@ func (ar *AccommodationRepo) SaveAccommodation(ctx context.Context, accommodatio
 	return &accommodation, nil
 }
 
+// SaveAccommodationWithOutbox inserts the accommodation and its outbox message in one
+// transaction, so the saga is started if and only if the accommodation was stored.
+func (ar *AccommodationRepo) SaveAccommodationWithOutbox(ctx context.Context, accommodation do.Accommodation, message do.OutboxMessage) (*do.Accommodation, *errors.ErrorStruct) {
+	ctx, span := ar.tracer.Start(ctx, "AccommodationRepo.SaveAccommodationWithOutbox")
+	defer span.End()
+	database := ar.cli.Database("accommodations-service")
+	accommodationCollection := database.Collection("accommodations")
+	outboxCollection := database.Collection("outbox")
+	accommodation.Rating = 0.0
+
+	session, err := ar.cli.StartSession()
+	if err != nil {
+		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Unable to start mongo session"))
+		ar.logger.LogError("accommodation-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError(err.Error(), 500)
+	}
+	defer session.EndSession(context.TODO())
+
+	_, err = session.WithTransaction(context.TODO(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		if _, err := accommodationCollection.InsertOne(sessionContext, accommodation); err != nil {
+			return nil, err
+		}
+		return outboxCollection.InsertOne(sessionContext, message)
+	})
+	if err != nil {
+		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Unable to save accommodation with outbox message"))
+		ar.logger.LogError("accommodation-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError(err.Error(), 500)
+	}
+	ar.logger.LogInfo("accommodation-repo", fmt.Sprintf("Accommodation %s inserted with outbox message", accommodation.Id.Hex()))
+	return &accommodation, nil
+}
+
 func (ar *AccommodationRepo) GetAccommodationById(ctx context.Context, id string) (*do.Accommodation, *errors.ErrorStruct) {
 	ctx, span := ar.tracer.Start(ctx, "AccommodationRepo.GetAccommodationById")
 	defer span.End()
@@ -54,6 +87,10 @@ func (ar *AccommodationRepo) GetAccommodationById(ctx context.Context, id string
 	var accommodation *do.Accommodation
 	accommId, _ := primitive.ObjectIDFromHex(id)
 	err := accommodationCollection.FindOne(context.TODO(), bson.M{"_id": accommId}).Decode(&accommodation)
+	if err == mongo.ErrNoDocuments {
+		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Accommodation with id %s does not exist", accommId))
+		return nil, errors.NewError("Accommodation not found", 404)
+	}
 	if err != nil {
 		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Failed to find one accommodation by id %s", accommId))
 		ar.logger.LogError("accommodation-repo", fmt.Sprintf("Error:"+err.Error()))
@@ -183,6 +220,9 @@ func (ar *AccommodationRepo) UpdateAccommodationById(ctx context.Context, accomm
 			{Key: "status", Value: accommodation.Status},
 		}},
 	}
+	if accommodation.CancellationPolicy != nil {
+		update[0].Value = append(update[0].Value.(bson.D), bson.E{Key: "cancellationPolicy", Value: accommodation.CancellationPolicy})
+	}
 
 	_, err := accommodationCollection.UpdateOne(context.TODO(), filter, update)
 	if err != nil {
@@ -205,6 +245,9 @@ func (ar *AccommodationRepo) UpdateAccommodationStatus(accommodation do.Accommod
 			{Key: "status", Value: accommodation.Status},
 		}},
 	}
+	if accommodation.CancellationPolicy != nil {
+		update[0].Value = append(update[0].Value.(bson.D), bson.E{Key: "cancellationPolicy", Value: accommodation.CancellationPolicy})
+	}
 
 	_, err := accommodationCollection.UpdateOne(context.TODO(), filter, update)
 	if err != nil {
@@ -281,6 +324,70 @@ func (ar *AccommodationRepo) DeleteAccommodationsByUserId(ctx context.Context, i
 	return nil
 }
 
+// ArchiveAccommodationsByUserId moves the accommodations of a user into the
+// archive collection and returns their ids.
+func (ar *AccommodationRepo) ArchiveAccommodationsByUserId(ctx context.Context, userId string) ([]string, *errors.ErrorStruct) {
+	ctx, span := ar.tracer.Start(ctx, "AccommodationRepo.ArchiveAccommodationsByUserId")
+	defer span.End()
+	return ar.moveAccommodationsByUserId(userId, "accommodations", "accommodations_archive")
+}
+
+func (ar *AccommodationRepo) RestoreAccommodationsByUserId(ctx context.Context, userId string) *errors.ErrorStruct {
+	ctx, span := ar.tracer.Start(ctx, "AccommodationRepo.RestoreAccommodationsByUserId")
+	defer span.End()
+	_, err := ar.moveAccommodationsByUserId(userId, "accommodations_archive", "accommodations")
+	return err
+}
+
+func (ar *AccommodationRepo) moveAccommodationsByUserId(userId, from, to string) ([]string, *errors.ErrorStruct) {
+	database := ar.cli.Database("accommodations-service")
+	fromCollection := database.Collection(from)
+	toCollection := database.Collection(to)
+	filter := bson.M{"userId": userId}
+
+	session, err := ar.cli.StartSession()
+	if err != nil {
+		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Unable to start mongo session"))
+		ar.logger.LogError("accommodation-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError(err.Error(), 500)
+	}
+	defer session.EndSession(context.TODO())
+
+	ids, err := session.WithTransaction(context.TODO(), func(sessionContext mongo.SessionContext) (interface{}, error) {
+		cursor, err := fromCollection.Find(sessionContext, filter)
+		if err != nil {
+			return nil, err
+		}
+		var accommodations []bson.M
+		if err := cursor.All(sessionContext, &accommodations); err != nil {
+			return nil, err
+		}
+		ids := []string{}
+		if len(accommodations) == 0 {
+			return ids, nil
+		}
+		documents := make([]interface{}, 0, len(accommodations))
+		for _, accommodation := range accommodations {
+			documents = append(documents, accommodation)
+			ids = append(ids, accommodation["_id"].(primitive.ObjectID).Hex())
+		}
+		if _, err := toCollection.InsertMany(sessionContext, documents); err != nil {
+			return nil, err
+		}
+		if _, err := fromCollection.DeleteMany(sessionContext, filter); err != nil {
+			return nil, err
+		}
+		return ids, nil
+	})
+	if err != nil {
+		ar.logger.LogError("accommodations-repo", fmt.Sprintf("Unable to move accommodations of user %s from %s to %s", userId, from, to))
+		ar.logger.LogError("accommodation-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError("Unable to move accommodations, database error", 500)
+	}
+	ar.logger.LogInfo("accommodation-repo", fmt.Sprintf("Moved accommodations of user %s from %s to %s", userId, from, to))
+	return ids.([]string), nil
+}
+
 func (ar *AccommodationRepo) SearchAccommodations(ctx context.Context, city, country string, numOfVisitors int, maxPrice int, conveniences []string) ([]do.Accommodation, *errors.ErrorStruct) {
 	ctx, span := ar.tracer.Start(ctx, "AccommodationRepo.SearchAccommodations")
 	defer span.End()
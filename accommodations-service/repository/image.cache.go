@@ -90,3 +90,13 @@ func (ic *ImageCache) GetAll(ctx context.Context, keys []string) ([][]byte, erro
 	ic.logger.Println("Cache hit")
 	return values, nil
 }
+
+func (ic *ImageCache) Delete(ctx context.Context, key string) error {
+	ctx, span := ic.tracer.Start(ctx, "ImageCache.Delete")
+	defer span.End()
+	err := ic.cli.Del(key).Err()
+	if err != nil {
+		ic.logger.Println("Error in deleting file from cache:", err)
+	}
+	return err
+}
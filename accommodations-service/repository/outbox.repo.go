@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"accommodations-service/config"
+	do "accommodations-service/domain"
+	"accommodations-service/errors"
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OutboxRepo struct {
+	cli    *mongo.Client
+	logger *config.Logger
+}
+
+func NewOutboxRepository(cli *mongo.Client, logger *config.Logger) *OutboxRepo {
+	return &OutboxRepo{
+		cli:    cli,
+		logger: logger,
+	}
+}
+
+func (or *OutboxRepo) FindPending(limit int64) ([]do.OutboxMessage, *errors.ErrorStruct) {
+	outboxCollection := or.cli.Database("accommodations-service").Collection("outbox")
+	findOptions := options.Find().SetSort(bson.D{{Key: "createdAt", Value: 1}}).SetLimit(limit)
+
+	cursor, err := outboxCollection.Find(context.TODO(), bson.M{"status": do.OutboxPending}, findOptions)
+	if err != nil {
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Unable to find pending outbox messages"))
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError("Unable to find outbox messages, database error", 500)
+	}
+	defer cursor.Close(context.TODO())
+
+	var messages []do.OutboxMessage
+	if err := cursor.All(context.TODO(), &messages); err != nil {
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Unable to decode outbox messages"))
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Error:"+err.Error()))
+		return nil, errors.NewError("Unable to decode outbox messages", 500)
+	}
+	return messages, nil
+}
+
+func (or *OutboxRepo) MarkSent(id primitive.ObjectID) *errors.ErrorStruct {
+	outboxCollection := or.cli.Database("accommodations-service").Collection("outbox")
+	filter := bson.D{{Key: "_id", Value: id}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "status", Value: do.OutboxSent},
+			{Key: "sentAt", Value: time.Now()},
+		}},
+	}
+
+	_, err := outboxCollection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Unable to mark outbox message %s as sent", id.Hex()))
+		or.logger.LogError("outbox-repo", fmt.Sprintf("Error:"+err.Error()))
+		return errors.NewError("Unable to update outbox message, database error", 500)
+	}
+	or.logger.LogInfo("outbox-repo", fmt.Sprintf("Outbox message %s marked as sent", id.Hex()))
+	return nil
+}
@@ -9,7 +9,7 @@ import (
 	"accommodations-service/repository"
 	"accommodations-service/utils"
 	"context"
-	events "example/saga/create_accommodation"
+	"example/saga/engine"
 	"fmt"
 	"log"
 	"mime/multipart"
@@ -17,6 +17,8 @@ import (
 
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -25,21 +27,25 @@ type AccommodationService struct {
 	validator               *utils.Validator
 	reservationsClient      *client.ReservationsClient
 	userClient              *client.UserClient
+	notificationsClient     *client.NotificationsClient
 	fileStorage             *repository.FileStorage
 	cache                   *repository.ImageCache
+	outboxRelay             *OutboxRelay
 	orchestrator            *orchestrator.CreateAccommodationOrchestrator
 	tracer                  trace.Tracer
 	logger                  *config.Logger
 }
 
-func NewAccommodationService(accommodationRepo *repository.AccommodationRepo, validator *utils.Validator, reservationsClient *client.ReservationsClient, userClient *client.UserClient, fileStorage *repository.FileStorage, cache *repository.ImageCache, orchestrator *orchestrator.CreateAccommodationOrchestrator, tracer trace.Tracer, logger *config.Logger) *AccommodationService {
+func NewAccommodationService(accommodationRepo *repository.AccommodationRepo, validator *utils.Validator, reservationsClient *client.ReservationsClient, userClient *client.UserClient, notificationsClient *client.NotificationsClient, fileStorage *repository.FileStorage, cache *repository.ImageCache, outboxRelay *OutboxRelay, orchestrator *orchestrator.CreateAccommodationOrchestrator, tracer trace.Tracer, logger *config.Logger) *AccommodationService {
 	return &AccommodationService{
 		accommodationRepository: accommodationRepo,
 		validator:               validator,
 		reservationsClient:      reservationsClient,
 		userClient:              userClient,
+		notificationsClient:     notificationsClient,
 		fileStorage:             fileStorage,
 		cache:                   cache,
+		outboxRelay:             outboxRelay,
 		orchestrator:            orchestrator,
 		tracer:                  tracer,
 		logger:                  logger,
@@ -51,17 +57,18 @@ func (as *AccommodationService) CreateAccommodation(accommodation domain.CreateA
 	defer span.End()
 	var imageIds []string
 	accomm := domain.Accommodation{
-		Name:             accommodation.Name,
-		Address:          accommodation.Address,
-		City:             accommodation.City,
-		Country:          accommodation.Country,
-		UserName:         accommodation.UserName,
-		UserId:           accommodation.UserId,
-		Email:            accommodation.Email,
-		Conveniences:     accommodation.Conveniences,
-		MinNumOfVisitors: accommodation.MinNumOfVisitors,
-		MaxNumOfVisitors: accommodation.MaxNumOfVisitors,
-		Paying:           accommodation.Paying,
+		Name:               accommodation.Name,
+		Address:            accommodation.Address,
+		City:               accommodation.City,
+		Country:            accommodation.Country,
+		UserName:           accommodation.UserName,
+		UserId:             accommodation.UserId,
+		Email:              accommodation.Email,
+		Conveniences:       accommodation.Conveniences,
+		MinNumOfVisitors:   accommodation.MinNumOfVisitors,
+		MaxNumOfVisitors:   accommodation.MaxNumOfVisitors,
+		Paying:             accommodation.Paying,
+		CancellationPolicy: accommodation.CancellationPolicy,
 	}
 	as.validator.ValidateAccommodation(&accomm)
 	//as.validator.ValidateAvailabilities(&accommodation)
@@ -86,63 +93,141 @@ func (as *AccommodationService) CreateAccommodation(accommodation domain.CreateA
 	}
 	accomm.ImageIds = imageIds
 	accomm.Status = "Pending"
-	newAccommodation, foundErr := as.accommodationRepository.SaveAccommodation(ctx, accomm)
+	accomm.Id = primitive.NewObjectID()
+	id := accomm.Id.Hex()
+
+	//err := as.reservationsClient.SendCreatedReservationsAvailabilities(ctx, id, accommodation)
+	message := domain.OutboxMessage{
+		Id:          primitive.NewObjectID(),
+		AggregateId: id,
+		Payload: domain.SendCreateAccommodationAvailability{
+			AccommodationID:    id,
+			Location:           accommodation.Location,
+			DateRange:          accommodation.AvailableAccommodationDates,
+			CancellationPolicy: accommodation.CancellationPolicy,
+			Paying:             accommodation.Paying,
+			HostID:             accommodation.UserId,
+		},
+		Status:       domain.OutboxPending,
+		CreatedAt:    time.Now(),
+		TraceContext: propagation.MapCarrier{},
+	}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.MapCarrier(message.TraceContext))
+	newAccommodation, foundErr := as.accommodationRepository.SaveAccommodationWithOutbox(ctx, accomm, message)
 	if foundErr != nil {
 		as.logger.LogError("accommodation-service", fmt.Sprintf("Error saving accommodation"))
 		as.logger.LogError("accommodation-service", fmt.Sprintf("Error:"+foundErr.GetErrorMessage()))
 		return nil, foundErr
 	}
 	as.logger.LogInfo("accommodation-service", "New accommodation created with id "+newAccommodation.Id.Hex())
-	id := newAccommodation.Id.Hex()
+	as.outboxRelay.Notify()
 
-	//err := as.reservationsClient.SendCreatedReservationsAvailabilities(ctx, id, accommodation)
-	reqData := domain.SendCreateAccommodationAvailability{
-		AccommodationID: id,
-		Location:        accommodation.Location,
-		DateRange:       accommodation.AvailableAccommodationDates,
-	}
-	var eventsDateRangeCasted []events.AvailableAccommodationDates
-	for _, value := range reqData.DateRange {
-		val := events.AvailableAccommodationDates{
-			AccommodationId: value.AccommodationId,
-			Location:        value.Location,
-			DateRange:       value.DateRange,
-			Price:           value.Price,
-		}
-		eventsDateRangeCasted = append(eventsDateRangeCasted, val)
-	}
+	return &domain.AccommodationDTO{
+		Id:                 id,
+		Name:               accommodation.Name,
+		UserName:           accommodation.UserName,
+		UserId:             accommodation.UserId,
+		Email:              accommodation.Email,
+		Address:            accommodation.Address,
+		City:               accommodation.City,
+		Country:            accommodation.Country,
+		Conveniences:       accommodation.Conveniences,
+		MinNumOfVisitors:   accommodation.MinNumOfVisitors,
+		MaxNumOfVisitors:   accommodation.MaxNumOfVisitors,
+		ImageIds:           imageIds,
+		Status:             accommodation.Status,
+		Paying:             accommodation.Paying,
+		CancellationPolicy: domain.PolicyOrDefault(accommodation.CancellationPolicy),
+	}, nil
+}
 
-	reqDataCasted := events.SendCreateAccommodationAvailability{
-		AccommodationID: reqData.AccommodationID,
-		Location:        reqData.Location,
-		DateRange:       eventsDateRangeCasted,
+// GetCreationStatus reports the progress of the saga that creates the accommodation
+// to the host that created it.
+func (as *AccommodationService) GetCreationStatus(ctx context.Context, accommodationID string, hostID string) (*domain.CreationStatusDTO, *errors.ErrorStruct) {
+	ctx, span := as.tracer.Start(ctx, "AccommodationService.GetCreationStatus")
+	defer span.End()
+
+	instance, err := as.orchestrator.Status(accommodationID)
+	if err == engine.ErrNotFound {
+		accommodation, foundErr := as.accommodationRepository.GetAccommodationById(ctx, accommodationID)
+		if foundErr != nil {
+			return nil, foundErr
+		}
+		if accommodation.UserId != hostID {
+			return nil, errors.NewError("Accommodation does not belong to the host", 403)
+		}
+		// Accommodations created before sagas were persisted have no saga to report on.
+		outcome := domain.CreationQueued
+		if accommodation.Status != string(domain.Pending) {
+			outcome = domain.CreationApproved
+		}
+		return &domain.CreationStatusDTO{
+			AccommodationId: accommodationID,
+			Outcome:         outcome,
+			History:         []domain.CreationStepDTO{},
+			Replies:         []string{},
+			Compensations:   []string{},
+		}, nil
 	}
-	err := as.orchestrator.Start(&reqDataCasted)
 	if err != nil {
-		as.logger.LogError("accommodation-service", fmt.Sprintf("Error in starting orchestrator"))
+		as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to get creation saga of accommodation %s", accommodationID))
 		as.logger.LogError("accommodation-service", fmt.Sprintf("Error:"+err.Error()))
-		as.DeleteAccommodation(ctx, id)
-		as.logger.LogInfo("accommodation-service", "Accommodation with id"+newAccommodation.Id.Hex()+"deleted")
-
-		return nil, errors.NewError("Service is not responding correctly", 500)
+		return nil, errors.NewError("Unable to retrieve creation status", 500)
+	}
+	owner := as.orchestrator.HostID(instance)
+	if owner == "" {
+		// A rolled back accommodation is gone, so its older sagas can no longer be
+		// matched to a host.
+		accommodation, foundErr := as.accommodationRepository.GetAccommodationById(ctx, accommodationID)
+		if foundErr != nil {
+			return nil, foundErr
+		}
+		owner = accommodation.UserId
+	}
+	if owner != hostID {
+		return nil, errors.NewError("Accommodation does not belong to the host", 403)
+	}
+
+	status := domain.CreationStatusDTO{
+		AccommodationId: accommodationID,
+		SagaState:       string(instance.State),
+		CurrentStep:     as.orchestrator.StepName(instance),
+		History:         []domain.CreationStepDTO{},
+		Replies:         []string{},
+		Compensations:   append([]string{}, instance.Compensations...),
+		FailureReason:   instance.FailureReason,
+		StartedAt:       instance.CreatedAt,
+		UpdatedAt:       instance.UpdatedAt,
+	}
+	timedOut := false
+	for _, record := range instance.History {
+		status.History = append(status.History, domain.CreationStepDTO{
+			Step:   record.Step,
+			Kind:   string(record.Kind),
+			Detail: record.Detail,
+			At:     record.At,
+		})
+		switch record.Kind {
+		case engine.ReplyReceived:
+			status.Replies = append(status.Replies, record.Detail)
+		case engine.StepTimedOut:
+			timedOut = true
+		}
 	}
-
-	return &domain.AccommodationDTO{
-		Id:               id,
-		Name:             accommodation.Name,
-		UserName:         accommodation.UserName,
-		UserId:           accommodation.UserId,
-		Email:            accommodation.Email,
-		Address:          accommodation.Address,
-		City:             accommodation.City,
-		Country:          accommodation.Country,
-		Conveniences:     accommodation.Conveniences,
-		MinNumOfVisitors: accommodation.MinNumOfVisitors,
-		MaxNumOfVisitors: accommodation.MaxNumOfVisitors,
-		ImageIds:         imageIds,
-		Status:           accommodation.Status,
-		Paying:           accommodation.Paying,
-	}, nil
+	switch instance.State {
+	case engine.Running:
+		status.Outcome = domain.CreationInProgress
+	case engine.Compensating:
+		status.Outcome = domain.CreationRollingBack
+	case engine.Completed:
+		status.Outcome = domain.CreationApproved
+	case engine.Compensated:
+		status.Outcome = domain.CreationFailed
+		if timedOut {
+			status.Outcome = domain.CreationTimedOut
+		}
+	}
+	return &status, nil
 }
 
 func (as *AccommodationService) GetImage(ctx context.Context, id string) ([]byte, *errors.ErrorStruct) {
@@ -184,21 +269,22 @@ func (as *AccommodationService) GetAllAccommodations(ctx context.Context) ([]*do
 		imageIds := accommodation.ImageIds
 
 		domainAccommodations = append(domainAccommodations, &domain.AccommodationDTO{
-			Id:               id,
-			Name:             accommodation.Name,
-			UserName:         accommodation.UserName,
-			UserId:           accommodation.UserId,
-			Email:            accommodation.Email,
-			Address:          accommodation.Address,
-			City:             accommodation.City,
-			Country:          accommodation.Country,
-			Conveniences:     accommodation.Conveniences,
-			MinNumOfVisitors: accommodation.MinNumOfVisitors,
-			MaxNumOfVisitors: accommodation.MaxNumOfVisitors,
-			ImageIds:         imageIds,
-			Rating:           accommodation.Rating,
-			Status:           accommodation.Status,
-			Paying:           accommodation.Paying,
+			Id:                 id,
+			Name:               accommodation.Name,
+			UserName:           accommodation.UserName,
+			UserId:             accommodation.UserId,
+			Email:              accommodation.Email,
+			Address:            accommodation.Address,
+			City:               accommodation.City,
+			Country:            accommodation.Country,
+			Conveniences:       accommodation.Conveniences,
+			MinNumOfVisitors:   accommodation.MinNumOfVisitors,
+			MaxNumOfVisitors:   accommodation.MaxNumOfVisitors,
+			ImageIds:           imageIds,
+			Rating:             accommodation.Rating,
+			Status:             accommodation.Status,
+			Paying:             accommodation.Paying,
+			CancellationPolicy: domain.PolicyOrDefault(accommodation.CancellationPolicy),
 		})
 	}
 	as.logger.LogInfo("accommodation-service", "Successfully retrieved all available accommodations")
@@ -215,22 +301,24 @@ func (as *AccommodationService) GetAccommodationById(ctx context.Context, accomm
 		return nil, err
 	}
 	id, _ := accomm.Id.MarshalJSON()
+	cancellationPolicy := domain.PolicyOrDefault(accomm.CancellationPolicy)
 	as.logger.LogInfo("accommodation-service", "Successfully retrieved accommodation with id"+accommodationId)
 	return &domain.Accommodation{
-		Id:               primitive.ObjectID(id),
-		Name:             accomm.Name,
-		UserName:         accomm.UserName,
-		UserId:           accomm.UserId,
-		Email:            accomm.Email,
-		Address:          accomm.Address,
-		City:             accomm.City,
-		Country:          accomm.Country,
-		Conveniences:     accomm.Conveniences,
-		MinNumOfVisitors: accomm.MinNumOfVisitors,
-		MaxNumOfVisitors: accomm.MaxNumOfVisitors,
-		ImageIds:         accomm.ImageIds,
-		Status:           accomm.Status,
-		Paying:           accomm.Paying,
+		Id:                 primitive.ObjectID(id),
+		Name:               accomm.Name,
+		UserName:           accomm.UserName,
+		UserId:             accomm.UserId,
+		Email:              accomm.Email,
+		Address:            accomm.Address,
+		City:               accomm.City,
+		Country:            accomm.Country,
+		Conveniences:       accomm.Conveniences,
+		MinNumOfVisitors:   accomm.MinNumOfVisitors,
+		MaxNumOfVisitors:   accomm.MaxNumOfVisitors,
+		ImageIds:           accomm.ImageIds,
+		Status:             accomm.Status,
+		Paying:             accomm.Paying,
+		CancellationPolicy: &cancellationPolicy,
 	}, nil
 
 }
@@ -250,21 +338,22 @@ func (as *AccommodationService) FindAccommodationByIds(ctx context.Context, ids
 		imageIds := accommodation.ImageIds
 		id := accommodation.Id.Hex()
 		domainAccommodations = append(domainAccommodations, &domain.AccommodationDTO{
-			Id:               id,
-			Name:             accommodation.Name,
-			UserName:         accommodation.UserName,
-			UserId:           accommodation.UserId,
-			Email:            accommodation.Email,
-			Address:          accommodation.Address,
-			City:             accommodation.City,
-			Country:          accommodation.Country,
-			Conveniences:     accommodation.Conveniences,
-			MinNumOfVisitors: accommodation.MinNumOfVisitors,
-			MaxNumOfVisitors: accommodation.MaxNumOfVisitors,
-			ImageIds:         imageIds,
-			Rating:           accommodation.Rating,
-			Status:           accommodation.Status,
-			Paying:           accommodation.Paying,
+			Id:                 id,
+			Name:               accommodation.Name,
+			UserName:           accommodation.UserName,
+			UserId:             accommodation.UserId,
+			Email:              accommodation.Email,
+			Address:            accommodation.Address,
+			City:               accommodation.City,
+			Country:            accommodation.Country,
+			Conveniences:       accommodation.Conveniences,
+			MinNumOfVisitors:   accommodation.MinNumOfVisitors,
+			MaxNumOfVisitors:   accommodation.MaxNumOfVisitors,
+			ImageIds:           imageIds,
+			Rating:             accommodation.Rating,
+			Status:             accommodation.Status,
+			Paying:             accommodation.Paying,
+			CancellationPolicy: domain.PolicyOrDefault(accommodation.CancellationPolicy),
 		})
 	}
 	as.logger.LogInfo("accommodation-service", "Successfully retrieved accommodations with multiple ids")
@@ -272,7 +361,10 @@ func (as *AccommodationService) FindAccommodationByIds(ctx context.Context, ids
 
 }
 
-func (as *AccommodationService) UpdateAccommodation(ctx context.Context, updatedAccommodation domain.Accommodation) (*domain.Accommodation, *errors.ErrorStruct) {
+// UpdateAccommodation saves the changes of the host. A changed cancellation policy
+// is handed to the reservations service first, with the token of the host, so
+// cancellations never use a policy the host was not shown.
+func (as *AccommodationService) UpdateAccommodation(ctx context.Context, updatedAccommodation domain.Accommodation, authorization string) (*domain.Accommodation, *errors.ErrorStruct) {
 	ctx, span := as.tracer.Start(ctx, "AccommodationService.UpdateAccommodation")
 	defer span.End()
 	as.validator.ValidateAccommodation(&updatedAccommodation)
@@ -287,6 +379,14 @@ func (as *AccommodationService) UpdateAccommodation(ctx context.Context, updated
 		return nil, errors.NewError(constructedError, 400)
 	}
 
+	if updatedAccommodation.CancellationPolicy != nil {
+		if err := as.reservationsClient.SetCancellationPolicy(ctx, authorization, updatedAccommodation.Id.Hex(), *updatedAccommodation.CancellationPolicy); err != nil {
+			as.logger.LogError("accommodations-service", fmt.Sprintf("Unable to update cancellation policy"))
+			as.logger.LogError("accommodation-service", fmt.Sprintf("Error:"+err.GetErrorMessage()))
+			return nil, err
+		}
+	}
+
 	log.Println("Prije update")
 	_, updateErr := as.accommodationRepository.UpdateAccommodationById(ctx, updatedAccommodation)
 	if updateErr != nil {
@@ -297,19 +397,20 @@ func (as *AccommodationService) UpdateAccommodation(ctx context.Context, updated
 	log.Println("Poslije update")
 	as.logger.LogInfo("accommodation-service", "Successfully updated accommodation")
 	return &domain.Accommodation{
-		Id:               updatedAccommodation.Id,
-		Name:             updatedAccommodation.Name,
-		UserName:         updatedAccommodation.UserName,
-		UserId:           updatedAccommodation.UserId,
-		Email:            updatedAccommodation.Email,
-		Address:          updatedAccommodation.Address,
-		City:             updatedAccommodation.City,
-		Country:          updatedAccommodation.Country,
-		Conveniences:     updatedAccommodation.Conveniences,
-		MinNumOfVisitors: updatedAccommodation.MinNumOfVisitors,
-		MaxNumOfVisitors: updatedAccommodation.MaxNumOfVisitors,
-		Status:           updatedAccommodation.Status,
-		Paying:           updatedAccommodation.Paying,
+		Id:                 updatedAccommodation.Id,
+		Name:               updatedAccommodation.Name,
+		UserName:           updatedAccommodation.UserName,
+		UserId:             updatedAccommodation.UserId,
+		Email:              updatedAccommodation.Email,
+		Address:            updatedAccommodation.Address,
+		City:               updatedAccommodation.City,
+		Country:            updatedAccommodation.Country,
+		Conveniences:       updatedAccommodation.Conveniences,
+		MinNumOfVisitors:   updatedAccommodation.MinNumOfVisitors,
+		MaxNumOfVisitors:   updatedAccommodation.MaxNumOfVisitors,
+		Status:             updatedAccommodation.Status,
+		Paying:             updatedAccommodation.Paying,
+		CancellationPolicy: updatedAccommodation.CancellationPolicy,
 	}, nil
 }
 
@@ -352,6 +453,35 @@ func (as *AccommodationService) DeleteAccommodationsByUserId(ctx context.Context
 
 	return nil
 }
+
+// ArchiveAccommodationsByUserId hides the accommodations of a user that is being
+// deleted; RestoreAccommodationsByUserId undoes it if the deletion fails.
+func (as *AccommodationService) ArchiveAccommodationsByUserId(ctx context.Context, userID string) ([]string, *errors.ErrorStruct) {
+	ctx, span := as.tracer.Start(ctx, "AccommodationService.ArchiveAccommodationsByUserId")
+	defer span.End()
+
+	ids, err := as.accommodationRepository.ArchiveAccommodationsByUserId(ctx, userID)
+	if err != nil {
+		as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to archive accommodations of user %s", userID))
+		return nil, err
+	}
+	as.logger.LogInfo("accommodation-service", fmt.Sprintf("Archived %d accommodations of user %s", len(ids), userID))
+	return ids, nil
+}
+
+func (as *AccommodationService) RestoreAccommodationsByUserId(ctx context.Context, userID string) *errors.ErrorStruct {
+	ctx, span := as.tracer.Start(ctx, "AccommodationService.RestoreAccommodationsByUserId")
+	defer span.End()
+
+	err := as.accommodationRepository.RestoreAccommodationsByUserId(ctx, userID)
+	if err != nil {
+		as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to restore accommodations of user %s", userID))
+		return err
+	}
+	as.logger.LogInfo("accommodation-service", fmt.Sprintf("Restored accommodations of user %s", userID))
+	return nil
+}
+
 func (as *AccommodationService) PutAccommodationRating(ctx context.Context, accommodationID string, accommodation domain.Accommodation) *errors.ErrorStruct {
 	ctx, span := as.tracer.Start(ctx, "AccommodationService.PutAccommodationRating")
 	defer span.End()
@@ -366,7 +496,7 @@ func (as *AccommodationService) PutAccommodationRating(ctx context.Context, acco
 	return nil
 }
 
-func (as *AccommodationService) SearchAccommodations(city, country string, numOfVisitors int, startDate string, endDate string, maxPrice int, conveniences []string, isDistinguishedString string, ctx context.Context) ([]domain.Accommodation, *errors.ErrorStruct) {
+func (as *AccommodationService) SearchAccommodations(city, country string, numOfVisitors int, startDate string, endDate string, maxPrice int, currency string, conveniences []string, isDistinguishedString string, ctx context.Context) ([]domain.Accommodation, *errors.ErrorStruct) {
 	ctx, span := as.tracer.Start(ctx, "AccommodationService.SearchAccommodations")
 	defer span.End()
 	log.Println("USLO U SERVIS")
@@ -484,7 +614,7 @@ func (as *AccommodationService) SearchAccommodations(city, country string, numOf
 	}
 
 	if startDate == "" && endDate == "" && isDistinguished == false && maxPrice != 0 {
-		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice)
+		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice, currency)
 		if err != nil {
 			as.logger.LogError("accommodations-service", fmt.Sprintf("Error getting accommodation below the price of %d", maxPrice))
 			as.logger.LogError("accommodation-service", fmt.Sprintf("Error:"+err.GetErrorMessage()))
@@ -534,7 +664,7 @@ func (as *AccommodationService) SearchAccommodations(city, country string, numOf
 			}
 		}
 
-		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice)
+		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice, currency)
 
 		if err != nil {
 			as.logger.LogError("accommodations-service", fmt.Sprintf("Error getting accommodation below price of %d", maxPrice))
@@ -570,7 +700,7 @@ func (as *AccommodationService) SearchAccommodations(city, country string, numOf
 			}
 		}
 
-		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice)
+		accBelowPrice, err := as.reservationsClient.GetAccommodationsBelowPrice(ctx, maxPrice, currency)
 		if err != nil {
 			as.logger.LogError("accommodations-service", fmt.Sprintf("Error getting accommodation below price of %d", maxPrice))
 			as.logger.LogError("accommodation-service", fmt.Sprintf("Error:"+err.GetErrorMessage()))
@@ -650,8 +780,15 @@ func generateDateRange(startDateStr, endDateStr string) ([]string, *errors.Error
 	return dates, nil
 }
 
+// ApproveAccommodation approves the accommodation while its saga waits for it. Once
+// the saga timed out and rolled the availability back, the accommodation stays
+// Pending and is rolled back too.
 func (as AccommodationService) ApproveAccommodation(id string) *errors.ErrorStruct {
 	log.Println("USLO DA POTVRDI AKOMODACIJU")
+	if !as.orchestrator.AwaitsApproval(id) {
+		as.logger.LogError("accommodations-service", fmt.Sprintf("Saga of accommodation %s no longer waits for its approval", id))
+		return errors.NewError("The creation of the accommodation was rolled back", 409)
+	}
 	acomm, err := as.GetAccommodationById(context.Background(), id)
 	if err != nil {
 		as.logger.LogError("accommodations-service", fmt.Sprintf("Failed to get accommodation by id in ApproveAccommodation func with id %s", id))
@@ -672,7 +809,52 @@ func (as AccommodationService) ApproveAccommodation(id string) *errors.ErrorStru
 
 func (as AccommodationService) DenyAccommodation(id string) error {
 	log.Println("DENY ACCOMMODATION")
-	as.DeleteAccommodation(context.Background(), id)
+	return as.removePendingAccommodation(context.Background(), id, "was denied")
+}
+
+// RollbackAccommodation compensates the create accommodation saga: the Pending
+// accommodation is removed together with its images and the host is told why.
+// Rolling back an accommodation that is already gone succeeds, so the command
+// may be delivered more than once.
+func (as AccommodationService) RollbackAccommodation(ctx context.Context, id string) error {
+	ctx, span := as.tracer.Start(ctx, "AccommodationService.RollbackAccommodation")
+	defer span.End()
+	return as.removePendingAccommodation(ctx, id, "could not be created, its availability was not saved")
+}
+
+func (as AccommodationService) removePendingAccommodation(ctx context.Context, id string, reason string) error {
+	accommodation, err := as.accommodationRepository.GetAccommodationById(ctx, id)
+	if err != nil {
+		if err.GetErrorStatus() == 404 {
+			as.logger.LogInfo("accommodation-service", fmt.Sprintf("Accommodation with id %s already removed", id))
+			return nil
+		}
+		return fmt.Errorf("%s", err.GetErrorMessage())
+	}
+	if accommodation.Status != string(domain.Pending) {
+		as.logger.LogInfo("accommodation-service", fmt.Sprintf("Accommodation with id %s is %s, not removing it", id, accommodation.Status))
+		return nil
+	}
+
+	for _, imageId := range accommodation.ImageIds {
+		if err := as.fileStorage.DeleteFile(ctx, imageId); err != nil {
+			as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to delete image %s of accommodation %s", imageId, id))
+		}
+		if err := as.cache.Delete(ctx, imageId); err != nil {
+			as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to delete cached image %s of accommodation %s", imageId, id))
+		}
+	}
+
+	deleteErr := as.accommodationRepository.DeleteAccommodationById(ctx, id)
+	if deleteErr != nil {
+		as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to delete accommodation with id %s", id))
+		return fmt.Errorf("%s", deleteErr.GetErrorMessage())
+	}
 	as.logger.LogInfo("accommodation-service", fmt.Sprintf("Accommodation with id %s deleted", id))
+
+	message := fmt.Sprintf("Your accommodation %s %s.", accommodation.Name, reason)
+	if notifyErr := as.notificationsClient.NotifyHost(ctx, accommodation.UserId, accommodation.Email, message); notifyErr != nil {
+		as.logger.LogError("accommodation-service", fmt.Sprintf("Unable to notify host %s about accommodation %s", accommodation.UserId, id))
+	}
 	return nil
 }
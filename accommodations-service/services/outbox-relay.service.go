@@ -0,0 +1,123 @@
+package services
+
+import (
+	"accommodations-service/config"
+	"accommodations-service/domain"
+	"accommodations-service/orchestrator"
+	"accommodations-service/repository"
+	"context"
+	events "example/saga/create_accommodation"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+)
+
+// outboxBatchSize caps how many outbox messages are relayed in one pass.
+const outboxBatchSize = 100
+
+// OutboxRelay starts the create accommodation saga for every outbox message that
+// was committed together with its accommodation. Start is idempotent, so a message
+// relayed twice after a crash only resends the current saga command.
+type OutboxRelay struct {
+	outboxRepository *repository.OutboxRepo
+	orchestrator     *orchestrator.CreateAccommodationOrchestrator
+	wake             chan struct{}
+	logger           *config.Logger
+}
+
+func NewOutboxRelay(outboxRepository *repository.OutboxRepo, orchestrator *orchestrator.CreateAccommodationOrchestrator, logger *config.Logger) *OutboxRelay {
+	return &OutboxRelay{
+		outboxRepository: outboxRepository,
+		orchestrator:     orchestrator,
+		wake:             make(chan struct{}, 1),
+		logger:           logger,
+	}
+}
+
+// Notify asks the relay to run now instead of waiting for the next poll.
+func (or *OutboxRelay) Notify() {
+	select {
+	case or.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (or *OutboxRelay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		or.relay()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-or.wake:
+		}
+	}
+}
+
+func (or *OutboxRelay) relay() {
+	messages, err := or.outboxRepository.FindPending(outboxBatchSize)
+	if err != nil {
+		or.logger.LogError("outbox-relay", fmt.Sprintf("Unable to load pending outbox messages"))
+		return
+	}
+	for _, message := range messages {
+		payload := castAvailability(message.Payload)
+		ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(message.TraceContext))
+		if err := or.orchestrator.Start(ctx, &payload); err != nil {
+			or.logger.LogError("outbox-relay", fmt.Sprintf("Unable to start saga for accommodation %s", message.AggregateId))
+			or.logger.LogError("outbox-relay", fmt.Sprintf("Error:"+err.Error()))
+			continue
+		}
+		// A message left pending is relayed again on the next pass, which only
+		// resends the current saga command.
+		if err := or.outboxRepository.MarkSent(message.Id); err != nil {
+			or.logger.LogError("outbox-relay", fmt.Sprintf("Unable to mark outbox message of accommodation %s as sent, it will be relayed again", message.AggregateId))
+		}
+	}
+}
+
+func castAvailability(reqData domain.SendCreateAccommodationAvailability) events.SendCreateAccommodationAvailability {
+	var eventsDateRangeCasted []events.AvailableAccommodationDates
+	for _, value := range reqData.DateRange {
+		val := events.AvailableAccommodationDates{
+			AccommodationId: value.AccommodationId,
+			Location:        value.Location,
+			DateRange:       value.DateRange,
+			Price:           value.Price,
+			Currency:        value.Currency,
+			Rules:           castStayRules(value.Rules),
+		}
+		eventsDateRangeCasted = append(eventsDateRangeCasted, val)
+	}
+
+	return events.SendCreateAccommodationAvailability{
+		AccommodationID:    reqData.AccommodationID,
+		Location:           reqData.Location,
+		DateRange:          eventsDateRangeCasted,
+		CancellationPolicy: castCancellationPolicy(reqData.CancellationPolicy),
+		Paying:             reqData.Paying,
+		HostID:             reqData.HostID,
+	}
+}
+
+func castCancellationPolicy(policy *domain.CancellationPolicy) *events.CancellationPolicy {
+	if policy == nil {
+		return nil
+	}
+	casted := &events.CancellationPolicy{Name: policy.Name}
+	for _, tier := range policy.Tiers {
+		casted.Tiers = append(casted.Tiers, events.RefundTier{DaysBeforeCheckIn: tier.DaysBeforeCheckIn, RefundPercent: tier.RefundPercent})
+	}
+	return casted
+}
+
+func castStayRules(rules *domain.StayRules) *events.StayRules {
+	if rules == nil {
+		return nil
+	}
+	casted := events.StayRules(*rules)
+	return &casted
+}
@@ -106,6 +106,8 @@ func (v *Validator) ValidateAccommodation(accommodation *domain.Accommodation) {
 	if accommodation.MinNumOfVisitors > accommodation.MaxNumOfVisitors {
 		v.Errors["MaxNumOfVisitors"] = "Minimum number can not exceed maximum!"
 	}
+	v.ValidateCancellationPolicy(accommodation.CancellationPolicy)
+	v.ValidatePaying(accommodation.Paying)
 
 	foundErrors := v.GetErrors()
 
@@ -116,6 +118,43 @@ func (v *Validator) ValidateAccommodation(accommodation *domain.Accommodation) {
 	}
 }
 
+var cancellationPolicies = map[string]bool{"flexible": true, "moderate": true, "strict": true, "custom": true}
+
+// ValidateCancellationPolicy checks the policy the host picked. The refund tiers of
+// the named policies belong to the reservations service, so only custom policies
+// may list tiers.
+func (v *Validator) ValidateCancellationPolicy(policy *domain.CancellationPolicy) {
+	delete(v.Errors, "CancellationPolicy")
+	if policy == nil {
+		return
+	}
+	if !cancellationPolicies[policy.Name] {
+		v.Errors["CancellationPolicy"] = "Cancellation policy must be flexible, moderate, strict or custom!"
+		return
+	}
+	if (policy.Name == "custom") != (len(policy.Tiers) > 0) {
+		v.Errors["CancellationPolicy"] = "Only a custom cancellation policy has refund tiers, and it needs at least one!"
+		return
+	}
+	for _, tier := range policy.Tiers {
+		if tier.DaysBeforeCheckIn < 0 || tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+			v.Errors["CancellationPolicy"] = "Refund tiers need a positive number of days and a refund between 0 and 100 percent!"
+			return
+		}
+	}
+}
+
+var payingModes = map[string]bool{"Per Accommodation": true, "Per Guest": true}
+
+// ValidatePaying checks how the accommodation is paid. Updates do not carry it, so
+// an empty value is left to the accommodation as stored.
+func (v *Validator) ValidatePaying(paying string) {
+	delete(v.Errors, "Paying")
+	if paying != "" && !payingModes[paying] {
+		v.Errors["Paying"] = "Paying must be Per Accommodation or Per Guest!"
+	}
+}
+
 func (v *Validator) ValidateAvailabilities(availabilities *domain.CreateAccommodation) {
 	layout := "2006-01-02" // Date layout format
 
@@ -9,6 +9,9 @@ type User struct {
 	Email     string             `json: "email" bson:"email"`
 	Role      string             `json: "role" bson:"role"`
 	Confirmed bool               `json: "confirmed" bson:"confirmed"`
+	// RegistrationPending is set until the register user saga created the user in
+	// every service, the user cannot log in before that.
+	RegistrationPending bool `json:"registrationPending" bson:"registrationPending"`
 }
 
 type UserDTO struct {
@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"auth-service/config"
+	"auth-service/services"
+	"context"
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+)
+
+type DeleteUserCommandHandler struct {
+	userService       *services.UserService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewDeleteUserCommandHandler(userService *services.UserService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		userService:       userService,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("auth-saga-handler", "Unable to subscribe to delete user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.DeleteUserReply{Payload: details}
+	switch command.Type {
+	case events.ArchiveUserAuth:
+		reply.Type = events.UserAuthArchived
+		if err := handler.userService.ArchiveUserById(context.Background(), details.UserID); err != nil {
+			handler.logger.LogError("auth-saga-handler", fmt.Sprintf("Unable to archive credentials of %s: %s", details.UserID, err.GetErrorMessage()))
+			reply.Type = events.UserAuthNotArchived
+		}
+	case events.RestoreUserAuth:
+		if err := handler.userService.RestoreUserById(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"auth-service/config"
+	"auth-service/services"
+	"context"
+	saga "example/saga/messaging"
+	events "example/saga/register_user"
+	"fmt"
+)
+
+// RegisterUserCommandHandler is the auth-service participant of the register user
+// saga: it completes the registration, or reopens it and removes the credentials
+// of a failed one.
+type RegisterUserCommandHandler struct {
+	userService       *services.UserService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewRegisterUserCommandHandler(userService *services.UserService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*RegisterUserCommandHandler, error) {
+	o := &RegisterUserCommandHandler{
+		userService:       userService,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("auth-saga-handler", "Unable to subscribe to register user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *RegisterUserCommandHandler) handle(command *events.RegisterUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.RegisterUserReply{Payload: details}
+	switch command.Type {
+	case events.CompleteRegistration:
+		reply.Type = events.RegistrationCompleted
+		if err := handler.userService.CompleteRegistration(context.Background(), details.UserID); err != nil {
+			if err.GetErrorStatus() >= 500 {
+				// Likely transient, let the command be redelivered.
+				return fmt.Errorf("%s", err.GetErrorMessage())
+			}
+			handler.logger.LogError("auth-saga-handler", fmt.Sprintf("Unable to complete registration of %s: %s", details.UserID, err.GetErrorMessage()))
+			reply.Type = events.RegistrationNotCompleted
+		}
+	case events.ReopenRegistration:
+		if err := handler.userService.ReopenRegistration(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	case events.RollbackUserCredentials:
+		if err := handler.userService.RollbackRegistration(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -5,12 +5,15 @@ import (
 	"auth-service/config"
 	"auth-service/handler"
 	"auth-service/middlewares"
+	"auth-service/orchestrator"
 	"auth-service/repository"
 	"auth-service/security"
 	"auth-service/services"
 	"auth-service/tracing"
 	"auth-service/utils"
 	"context"
+	"example/saga/messaging/nats"
+	"example/saga/store"
 	"net/http"
 	"os"
 	"os/signal"
@@ -39,8 +42,6 @@ func main() {
 	port := os.Getenv("PORT")
 	userServiceHost := os.Getenv("USER_SERVICE_HOST")
 	userServicePort := os.Getenv("USER_SERVICE_PORT")
-	notificationServiceHost := os.Getenv("NOTIFICATION_SERVICE_HOST")
-	notificationServicePort := os.Getenv("NOTIFICATION_SERVICE_PORT")
 	// clients
 
 	customHttpMailClient := &http.Client{Timeout: time.Second * 10}
@@ -66,28 +67,6 @@ func main() {
 		},
 	)
 	userClient := client.NewUserClient(userServiceHost, userServicePort, customUserServiceClient, userServiceCircuitBreaker)
-
-	customNotificationServiceClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			MaxIdleConnsPerHost: 10,
-			MaxConnsPerHost:     10,
-		},
-	}
-
-	notificationServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
-		gobreaker.Settings{
-			Name:        "notification-service",
-			MaxRequests: 1,
-			Timeout:     10 * time.Second,
-			Interval:    0,
-			OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-				log.Printf("Circuit Breaker %v: %v -> %v", name, from, to)
-			},
-		},
-	)
-
-	notificationClient := client.NewNotificationClient(notificationServiceHost, notificationServicePort, customNotificationServiceClient, notificationServiceCircuitBreaker)
 	// services
 	tracerConfig := tracing.GetConfig()
 	tracerProvider, err := tracing.NewTracerProvider("auth-service", tracerConfig.JaegerAddress)
@@ -112,12 +91,94 @@ func main() {
 	keyByte := []byte(jwtSecretKey)
 	jwtService := services.NewJWTService(keyByte)
 	encryptionService := &services.EncryptionService{SecretKey: secretKey}
-	userService := services.NewUserService(userRepo, passwordService, jwtService, validator, encryptionService, mailClient, userClient, notificationClient, tracer, logger)
+
+	// saga
+
+	registerUserCommandPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_COMMAND_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserReplySubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_REPLY_SUBJECT"),
+		"auth-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaStore := store.NewMongoStore(mongoService.GetCli(), "auth", "sagas")
+	registerUserOrchestrator, err := orchestrator.NewRegisterUserOrchestrator(registerUserCommandPublisher, registerUserReplySubscriber, sagaStore, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	userService := services.NewUserService(userRepo, passwordService, jwtService, validator, encryptionService, mailClient, userClient, registerUserOrchestrator, tracer, logger)
 	authHandler := handler.AuthHandler{
 		UserService: userService,
 		Tracer:      tracer,
 		Logger:      logger,
 	}
+	deleteUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deleteUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"auth-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewDeleteUserCommandHandler(userService, deleteUserReplyPublisher, deleteUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_COMMAND_SUBJECT"),
+		"auth-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewRegisterUserCommandHandler(userService, registerUserReplyPublisher, registerUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaContext, stopSagas := context.WithCancel(context.Background())
+	defer stopSagas()
+	err = registerUserOrchestrator.Resume(sagaContext)
+	if err != nil {
+		log.Println(err)
+	}
 	accessControl := security.NewAccessControl()
 	err = accessControl.LoadAccessConfig("./security/rbac.json")
 	if err != nil {
@@ -171,6 +232,7 @@ func main() {
 	if server.Shutdown(timeoutContext) != nil {
 		logger.Fatalf("Cannot gracefully shutdown...")
 	}
+	nats.Drain()
 	logger.Println("Server stopped")
 
 }
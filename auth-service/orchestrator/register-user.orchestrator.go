@@ -0,0 +1,135 @@
+package orchestrator
+
+import (
+	"auth-service/config"
+	"context"
+	"example/saga/engine"
+	saga "example/saga/messaging"
+	events "example/saga/register_user"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+)
+
+const (
+	registerUserSaga         = "register-user"
+	saveCredentialsStep      = "save-credentials"
+	createProfileStep        = "create-profile"
+	createInboxStep          = "create-inbox"
+	completeRegistrationStep = "complete-registration"
+	stepTimeout              = 30 * time.Second
+	timeoutCheckInterval     = 5 * time.Second
+	source                   = "register-user-orchestrator"
+)
+
+type RegisterUserOrchestrator struct {
+	sagas           *engine.Orchestrator
+	replySubscriber saga.Subscriber
+	logger          *config.Logger
+}
+
+func NewRegisterUserOrchestrator(publisher saga.Publisher, replySubscriber saga.Subscriber, store engine.Store, logger *config.Logger) (*RegisterUserOrchestrator, error) {
+	orchestrator := &RegisterUserOrchestrator{
+		sagas:           engine.NewOrchestrator(registerUserDefinition(), publisher, store),
+		replySubscriber: replySubscriber,
+		logger:          logger,
+	}
+	err := orchestrator.replySubscriber.Subscribe(orchestrator.handle)
+	if err != nil {
+		logger.LogError(source, "Unable to subscribe to register user replies")
+		return nil, err
+	}
+	return orchestrator, nil
+}
+
+// registerUserDefinition describes the saga: the credentials are saved locally as
+// pending before the saga starts, user-service creates the profile,
+// notifications-service creates the inbox and auth-service finally lets the user
+// log in. A failed step removes everything created before it; a registration
+// completed after its step timed out is reopened first, so the credentials are
+// pending again when they are removed.
+func registerUserDefinition() engine.Definition {
+	return engine.Definition{
+		Name: registerUserSaga,
+		Steps: []engine.Step{
+			{
+				Name:         saveCredentialsStep,
+				Compensation: commandOf(events.RollbackUserCredentials),
+			},
+			{
+				Name:         createProfileStep,
+				Command:      commandOf(events.CreateUserProfile),
+				Compensation: commandOf(events.RemoveUserProfile),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         createInboxStep,
+				Command:      commandOf(events.CreateUserInbox),
+				Compensation: commandOf(events.RemoveUserInbox),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         completeRegistrationStep,
+				Command:      commandOf(events.CompleteRegistration),
+				Compensation: commandOf(events.ReopenRegistration),
+				Timeout:      stepTimeout,
+			},
+		},
+	}
+}
+
+func commandOf(commandType events.RegisterUserCommandType) engine.CommandBuilder {
+	return func(instance *engine.Instance) (interface{}, error) {
+		var details events.RegisterUserDetails
+		if err := instance.Decode(&details); err != nil {
+			return nil, err
+		}
+		return &events.RegisterUserCommand{
+			Type:    commandType,
+			Payload: details,
+		}, nil
+	}
+}
+
+func (ruo *RegisterUserOrchestrator) Start(ctx context.Context, details *events.RegisterUserDetails) error {
+	ruo.logger.LogInfo(source, fmt.Sprintf("Starting register user saga for user %s", details.UserID))
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+	return ruo.sagas.StartTraced(details.UserID, details, traceContext)
+}
+
+// Resume restarts sagas left in flight by a previous run and starts watching step timeouts.
+func (ruo *RegisterUserOrchestrator) Resume(ctx context.Context) error {
+	err := ruo.sagas.Resume()
+	if err != nil {
+		ruo.logger.LogError(source, "Unable to resume sagas: "+err.Error())
+		return err
+	}
+	go ruo.sagas.WatchTimeouts(ctx, timeoutCheckInterval)
+	return nil
+}
+
+func (ruo *RegisterUserOrchestrator) handle(reply *events.RegisterUserReply) {
+	id := reply.Payload.UserID
+	var err error
+	switch reply.Type {
+	case events.UserProfileCreated:
+		err = ruo.sagas.StepSucceeded(id, createProfileStep, "UserProfileCreated")
+	case events.UserProfileNotCreated:
+		err = ruo.sagas.StepFailed(id, createProfileStep, "UserProfileNotCreated")
+	case events.UserInboxCreated:
+		err = ruo.sagas.StepSucceeded(id, createInboxStep, "UserInboxCreated")
+	case events.UserInboxNotCreated:
+		err = ruo.sagas.StepFailed(id, createInboxStep, "UserInboxNotCreated")
+	case events.RegistrationCompleted:
+		err = ruo.sagas.StepSucceeded(id, completeRegistrationStep, "RegistrationCompleted")
+	case events.RegistrationNotCompleted:
+		err = ruo.sagas.StepFailed(id, completeRegistrationStep, "RegistrationNotCompleted")
+	default:
+		return
+	}
+	if err != nil {
+		ruo.logger.LogError(source, fmt.Sprintf("Unable to handle reply for register user saga %s: %v", id, err))
+	}
+}
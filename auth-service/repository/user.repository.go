@@ -11,6 +11,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -237,3 +238,128 @@ func (u UserRepository) DeleteUserById(ctx context.Context, id string) (*domains
 	}
 	return user, nil
 }
+
+// DeletePendingUser removes the credentials of a user whose registration failed.
+// Users that finished registering are never touched.
+func (u UserRepository) DeletePendingUser(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserRepository.DeletePendingUser")
+	defer span.End()
+	userCollection := u.cli.Database("auth").Collection("user")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 400)
+	}
+	filter := bson.M{"_id": objectID, "registrationPending": true}
+	result, err := userCollection.DeleteOne(context.TODO(), filter)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	u.logger.LogInfo("auth-db", fmt.Sprintf("Deleted %d pending users with ID %v", result.DeletedCount, id))
+	return nil
+}
+
+// CompleteRegistration clears the pending flag of a user. It reports false when
+// the flag was already cleared, so a redelivered command does nothing twice.
+func (u UserRepository) CompleteRegistration(ctx context.Context, id string) (bool, *errors.ErrorStruct) {
+	ctx, span := u.tracer.Start(ctx, "UserRepository.CompleteRegistration")
+	defer span.End()
+	userCollection := u.cli.Database("auth").Collection("user")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return false, errors.NewError(err.Error(), 400)
+	}
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "registrationPending", Value: false},
+		}},
+	}
+	updateResult, err := userCollection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return false, errors.NewError(err.Error(), 500)
+	}
+	if updateResult.MatchedCount == 0 {
+		u.logger.LogError("auth-db", fmt.Sprintf("User %v to complete registration for not found", id))
+		return false, errors.NewError("User not found", 404)
+	}
+	u.logger.LogInfo("auth-db", fmt.Sprintf("Registration of user %v completed", id))
+	return updateResult.ModifiedCount > 0, nil
+}
+
+// ReopenRegistration sets the pending flag of a user again. A user that is already
+// gone is left alone, so the command may be delivered more than once.
+func (u UserRepository) ReopenRegistration(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserRepository.ReopenRegistration")
+	defer span.End()
+	userCollection := u.cli.Database("auth").Collection("user")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 400)
+	}
+	filter := bson.D{{Key: "_id", Value: objectID}}
+	update := bson.D{
+		{Key: "$set", Value: bson.D{
+			{Key: "registrationPending", Value: true},
+		}},
+	}
+	updateResult, err := userCollection.UpdateOne(context.TODO(), filter, update)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	u.logger.LogInfo("auth-db", fmt.Sprintf("Reopened registration of %d users with ID %v", updateResult.ModifiedCount, id))
+	return nil
+}
+
+// ArchiveUserById moves the credentials of a user being deleted out of the user
+// collection, so they can no longer log in but can be restored.
+func (u UserRepository) ArchiveUserById(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserRepository.ArchiveUserById")
+	defer span.End()
+	return u.moveUser(id, "user", "archived_user")
+}
+
+func (u UserRepository) RestoreUserById(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserRepository.RestoreUserById")
+	defer span.End()
+	return u.moveUser(id, "archived_user", "user")
+}
+
+// moveUser copies before it deletes, so running it again after a crash in between
+// completes the move.
+func (u UserRepository) moveUser(id, from, to string) *errors.ErrorStruct {
+	database := u.cli.Database("auth")
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 400)
+	}
+	filter := bson.M{"_id": objectID}
+	var user bson.M
+	err = database.Collection(from).FindOne(context.TODO(), filter).Decode(&user)
+	if err == mongo.ErrNoDocuments {
+		u.logger.LogInfo("auth-db", fmt.Sprintf("User %v not found in %s, nothing to move", id, from))
+		return nil
+	}
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	_, err = database.Collection(to).ReplaceOne(context.TODO(), filter, user, options.Replace().SetUpsert(true))
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	_, err = database.Collection(from).DeleteOne(context.TODO(), filter)
+	if err != nil {
+		u.logger.LogError("auth-db", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	u.logger.LogInfo("auth-db", fmt.Sprintf("User %v moved from %s to %s", id, from, to))
+	return nil
+}
@@ -5,9 +5,11 @@ import (
 	"auth-service/config"
 	"auth-service/domains"
 	"auth-service/errors"
+	"auth-service/orchestrator"
 	"auth-service/repository"
 	"auth-service/utils"
 	"context"
+	events "example/saga/register_user"
 	"fmt"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,16 +17,16 @@ import (
 )
 
 type UserService struct {
-	userRepository     *repository.UserRepository
-	passwordService    *PasswordService
-	jwtService         *JwtService
-	validator          *utils.Validator
-	encryptionService  *EncryptionService
-	mailClient         client.MailClientInterface
-	userClient         *client.UserClient
-	notificationClient *client.NotificationClient
-	tracer             trace.Tracer
-	logger             *config.Logger
+	userRepository    *repository.UserRepository
+	passwordService   *PasswordService
+	jwtService        *JwtService
+	validator         *utils.Validator
+	encryptionService *EncryptionService
+	mailClient        client.MailClientInterface
+	userClient        *client.UserClient
+	orchestrator      *orchestrator.RegisterUserOrchestrator
+	tracer            trace.Tracer
+	logger            *config.Logger
 }
 
 func NewUserService(userRepo *repository.UserRepository,
@@ -34,20 +36,20 @@ func NewUserService(userRepo *repository.UserRepository,
 	encryptionService *EncryptionService,
 	mailClient client.MailClientInterface,
 	userClient *client.UserClient,
-	notificationClient *client.NotificationClient,
+	orchestrator *orchestrator.RegisterUserOrchestrator,
 	tracer trace.Tracer,
 	logger *config.Logger) *UserService {
 	return &UserService{
-		userRepository:     userRepo,
-		passwordService:    passwordService,
-		jwtService:         jwtService,
-		validator:          validator,
-		encryptionService:  encryptionService,
-		mailClient:         mailClient,
-		userClient:         userClient,
-		notificationClient: notificationClient,
-		tracer:             tracer,
-		logger:             logger,
+		userRepository:    userRepo,
+		passwordService:   passwordService,
+		jwtService:        jwtService,
+		validator:         validator,
+		encryptionService: encryptionService,
+		mailClient:        mailClient,
+		userClient:        userClient,
+		orchestrator:      orchestrator,
+		tracer:            tracer,
+		logger:            logger,
 	}
 }
 func (u *UserService) CreateUser(ctx context.Context, registerUser domains.RegisterUser) (*domains.UserDTO, *errors.ErrorStruct) {
@@ -67,11 +69,12 @@ func (u *UserService) CreateUser(ctx context.Context, registerUser domains.Regis
 		return nil, errors.NewError("Choose better password that is more secure!", 400)
 	}
 	user := domains.User{
-		Email:     registerUser.Email,
-		Password:  registerUser.Password,
-		Username:  registerUser.Username,
-		Role:      registerUser.Role,
-		Confirmed: false,
+		Email:               registerUser.Email,
+		Password:            registerUser.Password,
+		Username:            registerUser.Username,
+		Role:                registerUser.Role,
+		Confirmed:           false,
+		RegistrationPending: true,
 	}
 	hashedPassword, err := u.passwordService.HashPassword(user.Password)
 	if err != nil {
@@ -84,47 +87,85 @@ func (u *UserService) CreateUser(ctx context.Context, registerUser domains.Regis
 	if foundErr != nil {
 		return nil, foundErr
 	}
-	id, err := newUser.ID.MarshalJSON()
-	if err != nil {
-		return nil, errors.NewError(err.Error(), 500)
-	}
-	token, encError := u.encryptionService.GenerateToken(string(id[1 : len(id)-1]))
-	if encError != nil {
-		u.logger.LogError("user-service", fmt.Sprintf("Error generating encrypted token for user with username %v", registerUser.Username))
-		return nil, encError
+	details := events.RegisterUserDetails{
+		UserID:    newUser.ID.Hex(),
+		FirstName: registerUser.FirstName,
+		LastName:  registerUser.LastName,
+		Email:     registerUser.Email,
+		Residence: registerUser.CurrentPlace,
+		Role:      registerUser.Role,
+		Username:  registerUser.Username,
+		Age:       registerUser.Age,
 	}
-	u.logger.LogInfo("user-service", "Token for user with username "+user.Username+" sucessfully created.")
-
-	errFromUserService := u.userClient.SendCreatedUser(ctx, newUser.ID.Hex(), registerUser)
-	u.logger.LogInfo("user-service", "User with username "+user.Username+" sucessfully sent to user service for creation.")
-
-	if errFromUserService != nil {
-		_, err := u.userRepository.DeleteUserById(ctx, newUser.ID.Hex())
-		if err != nil {
-			u.logger.LogError("user-service", fmt.Sprintf("Error creating user with username %v at user-service, rolling back.", registerUser.Username))
+	if err := u.orchestrator.Start(ctx, &details); err != nil {
+		u.logger.LogError("user-service", fmt.Sprintf("Unable to start register saga for user with username %v, rolling back: %v", registerUser.Username, err))
+		if err := u.userRepository.DeletePendingUser(ctx, details.UserID); err != nil {
 			return nil, err
 		}
-		return nil, errFromUserService
-	}
-	u.logger.LogInfo("user-service", "User with username "+user.Username+" sucessfully created.")
-	go func() {
-		u.mailClient.SendAccountConfirmationEmail(registerUser.Email, token)
-	}()
-	errFromNotificationService := u.notificationClient.CreateNewUserStructNotification(ctx, newUser.ID.Hex())
-	u.logger.LogInfo("user-service", "Creating user struct for user with username  "+user.Username+" in notification service.")
-
-	if errFromNotificationService != nil {
-		u.logger.LogError("user-service", fmt.Sprintf("Error creating user with username %v at notification-service.", registerUser.Username))
-		u.logger.LogError("user-service", err.Error())
+		return nil, errors.NewError("Service is not responding correctly", 500)
 	}
+	u.logger.LogInfo("user-service", "Register saga for user with username "+user.Username+" sucessfully started.")
 	return &domains.UserDTO{
-		ID:       string(id[1 : len(id)-1]),
+		ID:       details.UserID,
 		Email:    registerUser.Email,
 		Username: registerUser.Username,
 		Role:     registerUser.Role,
 	}, nil
 }
 
+// CompleteRegistration lets the user log in once the profile and the inbox exist,
+// and sends the account confirmation email.
+func (u UserService) CompleteRegistration(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.CompleteRegistration")
+	defer span.End()
+	user, err := u.userRepository.FindUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	token, err := u.encryptionService.GenerateToken(id)
+	if err != nil {
+		u.logger.LogError("user-service", fmt.Sprintf("Error generating encrypted token for user with username %v", user.Username))
+		return err
+	}
+	completed, err := u.userRepository.CompleteRegistration(ctx, id)
+	if err != nil {
+		return err
+	}
+	if completed {
+		go func() {
+			u.mailClient.SendAccountConfirmationEmail(user.Email, token)
+		}()
+	}
+	u.logger.LogInfo("user-service", "User with username "+user.Username+" sucessfully created.")
+	return nil
+}
+
+// ReopenRegistration marks the credentials of a registration that is being rolled
+// back as pending again, so the user can no longer log in and RollbackRegistration
+// removes them.
+func (u UserService) ReopenRegistration(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.ReopenRegistration")
+	defer span.End()
+	err := u.userRepository.ReopenRegistration(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.logger.LogInfo("user-service", fmt.Sprintf("Registration of user %v reopened.", id))
+	return nil
+}
+
+// RollbackRegistration removes the credentials saved for a registration that failed.
+func (u UserService) RollbackRegistration(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.RollbackRegistration")
+	defer span.End()
+	err := u.userRepository.DeletePendingUser(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.logger.LogInfo("user-service", fmt.Sprintf("Registration of user %v rolled back.", id))
+	return nil
+}
+
 func (u *UserService) LoginUser(ctx context.Context, loginData domains.LoginUser) (*domains.SuccessfullyLoggedUser, *errors.ErrorStruct) {
 	ctx, span := u.tracer.Start(ctx, "UserService.LoginUser")
 	defer span.End()
@@ -142,6 +183,10 @@ func (u *UserService) LoginUser(ctx context.Context, loginData domains.LoginUser
 		u.logger.LogError("user-service", "Bad credentials for user "+loginData.Email)
 		return nil, errors.NewError("Bad credentials", 401)
 	}
+	if user.RegistrationPending {
+		u.logger.LogError("user-service", "Registration of user "+loginData.Email+" is not completed yet")
+		return nil, errors.NewError("Your registration is still being processed, try again shortly", 403)
+	}
 	jwtToken, foundError := u.jwtService.CreateKey(user.Email, user.Role, user.ID.Hex())
 	if foundError != nil {
 		u.logger.LogError("user-service", foundError.Error())
@@ -377,3 +422,27 @@ func (u UserService) DeleteUserById(ctx context.Context, id string) (*domains.Us
 		Confirmed: user.Confirmed,
 	}, nil
 }
+
+// ArchiveUserById disables the login of a user whose account is being deleted.
+func (u UserService) ArchiveUserById(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.ArchiveUserById")
+	defer span.End()
+	err := u.userRepository.ArchiveUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.logger.LogInfo("user-service", fmt.Sprintf("Credentials of user %v archived.", id))
+	return nil
+}
+
+// RestoreUserById gives back the login taken by ArchiveUserById.
+func (u UserService) RestoreUserById(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.RestoreUserById")
+	defer span.End()
+	err := u.userRepository.RestoreUserById(ctx, id)
+	if err != nil {
+		return err
+	}
+	u.logger.LogInfo("user-service", fmt.Sprintf("Credentials of user %v restored.", id))
+	return nil
+}
@@ -57,6 +57,7 @@ import { UnloadService } from './services/unload/unload.service';
 import { HostReservationsTableComponent } from './components/host-reservations-table/host-reservations-table/host-reservations-table.component';
 import { ShowMetricsComponent } from './components/show-metrics/show-metrics.component';
 import { FormUpdateAvailabilityComponent } from './forms/form-update-availability/form-update-availability/form-update-availability.component';
+import { MoneyPipe } from './utils/money.pipe';
 @NgModule({
   declarations: [
     AppComponent,
@@ -106,6 +107,7 @@ import { FormUpdateAvailabilityComponent } from './forms/form-update-availabilit
     HostReservationsTableComponent,
     ShowMetricsComponent,
     FormUpdateAvailabilityComponent,
+    MoneyPipe,
   ],
   imports: [
     BrowserModule,
@@ -30,4 +30,26 @@ export class HostReservationsTableComponent {
       })
     }
   }
+
+  checkIn(reservation: Reservation) {
+    this.reservationsService.checkIn(reservation.id).subscribe({
+      next: (data) => {
+        reservation.state = data.data.state;
+      },
+      error: (err) => {
+        console.log(err)
+      }
+    })
+  }
+
+  checkOut(reservation: Reservation) {
+    this.reservationsService.checkOut(reservation.id).subscribe({
+      next: (data) => {
+        reservation.state = data.data.state;
+      },
+      error: (err) => {
+        console.log(err)
+      }
+    })
+  }
 }
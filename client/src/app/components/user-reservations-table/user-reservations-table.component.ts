@@ -36,19 +36,11 @@ export class UserReservationsTableComponent {
   cancelReservation(index: number) {
     const reservation = this.userReservations[index];
     if (reservation) {
-      this.reservationsService.deleteById(
-        reservation.country,
-        reservation.id,
-        reservation.userId,
-        reservation.hostId,
-        reservation.accommodationId,
-        reservation.endDate
-      )
+      this.reservationsService.cancel(reservation.id)
       .subscribe(
-        (deletedReservation) => {
-          // Handle success, if needed
-          console.log('Reservation canceled successfully:', deletedReservation);
-          // Optionally, you can remove the canceled reservation from the local array
+        (canceledReservation) => {
+          // The response carries the refund the guest gets back
+          console.log('Reservation canceled successfully:', canceledReservation.data.refund);
           this.userReservations.splice(index, 1);
         },
         (error) => {
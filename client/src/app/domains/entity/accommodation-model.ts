@@ -15,4 +15,10 @@ export interface Accommodation {
   AvailableAccommodationDates: DateAvailability[];
   imageIds: string[];
   paying:string;
+  cancellationPolicy?: CancellationPolicy;
+}
+
+export interface CancellationPolicy {
+  name: 'flexible' | 'moderate' | 'strict' | 'custom';
+  tiers?: { daysBeforeCheckIn: number; refundPercent: number }[];
 }
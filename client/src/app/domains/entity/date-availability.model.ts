@@ -1,6 +1,10 @@
+import { Money } from './money.model';
+import { StayRules } from './stay-rules.model';
+
 export interface DateAvailability {
   dateRange:string[]
-  price: number;
+  price: Money;
+  rules?: StayRules;
   id?: string;
   location?: string;
   accommodationID?: string;
@@ -0,0 +1,5 @@
+// Money is an amount in minor units of an ISO currency, cents for USD.
+export interface Money {
+  amount: number;
+  currency: string;
+}
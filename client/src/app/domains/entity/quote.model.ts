@@ -0,0 +1,22 @@
+import { Money } from './money.model';
+
+export interface NightlyRate {
+  date: string;
+  rate: Money;
+  amount: Money;
+}
+
+export interface Quote {
+  accommodationId: string;
+  paying: string;
+  guests: number;
+  nights: NightlyRate[];
+  subtotal: Money;
+  discount?: string;
+  discountPercent: number;
+  discountAmount: Money;
+  cleaningFee: Money;
+  total: Money;
+  charged: Money;
+  exchangeRate: number;
+}
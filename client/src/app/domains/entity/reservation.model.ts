@@ -1,3 +1,5 @@
+import { Money } from './money.model';
+
 /*
 	Id                gocql.UUID `json:"id"`
 	UserID            string     `json:"userId"`
@@ -7,7 +9,7 @@
 	Username          string     `json:"username"`
 	AccommodationName string     `json:"accommodationName"`
 	Location          string     `json:"location"`
-	Price             int        `json:"price"`
+	Price             Money      `json:"price"`
 	NumberOfDays      int        `json:"numOfDays"`
 	Continent         string     `json:"continent"`
 	DateRange         []string   `json:"dateRange"`
@@ -25,11 +27,32 @@ export interface Reservation {
     username: string
     accommodationName: string
     location: string
-    price: number
+    price: Money
     numberOfDays: number
     continent: string
     dateRange: string[]
     isActive: boolean
     country: string
     hostId: string
+    guests: number
+    party?: Party
+    state: string
+    refund?: Refund
+}
+
+export interface Party {
+    adults: number
+    children: number
+    infants: number
+    pets: boolean
+}
+
+export interface Refund {
+    policy: string
+    daysBeforeCheckIn: number
+    refundPercent: number
+    totalPrice: Money
+    refundAmount: Money
+    retainedAmount: Money
+    cancelledAt: string
 }
\ No newline at end of file
@@ -0,0 +1,8 @@
+export interface StayRules {
+  minNights: number;
+  maxNights: number;
+  checkInDays: string[];
+  checkOutDays: string[];
+  minNoticeDays: number;
+  maxHorizonDays: number;
+}
@@ -13,6 +13,7 @@ import { User } from 'src/app/domains/entity/user-profile.model';
 import { AccommodationsService } from 'src/app/services/accommodations-service/accommodations.service';
 import { UserService } from 'src/app/services/user/user.service';
 import { formatErrors } from 'src/app/utils/formatter.utils';
+import { currencies, defaultCurrency, fromWholeUnits } from 'src/app/utils/money.utils';
 import { Observable } from 'rxjs';
 
 
@@ -24,12 +25,14 @@ import { Observable } from 'rxjs';
 export class FormCreateAccommodationComponent {
   createAccommodationForm: FormGroup;
   payingRoles: string[] = ['Per Accommodation', 'Per Guest'];
+  currencies: string[] = currencies;
   convenienceList = [
     'WiFi',
     'Kitchen',
     'Air Conditioning',
     'Free Parking',
     'Pool',
+    'Pets Allowed',
   ];
   errors: string = '';
   user: UserAuth | null = null;
@@ -70,6 +73,7 @@ export class FormCreateAccommodationComponent {
       startDate: ['', Validators.required],
       endDate: ['', Validators.required],
       price: ['', [Validators.required, Validators.min(0)]],
+      currency: [defaultCurrency, Validators.required],
     });
   }
 
@@ -173,23 +177,24 @@ export class FormCreateAccommodationComponent {
     
     
 
-    function processDateAvailabilities(dateAvailabilitiesValue: any[]): { dateRange: string[], price: number }[] {
-      const processedData: { dateRange: string[], price: number }[] = [];
+    function processDateAvailabilities(dateAvailabilitiesValue: any[]): { dateRange: string[], price: number, currency: string }[] {
+      const processedData: { dateRange: string[], price: number, currency: string }[] = [];
     
       for (const entry of dateAvailabilitiesValue) {
         const startDate = new Date(entry.startDate);
         const endDate = new Date(entry.endDate);
-        const price = entry.price;
+        // The host enters whole units, the services store minor units
+        const price = fromWholeUnits(entry.price, entry.currency);
     
         // Generate date range
         const currentDates = getDatesBetween(startDate, endDate).map(date => date.toISOString().split('T')[0]);
         console.log(currentDates)
     
         // Do something with startDate, endDate, and price
-        console.log(`Start Date: ${startDate.toISOString().split('T')[0]}, End Date: ${endDate.toISOString().split('T')[0]}, Price: ${price}`);
+        console.log(`Start Date: ${startDate.toISOString().split('T')[0]}, End Date: ${endDate.toISOString().split('T')[0]}, Price: ${price.amount} ${price.currency}`);
         
         // Add currentDates and price to the processedData array
-        processedData.push({ dateRange: currentDates, price: price });
+        processedData.push({ dateRange: currentDates, price: price.amount, currency: price.currency });
       }
     
       // The processedData array now contains objects with dateRange (dates only) and price for every entry
@@ -3,9 +3,12 @@ import { Component, Input, OnInit } from '@angular/core';
 import { FormBuilder, FormGroup, Validators } from '@angular/forms';
 import { Accommodation } from 'src/app/domains/entity/accommodation-model';
 import { DateAvailability } from 'src/app/domains/entity/date-availability.model';
+import { Quote } from 'src/app/domains/entity/quote.model';
 import { UserAuth } from 'src/app/domains/entity/user-auth.model';
 import { ReservationService } from 'src/app/services/reservation-service/reservation.service';
 import { UserService } from 'src/app/services/user/user.service';
+import { Money } from 'src/app/domains/entity/money.model';
+import { currencies, defaultCurrency } from 'src/app/utils/money.utils';
 
 @Component({
   selector: 'app-reservation-form',
@@ -18,6 +21,8 @@ export class ReservationFormComponent implements OnInit {
   @Input() accommodationID!: string
   user: UserAuth | null = null;
   availabilityData: DateAvailability [] = [];
+  quote: Quote | null = null;
+  currencies: string[] = currencies;
   constructor(
     private fb: FormBuilder,
     private reservationService: ReservationService,
@@ -40,14 +45,43 @@ export class ReservationFormComponent implements OnInit {
   initializeForm() {
     this.reservationForm = this.fb.group({
       range: ['', [Validators.required]],
+      adults: [1, [Validators.required, Validators.min(1)]],
+      children: [0, [Validators.min(0)]],
+      infants: [0, [Validators.min(0)]],
+      pets: [false],
+      currency: [defaultCurrency],
     });
+    this.reservationForm.get('adults')?.valueChanges.subscribe(() => this.updateQuote());
+    this.reservationForm.get('children')?.valueChanges.subscribe(() => this.updateQuote());
+    this.reservationForm.get('currency')?.valueChanges.subscribe(() => this.updateQuote());
   }
 
   handleDateChange(rangeDates: Date[]) {
     console.log(rangeDates);
     const formattedRange = rangeDates.map((date) => this.formatDate(date));
     this.reservationForm.get('range')?.setValue(formattedRange);
+    this.updateQuote();
   }
+
+  updateQuote() {
+    let dateRange: string[] = this.reservationForm.value.range;
+    let guests: number = this.guests();
+    let currency: string = this.reservationForm.value.currency;
+    this.quote = null;
+    if (!dateRange || dateRange.length === 0 || !guests) {
+      return;
+    }
+    this.reservationService.quote(this.accommodationID, dateRange, guests, currency).subscribe({next: (data) => {
+      this.quote = data
+    },error: (err) => {
+      console.log(err)
+    }})
+  }
+  // Infants stay free and do not count as guests.
+  guests(): number {
+    return (this.reservationForm.value.adults || 0) + (this.reservationForm.value.children || 0);
+  }
+
   formatDate(date: Date) {
     let day = date.getDate();
     let month = date.getMonth() + 1;
@@ -59,8 +93,23 @@ export class ReservationFormComponent implements OnInit {
     return `${year}-${newMonth}-${newDay}`;
   }
 
+  joinWaitlist() {
+    let dateRange: string[] = this.reservationForm.value.range;
+    if (!dateRange || dateRange.length === 0) {
+      return;
+    }
+    this.reservationService.joinWaitlist({
+      accommodationId: this.accommodationID,
+      accommodationName: this.accommodation.name,
+      hostId: this.accommodation.userId,
+      username: this.user?.username,
+      dateRange: dateRange,
+      guests: this.guests(),
+    });
+  }
+
   submitReservation() {
-    if (!this.reservationForm.valid) {
+    if (!this.reservationForm.valid || !this.quote) {
       console.log('not valid');
       return;
     }
@@ -71,7 +120,14 @@ export class ReservationFormComponent implements OnInit {
     let username: string = this.user?.username as string;
     let accommodationName: string = this.accommodation.name;
     let location: string = "bb,Belgrade,Serbia";
-    let price: number = 50;
+    let price: Money = this.quote.charged;
+    let guests: number = this.quote.guests;
+    let party = {
+      adults: this.reservationForm.value.adults,
+      children: this.reservationForm.value.children || 0,
+      infants: this.reservationForm.value.infants || 0,
+      pets: this.reservationForm.value.pets,
+    };
     let numOfDays: number = this.reservationForm.value.range.length;
     let dateRange: string[] = this.reservationForm.value.range;
     let hostID: string = this.accommodation.userId
@@ -86,7 +142,9 @@ export class ReservationFormComponent implements OnInit {
     "price": price,
     "numOfDays": numOfDays,
     "dateRange": dateRange,
-    "hostID" : hostID
+    "hostID" : hostID,
+    "guests": guests,
+    "party": party
   }
   console.log(reservationData)
   this.reservationService.createReservation(reservationData)
@@ -2,6 +2,7 @@ import { Component, Input } from '@angular/core';
 import { FormArray, FormBuilder, FormGroup } from '@angular/forms';
 import { Router } from '@angular/router';
 import { AccommodationsService } from 'src/app/services/accommodations-service/accommodations.service';
+import { currencies, defaultCurrency } from 'src/app/utils/money.utils';
 
 @Component({
   selector: 'app-form-filter',
@@ -12,6 +13,7 @@ export class FormFilterComponent {
 
   filterForm: FormGroup;
   convenienceList: string[];
+  currencies: string[] = currencies;
 
   @Input() cityCopy!: string;
   @Input() countryCopy!: string;
@@ -22,12 +24,13 @@ export class FormFilterComponent {
   constructor(private fb: FormBuilder,private accommodationsService: AccommodationsService,private router: Router) {
     this.filterForm = this.fb.group({
       maxPrice: [''], // Assuming maxPrice is a FormControl
+      currency: [defaultCurrency],
       conveniences: this.fb.array([]),
       distinguished: ['']
     });
   
     // Populate the convenienceList array with your convenience options
-    this.convenienceList = ['WiFi', 'Kitchen', 'Air Conditioning', 'Free Parking', 'Pool'];
+    this.convenienceList = ['WiFi', 'Kitchen', 'Air Conditioning', 'Free Parking', 'Pool', 'Pets Allowed'];
   
     // Initialize the conveniences form array with checkboxes
     this.initializeConveniencesCheckboxes();
@@ -65,6 +68,7 @@ export class FormFilterComponent {
           startDate:this.startDateCopy,
           endDate: this.endDateCopy,
           maxPrice:this.filterForm.value.maxPrice as string,
+          currency:this.filterForm.value.currency as string,
           conveniences:this.fromBooleanToConveniences(),
           distinguished:this.filterForm.value.distinguished as string
           
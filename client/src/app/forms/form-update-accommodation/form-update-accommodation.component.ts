@@ -20,6 +20,7 @@ export class FormUpdateAccommodationComponent {
     'Air Conditioning',
     'Free Parking',
     'Pool',
+    'Pets Allowed',
   ];
   updateAccommodationForm: FormGroup;
   accommodation!: Accommodation;
@@ -14,6 +14,9 @@ import {
 import { formatErrors } from 'src/app/utils/formatter.utils';
 import { th } from 'date-fns/locale';
 import { DateAvailability } from 'src/app/domains/entity/date-availability.model';
+import { Money } from 'src/app/domains/entity/money.model';
+import { StayRules } from 'src/app/domains/entity/stay-rules.model';
+import { currencies, defaultCurrency, fromWholeUnits } from 'src/app/utils/money.utils';
 
 
 @Component({
@@ -25,10 +28,12 @@ export class FormUpdateAvailabilityComponent implements OnInit{
   user: UserAuth | null = null;
   updateAvailabilityForm: FormGroup;
   errors: string = '';
+  currencies: string[] = currencies;
+  weekdays: string[] = ['Monday', 'Tuesday', 'Wednesday', 'Thursday', 'Friday', 'Saturday', 'Sunday'];
   @Input()accommodationID!: string;
   @Input()id!: string;
   @Input()country!: string;
-  @Input()price!: number;
+  @Input()price!: Money;
   @Input()location!:string;
 
   
@@ -48,7 +53,14 @@ export class FormUpdateAvailabilityComponent implements OnInit{
     this.updateAvailabilityForm = this.fb.group({
       startDate: ['', Validators.required],
       endDate: ['', Validators.required],
-      price: ['', Validators.required]
+      price: ['', Validators.required],
+      currency: [this.price?.currency ?? defaultCurrency, Validators.required],
+      minNights: [0, Validators.min(0)],
+      maxNights: [0, Validators.min(0)],
+      checkInDays: [[]],
+      checkOutDays: [[]],
+      minNoticeDays: [0, Validators.min(0)],
+      maxHorizonDays: [0, Validators.min(0)]
     });
   }
 
@@ -72,27 +84,38 @@ export class FormUpdateAvailabilityComponent implements OnInit{
   const availabilites=this.processDateAvailabilities()
 
   this.reservationService.update(
-    this.accommodationID,this.id,this.country,this.price,this.location,[availabilites]
+    this.accommodationID,this.id,this.country,availabilites.price,this.location,[availabilites]
   )
 
   }
    processDateAvailabilities(): DateAvailability {
-    let processedData: DateAvailability = {dateRange: [],price:0,id: '',accommodationID: '',location: ''};
+    let processedData: DateAvailability = {dateRange: [],price:{amount: 0, currency: defaultCurrency},id: '',accommodationID: '',location: ''};
   
    
       const startDate = new Date(this.updateAvailabilityForm.get('startDate')?.value as string);
       const endDate = new Date(this.updateAvailabilityForm.get('endDate')?.value as string);
-      const price = this.updateAvailabilityForm.get('price')?.value
+      const price = fromWholeUnits(this.updateAvailabilityForm.get('price')?.value, this.updateAvailabilityForm.get('currency')?.value)
   
       // Generate date range
       const currentDates = this.getDatesBetween(startDate, endDate).map(date => date.toISOString().split('T')[0]);
       console.log(currentDates)
   
       // Do something with startDate, endDate, and price
-      console.log(`Start Date: ${startDate.toISOString().split('T')[0]}, End Date: ${endDate.toISOString().split('T')[0]}, Price: ${price}`);
+      console.log(`Start Date: ${startDate.toISOString().split('T')[0]}, End Date: ${endDate.toISOString().split('T')[0]}, Price: ${price.amount} ${price.currency}`);
       
+      // Zero and empty rules do not limit the stays
+      const form = this.updateAvailabilityForm.value;
+      const rules: StayRules = {
+        minNights: form.minNights || 0,
+        maxNights: form.maxNights || 0,
+        checkInDays: form.checkInDays || [],
+        checkOutDays: form.checkOutDays || [],
+        minNoticeDays: form.minNoticeDays || 0,
+        maxHorizonDays: form.maxHorizonDays || 0,
+      };
+
       // Add currentDates and price to the processedData array
-      processedData =  {dateRange: currentDates, price: price};
+      processedData =  {dateRange: currentDates, price: price, rules: rules};
     
   
     // The processedData array now contains objects with dateRange (dates only) and price for every entry
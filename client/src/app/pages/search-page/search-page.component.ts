@@ -16,6 +16,7 @@ export class SearchPageComponent {
   startDate!:string;
   endDate!:string;
   maxPrice!:string;
+  currency!:string;
   conveniences!:string[]
   distinguished!:string
 
@@ -37,6 +38,7 @@ export class SearchPageComponent {
       this.startDate=params['startDate'] || ""
       this.endDate=params['endDate']||""
       this.maxPrice=params['maxPrice']||"0"
+      this.currency=params['currency']||""
       this.conveniences=params['conveniences']||""
       this.distinguished=params['distinguished']||""
       // Once you have the query parameters, you can use them to perform the search
@@ -53,7 +55,7 @@ export class SearchPageComponent {
 
   private loadSearchedAccommodations(): void {
     console.log(this.city,this.country,this.numOfVisitors)
-    this.accommodationService.search(this.city as string,this.country as string,this.numOfVisitors as string,this.startDate as string,this.endDate as string,this.maxPrice as string,this.conveniences,this.distinguished).subscribe({
+    this.accommodationService.search(this.city as string,this.country as string,this.numOfVisitors as string,this.startDate as string,this.endDate as string,this.maxPrice as string,this.currency,this.conveniences,this.distinguished).subscribe({
       next: (response) => {
         this.accommodations = response.data;
         console.log(this.accommodations)
@@ -206,6 +206,7 @@ export class AccommodationsService {
     startDate: string,
     endDate: string,
     maxPrice:string,
+    currency:string,
     conveniences:string[],
     isDistinguished:string
   ): Observable<any> {
@@ -213,7 +214,7 @@ export class AccommodationsService {
     
     console.log('pocetni datum je', isDistinguished);
     return this.http.get<any>(
-      `${apiURL}/accommodations/search?city=${city}&country=${country}&numOfVisitors=${numOfVisitors}&startDate=${startDate}&endDate=${endDate}&maxPrice=${maxPrice}&conveniences=${conveniences}&isDistinguished=${isDistinguished}`
+      `${apiURL}/accommodations/search?city=${city}&country=${country}&numOfVisitors=${numOfVisitors}&startDate=${startDate}&endDate=${endDate}&maxPrice=${maxPrice}&currency=${currency}&conveniences=${conveniences}&isDistinguished=${isDistinguished}`
     );
   }
 
@@ -8,6 +8,8 @@ import { ToastNotificationType } from 'src/app/domains/enums/toast-notification-
 import { UserService } from '../user/user.service';
 import { UserAuth } from 'src/app/domains/entity/user-auth.model';
 import { DateAvailability } from 'src/app/domains/entity/date-availability.model';
+import { Quote } from 'src/app/domains/entity/quote.model';
+import { Money } from 'src/app/domains/entity/money.model';
 import { th } from 'date-fns/locale';
 
 @Injectable({
@@ -28,6 +30,11 @@ export class ReservationService {
     return this.http.get(url);
   }
 
+  quote(accommodationId: string, dateRange: string[], guests: number, currency: string): Observable<Quote> {
+    const url = `${this.apiURL}/reservations/quote`;
+    return this.http.post<Quote>(url, { accommodationId, dateRange, guests, currency });
+  }
+
   createReservation(reservationData: any): void {
     this.http.post(`${apiURL}/reservations/`, reservationData).subscribe(
       (data) => {
@@ -48,16 +55,57 @@ export class ReservationService {
     );
   }
 
-  deleteById(
-    country: string,
-    id: string,
-    userID: string,
-    hostID: string,
-    accommodationID: string,
-    endDate: string
-  ): Observable<any> {
-    const url = `${this.apiURL}/reservations/${country}/${id}/${userID}/${hostID}/${accommodationID}/${endDate}`;
-    return this.http.put(url,{});
+  joinWaitlist(waitlistData: any): void {
+    this.http.post(`${apiURL}/reservations/waitlist`, waitlistData).subscribe({
+      next: (data) => {
+        this.toastService.showToast(
+          'Success',
+          'You will be notified when the dates are free',
+          ToastNotificationType.Success
+        );
+      },
+      error: (err) => {
+        this.toastService.showToast(
+          'Error',
+          err.error.error,
+          ToastNotificationType.Error
+        );
+      },
+    });
+  }
+
+  getWaitlist(): Observable<any> {
+    return this.http.get(`${apiURL}/reservations/waitlist`);
+  }
+
+  leaveWaitlist(accommodationId: string, id: string): Observable<any> {
+    return this.http.delete(`${apiURL}/reservations/waitlist/${accommodationId}/${id}`);
+  }
+
+  modify(id: string, dateRange: string[], guests: number): Observable<any> {
+    const url = `${this.apiURL}/reservations/${id}`;
+    return this.http.put(url, { dateRange, guests });
+  }
+
+  cancel(id: string): Observable<any> {
+    const url = `${this.apiURL}/reservations/${id}/cancel`;
+    return this.http.put(url, {});
+  }
+
+  checkIn(id: string): Observable<any> {
+    return this.http.put(`${apiURL}/reservations/${id}/check-in`, {});
+  }
+
+  checkOut(id: string): Observable<any> {
+    return this.http.put(`${apiURL}/reservations/${id}/check-out`, {});
+  }
+
+  getArrivalInstructions(accommodationId: string): Observable<any> {
+    return this.http.get(`${apiURL}/reservations/arrival/${accommodationId}`);
+  }
+
+  setArrivalInstructions(accommodationId: string, instructions: any): Observable<any> {
+    return this.http.put(`${apiURL}/reservations/arrival/${accommodationId}`, instructions);
   }
 
   getAllReservationsById(id: string): Observable<any> {
@@ -88,13 +136,14 @@ export class ReservationService {
     accommodationID:string,
     id: string,
     country: string,
-    price: number,
+    price: Money,
     location: string,
     dateRange: DateAvailability[]
     ): void{
-      this.http.post(`${apiURL}/reservations/${accommodationID}/${id}/${country}/${price}`,{
+      this.http.post(`${apiURL}/reservations/${accommodationID}/${id}/${country}`,{
         accommodationID,
         location,
+        price,
         dateRange,
       }).subscribe({
         next: (data) => {
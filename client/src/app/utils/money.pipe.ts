@@ -0,0 +1,10 @@
+import { Pipe, PipeTransform } from '@angular/core';
+import { Money } from '../domains/entity/money.model';
+import { formatMoney } from './money.utils';
+
+@Pipe({ name: 'money' })
+export class MoneyPipe implements PipeTransform {
+  transform(money: Money | null | undefined): string {
+    return formatMoney(money);
+  }
+}
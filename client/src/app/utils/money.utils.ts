@@ -0,0 +1,26 @@
+import { Money } from '../domains/entity/money.model';
+
+export const defaultCurrency = 'USD';
+
+export const currencies = ['USD', 'EUR', 'GBP', 'CHF', 'RSD', 'BAM', 'HUF', 'JPY'];
+
+const zeroDecimalCurrencies = ['CLP', 'ISK', 'JPY', 'KRW', 'VND'];
+
+export const minorUnits = (currency: string): number =>
+  zeroDecimalCurrencies.includes(currency) ? 1 : 100;
+
+export const fromWholeUnits = (amount: number, currency: string): Money => ({
+  amount: Math.round(amount * minorUnits(currency)),
+  currency,
+});
+
+export const toWholeUnits = (money: Money): number =>
+  money.amount / minorUnits(money.currency);
+
+export const formatMoney = (money: Money | null | undefined): string => {
+  if (!money) {
+    return '';
+  }
+  const digits = minorUnits(money.currency) === 1 ? 0 : 2;
+  return `${toWholeUnits(money).toFixed(digits)} ${money.currency}`;
+};
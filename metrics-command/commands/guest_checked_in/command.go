@@ -0,0 +1,22 @@
+package guest_checked_in
+
+import (
+	"metrics-command/commands"
+	"time"
+)
+
+type GuestCheckedInCommand struct {
+	UserID          string
+	AccommodationID string
+	ReservationID   string
+	CheckedInAt     time.Time
+}
+
+func NewCommand(userID, accommodationID, reservationID string, checkedInAt time.Time) commands.Command {
+	return &GuestCheckedInCommand{
+		UserID:          userID,
+		AccommodationID: accommodationID,
+		ReservationID:   reservationID,
+		CheckedInAt:     checkedInAt,
+	}
+}
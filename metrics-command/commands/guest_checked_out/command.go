@@ -0,0 +1,22 @@
+package guest_checked_out
+
+import (
+	"metrics-command/commands"
+	"time"
+)
+
+type GuestCheckedOutCommand struct {
+	UserID          string
+	AccommodationID string
+	ReservationID   string
+	CheckedOutAt    time.Time
+}
+
+func NewCommand(userID, accommodationID, reservationID string, checkedOutAt time.Time) commands.Command {
+	return &GuestCheckedOutCommand{
+		UserID:          userID,
+		AccommodationID: accommodationID,
+		ReservationID:   reservationID,
+		CheckedOutAt:    checkedOutAt,
+	}
+}
@@ -4,16 +4,23 @@ import (
 	"errors"
 	"log"
 	"metrics-command/commands"
+	"metrics-command/commands/guest_checked_in"
+	"metrics-command/commands/guest_checked_out"
 	"metrics-command/commands/user_joined"
 	"metrics-command/commands/user_left"
 	"metrics-command/commands/user_rated"
+	"metrics-command/commands/user_reservation_retracted"
 	"metrics-command/commands/user_reserved"
 	"metrics-command/store"
+	"time"
 
 	"example/metrics_events"
+	guest_checked_in_event "example/metrics_events/guest_checked_in"
+	guest_checked_out_event "example/metrics_events/guest_checked_out"
 	user_joined_event "example/metrics_events/user_joined"
 	user_left_event "example/metrics_events/user_left"
 	user_rated_event "example/metrics_events/user_rated"
+	user_reservation_retracted_event "example/metrics_events/user_reservation_retracted"
 	user_reserved_event "example/metrics_events/user_reserved"
 )
 
@@ -43,10 +50,11 @@ func (h Handler) Handle(command commands.Command) error {
 		return h.store.StoreAndExpectLastEventNumber(
 			event.Stream(),
 			event.Type(),
+			event.SchemaVersion(),
 			eventJson,
 			uint64(event.ExpectedLastEventNumber()))
 	} else {
-		return h.store.Store(event.Stream(), event.Type(), eventJson)
+		return h.store.Store(event.Stream(), event.Type(), event.SchemaVersion(), eventJson)
 	}
 }
 
@@ -58,8 +66,14 @@ func (h Handler) execute(command commands.Command) (event metrics_events.Event,
 		event, err = h.createUserLeft(c)
 	case *user_reserved.UserReservedCommand:
 		event, err = h.createUserReserved(c)
+	case *user_reservation_retracted.UserReservationRetractedCommand:
+		event, err = h.createUserReservationRetracted(c)
 	case *user_rated.UserRatedCommand:
 		event, err = h.createUserRated(c)
+	case *guest_checked_in.GuestCheckedInCommand:
+		event, err = h.createGuestCheckedIn(c)
+	case *guest_checked_out.GuestCheckedOutCommand:
+		event, err = h.createGuestCheckedOut(c)
 	default:
 		err = errors.New("unknown command")
 	}
@@ -87,10 +101,27 @@ func (h Handler) createUserLeft(command *user_left.UserLeftCommand) (metrics_eve
 }
 
 func (h Handler) createUserReserved(command *user_reserved.UserReservedCommand) (metrics_events.Event, error) {
+	reservedAt, err := time.Parse(user_reserved_event.TimeLayoutV1, command.ReservedAt)
+	if err != nil {
+		return nil, err
+	}
 	return user_reserved_event.NewEvent(
 			command.UserID,
 			command.AccommodationID,
-			command.ReservedAt,
+			reservedAt,
+			-1),
+		nil
+}
+
+func (h Handler) createUserReservationRetracted(command *user_reservation_retracted.UserReservationRetractedCommand) (metrics_events.Event, error) {
+	reservedAt, err := time.Parse(user_reserved_event.TimeLayoutV1, command.ReservedAt)
+	if err != nil {
+		return nil, err
+	}
+	return user_reservation_retracted_event.NewEvent(
+			command.UserID,
+			command.AccommodationID,
+			reservedAt,
 			-1),
 		nil
 }
@@ -103,3 +134,23 @@ func (h Handler) createUserRated(command *user_rated.UserRatedCommand) (metrics_
 			-1),
 		nil
 }
+
+func (h Handler) createGuestCheckedIn(command *guest_checked_in.GuestCheckedInCommand) (metrics_events.Event, error) {
+	return guest_checked_in_event.NewEvent(
+			command.UserID,
+			command.AccommodationID,
+			command.ReservationID,
+			command.CheckedInAt,
+			-1),
+		nil
+}
+
+func (h Handler) createGuestCheckedOut(command *guest_checked_out.GuestCheckedOutCommand) (metrics_events.Event, error) {
+	return guest_checked_out_event.NewEvent(
+			command.UserID,
+			command.AccommodationID,
+			command.ReservationID,
+			command.CheckedOutAt,
+			-1),
+		nil
+}
@@ -0,0 +1,17 @@
+package user_reservation_retracted
+
+import "metrics-command/commands"
+
+type UserReservationRetractedCommand struct {
+	UserID          string
+	AccommodationID string
+	ReservedAt      string
+}
+
+func NewCommand(userID, accommodationID, reservedAt string) commands.Command {
+	return &UserReservationRetractedCommand{
+		UserID:          userID,
+		AccommodationID: accommodationID,
+		ReservedAt:      reservedAt,
+	}
+}
@@ -0,0 +1,11 @@
+package domains
+
+import "time"
+
+// Stay is the check-in or check-out of the guest of a reservation.
+type Stay struct {
+	UserID          string    `json:"userID"`
+	AccommodationID string    `json:"accommodationID"`
+	ReservationID   string    `json:"reservationID"`
+	At              time.Time `json:"at"`
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	events "example/saga/create_reservation"
+	saga "example/saga/messaging"
+	"log"
+	"metrics-command/commands/handler"
+	"metrics-command/commands/user_reservation_retracted"
+	"metrics-command/commands/user_reserved"
+)
+
+// CreateReservationCommandHandler is the metrics-command participant of the create
+// reservation saga: it records the UserReserved event of a confirmed reservation and
+// retracts it when a later step of the saga failed.
+type CreateReservationCommandHandler struct {
+	handler           handler.Handler
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+}
+
+func NewCreateReservationCommandHandler(handler handler.Handler, publisher saga.Publisher, subscriber saga.Subscriber) (*CreateReservationCommandHandler, error) {
+	o := &CreateReservationCommandHandler{
+		handler:           handler,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (h *CreateReservationCommandHandler) handle(command *events.CreateReservationCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	if command.Type == events.RetractUserReserved {
+		err := h.handler.Handle(user_reservation_retracted.NewCommand(details.UserID, details.AccommodationID, details.ReservedAt))
+		if err != nil {
+			log.Println(err)
+		}
+		return err
+	}
+	if command.Type != events.RecordUserReserved {
+		return nil
+	}
+	err := h.handler.Handle(user_reserved.NewCommand(details.UserID, details.AccommodationID, details.ReservedAt))
+	if err != nil {
+		// The event store being unreachable is transient, let the command be redelivered.
+		log.Println(err)
+		return err
+	}
+	reply := events.CreateReservationReply{
+		Type:    events.UserReservedRecorded,
+		Payload: details,
+	}
+	return saga.Reply(h.replyPublisher, reply, envelope)
+}
@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"metrics-command/commands/guest_checked_in"
+	"metrics-command/commands/guest_checked_out"
+	"metrics-command/commands/handler"
+	"metrics-command/domains"
+	"metrics-command/utils"
+	"net/http"
+)
+
+type StayHandler struct {
+	handler handler.Handler
+}
+
+func NewStayHandler(handler handler.Handler) *StayHandler {
+	return &StayHandler{
+		handler: handler,
+	}
+}
+
+func (h StayHandler) CreateCheckedIn(w http.ResponseWriter, r *http.Request) {
+	var req domains.Stay
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println(err)
+		utils.WriteErrorResp(err.Error(), 400, "api/metrics/checkedIn", w)
+		return
+	}
+	command := guest_checked_in.NewCommand(req.UserID, req.AccommodationID, req.ReservationID, req.At)
+	err = h.handler.Handle(command)
+	if err != nil {
+		log.Println(err)
+		utils.WriteErrorResp(err.Error(), 400, "api/metrics/checkedIn", w)
+		return
+	}
+	utils.WriteResp(string("Successfully checked in"), 200, w)
+}
+
+func (h StayHandler) CreateCheckedOut(w http.ResponseWriter, r *http.Request) {
+	var req domains.Stay
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Println(err)
+		utils.WriteErrorResp(err.Error(), 400, "api/metrics/checkedOut", w)
+		return
+	}
+	command := guest_checked_out.NewCommand(req.UserID, req.AccommodationID, req.ReservationID, req.At)
+	err = h.handler.Handle(command)
+	if err != nil {
+		log.Println(err)
+		utils.WriteErrorResp(err.Error(), 400, "api/metrics/checkedOut", w)
+		return
+	}
+	utils.WriteResp(string("Successfully checked out"), 200, w)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"example/saga/messaging/nats"
 	"fmt"
 	"log"
 	"metrics-command/commands/handler"
@@ -39,6 +40,34 @@ func main() {
 	userHandler := handlers.NewUserHandler(commandHandler)
 	reservationHandler := handlers.NewReservationHandler(commandHandler)
 	ratingHandler := handlers.NewRatingHandler(commandHandler)
+	stayHandler := handlers.NewStayHandler(commandHandler)
+
+	// saga
+
+	replyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	commandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_COMMAND_SUBJECT"),
+		"metrics-command")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handlers.NewCreateReservationCommandHandler(commandHandler, replyPublisher, commandSubscriber)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	port := os.Getenv("PORT")
 
@@ -47,6 +76,8 @@ func main() {
 	router.HandleFunc("/leftAt", userHandler.CreateLeftAt).Methods("POST")
 	router.HandleFunc("/reserved", reservationHandler.CreateReserved).Methods("POST")
 	router.HandleFunc("/rated", ratingHandler.CreateRatedAt).Methods("POST")
+	router.HandleFunc("/checkedIn", stayHandler.CreateCheckedIn).Methods("POST")
+	router.HandleFunc("/checkedOut", stayHandler.CreateCheckedOut).Methods("POST")
 	if len(port) == 0 {
 		port = "8080"
 	}
@@ -81,6 +112,7 @@ func main() {
 	if server.Shutdown(timeoutContext) != nil {
 		log.Fatalf("Cannot gracefully shutdown...")
 	}
+	nats.Drain()
 	log.Println("Server stopped")
 
 }
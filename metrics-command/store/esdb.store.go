@@ -2,7 +2,10 @@ package store
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"example/saga/messaging"
+	"time"
 
 	"github.com/EventStore/EventStore-Client-Go/esdb"
 	"github.com/gofrs/uuid"
@@ -23,36 +26,49 @@ func NewESDBStore(client *esdb.Client) EventStore {
 	}
 }
 
-func (e ESDBStore) Store(stream string, eventType string, event []byte) error {
-	id, err := uuid.NewV4()
+func (e ESDBStore) Store(stream string, eventType string, schemaVersion int, event []byte) error {
+	eventData, err := newEventData(eventType, schemaVersion, event)
 	if err != nil {
 		return err
 	}
-	eventData := esdb.EventData{
-		EventID:     id,
-		EventType:   eventType,
-		Data:        event,
-		ContentType: esdb.JsonContentType,
-	}
 	opts := esdb.AppendToStreamOptions{}
 	_, err = e.client.AppendToStream(context.Background(), stream, opts, eventData)
 	return err
 }
 
-func (e ESDBStore) StoreAndExpectLastEventNumber(stream string, eventType string, event []byte, lastEventNumber uint64) error {
-	id, err := uuid.NewV4()
+func (e ESDBStore) StoreAndExpectLastEventNumber(stream string, eventType string, schemaVersion int, event []byte, lastEventNumber uint64) error {
+	eventData, err := newEventData(eventType, schemaVersion, event)
 	if err != nil {
 		return err
 	}
-	eventData := esdb.EventData{
-		EventID:     id,
-		EventType:   eventType,
-		Data:        event,
-		ContentType: esdb.JsonContentType,
-	}
 	opts := esdb.AppendToStreamOptions{
 		ExpectedRevision: esdb.Revision(lastEventNumber),
 	}
 	_, err = e.client.AppendToStream(context.Background(), stream, opts, eventData)
 	return err
 }
+
+// newEventData keeps the envelope of the event, without its payload, as the event
+// metadata so readers know which schema version the data is in.
+func newEventData(eventType string, schemaVersion int, event []byte) (esdb.EventData, error) {
+	id, err := uuid.NewV4()
+	if err != nil {
+		return esdb.EventData{}, err
+	}
+	metadata, err := json.Marshal(messaging.Envelope{
+		MessageID:     id.String(),
+		MessageType:   eventType,
+		SchemaVersion: schemaVersion,
+		Timestamp:     time.Now().UTC(),
+	})
+	if err != nil {
+		return esdb.EventData{}, err
+	}
+	return esdb.EventData{
+		EventID:     id,
+		EventType:   eventType,
+		Data:        event,
+		Metadata:    metadata,
+		ContentType: esdb.JsonContentType,
+	}, nil
+}
@@ -1,6 +1,6 @@
 package store
 
 type EventStore interface {
-	Store(stream string, eventType string, event []byte) error
-	StoreAndExpectLastEventNumber(stream string, eventType string, event []byte, lastEventNumber uint64) error
+	Store(stream string, eventType string, schemaVersion int, event []byte) error
+	StoreAndExpectLastEventNumber(stream string, eventType string, schemaVersion int, event []byte, lastEventNumber uint64) error
 }
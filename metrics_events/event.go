@@ -1,14 +1,18 @@
 package metrics_events
 
 const (
-	EventTypeUserJoined   = "UserJoined"
-	EventTypeUserLeft     = "UserLeft"
-	EventTypeUserRated    = "UserRated"
-	EventTypeUserReserved = "UserReserved"
+	EventTypeUserJoined               = "UserJoined"
+	EventTypeUserLeft                 = "UserLeft"
+	EventTypeUserRated                = "UserRated"
+	EventTypeUserReserved             = "UserReserved"
+	EventTypeGuestCheckedIn           = "GuestCheckedIn"
+	EventTypeGuestCheckedOut          = "GuestCheckedOut"
+	EventTypeUserReservationRetracted = "UserReservationRetracted"
 )
 
 type Event interface {
 	Type() string
+	SchemaVersion() int
 	ToJSON() ([]byte, error)
 	FromJSON(jsonEvent []byte) error
 	Number() uint64
@@ -1,3 +1,7 @@
 module example/metrics_events
 
-go 1.21.2
+go 1.21.6
+
+require example/saga v1.0.0
+
+replace example/saga => ../saga
@@ -0,0 +1,67 @@
+package guest_checked_in
+
+import (
+	"encoding/json"
+	metrics_events "example/metrics_events"
+	"time"
+)
+
+// Event records that the host let the guest of a reservation in.
+type Event struct {
+	UserID                  string
+	AccommodationID         string
+	ReservationID           string
+	CheckedInAt             time.Time
+	expectedLastEventNumber int64
+	number                  uint64
+}
+
+func NewEvent(userID, accommodationID, reservationID string, checkedInAt time.Time, expectedLastEventNumber int64) metrics_events.Event {
+	return &Event{
+		UserID:                  userID,
+		AccommodationID:         accommodationID,
+		ReservationID:           reservationID,
+		CheckedInAt:             checkedInAt,
+		expectedLastEventNumber: expectedLastEventNumber,
+	}
+}
+
+func NewEmptyEvent() metrics_events.Event {
+	return &Event{}
+}
+
+func (e *Event) Type() string {
+	return metrics_events.EventTypeGuestCheckedIn
+}
+
+func (e *Event) SchemaVersion() int {
+	return 1
+}
+
+func (e *Event) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *Event) FromJSON(jsonEvent []byte) error {
+	return json.Unmarshal(jsonEvent, e)
+}
+
+func (e *Event) Number() uint64 {
+	return e.number
+}
+
+func (e *Event) SetNumber(number uint64) {
+	e.number = number
+}
+
+func (e *Event) Stream() string {
+	return "guest_checked_in"
+}
+
+func (e *Event) ExpectedLastEventNumber() int64 {
+	return e.expectedLastEventNumber
+}
+
+func (e *Event) SetExpectedLastEventNumber(number uint64) {
+	e.expectedLastEventNumber = int64(number)
+}
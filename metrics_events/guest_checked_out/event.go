@@ -0,0 +1,68 @@
+package guest_checked_out
+
+import (
+	"encoding/json"
+	metrics_events "example/metrics_events"
+	"time"
+)
+
+// Event records that the guest of a reservation left, as the host marked it or
+// once the stay was over.
+type Event struct {
+	UserID                  string
+	AccommodationID         string
+	ReservationID           string
+	CheckedOutAt            time.Time
+	expectedLastEventNumber int64
+	number                  uint64
+}
+
+func NewEvent(userID, accommodationID, reservationID string, checkedOutAt time.Time, expectedLastEventNumber int64) metrics_events.Event {
+	return &Event{
+		UserID:                  userID,
+		AccommodationID:         accommodationID,
+		ReservationID:           reservationID,
+		CheckedOutAt:            checkedOutAt,
+		expectedLastEventNumber: expectedLastEventNumber,
+	}
+}
+
+func NewEmptyEvent() metrics_events.Event {
+	return &Event{}
+}
+
+func (e *Event) Type() string {
+	return metrics_events.EventTypeGuestCheckedOut
+}
+
+func (e *Event) SchemaVersion() int {
+	return 1
+}
+
+func (e *Event) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *Event) FromJSON(jsonEvent []byte) error {
+	return json.Unmarshal(jsonEvent, e)
+}
+
+func (e *Event) Number() uint64 {
+	return e.number
+}
+
+func (e *Event) SetNumber(number uint64) {
+	e.number = number
+}
+
+func (e *Event) Stream() string {
+	return "guest_checked_out"
+}
+
+func (e *Event) ExpectedLastEventNumber() int64 {
+	return e.expectedLastEventNumber
+}
+
+func (e *Event) SetExpectedLastEventNumber(number uint64) {
+	e.expectedLastEventNumber = int64(number)
+}
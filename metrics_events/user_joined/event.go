@@ -31,6 +31,10 @@ func (e *Event) Type() string {
 	return metrics_events.EventTypeUserJoined
 }
 
+func (e *Event) SchemaVersion() int {
+	return 1
+}
+
 func (e *Event) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
@@ -32,6 +32,10 @@ func (e *Event) Type() string {
 	return metrics_events.EventTypeUserLeft
 }
 
+func (e *Event) SchemaVersion() int {
+	return 1
+}
+
 func (e *Event) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
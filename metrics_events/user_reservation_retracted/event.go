@@ -0,0 +1,69 @@
+package user_reservation_retracted
+
+import (
+	"encoding/json"
+	metrics_events "example/metrics_events"
+	"time"
+)
+
+// Event takes back the UserReserved event of a reservation whose saga failed after
+// it was recorded. ReservedAt is the time of the retracted event, so the reports
+// that counted it can be found.
+type Event struct {
+	UserID                  string
+	AccommodationID         string
+	ReservedAt              time.Time
+	expectedLastEventNumber int64
+	number                  uint64
+}
+
+func NewEvent(userID, accommodationID string, reservedAt time.Time, expectedLastEventNumber int64) metrics_events.Event {
+	return &Event{
+		UserID:                  userID,
+		AccommodationID:         accommodationID,
+		ReservedAt:              reservedAt,
+		expectedLastEventNumber: expectedLastEventNumber,
+	}
+}
+
+func NewEmptyEvent() metrics_events.Event {
+	return &Event{}
+}
+
+func (e *Event) Type() string {
+	return metrics_events.EventTypeUserReservationRetracted
+}
+
+func (e *Event) SchemaVersion() int {
+	return 1
+}
+
+func (e *Event) ToJSON() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+func (e *Event) FromJSON(jsonEvent []byte) error {
+	return json.Unmarshal(jsonEvent, e)
+}
+
+func (e *Event) Number() uint64 {
+	return e.number
+}
+
+func (e *Event) SetNumber(number uint64) {
+	e.number = number
+}
+
+// Stream is the stream of UserReserved, so a retraction is always read after the
+// event it takes back.
+func (e *Event) Stream() string {
+	return "user_reserved"
+}
+
+func (e *Event) ExpectedLastEventNumber() int64 {
+	return e.expectedLastEventNumber
+}
+
+func (e *Event) SetExpectedLastEventNumber(number uint64) {
+	e.expectedLastEventNumber = int64(number)
+}
@@ -3,17 +3,30 @@ package user_reserved
 import (
 	"encoding/json"
 	metrics_events "example/metrics_events"
+	"example/saga/messaging"
+	"time"
 )
 
+// Version 1 of the event kept ReservedAt as a "2006-01-02 15:04" string, without
+// seconds or time zone. Version 2 keeps it as a time.Time.
+const (
+	schemaVersion = 2
+	TimeLayoutV1  = "2006-01-02 15:04"
+)
+
+func init() {
+	messaging.RegisterUpcaster(metrics_events.EventTypeUserReserved, 1, upcastV1)
+}
+
 type Event struct {
 	UserID                  string
 	AccommodationID         string
-	ReservedAt              string
+	ReservedAt              time.Time
 	expectedLastEventNumber int64
 	number                  uint64
 }
 
-func NewEvent(userID, accommodationID, reservedAt string, expectedLastEventNumber int64) metrics_events.Event {
+func NewEvent(userID, accommodationID string, reservedAt time.Time, expectedLastEventNumber int64) metrics_events.Event {
 	return &Event{
 		UserID:                  userID,
 		AccommodationID:         accommodationID,
@@ -30,6 +43,10 @@ func (e *Event) Type() string {
 	return metrics_events.EventTypeUserReserved
 }
 
+func (e *Event) SchemaVersion() int {
+	return schemaVersion
+}
+
 func (e *Event) ToJSON() ([]byte, error) {
 	return json.Marshal(e)
 }
@@ -57,3 +74,24 @@ func (e *Event) ExpectedLastEventNumber() int64 {
 func (e *Event) SetExpectedLastEventNumber(number uint64) {
 	e.expectedLastEventNumber = int64(number)
 }
+
+// upcastV1 parses the version 1 ReservedAt string into a time.Time.
+func upcastV1(payload json.RawMessage) (json.RawMessage, error) {
+	var v1 struct {
+		UserID          string
+		AccommodationID string
+		ReservedAt      string
+	}
+	if err := json.Unmarshal(payload, &v1); err != nil {
+		return nil, err
+	}
+	reservedAt, err := time.Parse(TimeLayoutV1, v1.ReservedAt)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&Event{
+		UserID:          v1.UserID,
+		AccommodationID: v1.AccommodationID,
+		ReservedAt:      reservedAt,
+	})
+}
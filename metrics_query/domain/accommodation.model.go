@@ -17,6 +17,10 @@ type Accommodation struct {
 	LastAppliedUserReservedEventNumber int64             `json:"lastAppliedUserReservedEventNumber" bson:"lastAppliedUserReservedEventNumber"`
 	NumberOfRatings                    uint32            `json:"numberOfRatings" bson:"numberOfRatings"`
 	LastAppliedUserRatedEventNumber    int64             `json:"lastAppliedUserRatedEventNumber" bson:"lastAppliedUserRatedEventNumber"`
+	NumberOfCheckIns                   uint32            `json:"numberOfCheckIns" bson:"numberOfCheckIns"`
+	LastAppliedCheckedInEventNumber    int64             `json:"lastAppliedCheckedInEventNumber" bson:"lastAppliedCheckedInEventNumber"`
+	NumberOfCheckOuts                  uint32            `json:"numberOfCheckOuts" bson:"numberOfCheckOuts"`
+	LastAppliedCheckedOutEventNumber   int64             `json:"lastAppliedCheckedOutEventNumber" bson:"lastAppliedCheckedOutEventNumber"`
 }
 
 type DBAccommodation struct {
@@ -31,4 +35,8 @@ type DBAccommodation struct {
 	LastAppliedUserReservedEventNumber int64              `json:"lastAppliedUserReservedEventNumber" bson:"lastAppliedUserReservedEventNumber"`
 	NumberOfRatings                    uint32             `json:"numberOfRatings" bson:"numberOfRatings"`
 	LastAppliedUserRatedEventNumber    int64              `json:"lastAppliedUserRatedEventNumber" bson:"lastAppliedUserRatedEventNumber"`
+	NumberOfCheckIns                   uint32             `json:"numberOfCheckIns" bson:"numberOfCheckIns"`
+	LastAppliedCheckedInEventNumber    int64              `json:"lastAppliedCheckedInEventNumber" bson:"lastAppliedCheckedInEventNumber"`
+	NumberOfCheckOuts                  uint32             `json:"numberOfCheckOuts" bson:"numberOfCheckOuts"`
+	LastAppliedCheckedOutEventNumber   int64              `json:"lastAppliedCheckedOutEventNumber" bson:"lastAppliedCheckedOutEventNumber"`
 }
@@ -2,9 +2,12 @@ package events
 
 import (
 	"example/metrics_events"
+	guest_checked_in "example/metrics_events/guest_checked_in"
+	guest_checked_out "example/metrics_events/guest_checked_out"
 	user_joined "example/metrics_events/user_joined"
 	user_left "example/metrics_events/user_left"
 	user_rated "example/metrics_events/user_rated"
+	user_reservation_retracted "example/metrics_events/user_reservation_retracted"
 	user_reserved "example/metrics_events/user_reserved"
 	"log"
 	"metrics_query/domain"
@@ -221,11 +224,7 @@ func (h EventHandler) Handle(event metrics_events.Event) error {
 			}
 		}
 	case *user_reserved.Event:
-		eventDateStr, err := getTimeFromString(e.ReservedAt)
-		if err != nil {
-			return err
-		}
-		eventDay := getDayStart(*eventDateStr)
+		eventDay := getDayStart(e.ReservedAt)
 		accommodation, err := h.store.Read(e.AccommodationID, daily)
 		if err != nil {
 			return err
@@ -274,6 +273,78 @@ func (h EventHandler) Handle(event metrics_events.Event) error {
 				log.Println(err)
 			}
 		}
+
+	case *user_reservation_retracted.Event:
+		// Reports of an earlier day or month are closed, a retraction only changes
+		// the reports that are still open.
+		eventDay := getDayStart(e.ReservedAt)
+		accommodation, err := h.store.Read(e.AccommodationID, daily)
+		if err != nil {
+			return err
+		}
+		monthlyAccommodation, err := h.store.Read(e.AccommodationID, monthly)
+		if err != nil {
+			return err
+		}
+		if checkDay(accommodation.ReportingDate, eventDay) && accommodation.NumberOfReservations > 0 {
+			accommodation.NumberOfReservations -= 1
+			if err := h.store.Update(*accommodation, daily); err != nil {
+				log.Println(err)
+			}
+		}
+		if checkMonth(monthlyAccommodation.ReportingDate, eventDay) && monthlyAccommodation.NumberOfReservations > 0 {
+			monthlyAccommodation.NumberOfReservations -= 1
+			if err := h.store.Update(*monthlyAccommodation, monthly); err != nil {
+				log.Println(err)
+			}
+		}
+
+	case *guest_checked_in.Event:
+		return h.countStay(e.AccommodationID, e.CheckedInAt, func(accommodation *domain.Accommodation) {
+			accommodation.NumberOfCheckIns += 1
+			accommodation.LastAppliedCheckedInEventNumber = int64(e.Number())
+		})
+
+	case *guest_checked_out.Event:
+		return h.countStay(e.AccommodationID, e.CheckedOutAt, func(accommodation *domain.Accommodation) {
+			accommodation.NumberOfCheckOuts += 1
+			accommodation.LastAppliedCheckedOutEventNumber = int64(e.Number())
+		})
+	}
+	return nil
+}
+
+// countStay applies a check-in or check-out to the daily and monthly reports of the
+// accommodation, starting new reports when the event falls on a later day or month.
+// Accommodations nobody viewed yet get their reports here.
+func (h EventHandler) countStay(accommodationID string, at time.Time, count func(*domain.Accommodation)) error {
+	eventDay := getDayStart(at)
+	accommodation, err := h.store.Read(accommodationID, "daily")
+	if err != nil {
+		accommodation = generateBlankReport(eventDay, accommodationID)
+		count(accommodation)
+		if err := h.store.Create(*accommodation, "daily"); err != nil {
+			return err
+		}
+		return h.store.Create(*accommodation, "monthly")
+	}
+	monthlyAccommodation, err := h.store.Read(accommodationID, "monthly")
+	if err != nil {
+		return err
+	}
+	if !checkDay(accommodation.ReportingDate, eventDay) {
+		accommodation = generateBlankReport(eventDay, accommodationID)
+	}
+	if !checkMonth(monthlyAccommodation.ReportingDate, eventDay) {
+		monthlyAccommodation = generateBlankReport(eventDay, accommodationID)
+	}
+	count(accommodation)
+	count(monthlyAccommodation)
+	if err := h.store.Update(*accommodation, "daily"); err != nil {
+		log.Println(err)
+	}
+	if err := h.store.Update(*monthlyAccommodation, "monthly"); err != nil {
+		log.Println(err)
 	}
 	return nil
 }
@@ -323,6 +394,10 @@ func generateBlankReport(tm time.Time, id string) *domain.Accommodation {
 		LastAppliedUserReservedEventNumber: -1,
 		NumberOfRatings:                    0,
 		LastAppliedUserRatedEventNumber:    -1,
+		NumberOfCheckIns:                   0,
+		LastAppliedCheckedInEventNumber:    -1,
+		NumberOfCheckOuts:                  0,
+		LastAppliedCheckedOutEventNumber:   -1,
 	}
 	return &accommodation
 }
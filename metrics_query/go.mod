@@ -1,6 +1,6 @@
 module metrics_query
 
-go 1.21.2
+go 1.21.6
 
 require (
 	example/metrics_events v0.0.0-00010101000000-000000000000
@@ -33,3 +33,7 @@ require (
 )
 
 replace example/metrics_events => ../metrics_events
+
+require example/saga v1.0.0
+
+replace example/saga => ../saga
@@ -57,6 +57,10 @@ func (a AccommodationStore) Update(accommodation domain.Accommodation, collectio
 			{"lastAppliedUserReservedEventNumber", acc.LastAppliedUserReservedEventNumber},
 			{"numberOfRatings", acc.NumberOfRatings},
 			{"lastAppliedUserRatedEventNumber", acc.LastAppliedUserRatedEventNumber},
+			{"numberOfCheckIns", acc.NumberOfCheckIns},
+			{"lastAppliedCheckedInEventNumber", acc.LastAppliedCheckedInEventNumber},
+			{"numberOfCheckOuts", acc.NumberOfCheckOuts},
+			{"lastAppliedCheckedOutEventNumber", acc.LastAppliedCheckedOutEventNumber},
 		}},
 	}
 	_, err = db.UpdateOne(context.TODO(), filter, update)
@@ -85,6 +89,10 @@ func accToDBAcc(acc domain.Accommodation) (*domain.DBAccommodation, error) {
 		LastAppliedUserReservedEventNumber: acc.LastAppliedUserReservedEventNumber,
 		NumberOfRatings:                    acc.NumberOfRatings,
 		LastAppliedUserRatedEventNumber:    acc.LastAppliedUserRatedEventNumber,
+		NumberOfCheckIns:                   acc.NumberOfCheckIns,
+		LastAppliedCheckedInEventNumber:    acc.LastAppliedCheckedInEventNumber,
+		NumberOfCheckOuts:                  acc.NumberOfCheckOuts,
+		LastAppliedCheckedOutEventNumber:   acc.LastAppliedCheckedOutEventNumber,
 	}
 	return &ret, nil
 }
@@ -102,6 +110,10 @@ func dbToAcc(acc domain.DBAccommodation) *domain.Accommodation {
 		LastAppliedUserReservedEventNumber: acc.LastAppliedUserReservedEventNumber,
 		NumberOfRatings:                    acc.NumberOfRatings,
 		LastAppliedUserRatedEventNumber:    acc.LastAppliedUserRatedEventNumber,
+		NumberOfCheckIns:                   acc.NumberOfCheckIns,
+		LastAppliedCheckedInEventNumber:    acc.LastAppliedCheckedInEventNumber,
+		NumberOfCheckOuts:                  acc.NumberOfCheckOuts,
+		LastAppliedCheckedOutEventNumber:   acc.LastAppliedCheckedOutEventNumber,
 	}
 	return &ret
 }
@@ -2,11 +2,16 @@ package stream
 
 import (
 	"context"
+	"encoding/json"
 	"example/metrics_events"
+	guest_checked_in "example/metrics_events/guest_checked_in"
+	guest_checked_out "example/metrics_events/guest_checked_out"
 	user_joined "example/metrics_events/user_joined"
 	user_left "example/metrics_events/user_left"
 	user_rated "example/metrics_events/user_rated"
+	user_reservation_retracted "example/metrics_events/user_reservation_retracted"
 	user_reserved "example/metrics_events/user_reserved"
+	"example/saga/messaging"
 
 	"github.com/EventStore/EventStore-Client-Go/esdb"
 	"log"
@@ -56,13 +61,28 @@ func (s *ESDBEventStream) Process(processFn func(metrics_events.Event) error) {
 				event = user_rated.NewEmptyEvent()
 			case metrics_events.EventTypeUserReserved:
 				event = user_reserved.NewEmptyEvent()
+			case metrics_events.EventTypeUserReservationRetracted:
+				event = user_reservation_retracted.NewEmptyEvent()
+			case metrics_events.EventTypeGuestCheckedIn:
+				event = guest_checked_in.NewEmptyEvent()
+			case metrics_events.EventTypeGuestCheckedOut:
+				event = guest_checked_out.NewEmptyEvent()
 			}
 			if event == nil {
 				log.Println("unknown event type")
 				continue
 			}
 			event.SetNumber(streamEvent.EventNumber)
-			err := event.FromJSON(streamEvent.Data)
+			data, err := upcast(streamEvent.EventType, streamEvent.UserMetadata, streamEvent.Data)
+			if err == nil {
+				err = event.FromJSON(data)
+			}
+			if err != nil {
+				// retrying cannot fix an event that does not decode
+				log.Println(err)
+				s.sub.Nack(err.Error(), esdb.Nack_Park, e.EventAppeared)
+				continue
+			}
 			err = processFn(event)
 			if err != nil {
 				log.Println(err)
@@ -91,3 +111,15 @@ func (s *ESDBEventStream) subscribe() error {
 	s.sub = sub
 	return nil
 }
+
+// upcast brings the event data to the current schema version. The version is read
+// from the envelope kept in the event metadata; events written before the envelope
+// existed have none and are version 1.
+func upcast(eventType string, metadata, data []byte) ([]byte, error) {
+	version := 1
+	var envelope messaging.Envelope
+	if len(metadata) > 0 && json.Unmarshal(metadata, &envelope) == nil && envelope.SchemaVersion > 0 {
+		version = envelope.SchemaVersion
+	}
+	return messaging.Upcast(eventType, version, data)
+}
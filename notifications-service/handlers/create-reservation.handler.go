@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"context"
+	events "example/saga/create_reservation"
+	saga "example/saga/messaging"
+	"fmt"
+	"notifications-service/config"
+	"notifications-service/domains"
+	"notifications-service/services"
+)
+
+// CreateReservationCommandHandler is the notifications-service participant of the
+// create reservation saga: it tells the host about the new reservation.
+type CreateReservationCommandHandler struct {
+	service           *services.NotificationService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewCreateReservationCommandHandler(service *services.NotificationService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*CreateReservationCommandHandler, error) {
+	o := &CreateReservationCommandHandler{
+		service:           service,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("notifications-saga-handler", "Unable to subscribe to create reservation commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *CreateReservationCommandHandler) handle(command *events.CreateReservationCommand, envelope *saga.Envelope) error {
+	if command.Type != events.NotifyHost {
+		return nil
+	}
+	details := command.Payload
+	reply := events.CreateReservationReply{Type: events.HostNotified, Payload: details}
+	notification := domains.Notification{
+		Text: fmt.Sprintf("Reservation successfully created: %s reserved %s from %s to %s",
+			details.Username, details.AccommodationName, details.DateRange[0], details.DateRange[len(details.DateRange)-1]),
+	}
+	if _, err := handler.service.PushNewNotificationToUser(context.Background(), details.HostID, notification); err != nil {
+		if err.GetErrorStatus() >= 500 {
+			// Likely transient, let the command be redelivered.
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		handler.logger.LogError("notifications-saga-handler", fmt.Sprintf("Unable to notify host %s: %s", details.HostID, err.GetErrorMessage()))
+		reply.Type = events.HostNotNotified
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"context"
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+	"notifications-service/config"
+	"notifications-service/services"
+)
+
+type DeleteUserCommandHandler struct {
+	service           *services.NotificationService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewDeleteUserCommandHandler(service *services.NotificationService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		service:           service,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("notifications-saga-handler", "Unable to subscribe to delete user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.DeleteUserReply{Payload: details}
+	switch command.Type {
+	case events.ArchiveUserNotifications:
+		reply.Type = events.UserNotificationsArchived
+		if err := handler.service.ArchiveUserNotifications(context.Background(), details.UserID); err != nil {
+			handler.logger.LogError("notifications-saga-handler", fmt.Sprintf("Unable to archive notifications of %s: %s", details.UserID, err.GetErrorMessage()))
+			reply.Type = events.UserNotificationsNotArchived
+		}
+	case events.RestoreUserNotifications:
+		if err := handler.service.RestoreUserNotifications(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
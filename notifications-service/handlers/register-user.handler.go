@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	saga "example/saga/messaging"
+	events "example/saga/register_user"
+	"fmt"
+	"notifications-service/config"
+	"notifications-service/services"
+)
+
+// RegisterUserCommandHandler is the notifications-service participant of the
+// register user saga: it creates the inbox of a new user.
+type RegisterUserCommandHandler struct {
+	service           *services.NotificationService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewRegisterUserCommandHandler(service *services.NotificationService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*RegisterUserCommandHandler, error) {
+	o := &RegisterUserCommandHandler{
+		service:           service,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError("notifications-saga-handler", "Unable to subscribe to register user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *RegisterUserCommandHandler) handle(command *events.RegisterUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.RegisterUserReply{Payload: details}
+	switch command.Type {
+	case events.CreateUserInbox:
+		reply.Type = events.UserInboxCreated
+		if err := handler.service.CreateUserInbox(context.Background(), details.UserID); err != nil {
+			if err.GetErrorStatus() >= 500 {
+				// Likely transient, let the command be redelivered.
+				return fmt.Errorf("%s", err.GetErrorMessage())
+			}
+			handler.logger.LogError("notifications-saga-handler", fmt.Sprintf("Unable to create inbox of %s: %s", details.UserID, err.GetErrorMessage()))
+			reply.Type = events.UserInboxNotCreated
+		}
+	case events.RemoveUserInbox:
+		if err := handler.service.RemoveUserInbox(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"example/saga/messaging/nats"
 	"log"
 	"net/http"
 	"notifications-service/client"
@@ -89,6 +90,78 @@ func main() {
 	notificationRepository := repository.NewNotificationRepository(mongoService.GetCli(), logger, tracer)
 	notificationService := services.NewNotificationService(notificationRepository, mailClient, userClient, logger, tracer)
 	notificationHandler := handlers.NewNotificationHandler(notificationService, tracer)
+	deleteUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deleteUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"notifications-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handlers.NewDeleteUserCommandHandler(notificationService, deleteUserReplyPublisher, deleteUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_COMMAND_SUBJECT"),
+		"notifications-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handlers.NewRegisterUserCommandHandler(notificationService, registerUserReplyPublisher, registerUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	createReservationReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	createReservationCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_COMMAND_SUBJECT"),
+		"notifications-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handlers.NewCreateReservationCommandHandler(notificationService, createReservationReplyPublisher, createReservationCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
 
 	// router definitions
 
@@ -134,6 +207,7 @@ func main() {
 	if server.Shutdown(timeoutContext) != nil {
 		logger.Fatalln(err.Error())
 	}
+	nats.Drain()
 	logger.Println("Server stopped")
 
 }
@@ -9,6 +9,7 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -92,3 +93,69 @@ func (nr NotificationRepository) UpdateNotificationByID(ctx context.Context, use
 	nr.logger.LogInfo("notifications-repository", "Updating user notifications.")
 	return userNotification, nil
 }
+
+// DeleteUserNotification removes the notification structure of a user whose
+// registration failed. A missing structure is not an error.
+func (nr NotificationRepository) DeleteUserNotification(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := nr.tracer.Start(ctx, "NotificationRepository.DeleteUserNotification")
+	defer span.End()
+	userCollection := nr.cli.Database("notifications").Collection("notifications")
+	primitiveObjectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 400)
+	}
+	_, err = userCollection.DeleteOne(context.TODO(), bson.M{"_id": primitiveObjectID})
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	nr.logger.LogInfo("notifications-repository", "Deleted structure for user notification "+id)
+	return nil
+}
+
+// ArchiveUserNotifications moves the notifications of a user being deleted into
+// the archive collection; RestoreUserNotifications moves them back.
+func (nr NotificationRepository) ArchiveUserNotifications(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := nr.tracer.Start(ctx, "NotificationRepository.ArchiveUserNotifications")
+	defer span.End()
+	return nr.moveUserNotifications(id, "notifications", "archived_notifications")
+}
+
+func (nr NotificationRepository) RestoreUserNotifications(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := nr.tracer.Start(ctx, "NotificationRepository.RestoreUserNotifications")
+	defer span.End()
+	return nr.moveUserNotifications(id, "archived_notifications", "notifications")
+}
+
+func (nr NotificationRepository) moveUserNotifications(id, from, to string) *errors.ErrorStruct {
+	database := nr.cli.Database("notifications")
+	primitiveObjectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 400)
+	}
+	filter := bson.M{"_id": primitiveObjectID}
+	var userNotification bson.M
+	err = database.Collection(from).FindOne(context.TODO(), filter).Decode(&userNotification)
+	if err == mongo.ErrNoDocuments {
+		nr.logger.LogInfo("notifications-repository", "No notifications of user "+id+" in "+from)
+		return nil
+	}
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	_, err = database.Collection(to).ReplaceOne(context.TODO(), filter, userNotification, options.Replace().SetUpsert(true))
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	_, err = database.Collection(from).DeleteOne(context.TODO(), filter)
+	if err != nil {
+		nr.logger.LogError("notification-repository", err.Error())
+		return errors.NewError(err.Error(), 500)
+	}
+	nr.logger.LogInfo("notifications-repository", "Moved notifications of user "+id+" from "+from+" to "+to)
+	return nil
+}
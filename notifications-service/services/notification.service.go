@@ -48,6 +48,25 @@ func (ns NotificationService) CreateNewUserNotification(ctx context.Context, id
 	}, nil
 }
 
+// CreateUserInbox creates the notification structure for the register user saga.
+// A structure that already exists counts as created.
+func (ns NotificationService) CreateUserInbox(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := ns.tracer.Start(ctx, "NotificationService.CreateUserInbox")
+	defer span.End()
+	if _, err := ns.repo.FindOneUserNotificationByID(ctx, id); err == nil {
+		ns.logger.LogInfo("notification-service", fmt.Sprintf("User structure for user with id %v already exists", id))
+		return nil
+	}
+	_, err := ns.CreateNewUserNotification(ctx, id)
+	return err
+}
+
+func (ns NotificationService) RemoveUserInbox(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := ns.tracer.Start(ctx, "NotificationService.RemoveUserInbox")
+	defer span.End()
+	return ns.repo.DeleteUserNotification(ctx, id)
+}
+
 func (ns NotificationService) PushNewNotificationToUser(ctx context.Context, id string, notification domains.Notification) (*domains.UserNotificationDTO, *errors.ErrorStruct) {
 	ctx, span := ns.tracer.Start(ctx, "NotificationService.PushNewNotificationToUser")
 	defer span.End()
@@ -114,6 +133,18 @@ func (ns NotificationService) FindAllNotificationsByID(ctx context.Context, id s
 	}, nil
 }
 
+func (ns NotificationService) ArchiveUserNotifications(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := ns.tracer.Start(ctx, "NotificationService.ArchiveUserNotifications")
+	defer span.End()
+	return ns.repo.ArchiveUserNotifications(ctx, id)
+}
+
+func (ns NotificationService) RestoreUserNotifications(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := ns.tracer.Start(ctx, "NotificationService.RestoreUserNotifications")
+	defer span.End()
+	return ns.repo.RestoreUserNotifications(ctx, id)
+}
+
 func (ns NotificationService) makeAllNotificationsOpened(notifications *[]domains.Notification) *[]domains.Notification {
 	for i := range *notifications {
 		if (*notifications)[i].IsOpened {
@@ -1,15 +1,24 @@
 module recommendation-service
 
-go 1.21.2
+go 1.21.6
+
+require example/saga v1.0.0
 
 require (
 	github.com/gorilla/handlers v1.5.2
 	github.com/gorilla/mux v1.8.1
 	github.com/neo4j/neo4j-go-driver/v5 v5.15.0
+	github.com/sony/gobreaker v0.5.0
 )
 
 require (
-	github.com/eapache/go-resiliency v1.4.0 // indirect
 	github.com/felixge/httpsnoop v1.0.3 // indirect
-	github.com/sony/gobreaker v0.5.0 // indirect
+	github.com/klauspost/compress v1.17.2 // indirect
+	github.com/nats-io/nats.go v1.32.0 // indirect
+	github.com/nats-io/nkeys v0.4.7 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/sys v0.16.0 // indirect
 )
+
+replace example/saga => ../saga
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+	"log"
+	"recommendation-service/services"
+)
+
+type DeleteUserCommandHandler struct {
+	service           *services.RecommendationService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+}
+
+func NewDeleteUserCommandHandler(service *services.RecommendationService, publisher saga.Publisher, subscriber saga.Subscriber) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		service:           service,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.DeleteUserReply{Payload: details}
+	switch command.Type {
+	case events.ArchiveUserRatings:
+		reply.Type = events.UserRatingsArchived
+		if err := handler.service.ArchiveUser(details.UserID, details.AccommodationIDs); err != nil {
+			log.Println("Unable to archive ratings of user", details.UserID, err.GetErrorMessage())
+			reply.Type = events.UserRatingsNotArchived
+		}
+	case events.RestoreUserRatings:
+		if err := handler.service.RestoreUser(details.UserID, details.AccommodationIDs); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"example/saga/messaging/nats"
 	"log"
 	"net/http"
 	"os"
@@ -98,6 +99,30 @@ func main() {
 	recommendationRepository := repository.NewRecommendationRepository(neo4jService.GetDriver())
 	recommendationService := services.NewRecommendationService(recommendationRepository, accommodationClient)
 	recommendationHandler := handler.NewRecommendationHandler(recommendationService)
+	deleteUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deleteUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"recommendation-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewDeleteUserCommandHandler(recommendationService, deleteUserReplyPublisher, deleteUserCommandSubscriber)
+	if err != nil {
+		log.Fatal(err)
+	}
 	// routes
 
 	router := mux.NewRouter()
@@ -149,5 +174,6 @@ func main() {
 	if server.Shutdown(timeoutContext) != nil {
 		log.Fatal("Cannot gracefully shutdown...")
 	}
+	nats.Drain()
 	log.Println("Server stopped")
 }
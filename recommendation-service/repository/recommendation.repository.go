@@ -34,6 +34,7 @@ func (rr RecommendationRepository) GetAllRecommendationsForUser(id string, pastT
 				WHERE similarUser <> u AND r2.rate > 3.5
 				WITH u, accommodationsRatedByUser, collect(DISTINCT accommodation) AS accommodationsAbove
 				UNWIND accommodationsAbove AS a
+				WITH a WHERE a.archived IS NULL
 				MATCH (a)<-[ratings:RATED]-(guest:Guest)
 				WHERE ratings.rate >= 1 AND date(ratings.createdAt) >= date($passedDate)
 				WITH a, count(ratings) AS lowRatingsCount
@@ -81,6 +82,7 @@ func (rr RecommendationRepository) GetAllRecommendationsByRating() ([]domains.Re
 		func(transaction neo4j.ManagedTransaction) (any, error) {
 			result, err := transaction.Run(ctx,
 				`MATCH (a:Accommodation)<-[r:RATED]-()
+				WHERE a.archived IS NULL
 				WITH a, AVG(r.rate) AS averageRating
 				WHERE averageRating > 3.5
 				RETURN a.id AS accommodationId, averageRating AS avgRating
@@ -112,3 +114,48 @@ func (rr RecommendationRepository) GetAllRecommendationsByRating() ([]domains.Re
 	}
 	return recommendedAccommodations.([]domains.Recommendation), nil
 }
+
+// ArchiveUser hides a user that is being deleted, and the accommodations of a
+// host, from recommendations. RestoreUser undoes it.
+func (rr RecommendationRepository) ArchiveUser(userID string, accommodationIDs []string) *errors.ErrorStruct {
+	return rr.setArchived(userID, accommodationIDs,
+		`MATCH (n) WHERE (n:Guest OR n:Host) AND n.id = $userID SET n.archived = true`,
+		`UNWIND $accommodationIDs AS accommodationID
+		MATCH (a:Accommodation {id: accommodationID}) SET a.archived = true`)
+}
+
+func (rr RecommendationRepository) RestoreUser(userID string, accommodationIDs []string) *errors.ErrorStruct {
+	return rr.setArchived(userID, accommodationIDs,
+		`MATCH (n) WHERE (n:Guest OR n:Host) AND n.id = $userID REMOVE n.archived`,
+		`UNWIND $accommodationIDs AS accommodationID
+		MATCH (a:Accommodation {id: accommodationID}) REMOVE a.archived`)
+}
+
+func (rr RecommendationRepository) setArchived(userID string, accommodationIDs []string, userQuery, accommodationsQuery string) *errors.ErrorStruct {
+	ctx := context.Background()
+	session := rr.driver.NewSession(ctx, neo4j.SessionConfig{
+		DatabaseName: "neo4j",
+	})
+	defer session.Close(ctx)
+	if accommodationIDs == nil {
+		accommodationIDs = []string{}
+	}
+	_, err := session.ExecuteWrite(ctx,
+		func(transaction neo4j.ManagedTransaction) (any, error) {
+			params := map[string]any{
+				"userID":           userID,
+				"accommodationIDs": accommodationIDs,
+			}
+			if _, err := transaction.Run(ctx, userQuery, params); err != nil {
+				return nil, err
+			}
+			if _, err := transaction.Run(ctx, accommodationsQuery, params); err != nil {
+				return nil, err
+			}
+			return nil, nil
+		})
+	if err != nil {
+		return errors.NewError(err.Error(), 500)
+	}
+	return nil
+}
@@ -55,3 +55,11 @@ func (rs RecommendationService) GetAllRecommendationsByRating(ctx context.Contex
 	}
 	return returnedData, nil
 }
+
+func (rs RecommendationService) ArchiveUser(userID string, accommodationIDs []string) *errors.ErrorStruct {
+	return rs.repo.ArchiveUser(userID, accommodationIDs)
+}
+
+func (rs RecommendationService) RestoreUser(userID string, accommodationIDs []string) *errors.ErrorStruct {
+	return rs.repo.RestoreUser(userID, accommodationIDs)
+}
@@ -0,0 +1,231 @@
+// Package calendar reads and writes the iCalendar (RFC 5545) files other listing
+// platforms use to share which nights of an accommodation are taken. Only all-day
+// events matter to them: an event from DTSTART to DTEND takes the nights from its
+// start up to, but not including, its end.
+package calendar
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	nightLayout    = "2006-01-02"
+)
+
+// Event is a run of taken nights. End is the morning the last night ends.
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Nights lists the nights the event takes as dates like "2006-01-02".
+func (e Event) Nights() []string {
+	var nights []string
+	for night := e.Start; night.Before(e.End); night = night.AddDate(0, 0, 1) {
+		nights = append(nights, night.Format(nightLayout))
+	}
+	return nights
+}
+
+// Events joins consecutive nights into events, which start at the first night of
+// each run and end the morning after its last one. The nights must be sorted.
+func Events(nights []string, uid func(start string) string, summary string) ([]Event, error) {
+	var events []Event
+	for _, night := range nights {
+		date, err := time.Parse(nightLayout, night)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", night)
+		}
+		if last := len(events) - 1; last >= 0 && events[last].End.Equal(date) {
+			events[last].End = date.AddDate(0, 0, 1)
+			continue
+		}
+		events = append(events, Event{UID: uid(night), Summary: summary, Start: date, End: date.AddDate(0, 0, 1)})
+	}
+	return events, nil
+}
+
+// Write writes the events as a calendar named name, stamped with now.
+func Write(w io.Writer, name string, events []Event, now time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//reservations-service//calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escape(name),
+	}
+	stamp := now.UTC().Format(dateTimeLayout) + "Z"
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escape(event.UID),
+			"DTSTAMP:"+stamp,
+			"DTSTART;VALUE=DATE:"+event.Start.Format(dateLayout),
+			"DTEND;VALUE=DATE:"+event.End.Format(dateLayout),
+			"SUMMARY:"+escape(event.Summary),
+			"TRANSP:OPAQUE",
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+	for _, line := range lines {
+		if _, err := io.WriteString(w, fold(line)+"\r\n"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Parse reads the events of a calendar. Cancelled events, events that do not
+// block time and events without a start are skipped. Times of day are dropped, so
+// an event ending on a morning leaves that night free.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []Event
+	var event *Event
+	var skip bool
+	for number, line := range lines {
+		name, params, value := property(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			event, skip = &Event{}, false
+		case event == nil:
+			continue
+		case name == "END" && value == "VEVENT":
+			if !skip && !event.Start.IsZero() {
+				if event.End.IsZero() || !event.End.After(event.Start) {
+					event.End = event.Start.AddDate(0, 0, 1)
+				}
+				events = append(events, *event)
+			}
+			event = nil
+		case name == "UID":
+			event.UID = unescape(value)
+		case name == "SUMMARY":
+			event.Summary = unescape(value)
+		case name == "STATUS":
+			skip = skip || strings.EqualFold(value, "CANCELLED")
+		case name == "TRANSP":
+			skip = skip || strings.EqualFold(value, "TRANSPARENT")
+		case name == "DTSTART" || name == "DTEND":
+			date, err := parseDate(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", number+1, err)
+			}
+			if name == "DTSTART" {
+				event.Start = date
+			} else {
+				event.End = date
+			}
+		}
+	}
+	return events, nil
+}
+
+// unfold joins the lines RFC 5545 folded by starting them with a space or a tab.
+func unfold(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// property splits a content line such as "DTSTART;VALUE=DATE:20240101" into its
+// upper-cased name, its parameters and its value.
+func property(line string) (string, map[string]string, string) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", nil, ""
+	}
+	parts := strings.Split(line[:colon], ";")
+	params := make(map[string]string, len(parts)-1)
+	for _, param := range parts[1:] {
+		if key, value, found := strings.Cut(param, "="); found {
+			params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+		}
+	}
+	return strings.ToUpper(parts[0]), params, line[colon+1:]
+}
+
+// parseDate reads a DATE or DATE-TIME value as the date it falls on, in the time
+// zone the value names.
+func parseDate(value string, params map[string]string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if len(value) == len(dateLayout) {
+		date, err := time.Parse(dateLayout, value)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid date %q", value)
+		}
+		return date, nil
+	}
+	location := time.UTC
+	if strings.HasSuffix(value, "Z") {
+		value = strings.TrimSuffix(value, "Z")
+	} else if zone, exists := params["TZID"]; exists {
+		if loaded, err := time.LoadLocation(zone); err == nil {
+			location = loaded
+		}
+	}
+	moment, err := time.ParseInLocation(dateTimeLayout, value, location)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date-time %q", value)
+	}
+	return time.Date(moment.Year(), moment.Month(), moment.Day(), 0, 0, 0, 0, time.UTC), nil
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+var unescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escape(text string) string {
+	return escaper.Replace(text)
+}
+
+func unescape(text string) string {
+	return unescaper.Replace(text)
+}
+
+// fold breaks lines longer than the 75 octets RFC 5545 allows.
+func fold(line string) string {
+	limit := 75
+	if len(line) <= limit {
+		return line
+	}
+	var folded strings.Builder
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && line[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		folded.WriteString(line[:cut])
+		folded.WriteString("\r\n ")
+		line = line[cut:]
+		// The space that starts a continuation line counts toward its length.
+		limit = 74
+	}
+	folded.WriteString(line)
+	return folded.String()
+}
@@ -0,0 +1,198 @@
+package calendar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// maxCalendarSize bounds how much of an imported calendar is read.
+const maxCalendarSize = 4 << 20
+
+// maxRedirects bounds how many redirects are followed to a calendar.
+const maxRedirects = 5
+
+// ImportError is an error of importing a calendar whose Reason can be shown to
+// the host. The error it wraps is only for the logs, since it may tell about the
+// network the service runs in.
+type ImportError struct {
+	Reason string
+	Err    error
+}
+
+func (e *ImportError) Error() string {
+	if e.Err == nil {
+		return e.Reason
+	}
+	return e.Reason + ": " + e.Err.Error()
+}
+
+func (e *ImportError) Unwrap() error {
+	return e.Err
+}
+
+// Reason returns what the host may be told about err.
+func Reason(err error) string {
+	var importErr *ImportError
+	if errors.As(err, &importErr) {
+		return importErr.Reason
+	}
+	return "The calendar could not be imported"
+}
+
+// Sources opens the calendars hosts import: http and https URLs, or files of a
+// local directory, which stand in for a platform's feed while testing. Files are
+// named by a path relative to the directory or by a file:// URL inside it. URLs
+// may only point to public addresses, so hosts can not make the service fetch
+// from the network it runs in.
+type Sources struct {
+	client *http.Client
+	dir    string
+}
+
+// NewSources opens URLs with the given timeout and files from dir. An empty dir
+// turns file imports off.
+func NewSources(dir string, timeout time.Duration) *Sources {
+	sources := &Sources{dir: dir}
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublic}
+	sources.client = &http.Client{
+		Timeout: timeout,
+		// The names a URL resolves to are checked when dialing, which a proxy
+		// would do instead, so none is used.
+		Transport: &http.Transport{DialContext: dialer.DialContext, TLSHandshakeTimeout: timeout},
+		CheckRedirect: func(request *http.Request, via []*http.Request) error {
+			if len(via) >= maxRedirects {
+				return fmt.Errorf("calendar redirected more than %d times", maxRedirects)
+			}
+			return validateURL(request.URL)
+		},
+	}
+	return sources
+}
+
+// Validate tells why a calendar can not be imported from location, or returns nil
+// when it can.
+func (s *Sources) Validate(location string) error {
+	parsed, err := url.Parse(location)
+	if err != nil {
+		return fmt.Errorf("invalid calendar location %q", location)
+	}
+	switch parsed.Scheme {
+	case "http", "https":
+		return validateURL(parsed)
+	case "", "file":
+		_, err := s.file(parsed)
+		return err
+	default:
+		return fmt.Errorf("calendars can not be imported over %s", parsed.Scheme)
+	}
+}
+
+// Open returns the contents of the calendar at location. Its errors are
+// *ImportError.
+func (s *Sources) Open(ctx context.Context, location string) (io.ReadCloser, error) {
+	if err := s.Validate(location); err != nil {
+		return nil, &ImportError{Reason: err.Error()}
+	}
+	parsed, _ := url.Parse(location)
+	if parsed.Scheme == "http" || parsed.Scheme == "https" {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, &ImportError{Reason: "The calendar could not be fetched", Err: err}
+		}
+		response, err := s.client.Do(request)
+		if err != nil {
+			return nil, &ImportError{Reason: "The calendar could not be fetched", Err: err}
+		}
+		if response.StatusCode != http.StatusOK {
+			response.Body.Close()
+			return nil, &ImportError{Reason: fmt.Sprintf("The calendar URL answered %s", response.Status)}
+		}
+		return limited(response.Body), nil
+	}
+	path, _ := s.file(parsed)
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, &ImportError{Reason: "The calendar file could not be opened", Err: err}
+	}
+	return limited(file), nil
+}
+
+// validateURL refuses URLs of hosts that are internal by their name or address.
+// Names that resolve to internal addresses are refused when dialing.
+func validateURL(location *url.URL) error {
+	host := strings.ToLower(strings.TrimSuffix(location.Hostname(), "."))
+	if host == "" {
+		return fmt.Errorf("calendar URL %q has no host", location)
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		if !isPublic(ip) {
+			return fmt.Errorf("calendar URL %q points to an internal address", location)
+		}
+		return nil
+	}
+	if !strings.Contains(host, ".") || host == "localhost" ||
+		strings.HasSuffix(host, ".localhost") || strings.HasSuffix(host, ".local") || strings.HasSuffix(host, ".internal") {
+		return fmt.Errorf("calendar URL %q points to an internal host", location)
+	}
+	return nil
+}
+
+// sharedAddressSpace is the carrier-grade NAT range, which is not public either.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+func isPublic(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || sharedAddressSpace.Contains(ip))
+}
+
+// dialPublic refuses connections to internal addresses, whatever name or redirect
+// led to them.
+func dialPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if ip := net.ParseIP(host); ip == nil || !isPublic(ip) {
+		return fmt.Errorf("calendar host %s is an internal address", host)
+	}
+	return nil
+}
+
+// file resolves a file location inside the import directory.
+func (s *Sources) file(location *url.URL) (string, error) {
+	if s.dir == "" {
+		return "", fmt.Errorf("calendars can not be imported from files")
+	}
+	dir, err := filepath.Abs(s.dir)
+	if err != nil {
+		return "", err
+	}
+	path := location.Path
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(dir, path)
+	}
+	path = filepath.Clean(path)
+	if !strings.HasPrefix(path, dir+string(filepath.Separator)) {
+		return "", fmt.Errorf("calendar file %s is outside %s", location.Path, s.dir)
+	}
+	return path, nil
+}
+
+type limitedReader struct {
+	io.Reader
+	io.Closer
+}
+
+func limited(body io.ReadCloser) io.ReadCloser {
+	return limitedReader{Reader: io.LimitReader(body, maxCalendarSize), Closer: body}
+}
@@ -0,0 +1,75 @@
+package calendar
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateRefusesInternalHosts(t *testing.T) {
+	sources := NewSources("", time.Second)
+	tests := []struct {
+		location string
+		valid    bool
+	}{
+		{location: "https://calendar.example.com/feed.ics", valid: true},
+		{location: "http://93.184.216.34/feed.ics", valid: true},
+		{location: "http://localhost:8080/feed.ics"},
+		{location: "http://api.localhost/feed.ics"},
+		{location: "http://reservations-service:8080/feed.ics"},
+		{location: "http://metadata.google.internal/computeMetadata/v1/"},
+		{location: "http://printer.local/feed.ics"},
+		{location: "http://127.0.0.1/feed.ics"},
+		{location: "http://10.0.0.7/feed.ics"},
+		{location: "http://172.16.3.4/feed.ics"},
+		{location: "http://192.168.1.1/feed.ics"},
+		{location: "http://169.254.169.254/latest/meta-data/"},
+		{location: "http://100.64.0.1/feed.ics"},
+		{location: "http://0.0.0.0/feed.ics"},
+		{location: "http://[::1]/feed.ics"},
+		{location: "http://[fd00::1]/feed.ics"},
+		{location: "http:///feed.ics"},
+		{location: "gopher://calendar.example.com/feed.ics"},
+		{location: "feed.ics"},
+	}
+	for _, test := range tests {
+		t.Run(test.location, func(t *testing.T) {
+			err := sources.Validate(test.location)
+			if test.valid && err != nil {
+				t.Fatalf("Validate(%q) = %v, want nil", test.location, err)
+			}
+			if !test.valid && err == nil {
+				t.Fatalf("Validate(%q) = nil, want an error", test.location)
+			}
+		})
+	}
+}
+
+func TestOpenRefusesToDialInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		t.Error("the internal server was reached")
+	}))
+	defer server.Close()
+	sources := NewSources("", time.Second)
+
+	// Going around Validate, as a public name resolving to the server would.
+	_, err := sources.client.Get(server.URL)
+	if err == nil || !strings.Contains(err.Error(), "internal address") {
+		t.Fatalf("fetching %s gave %v, want it refused", server.URL, err)
+	}
+}
+
+func TestOpenTellsTheHostOnlyTheReason(t *testing.T) {
+	sources := NewSources("", time.Second)
+
+	_, err := sources.Open(context.Background(), "http://10.0.0.7/feed.ics")
+	if err == nil {
+		t.Fatal("opened an internal calendar")
+	}
+	if reason := Reason(err); strings.Contains(reason, "dial") || reason == "" {
+		t.Fatalf("reason %q tells more than the host should know", reason)
+	}
+}
@@ -0,0 +1,101 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reservation-service/domain"
+	"reservation-service/errors"
+
+	"github.com/sony/gobreaker"
+)
+
+type AccommodationsClient struct {
+	address        string
+	client         *http.Client
+	circuitBreaker *gobreaker.CircuitBreaker
+}
+
+// accommodationResponse is the part of an accommodation the party, the host and
+// the status are checked against.
+type accommodationResponse struct {
+	Data struct {
+		UserID           string   `json:"userId"`
+		Status           string   `json:"status"`
+		MinNumOfVisitors int      `json:"minNumOfVisitors"`
+		MaxNumOfVisitors int      `json:"maxNumOfVisitors"`
+		Conveniences     []string `json:"conveniences"`
+	} `json:"data"`
+}
+
+func NewAccommodationsClient(host, port string, client *http.Client, circuitBreaker *gobreaker.CircuitBreaker) *AccommodationsClient {
+	return &AccommodationsClient{
+		address:        fmt.Sprintf("http://%s:%s", host, port),
+		client:         client,
+		circuitBreaker: circuitBreaker,
+	}
+}
+
+func (ac AccommodationsClient) getAccommodation(ctx context.Context, accommodationID string) (*accommodationResponse, *errors.ReservationError) {
+	cbResp, err := ac.circuitBreaker.Execute(func() (interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, ac.address+"/"+accommodationID, nil)
+		if err != nil {
+			return nil, err
+		}
+		return ac.client.Do(req)
+	})
+	if err != nil {
+		return nil, errors.NewReservationError(503, "Accommodations service is unavailable")
+	}
+	resp := cbResp.(*http.Response)
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, errors.NewReservationError(404, "Accommodation not found")
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		return nil, errors.NewReservationError(502, fmt.Sprintf("Accommodations service answered %s", resp.Status))
+	}
+	var accommodation accommodationResponse
+	if err := json.NewDecoder(resp.Body).Decode(&accommodation); err != nil {
+		return nil, errors.NewReservationError(502, err.Error())
+	}
+	return &accommodation, nil
+}
+
+// GetOwner returns the id of the host the accommodation belongs to.
+func (ac AccommodationsClient) GetOwner(ctx context.Context, accommodationID string) (string, *errors.ReservationError) {
+	accommodation, err := ac.getAccommodation(ctx, accommodationID)
+	if err != nil {
+		return "", err
+	}
+	return accommodation.Data.UserID, nil
+}
+
+// GetStatus returns the status of the accommodation, such as Pending or Approved.
+func (ac AccommodationsClient) GetStatus(ctx context.Context, accommodationID string) (string, *errors.ReservationError) {
+	accommodation, err := ac.getAccommodation(ctx, accommodationID)
+	if err != nil {
+		return "", err
+	}
+	return accommodation.Data.Status, nil
+}
+
+// GetPartyLimits returns how many guests the accommodation takes and whether it
+// allows pets.
+func (ac AccommodationsClient) GetPartyLimits(ctx context.Context, accommodationID string) (*domain.PartyLimits, *errors.ReservationError) {
+	accommodation, err := ac.getAccommodation(ctx, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	limits := domain.PartyLimits{
+		MinGuests: accommodation.Data.MinNumOfVisitors,
+		MaxGuests: accommodation.Data.MaxNumOfVisitors,
+	}
+	for _, convenience := range accommodation.Data.Conveniences {
+		if convenience == domain.PetsAllowed {
+			limits.PetsAllowed = true
+		}
+	}
+	return &limits, nil
+}
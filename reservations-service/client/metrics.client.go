@@ -83,3 +83,46 @@ func (mc MetricsClient) SendReserved(ctx context.Context, userID, accommodationI
 	return errors.NewReservationError(500, err.Error())
 
 }
+
+// StayMetrics is the check-in or check-out of the guest of a reservation.
+type StayMetrics struct {
+	UserID          string    `json:"userID"`
+	AccommodationID string    `json:"accommodationID"`
+	ReservationID   string    `json:"reservationID"`
+	At              time.Time `json:"at"`
+}
+
+func (mc MetricsClient) SendCheckedIn(ctx context.Context, userID, accommodationID, reservationID string, at time.Time) *errors.ReservationError {
+	return mc.sendStay(ctx, "/checkedIn", StayMetrics{UserID: userID, AccommodationID: accommodationID, ReservationID: reservationID, At: at})
+}
+
+func (mc MetricsClient) SendCheckedOut(ctx context.Context, userID, accommodationID, reservationID string, at time.Time) *errors.ReservationError {
+	return mc.sendStay(ctx, "/checkedOut", StayMetrics{UserID: userID, AccommodationID: accommodationID, ReservationID: reservationID, At: at})
+}
+
+func (mc MetricsClient) sendStay(ctx context.Context, path string, metrics StayMetrics) *errors.ReservationError {
+	jsonData, err := json.Marshal(metrics)
+	if err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	cbResp, err := mc.circuitBreaker.Execute(func() (interface{}, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, mc.address+path, bytes.NewReader(jsonData))
+		if err != nil {
+			return nil, err
+		}
+		return mc.client.Do(req)
+	})
+	if err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	resp := cbResp.(*http.Response)
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 400 {
+		baseResp := domain.BaseErrorHttpResponse{}
+		if err := json.NewDecoder(resp.Body).Decode(&baseResp); err != nil {
+			return errors.NewReservationError(500, err.Error())
+		}
+		return errors.NewReservationError(resp.StatusCode, baseResp.Error)
+	}
+	return nil
+}
@@ -79,3 +79,67 @@ func (nc NotificationClient) SendReservationCanceledNotification(ctx context.Con
 		return
 	}
 }
+
+// SendReservationRequestNotification tells the host about a new reservation request
+// and the guest about the answer to it.
+func (nc NotificationClient) SendReservationRequestNotification(ctx context.Context, userId, message string) {
+	req := ReservationNotification{
+		Text:      message,
+		CreatedAt: time.Now().String(),
+		IsOpened:  false,
+	}
+	reqURL := nc.address + "/" + userId
+	res, err := nc.request(http.MethodPost, reqURL, req)
+	if err != nil || res.StatusCode != 502 {
+		log.Println(err)
+		return
+	}
+}
+
+func (nc NotificationClient) SendReservationModifiedNotification(ctx context.Context, userId, message string) {
+	req := ReservationNotification{
+		Text:      message,
+		CreatedAt: time.Now().String(),
+		IsOpened:  false,
+	}
+	reqURL := nc.address + "/" + userId
+	res, err := nc.request(http.MethodPost, reqURL, req)
+	if err != nil || res.StatusCode != 502 {
+		log.Println(err)
+		return
+	}
+}
+
+// SendWaitlistNotification tells a waitlisted guest the dates they wait for were
+// freed.
+func (nc NotificationClient) SendWaitlistNotification(ctx context.Context, userId, message string) {
+	req := ReservationNotification{
+		Text:      message,
+		CreatedAt: time.Now().String(),
+		IsOpened:  false,
+	}
+	reqURL := nc.address + "/" + userId
+	res, err := nc.request(http.MethodPost, reqURL, req)
+	if err != nil || res.StatusCode != 502 {
+		log.Println(err)
+		return
+	}
+}
+
+// SendStayNotification sends the guest the arrival instructions before the stay
+// and tells them when the host checked them in or out. The notification service
+// mails every notification it stores to the user, so the guest also gets the
+// instructions by mail.
+func (nc NotificationClient) SendStayNotification(ctx context.Context, userId, message string) {
+	req := ReservationNotification{
+		Text:      message,
+		CreatedAt: time.Now().String(),
+		IsOpened:  false,
+	}
+	reqURL := nc.address + "/" + userId
+	res, err := nc.request(http.MethodPost, reqURL, req)
+	if err != nil || res.StatusCode != 502 {
+		log.Println(err)
+		return
+	}
+}
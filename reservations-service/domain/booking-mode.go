@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// BookingMode is the host's choice between instant booking and request-to-book for
+// an accommodation. Accommodations without one are booked instantly.
+type BookingMode struct {
+	AccommodationID string `json:"accommodationId"`
+	HostID          string `json:"hostId"`
+	RequestToBook   bool   `json:"requestToBook"`
+}
+
+// ReservationRequest is a reservation waiting for the host to accept it.
+type ReservationRequest struct {
+	Id              gocql.UUID `json:"id"`
+	HostID          string     `json:"hostId"`
+	UserID          string     `json:"userId"`
+	AccommodationID string     `json:"accommodationId"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+}
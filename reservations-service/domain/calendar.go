@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// CalendarFeed is how an accommodation's calendar is shared with other listing
+// platforms. Its nights are published to whoever knows the token, and the calendar
+// at ImportURL, if any, blocks the nights the other platforms booked.
+type CalendarFeed struct {
+	AccommodationID string    `json:"accommodationId"`
+	HostID          string    `json:"hostId"`
+	Token           string    `json:"token"`
+	ImportURL       string    `json:"importUrl"`
+	ImportedAt      time.Time `json:"importedAt"`
+	// ImportError is why the last import failed, empty when it succeeded.
+	ImportError string `json:"importError"`
+}
+
+// CalendarBlock is a night an imported calendar takes.
+type CalendarBlock struct {
+	Night   string `json:"night"`
+	UID     string `json:"uid"`
+	Summary string `json:"summary"`
+}
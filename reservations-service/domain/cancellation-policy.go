@@ -0,0 +1,143 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+const (
+	FlexiblePolicy = "flexible"
+	ModeratePolicy = "moderate"
+	StrictPolicy   = "strict"
+	CustomPolicy   = "custom"
+)
+
+// RefundTier refunds RefundPercent of the price when the guest cancels at least
+// DaysBeforeCheckIn days before check-in.
+type RefundTier struct {
+	DaysBeforeCheckIn int `json:"daysBeforeCheckIn"`
+	RefundPercent     int `json:"refundPercent"`
+}
+
+// CancellationPolicy decides how much of the price a guest gets back. Only custom
+// policies carry their own tiers, the others use the preset tiers below.
+// Accommodations without a policy are flexible.
+type CancellationPolicy struct {
+	AccommodationID string       `json:"accommodationId"`
+	Name            string       `json:"name"`
+	Tiers           []RefundTier `json:"tiers,omitempty"`
+}
+
+var presetTiers = map[string][]RefundTier{
+	FlexiblePolicy: {{DaysBeforeCheckIn: 1, RefundPercent: 100}},
+	ModeratePolicy: {{DaysBeforeCheckIn: 5, RefundPercent: 100}, {DaysBeforeCheckIn: 1, RefundPercent: 50}},
+	StrictPolicy:   {{DaysBeforeCheckIn: 14, RefundPercent: 100}, {DaysBeforeCheckIn: 7, RefundPercent: 50}},
+}
+
+// Refund is the breakdown of a cancellation. It is recorded on the reservation.
+type Refund struct {
+	Policy            string    `json:"policy" cql:"policy"`
+	DaysBeforeCheckIn int       `json:"daysBeforeCheckIn" cql:"days_before_check_in"`
+	RefundPercent     int       `json:"refundPercent" cql:"refund_percent"`
+	TotalPrice        Money     `json:"totalPrice" cql:"total_price"`
+	RefundAmount      Money     `json:"refundAmount" cql:"refund_amount"`
+	RetainedAmount    Money     `json:"retainedAmount" cql:"retained_amount"`
+	CancelledAt       time.Time `json:"cancelledAt" cql:"cancelled_at"`
+}
+
+func DefaultCancellationPolicy(accommodationID string) *CancellationPolicy {
+	return &CancellationPolicy{AccommodationID: accommodationID, Name: FlexiblePolicy}
+}
+
+func (p *CancellationPolicy) Validate() error {
+	if p.Name == CustomPolicy {
+		if len(p.Tiers) == 0 {
+			return fmt.Errorf("a custom cancellation policy needs at least one tier")
+		}
+		days := make(map[int]bool)
+		for _, tier := range p.Tiers {
+			if tier.DaysBeforeCheckIn < 0 || tier.RefundPercent < 0 || tier.RefundPercent > 100 {
+				return fmt.Errorf("invalid refund tier %d days, %d%%", tier.DaysBeforeCheckIn, tier.RefundPercent)
+			}
+			if days[tier.DaysBeforeCheckIn] {
+				return fmt.Errorf("more than one refund tier for %d days", tier.DaysBeforeCheckIn)
+			}
+			days[tier.DaysBeforeCheckIn] = true
+		}
+		return nil
+	}
+	if _, exists := presetTiers[p.Name]; !exists {
+		return fmt.Errorf("unknown cancellation policy %q", p.Name)
+	}
+	if len(p.Tiers) > 0 {
+		return fmt.Errorf("only custom cancellation policies have tiers")
+	}
+	return nil
+}
+
+// RefundTiers returns the tiers of the policy, the longest notice first.
+func (p *CancellationPolicy) RefundTiers() []RefundTier {
+	tiers := p.Tiers
+	if p.Name != CustomPolicy {
+		tiers = presetTiers[p.Name]
+	}
+	sorted := append([]RefundTier(nil), tiers...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].DaysBeforeCheckIn > sorted[j].DaysBeforeCheckIn })
+	return sorted
+}
+
+// RefundFor computes the refund of a reservation cancelled at the given time. The
+// tier with the longest notice the guest still gave applies; with less notice than
+// every tier asks for nothing is refunded. A reservation without a valid check-in
+// date has no notice to compare, so it is an error.
+func (p *CancellationPolicy) RefundFor(reservation *Reservation, cancelledAt time.Time) (*Refund, error) {
+	daysBefore, err := daysBeforeCheckIn(reservation.StartDate, cancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	refund := &Refund{
+		Policy:            p.Name,
+		DaysBeforeCheckIn: daysBefore,
+		TotalPrice:        reservation.Price,
+		CancelledAt:       cancelledAt,
+	}
+	for _, tier := range p.RefundTiers() {
+		if daysBefore >= tier.DaysBeforeCheckIn {
+			refund.RefundPercent = tier.RefundPercent
+			break
+		}
+	}
+	refund.RefundAmount = reservation.Price.Percent(refund.RefundPercent)
+	refund.RetainedAmount = reservation.Price.Minus(refund.RefundAmount)
+	return refund, nil
+}
+
+// FullRefund is the refund of a reservation the guest was never charged for yet,
+// such as a request the host has not accepted.
+func FullRefund(reservation *Reservation, cancelledAt time.Time) (*Refund, error) {
+	daysBefore, err := daysBeforeCheckIn(reservation.StartDate, cancelledAt)
+	if err != nil {
+		return nil, err
+	}
+	return &Refund{
+		DaysBeforeCheckIn: daysBefore,
+		RefundPercent:     100,
+		TotalPrice:        reservation.Price,
+		RefundAmount:      reservation.Price,
+		RetainedAmount:    Money{Currency: reservation.Price.Currency},
+		CancelledAt:       cancelledAt,
+	}, nil
+}
+
+// daysBeforeCheckIn counts whole calendar days between the cancellation and the
+// check-in date.
+func daysBeforeCheckIn(startDate string, cancelledAt time.Time) (int, error) {
+	checkIn, err := time.ParseInLocation("2006-01-02", startDate, cancelledAt.Location())
+	if err != nil {
+		return 0, fmt.Errorf("invalid check-in date %q", startDate)
+	}
+	day := time.Date(cancelledAt.Year(), cancelledAt.Month(), cancelledAt.Day(), 0, 0, 0, 0, cancelledAt.Location())
+	return int(math.Round(checkIn.Sub(day).Hours() / 24)), nil
+}
@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+var cancelledAt = time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+func reservationIn(days int, price Money) *Reservation {
+	return &Reservation{StartDate: cancelledAt.AddDate(0, 0, days).Format("2006-01-02"), Price: price}
+}
+
+func TestRefundFor(t *testing.T) {
+	custom := &CancellationPolicy{Name: CustomPolicy, Tiers: []RefundTier{
+		{DaysBeforeCheckIn: 1, RefundPercent: 25},
+		{DaysBeforeCheckIn: 10, RefundPercent: 100},
+		{DaysBeforeCheckIn: 3, RefundPercent: 50},
+	}}
+	tests := []struct {
+		policy  *CancellationPolicy
+		days    int
+		percent int
+	}{
+		{policy: &CancellationPolicy{Name: FlexiblePolicy}, days: 1, percent: 100},
+		{policy: &CancellationPolicy{Name: FlexiblePolicy}, days: 0, percent: 0},
+		{policy: &CancellationPolicy{Name: ModeratePolicy}, days: 6, percent: 100},
+		{policy: &CancellationPolicy{Name: ModeratePolicy}, days: 5, percent: 100},
+		{policy: &CancellationPolicy{Name: ModeratePolicy}, days: 4, percent: 50},
+		{policy: &CancellationPolicy{Name: ModeratePolicy}, days: 1, percent: 50},
+		{policy: &CancellationPolicy{Name: ModeratePolicy}, days: 0, percent: 0},
+		{policy: &CancellationPolicy{Name: StrictPolicy}, days: 14, percent: 100},
+		{policy: &CancellationPolicy{Name: StrictPolicy}, days: 13, percent: 50},
+		{policy: &CancellationPolicy{Name: StrictPolicy}, days: 7, percent: 50},
+		{policy: &CancellationPolicy{Name: StrictPolicy}, days: 6, percent: 0},
+		{policy: custom, days: 10, percent: 100},
+		{policy: custom, days: 9, percent: 50},
+		{policy: custom, days: 3, percent: 50},
+		{policy: custom, days: 2, percent: 25},
+		{policy: custom, days: 1, percent: 25},
+		{policy: custom, days: 0, percent: 0},
+		{policy: custom, days: -1, percent: 0},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s %d days", test.policy.Name, test.days), func(t *testing.T) {
+			price := Money{Amount: 10000, Currency: "USD"}
+			refund, err := test.policy.RefundFor(reservationIn(test.days, price), cancelledAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refund.DaysBeforeCheckIn != test.days {
+				t.Fatalf("counted %d days before check-in, want %d", refund.DaysBeforeCheckIn, test.days)
+			}
+			if refund.RefundPercent != test.percent {
+				t.Fatalf("refunded %d%%, want %d%%", refund.RefundPercent, test.percent)
+			}
+			if want := price.Percent(test.percent); refund.RefundAmount != want {
+				t.Fatalf("refunded %s, want %s", refund.RefundAmount, want)
+			}
+			if refund.RefundAmount.Amount+refund.RetainedAmount.Amount != price.Amount {
+				t.Fatalf("refunded %s and retained %s of %s", refund.RefundAmount, refund.RetainedAmount, price)
+			}
+		})
+	}
+}
+
+func TestRefundForRoundsTheRefundDown(t *testing.T) {
+	tests := []struct {
+		price    Money
+		refund   Money
+		retained Money
+	}{
+		{price: Money{Amount: 999, Currency: "USD"}, refund: Money{Amount: 499, Currency: "USD"}, retained: Money{Amount: 500, Currency: "USD"}},
+		{price: Money{Amount: 1001, Currency: "JPY"}, refund: Money{Amount: 500, Currency: "JPY"}, retained: Money{Amount: 501, Currency: "JPY"}},
+		{price: Money{Amount: 1, Currency: "EUR"}, refund: Money{Currency: "EUR"}, retained: Money{Amount: 1, Currency: "EUR"}},
+	}
+	policy := &CancellationPolicy{Name: ModeratePolicy}
+	for _, test := range tests {
+		t.Run(test.price.String(), func(t *testing.T) {
+			refund, err := policy.RefundFor(reservationIn(2, test.price), cancelledAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if refund.RefundAmount != test.refund || refund.RetainedAmount != test.retained {
+				t.Fatalf("refunded %s and retained %s, want %s and %s", refund.RefundAmount, refund.RetainedAmount, test.refund, test.retained)
+			}
+		})
+	}
+}
+
+func TestRefundForCountsCalendarDays(t *testing.T) {
+	lateEvening := time.Date(2026, time.March, 1, 23, 59, 0, 0, time.UTC)
+	refund, err := (&CancellationPolicy{Name: FlexiblePolicy}).RefundFor(&Reservation{StartDate: "2026-03-02"}, lateEvening)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refund.DaysBeforeCheckIn != 1 || refund.RefundPercent != 100 {
+		t.Fatalf("got %d days and %d%%, want 1 day and 100%%", refund.DaysBeforeCheckIn, refund.RefundPercent)
+	}
+}
+
+func TestRefundForRejectsAnInvalidCheckIn(t *testing.T) {
+	for _, startDate := range []string{"", "2026-13-01", "01.03.2026"} {
+		reservation := &Reservation{StartDate: startDate, Price: Money{Amount: 10000, Currency: "USD"}}
+		if _, err := (&CancellationPolicy{Name: FlexiblePolicy}).RefundFor(reservation, cancelledAt); err == nil {
+			t.Fatalf("check-in %q was accepted", startDate)
+		}
+		if _, err := FullRefund(reservation, cancelledAt); err == nil {
+			t.Fatalf("check-in %q was accepted for a full refund", startDate)
+		}
+	}
+}
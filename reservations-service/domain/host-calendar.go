@@ -0,0 +1,153 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+const (
+	BlockNights   = "block"
+	UnblockNights = "unblock"
+	PriceNights   = "price"
+)
+
+// maxChangedNights bounds how many nights one calendar change can touch.
+const maxChangedNights = 2 * 366
+
+// CalendarChange is an edit a host makes to the calendar of an accommodation. It
+// applies to the listed nights and to the nights from From to To, both included,
+// that fall on one of the weekdays, or on any day when none are given. Weekdays
+// are named like in StayRules. "All Fridays and Saturdays in July at 120" is a
+// price change from July 1st to July 31st on Friday and Saturday.
+type CalendarChange struct {
+	Action   string   `json:"action"`
+	Nights   []string `json:"nights"`
+	From     string   `json:"from"`
+	To       string   `json:"to"`
+	Weekdays []string `json:"weekdays"`
+	// Price is the new nightly rate of a price change.
+	Price  Money  `json:"price"`
+	Reason string `json:"reason"`
+}
+
+func (c CalendarChange) Validate() error {
+	switch c.Action {
+	case BlockNights, UnblockNights:
+	case PriceNights:
+		if err := c.Price.ValidateNightlyRate(); err != nil {
+			return fmt.Errorf("invalid price: %v", err)
+		}
+		if c.Price.IsZero() {
+			return fmt.Errorf("price must be above zero")
+		}
+	default:
+		return fmt.Errorf("unknown calendar action %q", c.Action)
+	}
+	if (c.From == "") != (c.To == "") {
+		return fmt.Errorf("a range of nights needs both from and to")
+	}
+	if c.From == "" && len(c.Weekdays) > 0 {
+		return fmt.Errorf("weekdays need a range of nights")
+	}
+	for _, day := range c.Weekdays {
+		if _, exists := weekdays[day]; !exists {
+			return fmt.Errorf("unknown weekday %q", day)
+		}
+	}
+	return nil
+}
+
+// Dates returns the nights the change applies to, sorted and each once.
+func (c CalendarChange) Dates() ([]string, error) {
+	selected := make(map[string]bool)
+	for _, night := range c.Nights {
+		date, err := time.Parse("2006-01-02", night)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", night)
+		}
+		selected[date.Format("2006-01-02")] = true
+	}
+	if c.From != "" {
+		from, err := time.Parse("2006-01-02", c.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", c.From)
+		}
+		to, err := time.Parse("2006-01-02", c.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q", c.To)
+		}
+		if to.Before(from) {
+			return nil, fmt.Errorf("range ends on %s before it starts on %s", c.To, c.From)
+		}
+		if to.Sub(from) > maxChangedNights*24*time.Hour {
+			return nil, fmt.Errorf("a change can touch at most %d nights", maxChangedNights)
+		}
+		for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+			if allowedDay(c.Weekdays, date) {
+				selected[date.Format("2006-01-02")] = true
+			}
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("the change does not apply to any night")
+	}
+	if len(selected) > maxChangedNights {
+		return nil, fmt.Errorf("a change can touch at most %d nights", maxChangedNights)
+	}
+	nights := make([]string, 0, len(selected))
+	for night := range selected {
+		nights = append(nights, night)
+	}
+	sort.Strings(nights)
+	return nights, nil
+}
+
+// CalendarChangeRecord is a calendar change as it was applied: who made it, when
+// and the nights it touched.
+type CalendarChangeRecord struct {
+	Id              gocql.UUID `json:"id"`
+	AccommodationID string     `json:"accommodationId"`
+	HostID          string     `json:"hostId"`
+	Action          string     `json:"action"`
+	Nights          []string   `json:"nights"`
+	Price           Money      `json:"price"`
+	Reason          string     `json:"reason"`
+	ChangedAt       time.Time  `json:"changedAt"`
+}
+
+// CalendarNight is a night of the host's calendar. Price is the nightly rate when
+// the night is in an availability window. BlockedBy tells who blocked the night,
+// the host or an imported calendar, and is empty when nobody did.
+type CalendarNight struct {
+	Night         string      `json:"night"`
+	Available     bool        `json:"available"`
+	Price         *Money      `json:"price,omitempty"`
+	BlockedBy     string      `json:"blockedBy,omitempty"`
+	ReservationID *gocql.UUID `json:"reservationId,omitempty"`
+}
+
+const (
+	BlockedByHost   = "host"
+	BlockedByImport = "import"
+)
+
+// CalendarNights lays the nights out the way the host sees them. blockedBy and
+// reservedBy map the blocked and reserved nights to who blocked or reserved them.
+func CalendarNights(nights []string, windows []DateRangeWithPrice, blockedBy map[string]string, reservedBy map[string]gocql.UUID) []CalendarNight {
+	result := make([]CalendarNight, 0, len(nights))
+	for _, night := range nights {
+		day := CalendarNight{Night: night, BlockedBy: blockedBy[night]}
+		if rate, found := rateOf(night, windows); found {
+			day.Price = &rate
+		}
+		if id, reserved := reservedBy[night]; reserved {
+			day.ReservationID = &id
+		}
+		day.Available = day.Price != nil && day.BlockedBy == "" && day.ReservationID == nil
+		result = append(result, day)
+	}
+	return result
+}
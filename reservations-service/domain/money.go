@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+)
+
+// DefaultCurrency is the currency of prices stored before prices had one. Those
+// were whole units, not minor units.
+const DefaultCurrency = "USD"
+
+// Money is an amount in the minor units of an ISO 4217 currency, cents for USD.
+type Money struct {
+	Amount   int64  `json:"amount" cql:"amount"`
+	Currency string `json:"currency" cql:"currency"`
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// zeroDecimalCurrencies have no minor units. Every other currency has cents.
+var zeroDecimalCurrencies = map[string]bool{"CLP": true, "ISK": true, "JPY": true, "KRW": true, "VND": true}
+
+// MinorUnits returns how many minor units make one unit of the currency.
+func MinorUnits(currency string) int64 {
+	if zeroDecimalCurrencies[currency] {
+		return 1
+	}
+	return 100
+}
+
+// WholeUnits returns amount units of the currency, such as 10 dollars.
+func WholeUnits(amount int64, currency string) Money {
+	return Money{Amount: amount * MinorUnits(currency), Currency: currency}
+}
+
+// StoredMoney reads a price stored next to its currency. Prices stored without
+// one are whole units of the default currency.
+func StoredMoney(amount int, currency string) Money {
+	if currency == "" {
+		return WholeUnits(int64(amount), DefaultCurrency)
+	}
+	return Money{Amount: int64(amount), Currency: currency}
+}
+
+func ValidateCurrency(currency string) error {
+	if !currencyCode.MatchString(currency) {
+		return fmt.Errorf("invalid currency %q", currency)
+	}
+	return nil
+}
+
+func (m Money) Validate() error {
+	if m.Amount < 0 {
+		return fmt.Errorf("amount can not be negative")
+	}
+	return ValidateCurrency(m.Currency)
+}
+
+// MaxNightlyRate is the largest nightly rate, in minor units, the availability
+// tables hold, since they keep rates in CQL int columns.
+const MaxNightlyRate = math.MaxInt32
+
+// ValidateNightlyRate validates the nightly rate of an availability window.
+func (m Money) ValidateNightlyRate() error {
+	if err := m.Validate(); err != nil {
+		return err
+	}
+	if m.Amount > MaxNightlyRate {
+		return fmt.Errorf("nightly rate can not be above %s", Money{Amount: MaxNightlyRate, Currency: m.Currency})
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) Times(n int) Money {
+	return Money{Amount: m.Amount * int64(n), Currency: m.Currency}
+}
+
+// Percent returns percent of the amount, rounded down to a minor unit.
+func (m Money) Percent(percent int) Money {
+	return Money{Amount: m.Amount * int64(percent) / 100, Currency: m.Currency}
+}
+
+func (m Money) Minus(other Money) Money {
+	return Money{Amount: m.Amount - other.Amount, Currency: m.Currency}
+}
+
+func (m Money) String() string {
+	units := MinorUnits(m.Currency)
+	if units == 1 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+	return fmt.Sprintf("%d.%02d %s", m.Amount/units, m.Amount%units, m.Currency)
+}
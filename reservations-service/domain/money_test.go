@@ -0,0 +1,119 @@
+package domain
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestStoredMoney(t *testing.T) {
+	tests := []struct {
+		amount   int
+		currency string
+		want     Money
+	}{
+		{amount: 120, want: Money{Amount: 12000, Currency: DefaultCurrency}},
+		{amount: 0, want: Money{Amount: 0, Currency: DefaultCurrency}},
+		{amount: 12000, currency: "USD", want: Money{Amount: 12000, Currency: "USD"}},
+		{amount: 12000, currency: "EUR", want: Money{Amount: 12000, Currency: "EUR"}},
+		{amount: 12000, currency: "JPY", want: Money{Amount: 12000, Currency: "JPY"}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d %q", test.amount, test.currency), func(t *testing.T) {
+			if got := StoredMoney(test.amount, test.currency); got != test.want {
+				t.Fatalf("got %s, want %s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestWholeUnits(t *testing.T) {
+	tests := []struct {
+		currency string
+		want     int64
+	}{
+		{currency: "USD", want: 1000},
+		{currency: "EUR", want: 1000},
+		{currency: "RSD", want: 1000},
+		{currency: "JPY", want: 10},
+		{currency: "KRW", want: 10},
+		{currency: "ISK", want: 10},
+	}
+	for _, test := range tests {
+		t.Run(test.currency, func(t *testing.T) {
+			if got := WholeUnits(10, test.currency); got.Amount != test.want || got.Currency != test.currency {
+				t.Fatalf("got %v, want %d %s", got, test.want, test.currency)
+			}
+		})
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{money: Money{Amount: 12345, Currency: "USD"}, want: "123.45 USD"},
+		{money: Money{Amount: 5, Currency: "EUR"}, want: "0.05 EUR"},
+		{money: Money{Amount: 100, Currency: "EUR"}, want: "1.00 EUR"},
+		{money: Money{Amount: 0, Currency: "USD"}, want: "0.00 USD"},
+		{money: Money{Amount: 12345, Currency: "JPY"}, want: "12345 JPY"},
+		{money: Money{Amount: 0, Currency: "KRW"}, want: "0 KRW"},
+	}
+	for _, test := range tests {
+		t.Run(test.want, func(t *testing.T) {
+			if got := test.money.String(); got != test.want {
+				t.Fatalf("got %q", got)
+			}
+		})
+	}
+}
+
+func TestMoneyPercent(t *testing.T) {
+	tests := []struct {
+		money   Money
+		percent int
+		want    int64
+	}{
+		{money: Money{Amount: 10000, Currency: "USD"}, percent: 50, want: 5000},
+		{money: Money{Amount: 999, Currency: "USD"}, percent: 50, want: 499},
+		{money: Money{Amount: 999, Currency: "USD"}, percent: 33, want: 329},
+		{money: Money{Amount: 1, Currency: "USD"}, percent: 99, want: 0},
+		{money: Money{Amount: 999, Currency: "USD"}, percent: 100, want: 999},
+		{money: Money{Amount: 999, Currency: "USD"}, percent: 0, want: 0},
+		{money: Money{Amount: 1001, Currency: "JPY"}, percent: 50, want: 500},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d%% of %s", test.percent, test.money), func(t *testing.T) {
+			got := test.money.Percent(test.percent)
+			if got.Amount != test.want || got.Currency != test.money.Currency {
+				t.Fatalf("got %s, want %d %s", got, test.want, test.money.Currency)
+			}
+		})
+	}
+}
+
+func TestValidateNightlyRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		money Money
+		valid bool
+	}{
+		{name: "zero", money: Money{Amount: 0, Currency: "USD"}, valid: true},
+		{name: "largest", money: Money{Amount: MaxNightlyRate, Currency: "USD"}, valid: true},
+		{name: "above the largest", money: Money{Amount: MaxNightlyRate + 1, Currency: "USD"}},
+		{name: "negative", money: Money{Amount: -1, Currency: "USD"}},
+		{name: "no currency", money: Money{Amount: 100}},
+		{name: "lower case currency", money: Money{Amount: 100, Currency: "usd"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.money.ValidateNightlyRate()
+			if test.valid && err != nil {
+				t.Fatalf("refused: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package domain
+
+import "fmt"
+
+// PetsAllowed is the convenience of accommodations that take pets.
+const PetsAllowed = "Pets Allowed"
+
+// maxInfants bounds the infants of a party, who do not count as guests.
+const maxInfants = 5
+
+// Party is who a reservation is for. Adults and children are the guests, who are
+// counted against the limits of the accommodation and paid for when it is paid
+// per guest. Infants and pets are not.
+type Party struct {
+	Adults   int `json:"adults" cql:"adults"`
+	Children int `json:"children" cql:"children"`
+	Infants  int `json:"infants" cql:"infants"`
+	Pets     int `json:"pets" cql:"pets"`
+}
+
+// PartyOf is the party of a reservation made with a guest count only, as all were
+// before parties were recorded: the guests are taken for adults.
+func PartyOf(guests int) Party {
+	if guests < 1 {
+		guests = 1
+	}
+	return Party{Adults: guests}
+}
+
+func (p Party) Guests() int {
+	return p.Adults + p.Children
+}
+
+// PartyLimits is what the accommodation allows. A MaxGuests of zero does not limit
+// the guests.
+type PartyLimits struct {
+	MinGuests   int  `json:"minGuests"`
+	MaxGuests   int  `json:"maxGuests"`
+	PetsAllowed bool `json:"petsAllowed"`
+}
+
+// Check tells why the accommodation can not take the party, or returns nil when it
+// can.
+func (p Party) Check(limits PartyLimits) error {
+	if p.Adults < 0 || p.Children < 0 || p.Infants < 0 || p.Pets < 0 {
+		return fmt.Errorf("party can not have a negative count")
+	}
+	if p.Adults < 1 {
+		return fmt.Errorf("party needs at least one adult")
+	}
+	if p.Guests() < limits.MinGuests {
+		return fmt.Errorf("accommodation takes at least %d guests", limits.MinGuests)
+	}
+	if limits.MaxGuests > 0 && p.Guests() > limits.MaxGuests {
+		return fmt.Errorf("accommodation takes at most %d guests", limits.MaxGuests)
+	}
+	if p.Infants > maxInfants {
+		return fmt.Errorf("party can have at most %d infants", maxInfants)
+	}
+	if p.Pets > 0 && !limits.PetsAllowed {
+		return fmt.Errorf("accommodation does not allow pets")
+	}
+	return nil
+}
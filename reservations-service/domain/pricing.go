@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Paying modes of an accommodation, as the accommodations service names them.
+const (
+	PerAccommodation = "Per Accommodation"
+	PerGuest         = "Per Guest"
+)
+
+const (
+	weeklyStay  = 7
+	monthlyStay = 28
+)
+
+// Pricing holds what an accommodation charges on top of the nightly rates of its
+// availability windows. Paying comes from the accommodation, the fee and the
+// discounts are set by the host, who owns the pricing once set.
+type Pricing struct {
+	AccommodationID        string `json:"accommodationId"`
+	HostID                 string `json:"hostId"`
+	Paying                 string `json:"paying"`
+	CleaningFee            Money  `json:"cleaningFee"`
+	WeeklyDiscountPercent  int    `json:"weeklyDiscountPercent"`
+	MonthlyDiscountPercent int    `json:"monthlyDiscountPercent"`
+}
+
+// QuoteRequest asks what a stay of the given nights would cost. Currency is the
+// one the guest wants to see the quote in, the accommodation's own when empty.
+type QuoteRequest struct {
+	AccommodationID string   `json:"accommodationId"`
+	DateRange       []string `json:"dateRange"`
+	Guests          int      `json:"guests"`
+	Currency        string   `json:"currency,omitempty"`
+}
+
+// NightlyRate is the price of one night. Amount is the rate times the guests for
+// accommodations paid per guest and the rate itself otherwise.
+type NightlyRate struct {
+	Date   string `json:"date"`
+	Rate   Money  `json:"rate"`
+	Amount Money  `json:"amount"`
+}
+
+// Quote is the itemised price of a stay. Charged is what a reservation for the same
+// nights and guests must cost, in the currency of the accommodation. The other
+// amounts are in the currency the guest asked for, converted at ExchangeRate.
+type Quote struct {
+	AccommodationID string        `json:"accommodationId"`
+	Paying          string        `json:"paying"`
+	Guests          int           `json:"guests"`
+	Nights          []NightlyRate `json:"nights"`
+	Subtotal        Money         `json:"subtotal"`
+	Discount        string        `json:"discount,omitempty"`
+	DiscountPercent int           `json:"discountPercent"`
+	DiscountAmount  Money         `json:"discountAmount"`
+	CleaningFee     Money         `json:"cleaningFee"`
+	Total           Money         `json:"total"`
+	Charged         Money         `json:"charged"`
+	ExchangeRate    float64       `json:"exchangeRate"`
+}
+
+func DefaultPricing(accommodationID string) *Pricing {
+	return &Pricing{AccommodationID: accommodationID, Paying: PerAccommodation}
+}
+
+func (p *Pricing) Validate() error {
+	if p.Paying != PerAccommodation && p.Paying != PerGuest {
+		return fmt.Errorf("unknown paying mode %q", p.Paying)
+	}
+	if p.CleaningFee.Amount < 0 {
+		return fmt.Errorf("cleaning fee can not be negative")
+	}
+	if !p.CleaningFee.IsZero() {
+		if err := ValidateCurrency(p.CleaningFee.Currency); err != nil {
+			return err
+		}
+	}
+	for _, percent := range []int{p.WeeklyDiscountPercent, p.MonthlyDiscountPercent} {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("invalid discount %d%%", percent)
+		}
+	}
+	return nil
+}
+
+// Quote prices the nights from the availability windows of the accommodation.
+// When windows overlap the narrowest one sets the rate, since hosts add short
+// windows with special prices on top of longer seasonal ones; of equally long
+// windows the cheaper one wins. Stays of at least four weeks get the monthly
+// discount, stays of at least a week the weekly one. The cleaning fee is charged
+// once and is not discounted, so it must be in the currency of the rates.
+func (p *Pricing) Quote(nights []string, guests int, windows []DateRangeWithPrice) (*Quote, error) {
+	if len(nights) == 0 {
+		return nil, fmt.Errorf("date range is empty")
+	}
+	if guests < 1 {
+		guests = 1
+	}
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	quote := &Quote{AccommodationID: p.AccommodationID, Paying: p.Paying, Guests: guests, ExchangeRate: 1}
+	for i, night := range sorted {
+		if i > 0 && night == sorted[i-1] {
+			return nil, fmt.Errorf("night %s is in the date range twice", night)
+		}
+		rate, found := rateOf(night, windows)
+		if !found {
+			return nil, fmt.Errorf("accommodation not available on %s", night)
+		}
+		if i > 0 && rate.Currency != quote.Subtotal.Currency {
+			return nil, fmt.Errorf("the nights are priced in both %s and %s", quote.Subtotal.Currency, rate.Currency)
+		}
+		amount := rate
+		if p.Paying == PerGuest {
+			amount = rate.Times(guests)
+		}
+		quote.Nights = append(quote.Nights, NightlyRate{Date: night, Rate: rate, Amount: amount})
+		quote.Subtotal = Money{Amount: quote.Subtotal.Amount + amount.Amount, Currency: rate.Currency}
+	}
+	currency := quote.Subtotal.Currency
+	quote.CleaningFee = Money{Currency: currency}
+	if !p.CleaningFee.IsZero() {
+		if p.CleaningFee.Currency != currency {
+			return nil, fmt.Errorf("the cleaning fee is in %s but the nights are priced in %s", p.CleaningFee.Currency, currency)
+		}
+		quote.CleaningFee = p.CleaningFee
+	}
+	switch {
+	case len(sorted) >= monthlyStay && p.MonthlyDiscountPercent > 0:
+		quote.Discount = "monthly"
+		quote.DiscountPercent = p.MonthlyDiscountPercent
+	case len(sorted) >= weeklyStay && p.WeeklyDiscountPercent > 0:
+		quote.Discount = "weekly"
+		quote.DiscountPercent = p.WeeklyDiscountPercent
+	}
+	quote.DiscountAmount = quote.Subtotal.Percent(quote.DiscountPercent)
+	quote.Total = Money{Amount: quote.Subtotal.Amount - quote.DiscountAmount.Amount + quote.CleaningFee.Amount, Currency: currency}
+	quote.Charged = quote.Total
+	return quote, nil
+}
+
+func rateOf(night string, windows []DateRangeWithPrice) (Money, bool) {
+	window, found := windowOf(night, windows)
+	return window.Price, found
+}
+
+// windowOf returns the window that decides about the night, see Quote.
+func windowOf(night string, windows []DateRangeWithPrice) (DateRangeWithPrice, bool) {
+	var chosen DateRangeWithPrice
+	found := false
+	for _, window := range windows {
+		if !contains(window.DateRange, night) {
+			continue
+		}
+		narrower := len(window.DateRange) < len(chosen.DateRange)
+		cheaper := len(window.DateRange) == len(chosen.DateRange) && window.Price.Currency == chosen.Price.Currency && window.Price.Amount < chosen.Price.Amount
+		if !found || narrower || cheaper {
+			chosen, found = window, true
+		}
+	}
+	return chosen, found
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// nightsFrom returns count consecutive nights starting on first.
+func nightsFrom(first string, count int) []string {
+	start, err := time.Parse("2006-01-02", first)
+	if err != nil {
+		panic(err)
+	}
+	nights := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		nights = append(nights, start.AddDate(0, 0, i).Format("2006-01-02"))
+	}
+	return nights
+}
+
+func usd(amount int64) Money {
+	return Money{Amount: amount, Currency: "USD"}
+}
+
+func TestQuote(t *testing.T) {
+	season := DateRangeWithPrice{DateRange: nightsFrom("2026-06-01", 60), Price: usd(10000)}
+	tests := []struct {
+		name     string
+		pricing  Pricing
+		nights   []string
+		guests   int
+		windows  []DateRangeWithPrice
+		subtotal Money
+		discount string
+		total    Money
+	}{
+		{
+			name:     "per accommodation",
+			pricing:  Pricing{Paying: PerAccommodation},
+			nights:   nightsFrom("2026-06-01", 3),
+			guests:   4,
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(30000),
+			total:    usd(30000),
+		},
+		{
+			name:     "per guest",
+			pricing:  Pricing{Paying: PerGuest},
+			nights:   nightsFrom("2026-06-01", 3),
+			guests:   4,
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(120000),
+			total:    usd(120000),
+		},
+		{
+			name:     "per guest counts at least one guest",
+			pricing:  Pricing{Paying: PerGuest},
+			nights:   nightsFrom("2026-06-01", 2),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(20000),
+			total:    usd(20000),
+		},
+		{
+			name:     "cleaning fee is not discounted",
+			pricing:  Pricing{Paying: PerAccommodation, CleaningFee: usd(5000), WeeklyDiscountPercent: 10},
+			nights:   nightsFrom("2026-06-01", 7),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(70000),
+			discount: "weekly",
+			total:    usd(68000),
+		},
+		{
+			name:     "six nights get no weekly discount",
+			pricing:  Pricing{Paying: PerAccommodation, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 30},
+			nights:   nightsFrom("2026-06-01", 6),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(60000),
+			total:    usd(60000),
+		},
+		{
+			name:     "seven nights get the weekly discount",
+			pricing:  Pricing{Paying: PerAccommodation, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 30},
+			nights:   nightsFrom("2026-06-01", 7),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(70000),
+			discount: "weekly",
+			total:    usd(63000),
+		},
+		{
+			name:     "twenty seven nights get the weekly discount",
+			pricing:  Pricing{Paying: PerAccommodation, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 30},
+			nights:   nightsFrom("2026-06-01", 27),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(270000),
+			discount: "weekly",
+			total:    usd(243000),
+		},
+		{
+			name:     "twenty eight nights get the monthly discount",
+			pricing:  Pricing{Paying: PerAccommodation, WeeklyDiscountPercent: 10, MonthlyDiscountPercent: 30},
+			nights:   nightsFrom("2026-06-01", 28),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(280000),
+			discount: "monthly",
+			total:    usd(196000),
+		},
+		{
+			name:     "a month without a monthly discount gets the weekly one",
+			pricing:  Pricing{Paying: PerAccommodation, WeeklyDiscountPercent: 10},
+			nights:   nightsFrom("2026-06-01", 28),
+			windows:  []DateRangeWithPrice{season},
+			subtotal: usd(280000),
+			discount: "weekly",
+			total:    usd(252000),
+		},
+		{
+			name:    "the narrowest window sets the rate",
+			pricing: Pricing{Paying: PerAccommodation},
+			nights:  nightsFrom("2026-06-09", 3),
+			windows: []DateRangeWithPrice{
+				season,
+				{DateRange: nightsFrom("2026-06-10", 1), Price: usd(25000)},
+				{DateRange: nightsFrom("2026-06-08", 5), Price: usd(15000)},
+			},
+			subtotal: usd(15000 + 25000 + 15000),
+			total:    usd(55000),
+		},
+		{
+			name:    "the cheaper of equally narrow windows sets the rate",
+			pricing: Pricing{Paying: PerAccommodation},
+			nights:  nightsFrom("2026-06-10", 1),
+			windows: []DateRangeWithPrice{
+				season,
+				{DateRange: nightsFrom("2026-06-10", 2), Price: usd(20000)},
+				{DateRange: nightsFrom("2026-06-09", 2), Price: usd(18000)},
+			},
+			subtotal: usd(18000),
+			total:    usd(18000),
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			quote, err := test.pricing.Quote(test.nights, test.guests, test.windows)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if quote.Subtotal != test.subtotal {
+				t.Fatalf("subtotal is %s, want %s", quote.Subtotal, test.subtotal)
+			}
+			if quote.Discount != test.discount {
+				t.Fatalf("discount is %q, want %q", quote.Discount, test.discount)
+			}
+			if quote.Total != test.total || quote.Charged != test.total {
+				t.Fatalf("total is %s and charged %s, want %s", quote.Total, quote.Charged, test.total)
+			}
+			if len(quote.Nights) != len(test.nights) {
+				t.Fatalf("quoted %d nights, want %d", len(quote.Nights), len(test.nights))
+			}
+		})
+	}
+}
+
+func TestQuoteSortsTheNights(t *testing.T) {
+	windows := []DateRangeWithPrice{{DateRange: nightsFrom("2026-06-01", 5), Price: usd(10000)}}
+	quote, err := (&Pricing{Paying: PerAccommodation}).Quote([]string{"2026-06-03", "2026-06-01", "2026-06-02"}, 1, windows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, night := range nightsFrom("2026-06-01", 3) {
+		if quote.Nights[i].Date != night {
+			t.Fatalf("night %d is %s, want %s", i, quote.Nights[i].Date, night)
+		}
+	}
+}
+
+func TestQuoteRefuses(t *testing.T) {
+	season := DateRangeWithPrice{DateRange: nightsFrom("2026-06-01", 30), Price: usd(10000)}
+	tests := []struct {
+		name    string
+		pricing Pricing
+		nights  []string
+		windows []DateRangeWithPrice
+		err     string
+	}{
+		{
+			name:    "no nights",
+			pricing: Pricing{Paying: PerAccommodation},
+			windows: []DateRangeWithPrice{season},
+			err:     "date range is empty",
+		},
+		{
+			name:    "a night twice",
+			pricing: Pricing{Paying: PerAccommodation},
+			nights:  []string{"2026-06-02", "2026-06-01", "2026-06-02"},
+			windows: []DateRangeWithPrice{season},
+			err:     "twice",
+		},
+		{
+			name:    "a night without a window",
+			pricing: Pricing{Paying: PerAccommodation},
+			nights:  nightsFrom("2026-06-29", 3),
+			windows: []DateRangeWithPrice{season},
+			err:     "not available on 2026-07-01",
+		},
+		{
+			name:    "nights in two currencies",
+			pricing: Pricing{Paying: PerAccommodation},
+			nights:  nightsFrom("2026-06-29", 3),
+			windows: []DateRangeWithPrice{season, {DateRange: nightsFrom("2026-07-01", 5), Price: Money{Amount: 9000, Currency: "EUR"}}},
+			err:     "both USD and EUR",
+		},
+		{
+			name:    "a cleaning fee in another currency",
+			pricing: Pricing{Paying: PerAccommodation, CleaningFee: Money{Amount: 5000, Currency: "EUR"}},
+			nights:  nightsFrom("2026-06-01", 3),
+			windows: []DateRangeWithPrice{season},
+			err:     "cleaning fee is in EUR",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := test.pricing.Quote(test.nights, 1, test.windows)
+			if err == nil {
+				t.Fatal("quote was not refused")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("refused with %q, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestWindowOf(t *testing.T) {
+	season := DateRangeWithPrice{DateRange: nightsFrom("2026-06-01", 30), Price: usd(10000)}
+	week := DateRangeWithPrice{DateRange: nightsFrom("2026-06-08", 7), Price: usd(12000)}
+	cheapWeek := DateRangeWithPrice{DateRange: nightsFrom("2026-06-10", 7), Price: usd(9000)}
+	tests := []struct {
+		name    string
+		night   string
+		windows []DateRangeWithPrice
+		want    Money
+		found   bool
+	}{
+		{name: "only window", night: "2026-06-02", windows: []DateRangeWithPrice{season}, want: usd(10000), found: true},
+		{name: "narrower after wider", night: "2026-06-09", windows: []DateRangeWithPrice{season, week}, want: usd(12000), found: true},
+		{name: "narrower before wider", night: "2026-06-09", windows: []DateRangeWithPrice{week, season}, want: usd(12000), found: true},
+		{name: "cheaper of equally narrow", night: "2026-06-12", windows: []DateRangeWithPrice{season, week, cheapWeek}, want: usd(9000), found: true},
+		{name: "cheaper of equally narrow in any order", night: "2026-06-12", windows: []DateRangeWithPrice{cheapWeek, week, season}, want: usd(9000), found: true},
+		{name: "outside every window", night: "2026-07-02", windows: []DateRangeWithPrice{season, week}},
+		{name: "no windows", night: "2026-06-02"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			window, found := windowOf(test.night, test.windows)
+			if found != test.found {
+				t.Fatalf("found is %v, want %v", found, test.found)
+			}
+			if window.Price != test.want {
+				t.Fatalf("rate is %s, want %s", window.Price, test.want)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"encoding/json"
 	"io"
+	"time"
 
 	"github.com/gocql/gocql"
 )
@@ -16,28 +17,45 @@ type Reservation struct {
 	Username          string     `json:"username"`
 	AccommodationName string     `json:"accommodationName"`
 	Location          string     `json:"location"`
-	Price             int        `json:"price"`
+	Price             Money      `json:"price"`
 	NumberOfDays      int        `json:"numOfDays"`
 	Continent         string     `json:"continent"`
 	DateRange         []string   `json:"dateRange"`
 	IsActive          bool       `json:"isActive"`
 	Country           string     `json:"country"`
 	HostID            string     `json:"hostId"`
+	Guests            int        `json:"guests"`
+	// Party breaks the guests down. Reservations made before parties were recorded
+	// have none.
+	Party          *Party                         `json:"party,omitempty"`
+	State          ReservationState               `json:"state"`
+	StateChangedAt map[ReservationState]time.Time `json:"stateChangedAt"`
+	Refund         *Refund                        `json:"refund,omitempty"`
 }
 
 type FreeReservation struct {
 	Id              gocql.UUID           `json:"id"`
 	AccommodationID string               `json:"accommodationId"`
 	Location        string               `json:"location"`
-	Price           int                  `json:"price"`
+	Price           Money                `json:"price"`
 	Continent       string               `json:"continent"`
 	Country         string               `json:"country"`
 	DateRange       []DateRangeWithPrice `json:"dateRange"`
 }
 type DateRangeWithPrice struct {
+	DateRange []string  `json:"dateRange"`
+	Price     Money     `json:"price"`
+	Rules     StayRules `json:"rules"`
+}
+
+// ReservationChange is what a guest may change on a reservation. A party replaces
+// the guest count, which is kept for clients that only send one.
+type ReservationChange struct {
 	DateRange []string `json:"dateRange"`
-	Price     int      `json:"price"`
+	Guests    int      `json:"guests"`
+	Party     *Party   `json:"party,omitempty"`
 }
+
 type ReservationsInDateRangeRequest struct {
 	AccommodationIDs []string `json:"accommodationIDs"`
 	DateRange        []string `json:"dateRange"`
@@ -47,14 +65,21 @@ type CheckAvailabilityRequest struct {
 	DateRange       []string `json:"dateRange"`
 }
 type GetAvailabilityForAccommodation struct {
-	DateRange []string `json:"dateRange"`
-	Price     int      `json:"price"`
-	Id        string   `json:"id"`
+	DateRange []string  `json:"dateRange"`
+	Price     Money     `json:"price"`
+	Rules     StayRules `json:"rules"`
+	Id        string    `json:"id"`
+}
+
+// AvailabilityPrice is the nightly rate of one availability window.
+type AvailabilityPrice struct {
+	AccommodationID string `json:"accommodationId"`
+	Price           Money  `json:"price"`
 }
 
 type ReservationById []*Reservation
 
-func NewReservation(id gocql.UUID, userID, accommodationID string, startDate, endDate, username, accommodationName, location string, price, numOfDays int, continent string, dateRange []string, isActive bool, country string) *Reservation {
+func NewReservation(id gocql.UUID, userID, accommodationID string, startDate, endDate, username, accommodationName, location string, price Money, numOfDays int, continent string, dateRange []string, isActive bool, country string) *Reservation {
 	return &Reservation{
 		Id:                id,
 		UserID:            userID,
@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+type ReservationState string
+
+const (
+	Requested ReservationState = "Requested"
+	Confirmed ReservationState = "Confirmed"
+	CheckedIn ReservationState = "CheckedIn"
+	Completed ReservationState = "Completed"
+	Cancelled ReservationState = "Cancelled"
+	Declined  ReservationState = "Declined"
+	Expired   ReservationState = "Expired"
+)
+
+// transitions lists the states a reservation may move to from each state. Completed,
+// Cancelled, Declined and Expired are final. A confirmed reservation whose guest
+// never checked in is completed once its stay is over. Declined and Expired end a
+// request the host did not accept, so they do not count as cancellations.
+var transitions = map[ReservationState][]ReservationState{
+	Requested: {Confirmed, Cancelled, Declined, Expired},
+	Confirmed: {CheckedIn, Completed, Cancelled},
+	CheckedIn: {Completed},
+}
+
+func (s ReservationState) CanTransitionTo(next ReservationState) bool {
+	for _, allowed := range transitions[s] {
+		if allowed == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsOpen reports whether the reservation still holds its dates.
+func (s ReservationState) IsOpen() bool {
+	return s == Requested || s == Confirmed || s == CheckedIn
+}
+
+// Transition moves the reservation to next and records when it happened.
+func (r *Reservation) Transition(next ReservationState, at time.Time) bool {
+	if !r.State.CanTransitionTo(next) {
+		return false
+	}
+	r.State = next
+	if r.StateChangedAt == nil {
+		r.StateChangedAt = make(map[ReservationState]time.Time)
+	}
+	r.StateChangedAt[next] = at
+	r.IsActive = next != Cancelled
+	return true
+}
+
+// HasEnded reports whether the end date of the stay is today or earlier.
+func (r *Reservation) HasEnded(now time.Time) bool {
+	return r.EndDate != "" && r.EndDate <= now.Format("2006-01-02")
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// StayRules limit which stays an availability window can be booked for. Zero
+// values and empty weekday lists do not limit anything. Weekdays are named the way
+// time.Weekday prints them, such as "Saturday".
+type StayRules struct {
+	MinNights      int      `json:"minNights" cql:"min_nights"`
+	MaxNights      int      `json:"maxNights" cql:"max_nights"`
+	CheckInDays    []string `json:"checkInDays" cql:"check_in_days"`
+	CheckOutDays   []string `json:"checkOutDays" cql:"check_out_days"`
+	MinNoticeDays  int      `json:"minNoticeDays" cql:"min_notice_days"`
+	MaxHorizonDays int      `json:"maxHorizonDays" cql:"max_horizon_days"`
+}
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[day.String()] = day
+	}
+}
+
+func (r StayRules) Validate() error {
+	for _, value := range []int{r.MinNights, r.MaxNights, r.MinNoticeDays, r.MaxHorizonDays} {
+		if value < 0 {
+			return fmt.Errorf("stay rules can not be negative")
+		}
+	}
+	if r.MaxNights > 0 && r.MaxNights < r.MinNights {
+		return fmt.Errorf("maximum nights %d are below minimum nights %d", r.MaxNights, r.MinNights)
+	}
+	if r.MaxHorizonDays > 0 && r.MaxHorizonDays < r.MinNoticeDays {
+		return fmt.Errorf("booking horizon of %d days is shorter than the notice of %d days", r.MaxHorizonDays, r.MinNoticeDays)
+	}
+	for _, day := range append(append([]string(nil), r.CheckInDays...), r.CheckOutDays...) {
+		if _, exists := weekdays[day]; !exists {
+			return fmt.Errorf("unknown weekday %q", day)
+		}
+	}
+	return nil
+}
+
+// Check tells why a stay of the given nights, booked at now, breaks the rules, or
+// returns nil when it does not. The guest checks out the morning after the last
+// night.
+func (r StayRules) Check(nights []string, now time.Time) error {
+	if len(nights) == 0 {
+		return fmt.Errorf("date range is empty")
+	}
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	checkIn, err := time.ParseInLocation("2006-01-02", sorted[0], now.Location())
+	if err != nil {
+		return fmt.Errorf("invalid date %q", sorted[0])
+	}
+	lastNight, err := time.ParseInLocation("2006-01-02", sorted[len(sorted)-1], now.Location())
+	if err != nil {
+		return fmt.Errorf("invalid date %q", sorted[len(sorted)-1])
+	}
+	checkOut := lastNight.AddDate(0, 0, 1)
+
+	if r.MinNights > 0 && len(sorted) < r.MinNights {
+		return fmt.Errorf("stays must be at least %d nights", r.MinNights)
+	}
+	if r.MaxNights > 0 && len(sorted) > r.MaxNights {
+		return fmt.Errorf("stays can be at most %d nights", r.MaxNights)
+	}
+	if !allowedDay(r.CheckInDays, checkIn) {
+		return fmt.Errorf("check-in is only possible on %s", strings.Join(r.CheckInDays, ", "))
+	}
+	if !allowedDay(r.CheckOutDays, checkOut) {
+		return fmt.Errorf("check-out is only possible on %s", strings.Join(r.CheckOutDays, ", "))
+	}
+	notice, err := daysBeforeCheckIn(sorted[0], now)
+	if err != nil {
+		return err
+	}
+	if notice < r.MinNoticeDays {
+		return fmt.Errorf("stays must be booked at least %d days before check-in", r.MinNoticeDays)
+	}
+	if r.MaxHorizonDays > 0 && notice > r.MaxHorizonDays {
+		return fmt.Errorf("stays can be booked at most %d days ahead", r.MaxHorizonDays)
+	}
+	return nil
+}
+
+func allowedDay(days []string, date time.Time) bool {
+	if len(days) == 0 {
+		return true
+	}
+	for _, day := range days {
+		if weekdays[day] == date.Weekday() {
+			return true
+		}
+	}
+	return false
+}
+
+// StayRulesFor returns the rules of the window the stay checks in on, the same
+// window that sets the rate of the first night.
+func StayRulesFor(nights []string, windows []DateRangeWithPrice) (StayRules, bool) {
+	if len(nights) == 0 {
+		return StayRules{}, false
+	}
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	window, found := windowOf(sorted[0], windows)
+	return window.Rules, found
+}
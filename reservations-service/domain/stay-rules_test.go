@@ -0,0 +1,113 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// bookedAt is a Sunday.
+var bookedAt = time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+
+func TestStayRulesCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		rules  StayRules
+		nights []string
+		err    string
+	}{
+		{name: "no rules", nights: nightsFrom("2026-03-01", 1)},
+		{name: "empty stay", err: "date range is empty"},
+		{name: "invalid night", nights: []string{"2026-03-05", "tomorrow"}, err: "invalid date"},
+		{name: "below minimum nights", rules: StayRules{MinNights: 3}, nights: nightsFrom("2026-03-05", 2), err: "at least 3 nights"},
+		{name: "minimum nights", rules: StayRules{MinNights: 3}, nights: nightsFrom("2026-03-05", 3)},
+		{name: "maximum nights", rules: StayRules{MaxNights: 7}, nights: nightsFrom("2026-03-05", 7)},
+		{name: "above maximum nights", rules: StayRules{MaxNights: 7}, nights: nightsFrom("2026-03-05", 8), err: "at most 7 nights"},
+		{name: "check-in day", rules: StayRules{CheckInDays: []string{"Saturday"}}, nights: nightsFrom("2026-03-07", 2)},
+		{name: "other check-in day", rules: StayRules{CheckInDays: []string{"Saturday"}}, nights: nightsFrom("2026-03-06", 2), err: "check-in is only possible on Saturday"},
+		{name: "one of the check-in days", rules: StayRules{CheckInDays: []string{"Friday", "Saturday"}}, nights: nightsFrom("2026-03-06", 2)},
+		{name: "check-out day is the morning after the last night", rules: StayRules{CheckOutDays: []string{"Saturday"}}, nights: nightsFrom("2026-03-09", 5)},
+		{name: "last night on the check-out day", rules: StayRules{CheckOutDays: []string{"Saturday"}}, nights: nightsFrom("2026-03-09", 6), err: "check-out is only possible on Saturday"},
+		{name: "check-in from the earliest night", rules: StayRules{CheckInDays: []string{"Saturday"}}, nights: []string{"2026-03-08", "2026-03-07"}},
+		{name: "check-in today without notice", nights: nightsFrom("2026-03-01", 2)},
+		{name: "below notice", rules: StayRules{MinNoticeDays: 2}, nights: nightsFrom("2026-03-02", 2), err: "at least 2 days before check-in"},
+		{name: "notice", rules: StayRules{MinNoticeDays: 2}, nights: nightsFrom("2026-03-03", 2)},
+		{name: "horizon", rules: StayRules{MaxHorizonDays: 30}, nights: nightsFrom("2026-03-31", 2)},
+		{name: "beyond horizon", rules: StayRules{MaxHorizonDays: 30}, nights: nightsFrom("2026-04-01", 2), err: "at most 30 days ahead"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rules.Check(test.nights, bookedAt)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("refused: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("accepted")
+			}
+			if !strings.Contains(err.Error(), test.err) {
+				t.Fatalf("refused with %q, want %q", err, test.err)
+			}
+		})
+	}
+}
+
+func TestStayRulesValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules StayRules
+		valid bool
+	}{
+		{name: "no rules", valid: true},
+		{name: "all rules", rules: StayRules{MinNights: 2, MaxNights: 14, CheckInDays: []string{"Saturday"}, CheckOutDays: []string{"Saturday", "Sunday"}, MinNoticeDays: 1, MaxHorizonDays: 365}, valid: true},
+		{name: "equal minimum and maximum nights", rules: StayRules{MinNights: 7, MaxNights: 7}, valid: true},
+		{name: "minimum nights without maximum", rules: StayRules{MinNights: 7}, valid: true},
+		{name: "negative", rules: StayRules{MinNoticeDays: -1}},
+		{name: "maximum below minimum nights", rules: StayRules{MinNights: 7, MaxNights: 6}},
+		{name: "horizon shorter than notice", rules: StayRules{MinNoticeDays: 10, MaxHorizonDays: 9}},
+		{name: "unknown check-in day", rules: StayRules{CheckInDays: []string{"saturday"}}},
+		{name: "unknown check-out day", rules: StayRules{CheckOutDays: []string{"Sat"}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rules.Validate()
+			if test.valid && err != nil {
+				t.Fatalf("refused: %v", err)
+			}
+			if !test.valid && err == nil {
+				t.Fatal("accepted")
+			}
+		})
+	}
+}
+
+func TestStayRulesFor(t *testing.T) {
+	season := DateRangeWithPrice{DateRange: nightsFrom("2026-03-01", 31), Price: usd(10000), Rules: StayRules{MinNights: 2}}
+	week := DateRangeWithPrice{DateRange: nightsFrom("2026-03-07", 7), Price: usd(12000), Rules: StayRules{MinNights: 7}}
+	tests := []struct {
+		name   string
+		nights []string
+		want   int
+		found  bool
+	}{
+		{name: "window of the check-in", nights: nightsFrom("2026-03-02", 3), want: 2, found: true},
+		{name: "narrowest window of the check-in", nights: nightsFrom("2026-03-08", 3), want: 7, found: true},
+		{name: "later nights do not count", nights: nightsFrom("2026-03-05", 5), want: 2, found: true},
+		{name: "earliest night is the check-in", nights: []string{"2026-03-09", "2026-03-04"}, want: 2, found: true},
+		{name: "outside every window", nights: nightsFrom("2026-04-02", 2)},
+		{name: "empty stay"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, found := StayRulesFor(test.nights, []DateRangeWithPrice{season, week})
+			if found != test.found {
+				t.Fatalf("found is %v, want %v", found, test.found)
+			}
+			if rules.MinNights != test.want {
+				t.Fatalf("got rules with %d minimum nights, want %d", rules.MinNights, test.want)
+			}
+		})
+	}
+}
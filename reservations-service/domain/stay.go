@@ -0,0 +1,77 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	defaultCheckInTime = "14:00"
+	defaultNoticeHours = 24
+	// maxNoticeHours keeps access codes from going out weeks before the stay.
+	maxNoticeHours = 7 * 24
+)
+
+// ArrivalInstructions are what the guests of an accommodation are sent NoticeHours
+// before check-in on the first night of their stay.
+type ArrivalInstructions struct {
+	AccommodationID string `json:"accommodationId"`
+	// CheckInTime is the local time guests can arrive from, as 15:04.
+	CheckInTime  string `json:"checkInTime"`
+	Instructions string `json:"instructions"`
+	AccessCode   string `json:"accessCode"`
+	HouseRules   string `json:"houseRules"`
+	NoticeHours  int    `json:"noticeHours"`
+}
+
+// Validate fills in the default check-in time and notice and checks the rest.
+func (a *ArrivalInstructions) Validate() error {
+	if strings.TrimSpace(a.Instructions) == "" && strings.TrimSpace(a.AccessCode) == "" && strings.TrimSpace(a.HouseRules) == "" {
+		return fmt.Errorf("arrival instructions are empty")
+	}
+	if a.CheckInTime == "" {
+		a.CheckInTime = defaultCheckInTime
+	}
+	if _, err := time.Parse("15:04", a.CheckInTime); err != nil {
+		return fmt.Errorf("check-in time %q is not in the 15:04 format", a.CheckInTime)
+	}
+	if a.NoticeHours == 0 {
+		a.NoticeHours = defaultNoticeHours
+	}
+	if a.NoticeHours < 0 || a.NoticeHours > maxNoticeHours {
+		return fmt.Errorf("instructions can be sent at most %d hours before check-in", maxNoticeHours)
+	}
+	return nil
+}
+
+// CheckInAt returns when the guests of a stay starting on startDate can arrive.
+func (a ArrivalInstructions) CheckInAt(startDate string, location *time.Location) (time.Time, error) {
+	return time.ParseInLocation("2006-01-02 15:04", startDate+" "+a.CheckInTime, location)
+}
+
+// SendAt returns when the guests of a stay starting on startDate are sent the
+// instructions.
+func (a ArrivalInstructions) SendAt(startDate string, location *time.Location) (time.Time, error) {
+	checkInAt, err := a.CheckInAt(startDate, location)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return checkInAt.Add(-time.Duration(a.NoticeHours) * time.Hour), nil
+}
+
+// Message is the notification the guest of the reservation is sent.
+func (a ArrivalInstructions) Message(reservation Reservation) string {
+	var message strings.Builder
+	fmt.Fprintf(&message, "Your stay at %s starts on %s, check-in from %s.", reservation.AccommodationName, reservation.StartDate, a.CheckInTime)
+	if a.Instructions != "" {
+		fmt.Fprintf(&message, "\nCheck-in: %s", a.Instructions)
+	}
+	if a.AccessCode != "" {
+		fmt.Fprintf(&message, "\nAccess code: %s", a.AccessCode)
+	}
+	if a.HouseRules != "" {
+		fmt.Fprintf(&message, "\nHouse rules: %s", a.HouseRules)
+	}
+	return message.String()
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+type WaitlistState string
+
+const (
+	Waiting WaitlistState = "Waiting"
+	// Offered entries have the dates to themselves until their offer expires.
+	Offered WaitlistState = "Offered"
+	Booked  WaitlistState = "Booked"
+	// Lapsed entries let their offer expire or waited past the first night.
+	Lapsed WaitlistState = "Lapsed"
+)
+
+// WaitlistEntry is a guest waiting for booked dates of an accommodation to be
+// freed.
+type WaitlistEntry struct {
+	Id                gocql.UUID    `json:"id"`
+	AccommodationID   string        `json:"accommodationId"`
+	AccommodationName string        `json:"accommodationName"`
+	HostID            string        `json:"hostId"`
+	UserID            string        `json:"userId"`
+	Username          string        `json:"username"`
+	DateRange         []string      `json:"dateRange"`
+	Guests            int           `json:"guests"`
+	State             WaitlistState `json:"state"`
+	JoinedAt          time.Time     `json:"joinedAt"`
+	OfferExpiresAt    time.Time     `json:"offerExpiresAt"`
+}
+
+// HoldsOffer reports whether the dates are still offered to the entry at now.
+func (e WaitlistEntry) HoldsOffer(now time.Time) bool {
+	return e.State == Offered && e.OfferExpiresAt.After(now)
+}
+
+// Overlaps reports whether the entry waits for any of the nights.
+func (e WaitlistEntry) Overlaps(nights []string) bool {
+	return len(e.Overlap(nights)) > 0
+}
+
+// Overlap returns the nights the entry waits for.
+func (e WaitlistEntry) Overlap(nights []string) []string {
+	var overlap []string
+	for _, night := range nights {
+		for _, waited := range e.DateRange {
+			if night == waited {
+				overlap = append(overlap, night)
+				break
+			}
+		}
+	}
+	return overlap
+}
@@ -0,0 +1,31 @@
+package exchange
+
+import (
+	"fmt"
+	"math"
+	"reservation-service/domain"
+)
+
+// Provider knows the exchange rates between the currencies it supports.
+type Provider interface {
+	// Rate returns how many units of to one unit of from buys.
+	Rate(from, to string) (float64, error)
+	Currencies() []string
+}
+
+// Convert changes money into another currency, rounding to the nearest minor unit.
+func Convert(provider Provider, money domain.Money, to string) (domain.Money, error) {
+	if money.Currency == to {
+		return money, nil
+	}
+	rate, err := provider.Rate(money.Currency, to)
+	if err != nil {
+		return domain.Money{}, err
+	}
+	units := float64(money.Amount) / float64(domain.MinorUnits(money.Currency))
+	amount := math.Round(units * rate * float64(domain.MinorUnits(to)))
+	if math.IsInf(amount, 0) || math.IsNaN(amount) {
+		return domain.Money{}, fmt.Errorf("unable to convert %s to %s", money, to)
+	}
+	return domain.Money{Amount: int64(amount), Currency: to}, nil
+}
@@ -0,0 +1,52 @@
+package exchange
+
+import (
+	"reservation-service/domain"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	provider, err := NewStaticProviderFromRates("USD", map[string]float64{"EUR": 0.92, "JPY": 149.8, "RSD": 107.9})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name  string
+		money domain.Money
+		to    string
+		want  int64
+	}{
+		{name: "same currency", money: domain.Money{Amount: 12345, Currency: "USD"}, to: "USD", want: 12345},
+		{name: "cents to cents", money: domain.Money{Amount: 10000, Currency: "USD"}, to: "EUR", want: 9200},
+		{name: "rounds to the nearest cent", money: domain.Money{Amount: 1, Currency: "USD"}, to: "EUR", want: 1},
+		{name: "rounds up", money: domain.Money{Amount: 3, Currency: "USD"}, to: "EUR", want: 3},
+		{name: "rounds down", money: domain.Money{Amount: 10, Currency: "USD"}, to: "EUR", want: 9},
+		{name: "cents to a zero decimal currency", money: domain.Money{Amount: 10000, Currency: "USD"}, to: "JPY", want: 14980},
+		{name: "a zero decimal currency to cents", money: domain.Money{Amount: 14980, Currency: "JPY"}, to: "USD", want: 10000},
+		{name: "between two quoted currencies", money: domain.Money{Amount: 9200, Currency: "EUR"}, to: "RSD", want: 1079000},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := Convert(provider, test.money, test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got.Amount != test.want || got.Currency != test.to {
+				t.Fatalf("got %s, want %d %s", got, test.want, test.to)
+			}
+		})
+	}
+}
+
+func TestConvertRefusesUnknownCurrencies(t *testing.T) {
+	provider, err := NewStaticProviderFromRates("USD", map[string]float64{"EUR": 0.92})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Convert(provider, domain.Money{Amount: 100, Currency: "USD"}, "GBP"); err == nil {
+		t.Fatal("converted to a currency without a rate")
+	}
+	if _, err := Convert(provider, domain.Money{Amount: 100, Currency: "GBP"}, "USD"); err == nil {
+		t.Fatal("converted from a currency without a rate")
+	}
+}
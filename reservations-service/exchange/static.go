@@ -0,0 +1,71 @@
+package exchange
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// StaticProvider serves rates read once from a file, so prices can be converted
+// without reaching an outside service. The file holds the value of one unit of the
+// base currency in every other currency:
+//
+//	{"base": "USD", "rates": {"EUR": 0.92, "RSD": 108.1}}
+type StaticProvider struct {
+	base  string
+	rates map[string]float64
+}
+
+type ratesFile struct {
+	Base  string             `json:"base"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	var contents ratesFile
+	if err := json.NewDecoder(file).Decode(&contents); err != nil {
+		return nil, fmt.Errorf("unable to read exchange rates from %s: %w", path, err)
+	}
+	return NewStaticProviderFromRates(contents.Base, contents.Rates)
+}
+
+func NewStaticProviderFromRates(base string, rates map[string]float64) (*StaticProvider, error) {
+	if base == "" {
+		return nil, fmt.Errorf("exchange rates have no base currency")
+	}
+	provider := &StaticProvider{base: base, rates: map[string]float64{base: 1}}
+	for currency, rate := range rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("invalid exchange rate %v for %s", rate, currency)
+		}
+		provider.rates[currency] = rate
+	}
+	return provider, nil
+}
+
+func (p *StaticProvider) Rate(from, to string) (float64, error) {
+	fromRate, exists := p.rates[from]
+	if !exists {
+		return 0, fmt.Errorf("no exchange rate for %s", from)
+	}
+	toRate, exists := p.rates[to]
+	if !exists {
+		return 0, fmt.Errorf("no exchange rate for %s", to)
+	}
+	return toRate / fromRate, nil
+}
+
+func (p *StaticProvider) Currencies() []string {
+	currencies := make([]string, 0, len(p.rates))
+	for currency := range p.rates {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
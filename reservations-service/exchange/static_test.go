@@ -0,0 +1,81 @@
+package exchange
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNewStaticProvider(t *testing.T) {
+	provider, err := NewStaticProvider("rates.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rate, err := provider.Rate("USD", "EUR")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rate != 0.92 {
+		t.Fatalf("USD to EUR is %v, want 0.92", rate)
+	}
+	for _, currency := range provider.Currencies() {
+		if _, err := provider.Rate(currency, "USD"); err != nil {
+			t.Fatalf("%s has no rate: %v", currency, err)
+		}
+	}
+}
+
+func TestNewStaticProviderRefusesInvalidFiles(t *testing.T) {
+	tests := map[string]string{
+		"not json":      `base: USD`,
+		"no base":       `{"rates": {"EUR": 0.92}}`,
+		"zero rate":     `{"base": "USD", "rates": {"EUR": 0}}`,
+		"negative rate": `{"base": "USD", "rates": {"EUR": -0.92}}`,
+	}
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rates.json")
+			if err := os.WriteFile(path, []byte(contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := NewStaticProvider(path); err == nil {
+				t.Fatal("rates were accepted")
+			}
+		})
+	}
+	if _, err := NewStaticProvider(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Fatal("a missing file was accepted")
+	}
+}
+
+func TestStaticProviderRate(t *testing.T) {
+	provider, err := NewStaticProviderFromRates("USD", map[string]float64{"EUR": 0.5, "RSD": 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		from, to string
+		want     float64
+	}{
+		{from: "USD", to: "USD", want: 1},
+		{from: "USD", to: "EUR", want: 0.5},
+		{from: "EUR", to: "USD", want: 2},
+		{from: "EUR", to: "RSD", want: 200},
+		{from: "RSD", to: "EUR", want: 0.005},
+	}
+	for _, test := range tests {
+		t.Run(test.from+" to "+test.to, func(t *testing.T) {
+			rate, err := provider.Rate(test.from, test.to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if rate != test.want {
+				t.Fatalf("got %v, want %v", rate, test.want)
+			}
+		})
+	}
+	if want := []string{"EUR", "RSD", "USD"}; !reflect.DeepEqual(provider.Currencies(), want) {
+		t.Fatalf("currencies are %v, want %v", provider.Currencies(), want)
+	}
+}
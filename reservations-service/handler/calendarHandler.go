@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reservation-service/calendar"
+	"reservation-service/domain"
+	"reservation-service/utils"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+type calendarImportRequest struct {
+	ImportURL string `json:"importUrl"`
+}
+
+func (rh *ReservationHandler) GetCalendarFeed(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetCalendarFeed")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	feed, err := rh.ReservationService.GetCalendarFeed(ctx, hostID, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(feed, 200, rw)
+}
+
+func (rh *ReservationHandler) SetCalendarImport(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.SetCalendarImport")
+	defer span.End()
+	vars := mux.Vars(r)
+	var body calendarImportRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/calendar/{accommodationId}", rw)
+		return
+	}
+	hostID := r.Context().Value("userID").(string)
+	feed, err := rh.ReservationService.SetCalendarImport(ctx, hostID, vars["accommodationId"], body.ImportURL)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(feed, 200, rw)
+}
+
+func (rh *ReservationHandler) RotateCalendarToken(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.RotateCalendarToken")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	feed, err := rh.ReservationService.RotateCalendarToken(ctx, hostID, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/token", rw)
+		return
+	}
+	utils.WriteResp(feed, 200, rw)
+}
+
+// ImportCalendar starts importing the calendar of the accommodation. The import
+// runs after the response, whose feed shows the outcome of the previous one.
+func (rh *ReservationHandler) ImportCalendar(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.ImportCalendar")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	feed, err := rh.ReservationService.ImportCalendarNow(ctx, hostID, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/import", rw)
+		return
+	}
+	utils.WriteResp(feed, 202, rw)
+}
+
+// ExportCalendar serves the iCalendar feed of the accommodation to whoever has its
+// token, such as another listing platform.
+func (rh *ReservationHandler) ExportCalendar(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.ExportCalendar")
+	defer span.End()
+	vars := mux.Vars(r)
+	events, err := rh.ReservationService.ExportCalendar(ctx, vars["accommodationId"], r.URL.Query().Get("token"))
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/feed.ics", rw)
+		return
+	}
+	rw.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	rw.Header().Set("Cache-Control", "no-store")
+	rw.WriteHeader(200)
+	calendar.Write(rw, "Accommodation "+vars["accommodationId"], events, time.Now())
+}
+
+func (rh *ReservationHandler) GetHostCalendar(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetHostCalendar")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	query := r.URL.Query()
+	nights, err := rh.ReservationService.GetHostCalendar(ctx, hostID, vars["accommodationId"], query.Get("from"), query.Get("to"))
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/nights", rw)
+		return
+	}
+	utils.WriteResp(nights, 200, rw)
+}
+
+func (rh *ReservationHandler) ChangeCalendar(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.ChangeCalendar")
+	defer span.End()
+	vars := mux.Vars(r)
+	var change domain.CalendarChange
+	if err := json.NewDecoder(r.Body).Decode(&change); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/calendar/{accommodationId}/changes", rw)
+		return
+	}
+	hostID := r.Context().Value("userID").(string)
+	record, err := rh.ReservationService.ChangeCalendar(ctx, hostID, vars["accommodationId"], change)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/changes", rw)
+		return
+	}
+	utils.WriteResp(record, 200, rw)
+}
+
+func (rh *ReservationHandler) GetCalendarChanges(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetCalendarChanges")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	changes, err := rh.ReservationService.GetCalendarChanges(ctx, hostID, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/calendar/{accommodationId}/changes", rw)
+		return
+	}
+	utils.WriteResp(changes, 200, rw)
+}
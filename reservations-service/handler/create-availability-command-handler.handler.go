@@ -17,19 +17,6 @@ type CreateAvailabilityCommandHandler struct {
 	logger             *config.Logger
 }
 
-type SendCreateAccommodationAvailability struct {
-	AccommodationID string                        `json:"accommodationId"`
-	Location        string                        `json:"location"`
-	DateRange       []AvailableAccommodationDates `json:"dateRange"`
-}
-
-type AvailableAccommodationDates struct {
-	AccommodationId string   `json:"accommodationId"`
-	DateRange       []string `json:"dateRange"`
-	Location        string   `json:"location"`
-	Price           int      `json:"price"`
-}
-
 func NewCreateAvailabilityCommandHandler(reservationService *service.ReservationService, replyPublisher saga.Publisher, commandSubscriber saga.Subscriber, logger *config.Logger) (*CreateAvailabilityCommandHandler, error) {
 	o := &CreateAvailabilityCommandHandler{
 		reservationService: reservationService,
@@ -44,27 +31,74 @@ func NewCreateAvailabilityCommandHandler(reservationService *service.Reservation
 	return o, nil
 }
 
-func (handler CreateAvailabilityCommandHandler) handle(command *events.CreateAccommodationCommand) {
+// handle receives the command already upcast to the current schema version. The
+// reply keeps the correlation ID and trace context of the command's envelope.
+func (handler CreateAvailabilityCommandHandler) handle(command *events.CreateAccommodationCommand, envelope *saga.Envelope) error {
 	handler.logger.LogInfo("create-availability-handler", fmt.Sprintf("USLA KOMANDA U CREATE AVAILIABILIY %v", command.Type))
 	valueFromCommand := command.Payload
 	reply := events.CreateAccommodationReply{Payload: valueFromCommand}
 	switch command.Type {
 	case events.CreateAvailability:
+		// A command redelivered after the saga rolled the accommodation back must
+		// not bring its availability back.
+		rolledBack, rollbackErr := handler.reservationService.AvailabilityRolledBack(context.Background(), valueFromCommand.AccommodationID)
+		if rollbackErr != nil {
+			return rollbackErr
+		}
+		if rolledBack {
+			handler.logger.LogInfo("create-availability-handler", fmt.Sprintf("Accommodation %s was rolled back, not creating its availability", valueFromCommand.AccommodationID))
+			reply.Type = events.AvailabilityNotCreated
+			break
+		}
+		// Commands are redelivered until acked, so a repeated command must not insert the availability twice.
+		existing, err := handler.reservationService.GetAvailabilityForAccommodation(context.Background(), valueFromCommand.AccommodationID)
+		if err == nil && len(existing) > 0 {
+			reply.Type = events.AvailabilityCreated
+			break
+		}
 		var dateRangeCasted []domain.DateRangeWithPrice
 		for _, value := range valueFromCommand.DateRange {
 			val := domain.DateRangeWithPrice{
 				DateRange: value.DateRange,
-				Price:     value.Price,
+				Price:     domain.StoredMoney(value.Price, value.Currency),
+			}
+			if rules := value.Rules; rules != nil {
+				val.Rules = domain.StayRules{
+					MinNights:      rules.MinNights,
+					MaxNights:      rules.MaxNights,
+					CheckInDays:    rules.CheckInDays,
+					CheckOutDays:   rules.CheckOutDays,
+					MinNoticeDays:  rules.MinNoticeDays,
+					MaxHorizonDays: rules.MaxHorizonDays,
+				}
 			}
 			dateRangeCasted = append(dateRangeCasted, val)
 		}
+		if policy := valueFromCommand.CancellationPolicy; policy != nil {
+			cancellationPolicy := domain.CancellationPolicy{AccommodationID: valueFromCommand.AccommodationID, Name: policy.Name}
+			for _, tier := range policy.Tiers {
+				cancellationPolicy.Tiers = append(cancellationPolicy.Tiers, domain.RefundTier{DaysBeforeCheckIn: tier.DaysBeforeCheckIn, RefundPercent: tier.RefundPercent})
+			}
+			if err := handler.reservationService.SetCancellationPolicy(context.Background(), &cancellationPolicy); err != nil {
+				handler.logger.LogError("create-availability-handler", fmt.Sprintf("Unable to set cancellation policy of %s: %s", valueFromCommand.AccommodationID, err.Message))
+				reply.Type = events.AvailabilityNotCreated
+				break
+			}
+		}
+		if valueFromCommand.Paying != "" {
+			if err := handler.reservationService.SetPaying(context.Background(), valueFromCommand.AccommodationID, valueFromCommand.Paying); err != nil {
+				handler.logger.LogError("create-availability-handler", fmt.Sprintf("Unable to set paying mode of %s: %s", valueFromCommand.AccommodationID, err.Message))
+				reply.Type = events.AvailabilityNotCreated
+				break
+			}
+		}
 		freeAccommodation := domain.FreeReservation{
 			AccommodationID: valueFromCommand.AccommodationID,
 			Location:        valueFromCommand.Location,
 			DateRange:       dateRangeCasted,
 		}
 
-		_, err := handler.reservationService.CreateAvailability(context.Background(), freeAccommodation)
+		_, err = handler.reservationService.CreateAvailability(context.Background(), freeAccommodation)
 		if err != nil {
 			handler.logger.LogInfo("create-availability-handler", "VRACANJE NOT CREATED AVAILABILITY")
 			reply.Type = events.AvailabilityNotCreated
@@ -73,11 +107,20 @@ func (handler CreateAvailabilityCommandHandler) handle(command *events.CreateAcc
 		handler.logger.LogInfo("create-availability-handler", "VRACANJE CREATED AVAILABILITY")
 		reply.Type = events.AvailabilityCreated
 		break
+	case events.RollbackAvailability:
+		// Compensations get no reply; returning the error leaves the command to be redelivered.
+		if err := handler.reservationService.RollbackAvailability(context.Background(), valueFromCommand.AccommodationID); err != nil {
+			handler.logger.LogError("create-availability-handler", fmt.Sprintf("Unable to roll back availability of %s", valueFromCommand.AccommodationID))
+			return err
+		}
+		handler.logger.LogInfo("create-availability-handler", fmt.Sprintf("Rolled back availability of %s", valueFromCommand.AccommodationID))
+		return nil
 	default:
 		reply.Type = events.UnknownReply
 		break
 	}
 	if reply.Type != events.UnknownReply {
-		_ = handler.replyPublisher.Publish(reply)
+		_ = saga.Reply(handler.replyPublisher, reply, envelope)
 	}
+	return nil
 }
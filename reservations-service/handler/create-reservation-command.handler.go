@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	events "example/saga/create_reservation"
+	saga "example/saga/messaging"
+	"fmt"
+	"reservation-service/config"
+	"reservation-service/service"
+)
+
+// CreateReservationCommandHandler is the reservations-service participant of the
+// create reservation saga: it confirms the held reservation and undoes it.
+type CreateReservationCommandHandler struct {
+	reservationService *service.ReservationService
+	replyPublisher     saga.Publisher
+	commandSubscriber  saga.Subscriber
+	logger             *config.Logger
+}
+
+func NewCreateReservationCommandHandler(reservationService *service.ReservationService, replyPublisher saga.Publisher, commandSubscriber saga.Subscriber, logger *config.Logger) (*CreateReservationCommandHandler, error) {
+	o := &CreateReservationCommandHandler{
+		reservationService: reservationService,
+		replyPublisher:     replyPublisher,
+		commandSubscriber:  commandSubscriber,
+		logger:             logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler CreateReservationCommandHandler) handle(command *events.CreateReservationCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.CreateReservationReply{Payload: details}
+	switch command.Type {
+	case events.ConfirmReservation:
+		reply.Type = events.ReservationConfirmed
+		if err := handler.reservationService.ConfirmReservation(context.Background(), details); err != nil {
+			if err.Status >= 500 {
+				// Likely transient, let the command be redelivered.
+				return err
+			}
+			handler.logger.LogError("create-reservation-handler", fmt.Sprintf("Unable to confirm reservation %s: %s", details.ReservationID, err.Message))
+			reply.Type = events.ReservationNotConfirmed
+		}
+	case events.CancelReservation:
+		if err := handler.reservationService.CancelReservation(context.Background(), details); err != nil {
+			return err
+		}
+		return nil
+	case events.ReleaseHold:
+		if err := handler.reservationService.ReleaseHold(context.Background(), details); err != nil {
+			return err
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
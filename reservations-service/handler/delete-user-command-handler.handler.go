@@ -0,0 +1,71 @@
+package handler
+
+import (
+	"context"
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+	"reservation-service/config"
+	"reservation-service/service"
+)
+
+type DeleteUserCommandHandler struct {
+	reservationService *service.ReservationService
+	replyPublisher     saga.Publisher
+	commandSubscriber  saga.Subscriber
+	logger             *config.Logger
+}
+
+func NewDeleteUserCommandHandler(reservationService *service.ReservationService, replyPublisher saga.Publisher, commandSubscriber saga.Subscriber, logger *config.Logger) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		reservationService: reservationService,
+		replyPublisher:     replyPublisher,
+		commandSubscriber:  commandSubscriber,
+		logger:             logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.DeleteUserReply{Type: events.UnknownReply, Payload: details}
+	switch command.Type {
+	case events.CheckUserReservations:
+		allowed, err := handler.reservationService.UserDeleteAllowed(context.Background(), details.UserID, details.Role)
+		if err != nil {
+			// Retried until the database answers, a failed check says nothing about the user.
+			return err
+		}
+		reply.Type = events.UserReservationsChecked
+		if !allowed {
+			handler.logger.LogInfo("delete-user-handler", fmt.Sprintf("User %s has open reservations", details.UserID))
+			reply.Type = events.UserHasActiveReservations
+		}
+	case events.ArchiveUserAvailability:
+		reply.Type = events.UserAvailabilityArchived
+		if details.Role == "Host" {
+			err := handler.reservationService.ArchiveAvailability(context.Background(), details.UserID, details.AccommodationIDs)
+			if err != nil {
+				handler.logger.LogError("delete-user-handler", fmt.Sprintf("Unable to archive availability of host %s", details.UserID))
+				// A failed step is not compensated by the saga, so undo the part that was archived.
+				if err := handler.reservationService.RestoreAvailability(context.Background(), details.UserID); err != nil {
+					return err
+				}
+				reply.Type = events.UserAvailabilityNotArchived
+			}
+		}
+	case events.RestoreUserAvailability:
+		if err := handler.reservationService.RestoreAvailability(context.Background(), details.UserID); err != nil {
+			handler.logger.LogError("delete-user-handler", fmt.Sprintf("Unable to restore availability of host %s", details.UserID))
+			return err
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
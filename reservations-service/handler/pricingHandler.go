@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reservation-service/domain"
+	"reservation-service/utils"
+
+	"github.com/gorilla/mux"
+)
+
+func (rh *ReservationHandler) Quote(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.Quote")
+	defer span.End()
+	var request domain.QuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/quote", rw)
+		return
+	}
+	quote, err := rh.ReservationService.Quote(ctx, request)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/quote", rw)
+		return
+	}
+	utils.WriteResp(quote, 200, rw)
+}
+
+func (rh *ReservationHandler) GetPricing(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetPricing")
+	defer span.End()
+	vars := mux.Vars(r)
+	pricing, err := rh.ReservationService.GetPricing(ctx, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/pricing/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(pricing, 200, rw)
+}
+
+func (rh *ReservationHandler) SetPricing(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.SetPricing")
+	defer span.End()
+	vars := mux.Vars(r)
+	var pricing domain.Pricing
+	if err := json.NewDecoder(r.Body).Decode(&pricing); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/pricing/{accommodationId}", rw)
+		return
+	}
+	pricing.AccommodationID = vars["accommodationId"]
+	hostID := r.Context().Value("userID").(string)
+	updated, err := rh.ReservationService.SetPricing(ctx, hostID, pricing)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/pricing/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(updated, 200, rw)
+}
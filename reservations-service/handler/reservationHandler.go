@@ -40,7 +40,8 @@ func (r *ReservationHandler) CreateReservation(rw http.ResponseWriter, h *http.R
 		utils.WriteErrorResp(err.Message, err.Status, "api/reservations", rw)
 		return
 	}
-	utils.WriteResp(newRes, 201, rw)
+	// The dates are held, the reservation is confirmed by the saga.
+	utils.WriteResp(newRes, 202, rw)
 }
 func (r *ReservationHandler) CreateAvailability(rw http.ResponseWriter, h *http.Request) {
 	ctx, span := r.Tracer.Start(h.Context(), "ReservationHandler.CreateAvailability")
@@ -162,32 +163,81 @@ func (rh ReservationHandler) ReservationsInDateRangeHandler(w http.ResponseWrite
 	utils.WriteResp(reservations, 201, w)
 }
 
-func (rh *ReservationHandler) DeleteReservationById(rw http.ResponseWriter, r *http.Request) {
-	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.DeleteReservationById")
+// CancelReservation cancels a reservation of the logged in guest and responds with
+// the reservation, including the refund it was given.
+func (rh *ReservationHandler) CancelReservation(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.CancelReservation")
 	defer span.End()
 	vars := mux.Vars(r)
-	id := vars["id"]
-	country := vars["country"]
-	userID := vars["userID"]
-	hostID := vars["hostID"]
-	accommodationID := vars["accommodationID"]
-	endDate := vars["endDate"]
+	userID := r.Context().Value("userID").(string)
 
-	deletedReservation, err := rh.ReservationService.DeleteReservationById(ctx, country, id, userID, hostID, accommodationID, endDate)
+	canceledReservation, err := rh.ReservationService.CancelReservationById(ctx, vars["id"], userID)
 	if err != nil {
-		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/{country}/{id}/{userID}/{hostID}/{accommodationID}/{endDate}", rw)
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/{id}/cancel", rw)
 		return
 	}
+	utils.WriteResp(canceledReservation, 200, rw)
+}
 
-	rw.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(rw).Encode(deletedReservation)
+// ModifyReservation changes the dates or guest count of a reservation of the logged
+// in guest.
+func (rh *ReservationHandler) ModifyReservation(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.ModifyReservation")
+	defer span.End()
+	vars := mux.Vars(r)
+	userID := r.Context().Value("userID").(string)
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	var change domain.ReservationChange
+	if err := decoder.Decode(&change); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/{id}", rw)
+		return
+	}
+	modifiedReservation, err := rh.ReservationService.ModifyReservation(ctx, userID, vars["id"], change)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/{id}", rw)
+		return
+	}
+	utils.WriteResp(modifiedReservation, 200, rw)
+}
+
+func (rh *ReservationHandler) GetCancellationPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetCancellationPolicy")
+	defer span.End()
+	vars := mux.Vars(r)
+	policy, err := rh.ReservationService.GetCancellationPolicy(ctx, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/cancellation-policy/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(policy, 200, rw)
+}
+
+// SetCancellationPolicy stores the policy the accommodations service sends, with the
+// token of the host, when the host updates the accommodation.
+func (rh *ReservationHandler) SetCancellationPolicy(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.SetCancellationPolicy")
+	defer span.End()
+	vars := mux.Vars(r)
+	var policy domain.CancellationPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/cancellation-policy/{accommodationId}", rw)
+		return
+	}
+	policy.AccommodationID = vars["accommodationId"]
+	hostID := r.Context().Value("userID").(string)
+	if err := rh.ReservationService.SetHostCancellationPolicy(ctx, hostID, &policy); err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/cancellation-policy/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(policy, 200, rw)
 }
 
 func (rh *ReservationHandler) GetCancelationPercentage(rw http.ResponseWriter, r *http.Request) {
 	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetCancelationPercentage")
 	defer span.End()
 	vars := mux.Vars(r)
-	hostID := vars["hostID"]
+	hostID := vars["hostId"]
 	percentage, err := rh.ReservationService.CalculatePercentageCanceled(ctx, hostID)
 	if err != nil {
 		utils.WriteErrorResp(err.Message, err.Status, "/api/reservations/percentage-cancelation/{hostID}", rw)
@@ -236,24 +286,17 @@ func (rh *ReservationHandler) UpdateAvailability(w http.ResponseWriter, r *http.
 	accommodationID := vars["accommodationId"]
 	id := vars["id"]
 	country := vars["country"]
-	priceStr := vars["price"]
-
-	price, err := strconv.Atoi(priceStr)
-	if err != nil {
-		http.Error(w, "Invalid price parameter", http.StatusBadRequest)
-		return
-	}
 
 	var updatedReservation domain.FreeReservation
-	err = json.NewDecoder(r.Body).Decode(&updatedReservation)
+	err := json.NewDecoder(r.Body).Decode(&updatedReservation)
 	if err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
 
-	result, reservationErr := rh.ReservationService.UpdateAvailability(ctx, accommodationID, id, country, price, &updatedReservation)
+	result, reservationErr := rh.ReservationService.UpdateAvailability(ctx, accommodationID, id, country, &updatedReservation)
 	if reservationErr != nil {
-		http.Error(w, reservationErr.Message, 500)
+		http.Error(w, reservationErr.Message, reservationErr.Status)
 		return
 	}
 
@@ -271,10 +314,19 @@ func (rh *ReservationHandler) GetAccommodationIDsByMaxPrice(rw http.ResponseWrit
 		utils.WriteErrorResp(err.Error(), 500, "api/reservations/price/myprice/janko/mateja/aca/{maxPrice}", rw)
 		return
 	}
+	// The guest enters whole units, in their own currency when they picked one.
+	currency := r.URL.Query().Get("currency")
+	if currency == "" {
+		currency = domain.DefaultCurrency
+	}
+	if err := domain.ValidateCurrency(currency); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/price/myprice/janko/mateja/aca/{maxPrice}", rw)
+		return
+	}
 	log.Println(maxPrice)
-	accommodations, erro := rh.ReservationService.GetAccommodationIDsByMaxPrice(ctx, maxPrice)
+	accommodations, erro := rh.ReservationService.GetAccommodationIDsByMaxPrice(ctx, domain.WholeUnits(int64(maxPrice), currency))
 	if erro != nil {
-		utils.WriteErrorResp(err.Error(), 500, "api/reservations/price/myprice/janko/mateja/aca/{maxPrice}", rw)
+		utils.WriteErrorResp(erro.Message, erro.Status, "api/reservations/price/myprice/janko/mateja/aca/{maxPrice}", rw)
 		return
 	}
 
@@ -0,0 +1,81 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reservation-service/utils"
+
+	"github.com/gorilla/mux"
+)
+
+type bookingModeRequest struct {
+	RequestToBook bool `json:"requestToBook"`
+}
+
+func (rh *ReservationHandler) GetBookingMode(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetBookingMode")
+	defer span.End()
+	vars := mux.Vars(r)
+	mode, err := rh.ReservationService.GetBookingMode(ctx, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/booking-mode/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(mode, 200, rw)
+}
+
+func (rh *ReservationHandler) SetBookingMode(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.SetBookingMode")
+	defer span.End()
+	vars := mux.Vars(r)
+	var body bookingModeRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/booking-mode/{accommodationId}", rw)
+		return
+	}
+	hostID := r.Context().Value("userID").(string)
+	mode, err := rh.ReservationService.SetBookingMode(ctx, hostID, vars["accommodationId"], body.RequestToBook)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/booking-mode/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(mode, 200, rw)
+}
+
+func (rh *ReservationHandler) GetPendingRequests(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetPendingRequests")
+	defer span.End()
+	hostID := r.Context().Value("userID").(string)
+	requests, err := rh.ReservationService.GetPendingRequests(ctx, hostID)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/requests", rw)
+		return
+	}
+	utils.WriteResp(requests, 200, rw)
+}
+
+func (rh *ReservationHandler) AcceptRequest(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.AcceptRequest")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	reservation, err := rh.ReservationService.AcceptRequest(ctx, hostID, vars["id"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/requests/{id}/accept", rw)
+		return
+	}
+	utils.WriteResp(reservation, 200, rw)
+}
+
+func (rh *ReservationHandler) DeclineRequest(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.DeclineRequest")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	reservation, err := rh.ReservationService.DeclineRequest(ctx, hostID, vars["id"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/requests/{id}/decline", rw)
+		return
+	}
+	utils.WriteResp(reservation, 200, rw)
+}
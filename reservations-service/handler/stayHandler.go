@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reservation-service/domain"
+	"reservation-service/utils"
+
+	"github.com/gorilla/mux"
+)
+
+func (rh *ReservationHandler) GetArrivalInstructions(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetArrivalInstructions")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	instructions, err := rh.ReservationService.GetArrivalInstructions(ctx, hostID, vars["accommodationId"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/arrival/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(instructions, 200, rw)
+}
+
+func (rh *ReservationHandler) SetArrivalInstructions(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.SetArrivalInstructions")
+	defer span.End()
+	vars := mux.Vars(r)
+	var instructions domain.ArrivalInstructions
+	if err := json.NewDecoder(r.Body).Decode(&instructions); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/arrival/{accommodationId}", rw)
+		return
+	}
+	instructions.AccommodationID = vars["accommodationId"]
+	hostID := r.Context().Value("userID").(string)
+	if err := rh.ReservationService.SetArrivalInstructions(ctx, hostID, &instructions); err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/arrival/{accommodationId}", rw)
+		return
+	}
+	utils.WriteResp(instructions, 200, rw)
+}
+
+func (rh *ReservationHandler) DeleteArrivalInstructions(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.DeleteArrivalInstructions")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	if err := rh.ReservationService.DeleteArrivalInstructions(ctx, hostID, vars["accommodationId"]); err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/arrival/{accommodationId}", rw)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
+
+func (rh *ReservationHandler) CheckIn(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.CheckIn")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	reservation, err := rh.ReservationService.CheckIn(ctx, hostID, vars["id"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/{id}/check-in", rw)
+		return
+	}
+	utils.WriteResp(reservation, 200, rw)
+}
+
+func (rh *ReservationHandler) CheckOut(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.CheckOut")
+	defer span.End()
+	vars := mux.Vars(r)
+	hostID := r.Context().Value("userID").(string)
+	reservation, err := rh.ReservationService.CheckOut(ctx, hostID, vars["id"])
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/{id}/check-out", rw)
+		return
+	}
+	utils.WriteResp(reservation, 200, rw)
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reservation-service/domain"
+	"reservation-service/utils"
+
+	"github.com/gorilla/mux"
+)
+
+func (rh *ReservationHandler) JoinWaitlist(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.JoinWaitlist")
+	defer span.End()
+	var entry domain.WaitlistEntry
+	if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+		utils.WriteErrorResp(err.Error(), 400, "api/reservations/waitlist", rw)
+		return
+	}
+	entry.UserID = r.Context().Value("userID").(string)
+	joined, err := rh.ReservationService.JoinWaitlist(ctx, entry)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/waitlist", rw)
+		return
+	}
+	utils.WriteResp(joined, 201, rw)
+}
+
+func (rh *ReservationHandler) GetWaitlist(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.GetWaitlist")
+	defer span.End()
+	userID := r.Context().Value("userID").(string)
+	entries, err := rh.ReservationService.GetWaitlist(ctx, userID)
+	if err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/waitlist", rw)
+		return
+	}
+	utils.WriteResp(entries, 200, rw)
+}
+
+func (rh *ReservationHandler) LeaveWaitlist(rw http.ResponseWriter, r *http.Request) {
+	ctx, span := rh.Tracer.Start(r.Context(), "ReservationHandler.LeaveWaitlist")
+	defer span.End()
+	vars := mux.Vars(r)
+	userID := r.Context().Value("userID").(string)
+	if err := rh.ReservationService.LeaveWaitlist(ctx, userID, vars["accommodationId"], vars["id"]); err != nil {
+		utils.WriteErrorResp(err.Message, err.Status, "api/reservations/waitlist/{accommodationId}/{id}", rw)
+		return
+	}
+	rw.WriteHeader(http.StatusNoContent)
+}
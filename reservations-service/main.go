@@ -6,10 +6,13 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"reservation-service/calendar"
 	"reservation-service/client"
 	"reservation-service/config"
+	"reservation-service/exchange"
 	"reservation-service/handler"
 	"reservation-service/middlewares"
+	"reservation-service/orchestrator"
 	"reservation-service/repository"
 	"reservation-service/service"
 	"reservation-service/utils"
@@ -31,6 +34,9 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(migrate(os.Args[2:]))
+	}
 	port := os.Getenv("PORT")
 	if len(port) == 0 {
 		port = "8080"
@@ -48,6 +54,8 @@ func main() {
 	log.Println("HOST", metricsCommandHost)
 	metricsCommandPort := os.Getenv("COMMAND_SERVICE_PORT")
 	log.Println("PORT", metricsCommandPort)
+	accommodationServiceHost := os.Getenv("ACCOMMODATION_SERVICE_HOST")
+	accommodationServicePort := os.Getenv("ACCOMMODATION_SERVICE_PORT")
 	customNotificationServiceClient := &http.Client{
 		Transport: &http.Transport{
 			MaxIdleConns:        10,
@@ -88,9 +96,22 @@ func main() {
 		},
 	)
 
+	accommodationsServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
+		gobreaker.Settings{
+			Name:        "accommodations-service",
+			MaxRequests: 1,
+			Timeout:     10 * time.Second,
+			Interval:    0,
+			OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
+				log.Printf("Circuit Breaker %v: %v -> %v", name, from, to)
+			},
+		},
+	)
+
 	validator := utils.NewValidator()
 	notificationsClient := client.NewNotificationClient(notificationServiceHost, notificationServicePort, customNotificationServiceClient, notificationServiceCircuitBreaker)
 	metricsClient := client.NewMetricsClient(metricsCommandHost, metricsCommandPort, customMetricsServiceClient, metricsServiceCircuitBreaker)
+	accommodationsClient := client.NewAccommodationsClient(accommodationServiceHost, accommodationServicePort, &http.Client{Timeout: 5 * time.Second}, accommodationsServiceCircuitBreaker)
 	tracerConfig := tracing.GetConfig()
 	tracerProvider, err := tracing.NewTracerProvider("reservations-service", tracerConfig.JaegerAddress)
 	if err != nil {
@@ -111,12 +132,18 @@ func main() {
 		})
 	}
 	defer store.CloseSession()
-	store.CreateTables()
+	migrator, err := store.Migrator()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 	reservationRepo, err := repository.New(logger, tracer)
 	if err != nil {
 		return
 	}
-	publisher, err := nats.NewNATSPublisher(
+	publisher, err := nats.NewJetStreamPublisher(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -126,7 +153,7 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-	commandSubscriber, err := nats.NewNATSSubscriber(
+	commandSubscriber, err := nats.NewJetStreamSubscriber(
 		os.Getenv("NATS_HOST"),
 		os.Getenv("NATS_PORT"),
 		os.Getenv("NATS_USER"),
@@ -137,11 +164,116 @@ func main() {
 		log.Fatal(err)
 	}
 
-	reservationService := service.NewReservationService(reservationRepo, validator, notificationsClient, logger, tracer, metricsClient)
+	reservationCommandPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_COMMAND_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reservationReplySubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_REPLY_SUBJECT"),
+		"reservations-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaStore := repository.NewSagaStore(reservationRepo, "create-reservation")
+	createReservationOrchestrator, err := orchestrator.NewCreateReservationOrchestrator(reservationCommandPublisher, reservationReplySubscriber, sagaStore, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	requestWindow, err := time.ParseDuration(os.Getenv("RESERVATION_REQUEST_WINDOW"))
+	if err != nil {
+		requestWindow = 24 * time.Hour
+	}
+	waitlistOfferWindow, err := time.ParseDuration(os.Getenv("WAITLIST_OFFER_WINDOW"))
+	if err != nil {
+		waitlistOfferWindow = 2 * time.Hour
+	}
+	ratesFile := os.Getenv("EXCHANGE_RATES_FILE")
+	if ratesFile == "" {
+		ratesFile = "exchange/rates.json"
+	}
+	rates, err := exchange.NewStaticProvider(ratesFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	calendarImportInterval, err := time.ParseDuration(os.Getenv("CALENDAR_IMPORT_INTERVAL"))
+	if err != nil {
+		calendarImportInterval = time.Hour
+	}
+	calendars := calendar.NewSources(os.Getenv("CALENDAR_IMPORT_DIR"), 10*time.Second)
+	reservationService := service.NewReservationService(reservationRepo, validator, notificationsClient, logger, tracer, metricsClient, createReservationOrchestrator, requestWindow, waitlistOfferWindow, rates, calendars, accommodationsClient)
 	_, err = handler.NewCreateAvailabilityCommandHandler(reservationService, publisher, commandSubscriber, logger)
 	if err != nil {
 		log.Fatal(err)
 	}
+	deleteUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	deleteUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"reservations-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewDeleteUserCommandHandler(reservationService, deleteUserReplyPublisher, deleteUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reservationReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	reservationCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("CREATE_RESERVATION_COMMAND_SUBJECT"),
+		"reservations-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewCreateReservationCommandHandler(reservationService, reservationReplyPublisher, reservationCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaContext, stopSagas := context.WithCancel(context.Background())
+	defer stopSagas()
+	err = createReservationOrchestrator.Resume(sagaContext)
+	if err != nil {
+		log.Println(err)
+	}
+	go reservationService.WatchRequests(sagaContext, time.Minute)
+	go reservationService.WatchCalendars(sagaContext, calendarImportInterval)
+	go reservationService.WatchWaitlist(sagaContext, time.Minute)
+	go reservationService.WatchStays(sagaContext, time.Minute)
 	reservationsHandler := handler.ReservationHandler{
 		ReservationService: reservationService,
 		Tracer:             tracer,
@@ -156,17 +288,44 @@ func main() {
 	router := mux.NewRouter()
 	router.HandleFunc("/user/guest/{userId}", reservationsHandler.GetReservationsByUser).Methods("GET")
 	router.HandleFunc("/", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.CreateReservation))).Methods("POST")
+	router.HandleFunc("/booking-mode/{accommodationId}", reservationsHandler.GetBookingMode).Methods("GET")
+	router.HandleFunc("/booking-mode/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.SetBookingMode))).Methods("PUT")
+	router.HandleFunc("/cancellation-policy/{accommodationId}", reservationsHandler.GetCancellationPolicy).Methods("GET")
+	router.HandleFunc("/cancellation-policy/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.SetCancellationPolicy))).Methods("PUT")
+	router.HandleFunc("/pricing/{accommodationId}", reservationsHandler.GetPricing).Methods("GET")
+	router.HandleFunc("/pricing/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.SetPricing))).Methods("PUT")
+	router.HandleFunc("/calendar/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.GetCalendarFeed))).Methods("GET")
+	router.HandleFunc("/calendar/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.SetCalendarImport))).Methods("PUT")
+	router.HandleFunc("/calendar/{accommodationId}/token", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.RotateCalendarToken))).Methods("POST")
+	router.HandleFunc("/calendar/{accommodationId}/import", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.ImportCalendar))).Methods("POST")
+	router.HandleFunc("/calendar/{accommodationId}/feed.ics", reservationsHandler.ExportCalendar).Methods("GET")
+	router.HandleFunc("/calendar/{accommodationId}/nights", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.GetHostCalendar))).Methods("GET")
+	router.HandleFunc("/calendar/{accommodationId}/changes", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.ChangeCalendar))).Methods("POST")
+	router.HandleFunc("/calendar/{accommodationId}/changes", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.GetCalendarChanges))).Methods("GET")
+	router.HandleFunc("/arrival/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.GetArrivalInstructions))).Methods("GET")
+	router.HandleFunc("/arrival/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.SetArrivalInstructions))).Methods("PUT")
+	router.HandleFunc("/arrival/{accommodationId}", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.DeleteArrivalInstructions))).Methods("DELETE")
+	router.HandleFunc("/quote", reservationsHandler.Quote).Methods("POST")
+	router.HandleFunc("/waitlist", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.JoinWaitlist))).Methods("POST")
+	router.HandleFunc("/waitlist", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.GetWaitlist))).Methods("GET")
+	router.HandleFunc("/waitlist/{accommodationId}/{id}", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.LeaveWaitlist))).Methods("DELETE")
+	router.HandleFunc("/requests", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.GetPendingRequests))).Methods("GET")
+	router.HandleFunc("/requests/{id}/accept", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.AcceptRequest))).Methods("PUT")
+	router.HandleFunc("/requests/{id}/decline", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.DeclineRequest))).Methods("PUT")
 	router.HandleFunc("/accommodations", reservationsHandler.ReservationsInDateRangeHandler).Methods("GET")
 	router.HandleFunc("/availability", reservationsHandler.CreateAvailability).Methods("POST")
 	router.HandleFunc("/user/host/{hostId}", reservationsHandler.GetReservationsByHost).Methods("GET")
 	//router.HandleFunc("/accommodations/{accommodationID}", reservationsHandler.GetReservationsByAccommodation).Methods("GET")
 	router.HandleFunc("/accommodation/dates", reservationsHandler.GetAvailableDates).Methods("GET")
-	router.HandleFunc("/{country}/{id}/{userID}/{hostID}/{accommodationID}/{endDate}", reservationsHandler.DeleteReservationById).Methods("PUT")
+	router.HandleFunc("/{id}", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.ModifyReservation))).Methods("PUT")
+	router.HandleFunc("/{id}/cancel", middlewares.ValidateJWT(middlewares.RoleValidator("Guest", reservationsHandler.CancelReservation))).Methods("PUT")
+	router.HandleFunc("/{id}/check-in", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.CheckIn))).Methods("PUT")
+	router.HandleFunc("/{id}/check-out", middlewares.ValidateJWT(middlewares.RoleValidator("Host", reservationsHandler.CheckOut))).Methods("PUT")
 	router.HandleFunc("/{accommodationId}/availability", reservationsHandler.GetAvailabilityForAccommodation).Methods("GET")
 	router.HandleFunc("/percentage-cancelation/{hostId}", reservationsHandler.GetCancelationPercentage).Methods("GET")
 	router.HandleFunc("/{accommodationId}/{userId}", reservationsHandler.GetReservationsByAccommodationWithEndDate).Methods("GET")
 	router.HandleFunc("/host/{hostId}/{userId}", reservationsHandler.GetReservationsByHostWithEndDate).Methods("GET")
-	router.HandleFunc("/{accommodationId}/{id}/{country}/{price}", reservationsHandler.UpdateAvailability).Methods("POST")
+	router.HandleFunc("/{accommodationId}/{id}/{country}", reservationsHandler.UpdateAvailability).Methods("POST")
 	router.HandleFunc("/price/myPrice/{maxPrice}", reservationsHandler.GetAccommodationIDsByMaxPrice).Methods("GET")
 
 	headersOk := gorillaHandlers.AllowedHeaders([]string{"X-Requested-With", "Content-Type", "Authorization"})
@@ -206,6 +365,7 @@ func main() {
 			"error":  err.Error(),
 		})
 	}
+	nats.Drain()
 	logger.LogInfo("server-main", "Server shut down")
 
 }
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reservation-service/config"
+	"reservation-service/repository"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const migrateUsage = `usage: main migrate [up|status|verify]
+  up      apply the migrations not applied yet (default)
+  status  list the migrations and when they were applied
+  verify  fail unless every migration is applied unchanged`
+
+// migrate runs the migrate command of the binary and returns its exit code. The
+// service applies pending migrations on start as well; the command lets them be
+// applied, listed or checked without starting it.
+func migrate(args []string) int {
+	command := "up"
+	if len(args) > 0 {
+		command = args[0]
+	}
+	if len(args) > 1 || (command != "up" && command != "status" && command != "verify") {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	logger := config.NewLogger("./logs/migrations.log")
+	store, err := repository.New(logger, trace.NewNoopTracerProvider().Tracer("migrations"))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to connect to cassandra: %v\n", err)
+		return 1
+	}
+	defer store.CloseSession()
+	migrator, err := store.Migrator()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to load migrations: %v\n", err)
+		return 1
+	}
+
+	switch command {
+	case "status":
+		statuses, err := migrator.Status()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to read applied migrations: %v\n", err)
+			return 1
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.Applied != nil {
+				applied = "applied " + status.Applied.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d  %-30s  %s\n", status.Migration.Version, status.Migration.Name, applied)
+		}
+	case "verify":
+		if err := migrator.Verify(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		fmt.Println("schema is up to date")
+	default:
+		applied, err := migrator.Up(context.Background())
+		for _, migration := range applied {
+			fmt.Printf("applied %s\n", migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("nothing to apply")
+		}
+	}
+	return 0
+}
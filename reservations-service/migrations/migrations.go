@@ -0,0 +1,248 @@
+// Package migrations versions the Cassandra schema of the reservations service.
+// Migrations are CQL files named NNNN_name.cql in this directory, embedded into the
+// binary, or Go functions for changes CQL can not express, such as converting
+// data. Each one is applied once, in version order, and recorded in
+// schema_migrations with a checksum of its statements.
+package migrations
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"reservation-service/config"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+//go:embed *.cql
+var files embed.FS
+
+type Migration struct {
+	Version    int
+	Name       string
+	Statements []string
+	// Apply runs a migration written in Go. Migrations from CQL files have none.
+	Apply func(ctx context.Context) error
+}
+
+// Checksum identifies the statements of a migration, so a file edited after it was
+// applied is found by Verify. Go migrations are identified by their name.
+func (m Migration) Checksum() string {
+	sum := sha256.New()
+	sum.Write([]byte(m.Name))
+	for _, statement := range m.Statements {
+		sum.Write([]byte{0})
+		sum.Write([]byte(statement))
+	}
+	return hex.EncodeToString(sum.Sum(nil))
+}
+
+// Applied is a migration as schema_migrations records it.
+type Applied struct {
+	Version   int
+	Name      string
+	Checksum  string
+	AppliedAt time.Time
+}
+
+// Status is a known migration together with its record, if it was applied.
+type Status struct {
+	Migration Migration
+	Applied   *Applied
+}
+
+type Migrator struct {
+	session    *gocql.Session
+	logger     *config.Logger
+	migrations []Migration
+}
+
+// NewMigrator loads the CQL migrations and adds the Go ones to them. Versions must
+// be unique.
+func NewMigrator(session *gocql.Session, logger *config.Logger, goMigrations ...Migration) (*Migrator, error) {
+	migrations, err := load()
+	if err != nil {
+		return nil, err
+	}
+	migrations = append(migrations, goMigrations...)
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migrations[i-1].Name, migrations[i].Name, migrations[i].Version)
+		}
+	}
+	return &Migrator{session: session, logger: logger, migrations: migrations}, nil
+}
+
+func load() ([]Migration, error) {
+	names, err := files.ReadDir(".")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, entry := range names {
+		name := entry.Name()
+		version, err := strconv.Atoi(strings.SplitN(name, "_", 2)[0])
+		if err != nil || !strings.Contains(name, "_") {
+			return nil, fmt.Errorf("migration file %s is not named NNNN_name.cql", name)
+		}
+		content, err := files.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, Migration{
+			Version:    version,
+			Name:       strings.TrimSuffix(name, path.Ext(name)),
+			Statements: statements(string(content)),
+		})
+	}
+	return migrations, nil
+}
+
+// statements splits a CQL file into its statements. Lines starting with -- are
+// comments and every statement ends with a semicolon at the end of a line.
+func statements(content string) []string {
+	var result []string
+	var current []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current = append(current, strings.TrimRight(line, " \t\r"))
+		if strings.HasSuffix(trimmed, ";") {
+			statement := strings.TrimSuffix(strings.TrimSpace(strings.Join(current, "\n")), ";")
+			result = append(result, statement)
+			current = nil
+		}
+	}
+	if len(current) > 0 {
+		result = append(result, strings.TrimSpace(strings.Join(current, "\n")))
+	}
+	return result
+}
+
+func (m *Migrator) createTable() error {
+	return m.session.Query(`CREATE TABLE IF NOT EXISTS schema_migrations
+		(version int, name text, checksum text, applied_at timestamp, PRIMARY KEY(version))`).Exec()
+}
+
+func (m *Migrator) applied() (map[int]Applied, error) {
+	if err := m.createTable(); err != nil {
+		return nil, err
+	}
+	iter := m.session.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`).Iter()
+	applied := make(map[int]Applied)
+	var record Applied
+	for iter.Scan(&record.Version, &record.Name, &record.Checksum, &record.AppliedAt) {
+		applied[record.Version] = record
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// Status lists every known migration with its record, oldest first.
+func (m *Migrator) Status() ([]Status, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := Status{Migration: migration}
+		if record, exists := applied[migration.Version]; exists {
+			status.Applied = &record
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Up applies the migrations that were not applied yet and returns them. It stops
+// at the first failing migration, which stays unrecorded, so it is tried again by
+// the next run; its statements must be safe to repeat.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, exists := applied[migration.Version]; exists {
+			continue
+		}
+		if err := m.apply(ctx, migration); err != nil {
+			return done, fmt.Errorf("migration %s: %v", migration.Name, err)
+		}
+		record := map[string]interface{}{}
+		inserted, err := m.session.Query(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES(?, ?, ?, ?) IF NOT EXISTS`,
+			migration.Version, migration.Name, migration.Checksum(), time.Now()).MapScanCAS(record)
+		if err != nil {
+			return done, fmt.Errorf("migration %s: unable to record it: %v", migration.Name, err)
+		}
+		if inserted {
+			m.logger.LogInfo("migrations", fmt.Sprintf("Applied migration %s", migration.Name))
+		} else {
+			m.logger.LogInfo("migrations", fmt.Sprintf("Migration %s was applied concurrently", migration.Name))
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+func (m *Migrator) apply(ctx context.Context, migration Migration) error {
+	if migration.Apply != nil {
+		return migration.Apply(ctx)
+	}
+	for _, statement := range migration.Statements {
+		if err := m.session.Query(statement).Exec(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verify reports every difference between the known migrations and the applied
+// ones: migrations not applied yet, migrations changed since they were applied and
+// applied migrations this binary does not know.
+func (m *Migrator) Verify() error {
+	applied, err := m.applied()
+	if err != nil {
+		return err
+	}
+	return verify(m.migrations, applied)
+}
+
+func verify(migrations []Migration, applied map[int]Applied) error {
+	var problems []string
+	known := make(map[int]bool, len(migrations))
+	for _, migration := range migrations {
+		known[migration.Version] = true
+		record, exists := applied[migration.Version]
+		switch {
+		case !exists:
+			problems = append(problems, fmt.Sprintf("%s is not applied", migration.Name))
+		case record.Checksum != migration.Checksum():
+			problems = append(problems, fmt.Sprintf("%s changed after it was applied", migration.Name))
+		}
+	}
+	for version, record := range applied {
+		if !known[version] {
+			problems = append(problems, fmt.Sprintf("%s is applied but unknown", record.Name))
+		}
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("schema does not match the migrations: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
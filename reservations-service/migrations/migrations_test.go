@@ -0,0 +1,169 @@
+package migrations
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestStatements(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    []string
+	}{
+		{name: "empty"},
+		{name: "only comments", content: "-- nothing yet\n\n  -- indented\n"},
+		{
+			name:    "one per line",
+			content: "CREATE TABLE a (id int PRIMARY KEY);\nCREATE TABLE b (id int PRIMARY KEY);\n",
+			want:    []string{"CREATE TABLE a (id int PRIMARY KEY)", "CREATE TABLE b (id int PRIMARY KEY)"},
+		},
+		{
+			name:    "spanning lines",
+			content: "-- A table.\n\nCREATE TABLE a\n\t(id int,\n\t PRIMARY KEY(id));  \r\n",
+			want:    []string{"CREATE TABLE a\n\t(id int,\n\t PRIMARY KEY(id))"},
+		},
+		{
+			name:    "comments between lines of a statement",
+			content: "CREATE TABLE a\n-- the key\n\t(id int PRIMARY KEY);\n",
+			want:    []string{"CREATE TABLE a\n\t(id int PRIMARY KEY)"},
+		},
+		{
+			name:    "semicolon inside a line",
+			content: "INSERT INTO a (id, note) VALUES(1, 'a;b');\n",
+			want:    []string{"INSERT INTO a (id, note) VALUES(1, 'a;b')"},
+		},
+		{
+			name:    "last statement without semicolon",
+			content: "CREATE TABLE a (id int PRIMARY KEY);\nALTER TABLE a ADD note text\n",
+			want:    []string{"CREATE TABLE a (id int PRIMARY KEY)", "ALTER TABLE a ADD note text"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := statements(test.content); !reflect.DeepEqual(got, test.want) {
+				t.Fatalf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestNewMigratorOrdersFilesAndGoMigrations(t *testing.T) {
+	migrator, err := NewMigrator(nil, nil,
+		Migration{Version: 10, Name: "0010_request_deadlines", Apply: func(context.Context) error { return nil }},
+		Migration{Version: 2, Name: "0002_nightly_rows", Apply: func(context.Context) error { return nil }})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for i, migration := range migrator.migrations {
+		names = append(names, migration.Name)
+		if i > 0 && migration.Version <= migrator.migrations[i-1].Version {
+			t.Fatalf("%s comes after %s", migration.Name, migrator.migrations[i-1].Name)
+		}
+	}
+	want := []string{
+		"0001_initial_schema",
+		"0002_nightly_rows",
+		"0003_drop_data_migrations",
+		"0009_request_deadlines",
+		"0010_request_deadlines",
+		"0011_rolled_back_accommodations",
+	}
+	position := 0
+	for _, name := range names {
+		if position < len(want) && name == want[position] {
+			position++
+		}
+	}
+	if position != len(want) {
+		t.Fatalf("migrations are %v, want %v in this order", names, want)
+	}
+	for _, migration := range migrator.migrations {
+		isGo := migration.Version == 2 || migration.Version == 10
+		if isGo != (migration.Apply != nil) {
+			t.Fatalf("%s has Apply %v", migration.Name, migration.Apply != nil)
+		}
+		if !isGo && len(migration.Statements) == 0 {
+			t.Fatalf("%s has no statements", migration.Name)
+		}
+	}
+}
+
+func TestNewMigratorRefusesSharedVersions(t *testing.T) {
+	_, err := NewMigrator(nil, nil, Migration{Version: 1, Name: "0001_other", Apply: func(context.Context) error { return nil }})
+	if err == nil || !strings.Contains(err.Error(), "share version 1") {
+		t.Fatalf("got %v, want the shared version refused", err)
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	migration := Migration{Version: 1, Name: "0001_a", Statements: []string{"CREATE TABLE a (id int PRIMARY KEY)"}}
+	if migration.Checksum() != migration.Checksum() {
+		t.Fatal("checksum is not stable")
+	}
+	changes := map[string]Migration{
+		"statement edited":  {Version: 1, Name: "0001_a", Statements: []string{"CREATE TABLE a (id text PRIMARY KEY)"}},
+		"statement added":   {Version: 1, Name: "0001_a", Statements: []string{"CREATE TABLE a (id int PRIMARY KEY)", "ALTER TABLE a ADD note text"}},
+		"renamed":           {Version: 1, Name: "0001_b", Statements: migration.Statements},
+		"statements joined": {Version: 1, Name: "0001_a", Statements: []string{"CREATE TABLE a", "(id int PRIMARY KEY)"}},
+	}
+	for name, changed := range changes {
+		if changed.Checksum() == migration.Checksum() {
+			t.Fatalf("%s keeps the checksum", name)
+		}
+	}
+	goMigration := Migration{Version: 2, Name: "0002_nightly_rows", Apply: func(context.Context) error { return nil }}
+	sameName := Migration{Version: 2, Name: "0002_nightly_rows", Apply: func(context.Context) error { return context.Canceled }}
+	if goMigration.Checksum() != sameName.Checksum() {
+		t.Fatal("a Go migration is not identified by its name")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	first := Migration{Version: 1, Name: "0001_a", Statements: []string{"CREATE TABLE a (id int PRIMARY KEY)"}}
+	second := Migration{Version: 2, Name: "0002_b", Apply: func(context.Context) error { return nil }}
+	record := func(migration Migration) Applied {
+		return Applied{Version: migration.Version, Name: migration.Name, Checksum: migration.Checksum()}
+	}
+	tests := []struct {
+		name     string
+		applied  map[int]Applied
+		problems []string
+	}{
+		{name: "all applied", applied: map[int]Applied{1: record(first), 2: record(second)}},
+		{name: "not applied", applied: map[int]Applied{1: record(first)}, problems: []string{"0002_b is not applied"}},
+		{name: "nothing applied", applied: map[int]Applied{}, problems: []string{"0001_a is not applied", "0002_b is not applied"}},
+		{
+			name:     "changed after it was applied",
+			applied:  map[int]Applied{1: {Version: 1, Name: "0001_a", Checksum: "edited"}, 2: record(second)},
+			problems: []string{"0001_a changed after it was applied"},
+		},
+		{
+			name:     "unknown",
+			applied:  map[int]Applied{1: record(first), 2: record(second), 3: {Version: 3, Name: "0003_newer"}},
+			problems: []string{"0003_newer is applied but unknown"},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := verify([]Migration{first, second}, test.applied)
+			if len(test.problems) == 0 {
+				if err != nil {
+					t.Fatalf("got %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("no problems found")
+			}
+			for _, problem := range test.problems {
+				if !strings.Contains(err.Error(), problem) {
+					t.Fatalf("%q does not report %q", err, problem)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package orchestrator
+
+import (
+	"context"
+	events "example/saga/create_reservation"
+	"example/saga/engine"
+	saga "example/saga/messaging"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"reservation-service/config"
+	"time"
+)
+
+const (
+	createReservationSaga  = "create-reservation"
+	holdDatesStep          = "hold-dates"
+	confirmReservationStep = "confirm-reservation"
+	recordMetricsStep      = "record-metrics"
+	notifyHostStep         = "notify-host"
+	stepTimeout            = 30 * time.Second
+	timeoutCheckInterval   = 5 * time.Second
+	source                 = "create-reservation-orchestrator"
+)
+
+type CreateReservationOrchestrator struct {
+	sagas           *engine.Orchestrator
+	replySubscriber saga.Subscriber
+	logger          *config.Logger
+}
+
+func NewCreateReservationOrchestrator(publisher saga.Publisher, replySubscriber saga.Subscriber, store engine.Store, logger *config.Logger) (*CreateReservationOrchestrator, error) {
+	orchestrator := &CreateReservationOrchestrator{
+		sagas:           engine.NewOrchestrator(createReservationDefinition(), publisher, store),
+		replySubscriber: replySubscriber,
+		logger:          logger,
+	}
+	err := orchestrator.replySubscriber.Subscribe(orchestrator.handle)
+	if err != nil {
+		logger.LogError(source, "Unable to subscribe to create reservation replies")
+		return nil, err
+	}
+	return orchestrator, nil
+}
+
+// createReservationDefinition describes the saga: the dates are held locally before
+// the saga starts, then the reservation is confirmed, metrics-command records the
+// UserReserved event and the host is notified. A failed or timed out step retracts
+// the event, cancels the reservation and releases the hold.
+func createReservationDefinition() engine.Definition {
+	return engine.Definition{
+		Name: createReservationSaga,
+		Steps: []engine.Step{
+			{
+				Name:         holdDatesStep,
+				Compensation: commandOf(events.ReleaseHold),
+			},
+			{
+				Name:         confirmReservationStep,
+				Command:      commandOf(events.ConfirmReservation),
+				Compensation: commandOf(events.CancelReservation),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         recordMetricsStep,
+				Command:      commandOf(events.RecordUserReserved),
+				Compensation: commandOf(events.RetractUserReserved),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:    notifyHostStep,
+				Command: commandOf(events.NotifyHost),
+				Timeout: stepTimeout,
+			},
+		},
+	}
+}
+
+func commandOf(commandType events.CreateReservationCommandType) engine.CommandBuilder {
+	return func(instance *engine.Instance) (interface{}, error) {
+		var details events.CreateReservationDetails
+		if err := instance.Decode(&details); err != nil {
+			return nil, err
+		}
+		return &events.CreateReservationCommand{
+			Type:    commandType,
+			Payload: details,
+		}, nil
+	}
+}
+
+func (cro *CreateReservationOrchestrator) Start(ctx context.Context, details *events.CreateReservationDetails) error {
+	cro.logger.LogInfo(source, fmt.Sprintf("Starting create reservation saga for reservation %s", details.ReservationID))
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+	return cro.sagas.StartTraced(details.ReservationID, details, traceContext)
+}
+
+// Resume restarts sagas left in flight by a previous run and starts watching step timeouts.
+func (cro *CreateReservationOrchestrator) Resume(ctx context.Context) error {
+	err := cro.sagas.Resume()
+	if err != nil {
+		cro.logger.LogError(source, "Unable to resume sagas: "+err.Error())
+		return err
+	}
+	go cro.sagas.WatchTimeouts(ctx, timeoutCheckInterval)
+	return nil
+}
+
+func (cro *CreateReservationOrchestrator) handle(reply *events.CreateReservationReply) {
+	id := reply.Payload.ReservationID
+	var err error
+	switch reply.Type {
+	case events.ReservationConfirmed:
+		err = cro.sagas.StepSucceeded(id, confirmReservationStep, "ReservationConfirmed")
+	case events.ReservationNotConfirmed:
+		err = cro.sagas.StepFailed(id, confirmReservationStep, "ReservationNotConfirmed")
+	case events.UserReservedRecorded:
+		err = cro.sagas.StepSucceeded(id, recordMetricsStep, "UserReservedRecorded")
+	case events.UserReservedNotRecorded:
+		err = cro.sagas.StepFailed(id, recordMetricsStep, "UserReservedNotRecorded")
+	case events.HostNotified:
+		err = cro.sagas.StepSucceeded(id, notifyHostStep, "HostNotified")
+	case events.HostNotNotified:
+		err = cro.sagas.StepFailed(id, notifyHostStep, "HostNotNotified")
+	default:
+		return
+	}
+	if err != nil {
+		cro.logger.LogError(source, fmt.Sprintf("Unable to handle reply for reservation %s: %v", id, err))
+	}
+}
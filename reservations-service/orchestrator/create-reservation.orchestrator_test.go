@@ -0,0 +1,200 @@
+package orchestrator
+
+import (
+	"context"
+	events "example/saga/create_reservation"
+	"example/saga/engine"
+	"example/saga/sagatest"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"path/filepath"
+	"reflect"
+	"reservation-service/config"
+	"sort"
+	"testing"
+	"time"
+)
+
+// newTestOrchestrator runs the create reservation saga on the harness, with every
+// step timing out after timeout.
+func newTestOrchestrator(t *testing.T, harness *sagatest.Harness, timeout time.Duration) *CreateReservationOrchestrator {
+	t.Helper()
+	definition := createReservationDefinition()
+	for i := range definition.Steps {
+		if definition.Steps[i].Timeout > 0 {
+			definition.Steps[i].Timeout = timeout
+		}
+	}
+	orchestrator := &CreateReservationOrchestrator{
+		sagas:           engine.NewOrchestrator(definition, harness.CreateReservationCommandPublisher(), harness.Store),
+		replySubscriber: harness.CreateReservationReplySubscriber("reservations-saga-orchestrator"),
+		logger:          config.NewLogger(filepath.Join(t.TempDir(), "orchestrator.log")),
+	}
+	if err := orchestrator.replySubscriber.Subscribe(orchestrator.handle); err != nil {
+		t.Fatal(err)
+	}
+	return orchestrator
+}
+
+// watchTimeouts expires the steps of sagas until the returned func is called.
+func watchTimeouts(sagas *engine.Orchestrator) func() {
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		sagas.WatchTimeouts(ctx, time.Millisecond)
+		close(stopped)
+	}()
+	return func() {
+		cancel()
+		<-stopped
+	}
+}
+
+func newParticipants(t *testing.T, harness *sagatest.Harness) *sagatest.FakeReservationParticipants {
+	t.Helper()
+	participants, err := harness.FakeReservationParticipants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return participants
+}
+
+// assertSaga checks the state of the saga, the steps it compensated in order and
+// the commands the participants received. Compensations are sent without waiting
+// for replies, so participants may receive them in any order.
+func assertSaga(t *testing.T, harness *sagatest.Harness, participants *sagatest.FakeReservationParticipants, id string, state engine.State, compensated []string, commands ...events.CreateReservationCommandType) {
+	t.Helper()
+	instance, err := harness.Store.Get(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if instance.State != state {
+		t.Fatalf("saga is %s, want %s", instance.State, state)
+	}
+	if len(instance.Compensations) != 0 || len(compensated) != 0 {
+		if !reflect.DeepEqual(instance.Compensations, compensated) {
+			t.Fatalf("saga compensated %v, want %v", instance.Compensations, compensated)
+		}
+	}
+	received := participants.Received()
+	sort.Slice(received, func(i, j int) bool { return received[i] < received[j] })
+	sort.Slice(commands, func(i, j int) bool { return commands[i] < commands[j] })
+	if !reflect.DeepEqual(received, commands) {
+		t.Fatalf("participants received %v, want %v", received, commands)
+	}
+}
+
+func TestCreateReservationCompletes(t *testing.T) {
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	if err := orchestrator.Start(context.Background(), &events.CreateReservationDetails{ReservationID: "booked"}); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertSaga(t, harness, participants, "booked", engine.Completed, nil,
+		events.ConfirmReservation, events.RecordUserReserved, events.NotifyHost)
+}
+
+func TestCreateReservationCancelsWhenConfirmationFails(t *testing.T) {
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	participants.FailOn(events.ConfirmReservation)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	if err := orchestrator.Start(context.Background(), &events.CreateReservationDetails{ReservationID: "refused"}); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertSaga(t, harness, participants, "refused", engine.Compensated,
+		[]string{holdDatesStep},
+		events.ConfirmReservation, events.ReleaseHold)
+}
+
+func TestCreateReservationCancelsWhenMetricsFail(t *testing.T) {
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	participants.FailOn(events.RecordUserReserved)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	if err := orchestrator.Start(context.Background(), &events.CreateReservationDetails{ReservationID: "unrecorded"}); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertSaga(t, harness, participants, "unrecorded", engine.Compensated,
+		[]string{confirmReservationStep, holdDatesStep},
+		events.ConfirmReservation, events.RecordUserReserved, events.CancelReservation, events.ReleaseHold)
+}
+
+func TestCreateReservationRetractsMetricsWhenNotifyingFails(t *testing.T) {
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	participants.FailOn(events.NotifyHost)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+
+	if err := orchestrator.Start(context.Background(), &events.CreateReservationDetails{ReservationID: "unnotified"}); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	assertSaga(t, harness, participants, "unnotified", engine.Compensated,
+		[]string{recordMetricsStep, confirmReservationStep, holdDatesStep},
+		events.ConfirmReservation, events.RecordUserReserved, events.NotifyHost,
+		events.RetractUserReserved, events.CancelReservation, events.ReleaseHold)
+}
+
+func TestCreateReservationCancelsWhenConfirmationTimesOut(t *testing.T) {
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	participants.IgnoreOn(events.ConfirmReservation)
+	orchestrator := newTestOrchestrator(t, harness, 10*time.Millisecond)
+	defer watchTimeouts(orchestrator.sagas)()
+
+	if err := orchestrator.Start(context.Background(), &events.CreateReservationDetails{ReservationID: "silent"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := harness.WaitFor("silent", engine.Compensated, 5*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// The confirmation may still have been applied, so it is cancelled as well.
+	assertSaga(t, harness, participants, "silent", engine.Compensated,
+		[]string{confirmReservationStep, holdDatesStep},
+		events.ConfirmReservation, events.CancelReservation, events.ReleaseHold)
+}
+
+func TestCreateReservationCommandsCarryTheTraceOfTheRequest(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator())
+	harness := sagatest.NewHarness()
+	participants := newParticipants(t, harness)
+	participants.FailOn(events.NotifyHost)
+	orchestrator := newTestOrchestrator(t, harness, time.Minute)
+	request := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), request)
+
+	if err := orchestrator.Start(ctx, &events.CreateReservationDetails{ReservationID: "traced"}); err != nil {
+		t.Fatal(err)
+	}
+	harness.Settle()
+
+	traces := participants.TraceContexts()
+	if len(traces) != 6 {
+		t.Fatalf("participants received %d commands, want 6", len(traces))
+	}
+	for i, traceContext := range traces {
+		got := otel.GetTextMapPropagator().Extract(context.Background(), propagation.MapCarrier(traceContext))
+		if traceID := trace.SpanContextFromContext(got).TraceID(); traceID != request.TraceID() {
+			t.Fatalf("command %d is in trace %s, want %s", i, traceID, request.TraceID())
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// GetCalendarFeed returns the calendar feed of an accommodation, or one without a
+// host or token when it was never set up.
+func (rr *ReservationRepo) GetCalendarFeed(ctx context.Context, accommodationID string) (*domain.CalendarFeed, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetCalendarFeed")
+	defer span.End()
+	feed := domain.CalendarFeed{AccommodationID: accommodationID}
+	err := rr.session.Query(`SELECT host_id, token, import_url, imported_at, import_error FROM calendar_feeds WHERE accommodation_id = ?`, accommodationID).
+		Scan(&feed.HostID, &feed.Token, &feed.ImportURL, &feed.ImportedAt, &feed.ImportError)
+	if err != nil && err != gocql.ErrNotFound {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get calendar feed, database error")
+	}
+	return &feed, nil
+}
+
+// SaveCalendarFeed stores who owns the feed, its token and where it imports from.
+// The outcome of the last import is kept.
+func (rr *ReservationRepo) SaveCalendarFeed(ctx context.Context, feed *domain.CalendarFeed) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveCalendarFeed")
+	defer span.End()
+	err := rr.session.Query(`UPDATE calendar_feeds SET host_id = ?, token = ?, import_url = ? WHERE accommodation_id = ?`,
+		feed.HostID, feed.Token, feed.ImportURL, feed.AccommodationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save calendar feed, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Saved calendar feed of accommodation %v", feed.AccommodationID))
+	return nil
+}
+
+// SaveImportResult records when the calendar of an accommodation was last imported
+// and why that failed, empty when it did not.
+func (rr *ReservationRepo) SaveImportResult(ctx context.Context, accommodationID string, importedAt time.Time, importError string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveImportResult")
+	defer span.End()
+	err := rr.session.Query(`UPDATE calendar_feeds SET imported_at = ?, import_error = ? WHERE accommodation_id = ?`,
+		importedAt, importError, accommodationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save calendar import, database error")
+	}
+	return nil
+}
+
+// GetCalendarImports returns the feeds that import a calendar. Feeds are one per
+// accommodation at most, so they are scanned across all accommodations.
+func (rr *ReservationRepo) GetCalendarImports(ctx context.Context) ([]domain.CalendarFeed, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetCalendarImports")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT accommodation_id, host_id, token, import_url, imported_at, import_error FROM calendar_feeds`).Iter().Scanner()
+	var feeds []domain.CalendarFeed
+	for scanner.Next() {
+		var feed domain.CalendarFeed
+		if err := scanner.Scan(&feed.AccommodationID, &feed.HostID, &feed.Token, &feed.ImportURL, &feed.ImportedAt, &feed.ImportError); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get calendar feeds, database error")
+		}
+		if feed.ImportURL != "" {
+			feeds = append(feeds, feed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get calendar feeds, database error")
+	}
+	return feeds, nil
+}
+
+// CalendarBlocks returns the nights the imported calendar of an accommodation
+// blocks, in order.
+func (rr *ReservationRepo) CalendarBlocks(ctx context.Context, accommodationID string) ([]domain.CalendarBlock, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.CalendarBlocks")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT night, uid, summary FROM calendar_blocks WHERE accommodation_id = ?`, accommodationID).Iter().Scanner()
+	var blocks []domain.CalendarBlock
+	for scanner.Next() {
+		var block domain.CalendarBlock
+		if err := scanner.Scan(&block.Night, &block.UID, &block.Summary); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get calendar blocks, database error")
+		}
+		blocks = append(blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get calendar blocks, database error")
+	}
+	return blocks, nil
+}
+
+// ReplaceCalendarBlocks makes blocks the nights the imported calendar of an
+// accommodation blocks. Nights no longer blocked are deleted one by one rather
+// than with the partition, since a batch gives the deletion and the new rows the
+// same timestamp and the deletion would win.
+func (rr *ReservationRepo) ReplaceCalendarBlocks(ctx context.Context, accommodationID string, blocks []domain.CalendarBlock) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReplaceCalendarBlocks")
+	defer span.End()
+	previous, err := rr.CalendarBlocks(ctx, accommodationID)
+	if err != nil {
+		return err
+	}
+	kept := make(map[string]bool, len(blocks))
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	for _, block := range blocks {
+		kept[block.Night] = true
+		batch.Query(`INSERT INTO calendar_blocks (accommodation_id, night, uid, summary) VALUES(?, ?, ?, ?)`,
+			accommodationID, block.Night, block.UID, block.Summary)
+	}
+	for _, block := range previous {
+		if !kept[block.Night] {
+			batch.Query(`DELETE FROM calendar_blocks WHERE accommodation_id = ? AND night = ?`, accommodationID, block.Night)
+		}
+	}
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save calendar blocks, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Accommodation %v has %d nights blocked by its imported calendar", accommodationID, len(blocks)))
+	return nil
+}
+
+// BlockedInDateRange returns the accommodations the host or an imported calendar
+// blocks any of the nights of.
+func (rr *ReservationRepo) BlockedInDateRange(ctx context.Context, accommodationIDs []string, dateRange []string) ([]string, *errors.ReservationError) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.BlockedInDateRange")
+	defer span.End()
+	result := make([]string, 0)
+	if len(dateRange) == 0 {
+		return result, nil
+	}
+	for _, accommodationID := range accommodationIDs {
+		blocked, err := rr.hasBlockedNight(ctx, accommodationID, dateRange)
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to retrieve calendar blocks, database error")
+		}
+		if blocked {
+			result = append(result, accommodationID)
+		}
+	}
+	return result, nil
+}
+
+// OpenReservationsOfAccommodation returns the reservations of an accommodation that
+// still hold their nights.
+func (rr *ReservationRepo) OpenReservationsOfAccommodation(ctx context.Context, accommodationID string) ([]domain.Reservation, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.OpenReservationsOfAccommodation")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT id, state, date_range FROM reservation_by_accommodation WHERE accommodation_id = ?`, accommodationID).
+		Iter().Scanner()
+	var reservations []domain.Reservation
+	for scanner.Next() {
+		reservation := domain.Reservation{AccommodationID: accommodationID}
+		if err := scanner.Scan(&reservation.Id, &reservation.State, &reservation.DateRange); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+		}
+		if reservation.State.IsOpen() {
+			reservations = append(reservations, reservation)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+	}
+	return reservations, nil
+}
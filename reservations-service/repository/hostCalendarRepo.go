@@ -0,0 +1,236 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// HostBlocks returns the nights the host blocked, in order, with the reason as
+// their summary.
+func (rr *ReservationRepo) HostBlocks(ctx context.Context, accommodationID string) ([]domain.CalendarBlock, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.HostBlocks")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT night, reason FROM host_blocks WHERE accommodation_id = ?`, accommodationID).Iter().Scanner()
+	var blocks []domain.CalendarBlock
+	for scanner.Next() {
+		var block domain.CalendarBlock
+		if err := scanner.Scan(&block.Night, &block.Summary); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get blocked nights, database error")
+		}
+		blocks = append(blocks, block)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get blocked nights, database error")
+	}
+	return blocks, nil
+}
+
+// BlockNights blocks the nights in batches of nightsPerBatch. When a batch fails
+// the nights of the batches before it stay blocked, which blocking them again
+// repeats harmlessly.
+func (rr *ReservationRepo) BlockNights(ctx context.Context, accommodationID, hostID, reason string, nights []string, blockedAt time.Time) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.BlockNights")
+	defer span.End()
+	for _, chunk := range nightChunks(nights) {
+		batch := rr.session.NewBatch(gocql.LoggedBatch)
+		for _, night := range chunk {
+			batch.Query(`INSERT INTO host_blocks (accommodation_id, night, host_id, reason, blocked_at) VALUES(?, ?, ?, ?, ?)`,
+				accommodationID, night, hostID, reason, blockedAt)
+		}
+		if err := rr.session.ExecuteBatch(batch); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to block nights, database error")
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Host %v blocked %d nights of accommodation %v", hostID, len(nights), accommodationID))
+	return nil
+}
+
+func (rr *ReservationRepo) UnblockNights(ctx context.Context, accommodationID string, nights []string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.UnblockNights")
+	defer span.End()
+	for _, chunk := range nightChunks(nights) {
+		batch := rr.session.NewBatch(gocql.LoggedBatch)
+		for _, night := range chunk {
+			batch.Query(`DELETE FROM host_blocks WHERE accommodation_id = ? AND night = ?`, accommodationID, night)
+		}
+		if err := rr.session.ExecuteBatch(batch); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to unblock nights, database error")
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Unblocked %d nights of accommodation %v", len(nights), accommodationID))
+	return nil
+}
+
+// ReservedNights maps the nights a reservation holds to that reservation.
+func (rr *ReservationRepo) ReservedNights(ctx context.Context, accommodationID string, nights []string) (map[string]gocql.UUID, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReservedNights")
+	defer span.End()
+	reserved, err := rr.reservedNights(ctx, accommodationID, nights)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to retrieve reservations, database error")
+	}
+	return reserved, nil
+}
+
+type availabilityWindow struct {
+	id        gocql.UUID
+	location  string
+	price     int
+	currency  string
+	rules     domain.StayRules
+	continent string
+	country   string
+	dateRange []string
+}
+
+// RepriceNights sets the nightly rate of the nights. The nights are taken out of
+// the windows they were in and moved to new windows at the price, one for each
+// window they left, which keep the location and stay rules of the old ones. Nights
+// outside every window become available in a window of their own, placed like the
+// other windows of the accommodation, so it needs at least one. The nights are
+// priced in batches of nightsPerBatch; when a batch fails, the nights of the
+// batches before it keep their new price.
+func (rr *ReservationRepo) RepriceNights(ctx context.Context, accommodationID string, nights []string, price domain.Money) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.RepriceNights")
+	defer span.End()
+	if err := price.ValidateNightlyRate(); err != nil {
+		return errors.NewReservationError(400, err.Error())
+	}
+	for _, chunk := range nightChunks(nights) {
+		if err := rr.repriceChunk(ctx, accommodationID, chunk, price); err != nil {
+			return err
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Priced %d nights of accommodation %v at %s", len(nights), accommodationID, price))
+	return nil
+}
+
+// repriceChunk prices nights in one batch, moving them out of the windows as they
+// are after the previous chunk.
+func (rr *ReservationRepo) repriceChunk(ctx context.Context, accommodationID string, nights []string, price domain.Money) error {
+	scanner := rr.session.Query(`SELECT id, location, price, currency, rules, continent, country, date_range FROM free_accommodation WHERE accommodation_id = ?`,
+		accommodationID).Iter().Scanner()
+	var windows []availabilityWindow
+	for scanner.Next() {
+		var window availabilityWindow
+		if err := scanner.Scan(&window.id, &window.location, &window.price, &window.currency, &window.rules, &window.continent, &window.country, &window.dateRange); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to read availability, database error")
+		}
+		windows = append(windows, window)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read availability, database error")
+	}
+	if len(windows) == 0 {
+		return errors.NewReservationError(400, "The accommodation has no availability to price")
+	}
+
+	repriced := make(map[string]bool, len(nights))
+	for _, night := range nights {
+		repriced[night] = true
+	}
+	moved := make(map[string]bool, len(nights))
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	insert := func(like availabilityWindow, dateRange []string) {
+		id, _ := gocql.RandomUUID()
+		batch.Query(`INSERT INTO free_accommodation (id, accommodation_id, location, price, currency, rules, continent, country, date_range)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, accommodationID, like.location, int(price.Amount), price.Currency, like.rules, like.continent, like.country, dateRange)
+		batch.Query(`INSERT INTO avl_by_price (id, accommodation_id, location, price, currency, continent, country, is_active)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?)`,
+			id, accommodationID, like.location, int(price.Amount), price.Currency, like.continent, like.country, true)
+		addAvailabilityNights(batch, accommodationID, id, dateRange)
+	}
+	for _, window := range windows {
+		var kept, taken, movedHere []string
+		for _, night := range window.dateRange {
+			if !repriced[night] {
+				kept = append(kept, night)
+				continue
+			}
+			taken = append(taken, night)
+			if !moved[night] {
+				moved[night] = true
+				movedHere = append(movedHere, night)
+			}
+		}
+		if len(taken) == 0 {
+			continue
+		}
+		if len(kept) == 0 {
+			batch.Query(`DELETE FROM free_accommodation WHERE accommodation_id = ? AND country = ? AND id = ?`, accommodationID, window.country, window.id)
+			batch.Query(`DELETE FROM avl_by_price WHERE is_active = ? AND price = ? AND id = ?`, true, window.price, window.id)
+		} else {
+			batch.Query(`UPDATE free_accommodation SET date_range = ? WHERE accommodation_id = ? AND country = ? AND id = ?`,
+				kept, accommodationID, window.country, window.id)
+		}
+		removeAvailabilityNights(batch, accommodationID, window.id, taken)
+		if len(movedHere) > 0 {
+			insert(window, movedHere)
+		}
+	}
+	var opened []string
+	for _, night := range nights {
+		if !moved[night] {
+			opened = append(opened, night)
+		}
+	}
+	if len(opened) > 0 {
+		placed := windows[0]
+		placed.rules = domain.StayRules{}
+		insert(placed, opened)
+	}
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to price nights, database error")
+	}
+	return nil
+}
+
+func (rr *ReservationRepo) InsertCalendarChange(ctx context.Context, record *domain.CalendarChangeRecord) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.InsertCalendarChange")
+	defer span.End()
+	err := rr.session.Query(`INSERT INTO calendar_changes (accommodation_id, id, host_id, action, nights, price, reason) VALUES(?, ?, ?, ?, ?, ?, ?)`,
+		record.AccommodationID, record.Id, record.HostID, record.Action, record.Nights, record.Price, record.Reason).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to record calendar change, database error")
+	}
+	return nil
+}
+
+// GetCalendarChanges returns the latest calendar changes of an accommodation,
+// newest first.
+func (rr *ReservationRepo) GetCalendarChanges(ctx context.Context, accommodationID string, limit int) ([]domain.CalendarChangeRecord, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetCalendarChanges")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT id, host_id, action, nights, price, reason FROM calendar_changes WHERE accommodation_id = ? LIMIT ?`,
+		accommodationID, limit).Iter().Scanner()
+	var records []domain.CalendarChangeRecord
+	for scanner.Next() {
+		record := domain.CalendarChangeRecord{AccommodationID: accommodationID}
+		if err := scanner.Scan(&record.Id, &record.HostID, &record.Action, &record.Nights, &record.Price, &record.Reason); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get calendar changes, database error")
+		}
+		record.ChangedAt = record.Id.Time()
+		records = append(records, record)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get calendar changes, database error")
+	}
+	return records, nil
+}
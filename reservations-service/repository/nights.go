@@ -0,0 +1,274 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"sort"
+
+	"github.com/gocql/gocql"
+)
+
+// nightsPerBatch bounds how many nights one batch writes. A night takes a
+// statement or two, and Cassandra refuses batches above 50 KB by default.
+const nightsPerBatch = 50
+
+// nightSlice returns the first and last of the nights and the nights as a set. The
+// nights of a stay are read with one slice from the first to the last night, which
+// the set then narrows to the nights asked for.
+func nightSlice(nights []string) (string, string, map[string]bool) {
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	wanted := make(map[string]bool, len(sorted))
+	for _, night := range sorted {
+		wanted[night] = true
+	}
+	return sorted[0], sorted[len(sorted)-1], wanted
+}
+
+func addAvailabilityNights(batch *gocql.Batch, accommodationID string, availabilityID gocql.UUID, nights []string) {
+	for _, night := range nights {
+		batch.Query(`INSERT INTO availability_nights (accommodation_id, night, availability_id) VALUES(?, ?, ?)`,
+			accommodationID, night, availabilityID)
+	}
+}
+
+func removeAvailabilityNights(batch *gocql.Batch, accommodationID string, availabilityID gocql.UUID, nights []string) {
+	for _, night := range nights {
+		batch.Query(`DELETE FROM availability_nights WHERE accommodation_id = ? AND night = ? AND availability_id = ?`,
+			accommodationID, night, availabilityID)
+	}
+}
+
+func addReservationNights(batch *gocql.Batch, reservation *domain.Reservation, nights []string) {
+	for _, night := range nights {
+		batch.Query(`INSERT INTO reservation_nights (accommodation_id, night, reservation_id, state) VALUES(?, ?, ?, ?)`,
+			reservation.AccommodationID, night, reservation.Id, reservation.State)
+	}
+}
+
+func removeReservationNights(batch *gocql.Batch, accommodationID string, reservationID gocql.UUID, nights []string) {
+	for _, night := range nights {
+		batch.Query(`DELETE FROM reservation_nights WHERE accommodation_id = ? AND night = ? AND reservation_id = ?`,
+			accommodationID, night, reservationID)
+	}
+}
+
+// availabilityOfNights returns the availability windows each of the nights is in.
+// Nights outside every window are left out.
+func (rr *ReservationRepo) availabilityOfNights(ctx context.Context, accommodationID string, nights []string) (map[string][]gocql.UUID, error) {
+	first, last, wanted := nightSlice(nights)
+	scanner := rr.session.Query(`SELECT night, availability_id FROM availability_nights WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+		accommodationID, first, last).Iter().Scanner()
+	windows := make(map[string][]gocql.UUID)
+	for scanner.Next() {
+		var night string
+		var availabilityID gocql.UUID
+		if err := scanner.Scan(&night, &availabilityID); err != nil {
+			return nil, err
+		}
+		if wanted[night] {
+			windows[night] = append(windows[night], availabilityID)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return windows, nil
+}
+
+// hasOpenReservation tells whether a reservation that still holds its nights is on
+// any of the nights.
+func (rr *ReservationRepo) hasOpenReservation(ctx context.Context, accommodationID string, nights []string) (bool, error) {
+	first, last, wanted := nightSlice(nights)
+	iter := rr.session.Query(`SELECT night, state FROM reservation_nights WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+		accommodationID, first, last).Iter()
+	var night string
+	var state domain.ReservationState
+	for iter.Scan(&night, &state) {
+		if wanted[night] && state.IsOpen() {
+			iter.Close()
+			return true, nil
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// hasBlockedNight tells whether the host or an imported calendar blocks any of the
+// nights.
+func (rr *ReservationRepo) hasBlockedNight(ctx context.Context, accommodationID string, nights []string) (bool, error) {
+	first, last, wanted := nightSlice(nights)
+	for _, query := range []string{
+		`SELECT night FROM calendar_blocks WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+		`SELECT night FROM host_blocks WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+	} {
+		iter := rr.session.Query(query, accommodationID, first, last).Iter()
+		var night string
+		for iter.Scan(&night) {
+			if wanted[night] {
+				iter.Close()
+				return true, nil
+			}
+		}
+		if err := iter.Close(); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
+
+// reservedNights maps the nights held by a reservation to that reservation. Nights
+// claimed for a reservation on hold, a request waiting for the host or a waitlist
+// offer are held as well.
+func (rr *ReservationRepo) reservedNights(ctx context.Context, accommodationID string, nights []string) (map[string]gocql.UUID, error) {
+	first, last, wanted := nightSlice(nights)
+	iter := rr.session.Query(`SELECT night, reservation_id, state FROM reservation_nights WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+		accommodationID, first, last).Iter()
+	reserved := make(map[string]gocql.UUID)
+	var night string
+	var reservationID gocql.UUID
+	var state domain.ReservationState
+	for iter.Scan(&night, &reservationID, &state) {
+		if wanted[night] && state.IsOpen() {
+			reserved[night] = reservationID
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	iter = rr.session.Query(`SELECT night, reservation_id FROM night_claims WHERE accommodation_id = ? AND night >= ? AND night <= ?`,
+		accommodationID, first, last).Iter()
+	for iter.Scan(&night, &reservationID) {
+		if _, found := reserved[night]; wanted[night] && !found {
+			reserved[night] = reservationID
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return reserved, nil
+}
+
+// nightChunks splits nights into chunks of at most nightsPerBatch, so the
+// statements written for each chunk stay below the batch size Cassandra refuses.
+func nightChunks(nights []string) [][]string {
+	var chunks [][]string
+	for len(nights) > nightsPerBatch {
+		chunks = append(chunks, nights[:nightsPerBatch])
+		nights = nights[nightsPerBatch:]
+	}
+	if len(nights) > 0 {
+		chunks = append(chunks, nights)
+	}
+	return chunks
+}
+
+// executeWithNights executes batch together with the statements addNights adds
+// for the first chunk of nights, then a batch of the same type for each further
+// chunk. The nightly rows are keyed by night, so a write that fails partway can
+// be repeated.
+func (rr *ReservationRepo) executeWithNights(batch *gocql.Batch, nights []string, addNights func(batch *gocql.Batch, chunk []string)) error {
+	chunks := nightChunks(nights)
+	if len(chunks) == 0 {
+		if batch.Size() == 0 {
+			return nil
+		}
+		return rr.session.ExecuteBatch(batch)
+	}
+	for i, chunk := range chunks {
+		if i > 0 {
+			batch = rr.session.NewBatch(batch.Type)
+		}
+		addNights(batch, chunk)
+		if err := rr.session.ExecuteBatch(batch); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// migrateToNightlyRows fills availability_nights and reservation_nights from the
+// date ranges of the availability windows and reservations stored before them,
+// then drops what only the per-day lookups needed: the date_range indexes of
+// free_accommodation and reservation_by_accommodation and the date_range column of
+// avl_by_price. The rows it writes are keyed by night, so a run interrupted
+// halfway can be repeated.
+func (rr *ReservationRepo) migrateToNightlyRows(ctx context.Context) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.migrateToNightlyRows")
+	defer span.End()
+	windows := 0
+	iter := rr.session.Query(`SELECT accommodation_id, id, date_range FROM free_accommodation`).Iter()
+	var accommodationID string
+	var id gocql.UUID
+	var dateRange []string
+	for iter.Scan(&accommodationID, &id, &dateRange) {
+		err := rr.executeWithNights(rr.session.NewBatch(gocql.UnloggedBatch), dateRange, func(batch *gocql.Batch, chunk []string) {
+			addAvailabilityNights(batch, accommodationID, id, chunk)
+		})
+		if err != nil {
+			iter.Close()
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to migrate availability nights")
+		}
+		windows++
+	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read availability, database error")
+	}
+
+	reservations := 0
+	iter = rr.session.Query(`SELECT accommodation_id, id, state, date_range FROM reservation_by_accommodation`).Iter()
+	var reservation domain.Reservation
+	for iter.Scan(&reservation.AccommodationID, &reservation.Id, &reservation.State, &reservation.DateRange) {
+		err := rr.executeWithNights(rr.session.NewBatch(gocql.UnloggedBatch), reservation.DateRange, func(batch *gocql.Batch, chunk []string) {
+			addReservationNights(batch, &reservation, chunk)
+		})
+		if err != nil {
+			iter.Close()
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to migrate reservation nights")
+		}
+		reservations++
+	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read reservations, database error")
+	}
+
+	for _, index := range []string{"free_accommodation_date_range_idx", "reservation_by_accommodation_date_range_idx"} {
+		if err := rr.session.Query(`DROP INDEX IF EXISTS ` + index).Exec(); err != nil {
+			rr.logger.Println(err)
+		}
+	}
+	// Keyspaces created after the change never had the column.
+	if err := rr.session.Query(`ALTER TABLE avl_by_price DROP date_range`).Exec(); err != nil {
+		rr.logger.Println(err)
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Migrated %d availability windows and %d reservations to nightly rows", windows, reservations))
+	return nil
+}
+
+// nightsNotIn returns the nights that are not in kept.
+func nightsNotIn(nights, kept []string) []string {
+	keep := make(map[string]bool, len(kept))
+	for _, night := range kept {
+		keep[night] = true
+	}
+	var rest []string
+	for _, night := range nights {
+		if !keep[night] {
+			rest = append(rest, night)
+		}
+	}
+	return rest
+}
+
+// sameNights reports whether a and b hold the same nights.
+func sameNights(a, b []string) bool {
+	return len(a) == len(b) && len(nightsNotIn(a, b)) == 0
+}
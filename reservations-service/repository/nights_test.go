@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestNightChunks(t *testing.T) {
+	nights := func(n int) []string {
+		var nights []string
+		for i := 0; i < n; i++ {
+			nights = append(nights, fmt.Sprintf("night-%d", i))
+		}
+		return nights
+	}
+	tests := []struct {
+		nights int
+		sizes  []int
+	}{
+		{nights: 0},
+		{nights: 1, sizes: []int{1}},
+		{nights: nightsPerBatch, sizes: []int{nightsPerBatch}},
+		{nights: nightsPerBatch + 1, sizes: []int{nightsPerBatch, 1}},
+		{nights: 365, sizes: []int{50, 50, 50, 50, 50, 50, 50, 15}},
+	}
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%d nights", test.nights), func(t *testing.T) {
+			all := nights(test.nights)
+			chunks := nightChunks(all)
+			if len(chunks) != len(test.sizes) {
+				t.Fatalf("got %d chunks, want %d", len(chunks), len(test.sizes))
+			}
+			next := 0
+			for i, chunk := range chunks {
+				if len(chunk) != test.sizes[i] {
+					t.Fatalf("chunk %d has %d nights, want %d", i, len(chunk), test.sizes[i])
+				}
+				for _, night := range chunk {
+					if night != all[next] {
+						t.Fatalf("chunk %d has %s where %s belongs", i, night, all[next])
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestNightsNotIn(t *testing.T) {
+	tests := []struct {
+		name         string
+		nights, kept []string
+		want         []string
+	}{
+		{name: "moved later", nights: []string{"01", "02", "03"}, kept: []string{"02", "03", "04"}, want: []string{"01"}},
+		{name: "shortened", nights: []string{"01", "02", "03"}, kept: []string{"01"}, want: []string{"02", "03"}},
+		{name: "extended", nights: []string{"01", "02"}, kept: []string{"01", "02", "03"}},
+		{name: "moved apart", nights: []string{"01", "02"}, kept: []string{"05", "06"}, want: []string{"01", "02"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := nightsNotIn(test.nights, test.kept)
+			if fmt.Sprint(got) != fmt.Sprint(test.want) {
+				t.Fatalf("got %v, want %v", got, test.want)
+			}
+		})
+	}
+}
@@ -9,12 +9,13 @@ import (
 	"reservation-service/config"
 	"reservation-service/domain"
 	"reservation-service/errors"
+	"reservation-service/migrations"
 	"reservation-service/utils"
+	"sort"
 
 	"time"
 
 	"github.com/gocql/gocql"
-	"github.com/pariz/gountries"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -67,152 +68,18 @@ func (rr *ReservationRepo) CloseSession() {
 	rr.session.Close()
 }
 
-func (rr *ReservationRepo) CreateTables() {
-	// Drop existing tables first
-	rr.DropTables()
-
-	// Create new tables
-	err := rr.session.Query(
-		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
-		(id UUID, user_id text, accommodation_id text, start_date text, end_date text, username text, accommodation_name text,location text,price int,
-			num_of_days int,continent text, date_range set<text>,is_active boolean,country text,host_id text,
-		PRIMARY KEY((continent),country,id)) WITH CLUSTERING ORDER BY (country ASC,id ASC)`, "reservations")).Exec()
-	if err != nil {
-		rr.logger.Println(err)
-	}
-	err = rr.session.Query(
-		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
-			(id UUID, accommodation_id text,  location text, price int, continent text, country text, date_range set<text>,
-			 PRIMARY KEY((accommodation_id),country,id))
-			WITH CLUSTERING ORDER BY(country ASC,id ASC)`, "free_accommodation")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-	err = rr.session.Query(
-		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
-			(id UUID, accommodation_id text,  location text, price int, continent text, country text, date_range set<text>,is_active boolean,
-			 PRIMARY KEY((is_active),price,id))
-			WITH CLUSTERING ORDER BY(price ASC,id ASC)`, "avl_by_price")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-
-	err = rr.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
-		id UUID,
-		user_id text,
-		accommodation_id text,
-		start_date text,
-		end_date text,
-		username text,
-		accommodation_name text,
-		location text,
-		price int,
-		num_of_days int,
-		continent text,
-		date_range set<text>,
-		is_active boolean,
-		country text,
-		host_id text,
-		PRIMARY KEY (user_id, id)
-	) WITH CLUSTERING ORDER BY ( id ASC)`, "reservation_by_user")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-
-	err = rr.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
-		id UUID,
-		user_id text,
-		accommodation_id text,
-		start_date text,
-		end_date text,
-		username text,
-		accommodation_name text,
-		location text,
-		price int,
-		num_of_days int,
-		continent text,
-		date_range set<text>,
-		is_active boolean,
-		country text,
-		host_id text,
-		PRIMARY KEY (host_id,user_id,end_date, id)
-	) WITH CLUSTERING ORDER BY (user_id ASC,end_date ASC, id ASC)`, "reservation_by_host")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-	err = rr.session.Query(fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
-			id UUID,
-			user_id text,
-			accommodation_id text,
-			start_date text,
-			end_date text,
-			username text,
-			accommodation_name text,
-			location text,
-			price int,
-			num_of_days int,
-			continent text,
-			date_range set<text>,
-			is_active boolean,
-			country text,
-			host_id text,
-			PRIMARY KEY (accommodation_id,user_id,end_date, id)
-		) WITH CLUSTERING ORDER BY (user_id ASC,end_date ASC, id ASC)`, "reservation_by_accommodation")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-
-	err = rr.session.Query(
-		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s
-			(id UUID, host_id text, ,
-			 PRIMARY KEY((host_id),id))
-			WITH CLUSTERING ORDER BY(id ASC)`, "deleted_reservations")).Exec()
-
-	if err != nil {
-		rr.logger.Println(err)
-	}
-
-	err = rr.session.Query(fmt.Sprintf("CREATE INDEX ON reservation_by_accommodation (date_range);")).Exec()
-	if err != nil {
-		rr.logger.Println(err)
-	}
-	err = rr.session.Query(fmt.Sprintf("CREATE INDEX ON free_accommodation (date_range);")).Exec()
-	if err != nil {
-		rr.logger.Println(err)
-	}
-
-}
-
-func (rr *ReservationRepo) DropTables() {
-	dropTable := func(tableName string) {
-		err := rr.session.Query(fmt.Sprintf("DROP TABLE IF EXISTS %s", tableName)).Exec()
-		if err != nil {
-			rr.logger.Println(err)
-		} else {
-			rr.logger.Printf("Table %s dropped successfully", tableName)
-		}
-	}
-
-	dropTable("reservations")
-	//dropTable("free_accommodation")
-	dropTable("reservation_by_user")
-	dropTable("reservation_by_host")
-	dropTable("reservation_by_accommodation")
-	dropTable("deleted_reservations")
-	//	dropTable("avl_by_price")
-
+// Migrator applies the schema migrations of the keyspace, see package migrations.
+func (rr *ReservationRepo) Migrator() (*migrations.Migrator, error) {
+	return migrations.NewMigrator(rr.session, rr.logger,
+		migrations.Migration{Version: 2, Name: "0002_nightly_rows", Apply: rr.migrateToNightlyRows},
+		migrations.Migration{Version: 10, Name: "0010_request_deadlines", Apply: rr.migrateRequestDeadlines})
 }
 
 func (rr *ReservationRepo) GetReservationsByUser(ctx context.Context, id string) ([]domain.Reservation, error) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservationsByUser")
 	defer span.End()
 	scanner := rr.session.Query(`SELECT id,accommodation_id, user_id, start_date, end_date,username,accommodation_name,location,price,
-	num_of_days,date_range,is_active,country,host_id FROM reservation_by_user
+	num_of_days,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund FROM reservation_by_user
 	 WHERE user_id = ?`,
 		id).Iter().Scanner()
 
@@ -222,7 +89,8 @@ func (rr *ReservationRepo) GetReservationsByUser(ctx context.Context, id string)
 
 		err := scanner.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.UserID, &reservation.StartDate,
 			&reservation.EndDate, &reservation.Username, &reservation.AccommodationName, &reservation.Location, &reservation.Price,
-			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID)
+			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID,
+			&reservation.Guests, &reservation.Party, &reservation.State, &reservation.StateChangedAt, &reservation.Refund)
 		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, err
@@ -243,7 +111,7 @@ func (rr *ReservationRepo) GetReservationsByHost(ctx context.Context, id string)
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservationsByHost")
 	defer span.End()
 	scanner := rr.session.Query(`SELECT id,accommodation_id, user_id, start_date, end_date,username,accommodation_name,location,price,
-	num_of_days,date_range,is_active,country,host_id FROM reservation_by_host
+	num_of_days,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund FROM reservation_by_host
 	 WHERE  host_id = ?`,
 		id).Iter().Scanner()
 
@@ -253,7 +121,8 @@ func (rr *ReservationRepo) GetReservationsByHost(ctx context.Context, id string)
 
 		err := scanner.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.UserID, &reservation.StartDate,
 			&reservation.EndDate, &reservation.Username, &reservation.AccommodationName, &reservation.Location, &reservation.Price,
-			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID)
+			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID,
+			&reservation.Guests, &reservation.Party, &reservation.State, &reservation.StateChangedAt, &reservation.Refund)
 		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, err
@@ -283,19 +152,26 @@ func (rr *ReservationRepo) InsertAvailability(ctx context.Context, reservation *
 		return nil, errors.NewReservationError(500, err.Error())
 	}
 
+	for _, drwp := range reservation.DateRange {
+		if err := drwp.Price.ValidateNightlyRate(); err != nil {
+			return nil, errors.NewReservationError(400, err.Error())
+		}
+	}
 	for _, drwp := range reservation.DateRange {
 		batch := rr.session.NewBatch(gocql.LoggedBatch)
 		ID, _ := gocql.RandomUUID()
 		batch.Query(`
-				INSERT INTO free_accommodation (id, accommodation_id, location, price, continent, country, date_range)
-				VALUES(?, ?, ?, ?, ?, ?, ?)
-			`, ID, reservation.AccommodationID, reservation.Location, drwp.Price, continent, country, drwp.DateRange)
+				INSERT INTO free_accommodation (id, accommodation_id, location, price, currency, rules, continent, country, date_range)
+				VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, ID, reservation.AccommodationID, reservation.Location, int(drwp.Price.Amount), drwp.Price.Currency, drwp.Rules, continent, country, drwp.DateRange)
 		batch.Query(`
-				INSERT INTO avl_by_price (id, accommodation_id, location, price, continent, country, date_range,is_active)
-				VALUES(?, ?, ?, ?, ?, ?, ?,?)
-			`, ID, reservation.AccommodationID, reservation.Location, drwp.Price, continent, country, drwp.DateRange, true)
-
-		if err := rr.session.ExecuteBatch(batch); err != nil {
+				INSERT INTO avl_by_price (id, accommodation_id, location, price, currency, continent, country, is_active)
+				VALUES(?, ?, ?, ?, ?, ?, ?, ?)
+			`, ID, reservation.AccommodationID, reservation.Location, int(drwp.Price.Amount), drwp.Price.Currency, continent, country, true)
+		err := rr.executeWithNights(batch, drwp.DateRange, func(batch *gocql.Batch, chunk []string) {
+			addAvailabilityNights(batch, reservation.AccommodationID, ID, chunk)
+		})
+		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, err
 		}
@@ -308,30 +184,45 @@ func (rr *ReservationRepo) InsertAvailability(ctx context.Context, reservation *
 	return reservation, nil
 }
 
+// AvailableDates returns the availability windows that hold any of the nights,
+// each window once.
 func (rr *ReservationRepo) AvailableDates(ctx context.Context, accommodationID string, dateRange []string) ([]domain.FreeReservation, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.AvailableDates")
 	defer span.End()
 	var result []domain.FreeReservation
-	for _, date := range dateRange {
-		query := `
-        SELECT id, accommodation_id,location, price,country
-        FROM free_accommodation 
-        WHERE accommodation_id = ? 
-        AND date_range CONTAINS ?
-        `
-
-		iter := rr.session.Query(query, accommodationID, date).Iter()
-
-		var reservation domain.FreeReservation
-		for iter.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.Location, &reservation.Price, reservation.Country) {
-			result = append(result, reservation)
+	if len(dateRange) == 0 {
+		return result, nil
+	}
+	windowsOfNights, err := rr.availabilityOfNights(ctx, accommodationID, dateRange)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to check availability, database error")
+	}
+	windows := make(map[gocql.UUID]bool)
+	for _, ids := range windowsOfNights {
+		for _, id := range ids {
+			windows[id] = true
 		}
+	}
+	if len(windows) == 0 {
+		return result, nil
+	}
 
-		if err := iter.Close(); err != nil {
-			rr.logger.LogError("reservationsRepo", err.Error())
-			return nil, errors.NewReservationError(500, "Unable to check availability, database error")
+	iter := rr.session.Query(`SELECT id, accommodation_id, location, price, currency, country FROM free_accommodation WHERE accommodation_id = ?`,
+		accommodationID).Iter()
+	var reservation domain.FreeReservation
+	var price int
+	var currency string
+	for iter.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.Location, &price, &currency, &reservation.Country) {
+		if windows[reservation.Id] {
+			reservation.Price = domain.StoredMoney(price, currency)
+			result = append(result, reservation)
 		}
 	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to check availability, database error")
+	}
 	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found availability by accommodationID and dateRange: %v", result))
 	return result, nil
 }
@@ -339,7 +230,10 @@ func (rr *ReservationRepo) AvailableDates(ctx context.Context, accommodationID s
 func (rr *ReservationRepo) InsertReservation(ctx context.Context, reservation *domain.Reservation) (*domain.Reservation, error) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.InsertReservation")
 	defer span.End()
-	Id, _ := gocql.RandomUUID()
+	Id := reservation.Id
+	if Id == (gocql.UUID{}) {
+		Id, _ = gocql.RandomUUID()
+	}
 	country, err := utils.GetCountry(reservation.Location)
 	if err != nil {
 		return nil, errors.NewReservationError(500, err.Error())
@@ -355,126 +249,309 @@ func (rr *ReservationRepo) InsertReservation(ctx context.Context, reservation *d
 	println(startDate, endDate)
 
 	batch := rr.session.NewBatch(gocql.LoggedBatch)
-
-	// Insert into reservations table
-	batch.Query(`INSERT INTO reservations (id,user_id,accommodation_id,start_date,end_date,username,accommodation_name,location,price,num_of_days,
-	    continent,date_range,is_active,country,host_id)
-	    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, Id, reservation.UserID, reservation.AccommodationID, startDate,
-		endDate, reservation.Username, reservation.AccommodationName, reservation.Location,
-		reservation.Price, reservation.NumberOfDays, continent, reservation.DateRange, true, country, reservation.HostID)
-	batch.Query(`INSERT INTO reservation_by_user (id,user_id,accommodation_id,start_date,end_date,username,accommodation_name,location,price,num_of_days,
-	    continent,date_range,is_active,country,host_id)
-	    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, Id, reservation.UserID, reservation.AccommodationID, startDate,
-		endDate, reservation.Username, reservation.AccommodationName, reservation.Location,
-		reservation.Price, reservation.NumberOfDays, continent, reservation.DateRange, true, country, reservation.HostID)
-	batch.Query(`INSERT INTO reservation_by_host (id,user_id,accommodation_id,start_date,end_date,username,accommodation_name,location,price,num_of_days,
-	    continent,date_range,is_active,country,host_id)
-	    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, Id, reservation.UserID, reservation.AccommodationID, startDate,
-		endDate, reservation.Username, reservation.AccommodationName, reservation.Location,
-		reservation.Price, reservation.NumberOfDays, continent, reservation.DateRange, true, country, reservation.HostID)
-	batch.Query(`INSERT INTO reservation_by_accommodation (id,user_id,accommodation_id,start_date,end_date,username,accommodation_name,location,price,num_of_days,
-			continent,date_range,is_active,country,host_id)
-			VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, Id, reservation.UserID, reservation.AccommodationID, startDate,
-		endDate, reservation.Username, reservation.AccommodationName, reservation.Location,
-		reservation.Price, reservation.NumberOfDays, continent, reservation.DateRange, true, country, reservation.HostID)
-
-	if err := rr.session.ExecuteBatch(batch); err != nil {
+	reservation.Id = Id
+	insertReservationRows(batch, reservationTables, reservation, startDate, endDate, continent, country)
+	if err := rr.executeWithNights(batch, reservation.DateRange, func(batch *gocql.Batch, chunk []string) {
+		addReservationNights(batch, reservation, chunk)
+	}); err != nil {
 		rr.logger.LogError("reservationsRepo", err.Error())
 		return nil, err
 	}
 
-	reservation.Id = Id
 	reservation.Country = country
 	reservation.Continent = continent
-	reservation.IsActive = true
 	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Inserted reservation: %v", reservation))
 
 	return reservation, nil
 }
 
-func (rr *ReservationRepo) DeleteById(ctx context.Context, country string, id, userID, hostID, accommodationID, endDate string) (*domain.Reservation, *errors.ReservationError) {
-	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteById")
+// requestDeadlineLookback is how far back GetExpiredRequests looks for deadlines.
+const requestDeadlineLookback = 30 * 24 * time.Hour
+
+var reservationTables = []string{"reservations", "reservation_by_user", "reservation_by_host", "reservation_by_accommodation"}
+
+// insertReservationRows adds the inserts of the reservation into tables to batch.
+func insertReservationRows(batch *gocql.Batch, tables []string, reservation *domain.Reservation, startDate, endDate, continent, country string) {
+	for _, table := range tables {
+		batch.Query(fmt.Sprintf(`INSERT INTO %s (id,user_id,accommodation_id,start_date,end_date,username,accommodation_name,location,price,num_of_days,
+	    continent,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund)
+	    VALUES(?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?,?)`, table), reservation.Id, reservation.UserID, reservation.AccommodationID, startDate,
+			endDate, reservation.Username, reservation.AccommodationName, reservation.Location,
+			reservation.Price, reservation.NumberOfDays, continent, reservation.DateRange, reservation.IsActive, country, reservation.HostID,
+			reservation.Guests, reservation.Party, reservation.State, reservation.StateChangedAt, reservation.Refund)
+	}
+}
+
+// UpdateStay writes the new dates, party and price of a reservation to all four
+// tables and moves its nights. The end date is part of the keys of reservation_by_host and
+// reservation_by_accommodation, so when it changes their rows are moved. The
+// update of reservation_by_user is a lightweight transaction on the previous state
+// and dates, so of two concurrent changes of the same reservation only one is
+// applied. It also records the dates the stay moved from, which RepairReservation
+// needs when the other tables were not written.
+func (rr *ReservationRepo) UpdateStay(ctx context.Context, previous, updated *domain.Reservation) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.UpdateStay")
 	defer span.End()
-	countryData := gountries.New()
+	startDate := updated.DateRange[0]
+	endDate := updated.DateRange[len(updated.DateRange)-1]
+	current := map[string]interface{}{}
+	applied, err := rr.session.Query(`UPDATE reservation_by_user SET start_date = ?, end_date = ?, date_range = ?, num_of_days = ?, price = ?, guests = ?, party = ?,
+		moved_from_end_date = ?, moved_from_date_range = ?
+		WHERE user_id = ? AND id = ? IF state = ? AND date_range = ?`,
+		startDate, endDate, updated.DateRange, updated.NumberOfDays, updated.Price, updated.Guests, updated.Party,
+		previous.EndDate, previous.DateRange,
+		updated.UserID, updated.Id, previous.State, previous.DateRange).MapScanCAS(current)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to update the reservation, database error")
+	}
+	if !applied {
+		dateRange, _ := current["date_range"].([]string)
+		if current["state"] != string(updated.State) || !sameNights(dateRange, updated.DateRange) {
+			return false, nil
+		}
+		return rr.repairStored(ctx, updated)
+	}
+	if err := rr.writeStay(previous, updated); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to update the reservation, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Reservation %v moved to %s - %s", updated.Id, startDate, endDate))
+	return true, nil
+}
 
-	result, err := countryData.FindCountryByName(country)
+// writeStay writes a reservation that moved from the dates of previous to the
+// tables other than reservation_by_user. Every statement sets the stored values,
+// so it can be repeated.
+func (rr *ReservationRepo) writeStay(previous, updated *domain.Reservation) error {
+	continent, continentErr := utils.GetContinent(updated.Location)
+	if continentErr != nil {
+		return continentErr
+	}
+	startDate := updated.DateRange[0]
+	endDate := updated.DateRange[len(updated.DateRange)-1]
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	if previous.EndDate != endDate {
+		batch.Query(`DELETE FROM reservation_by_host WHERE host_id = ? AND user_id = ? AND end_date = ? AND id = ?`, previous.HostID, previous.UserID, previous.EndDate, previous.Id)
+		batch.Query(`DELETE FROM reservation_by_accommodation WHERE accommodation_id = ? AND user_id = ? AND end_date = ? AND id = ?`, previous.AccommodationID, previous.UserID, previous.EndDate, previous.Id)
+	}
+	insertReservationRows(batch, []string{"reservations", "reservation_by_host", "reservation_by_accommodation"}, updated, startDate, endDate, continent, updated.Country)
+	// Statements of a batch share a timestamp, and a delete wins over an insert
+	// with the same one, so only the nights the stay gives up are removed.
+	err := rr.executeWithNights(batch, updated.DateRange, func(batch *gocql.Batch, chunk []string) {
+		addReservationNights(batch, updated, chunk)
+	})
 	if err != nil {
-		return nil, errors.NewReservationError(500, err.Error())
+		return err
+	}
+	return rr.executeWithNights(rr.session.NewBatch(gocql.LoggedBatch), nightsNotIn(previous.DateRange, updated.DateRange), func(batch *gocql.Batch, chunk []string) {
+		removeReservationNights(batch, previous.AccommodationID, previous.Id, chunk)
+	})
+}
+
+// UpdateState writes the state and refund of a reservation to all four tables and
+// the state to its nights. The
+// update of reservation_by_user is a lightweight transaction on the previous state,
+// so of two concurrent transitions of the same reservation only one is applied.
+// When reservation_by_user already holds the new state but the other tables do
+// not, they are repaired and the stored reservation is reported as applied.
+func (rr *ReservationRepo) UpdateState(ctx context.Context, reservation *domain.Reservation, previous domain.ReservationState) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.UpdateState")
+	defer span.End()
+	changedAt := reservation.StateChangedAt[reservation.State]
+	current := map[string]interface{}{}
+	applied, err := rr.session.Query(`UPDATE reservation_by_user SET state = ?, state_changed_at[?] = ?, is_active = ?, refund = ?
+		WHERE user_id = ? AND id = ? IF state = ?`,
+		reservation.State, reservation.State, changedAt, reservation.IsActive, reservation.Refund, reservation.UserID, reservation.Id, previous).
+		MapScanCAS(current)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to update the reservation, database error")
+	}
+	if !applied {
+		if current["state"] != string(reservation.State) {
+			return false, nil
+		}
+		return rr.repairStored(ctx, reservation)
+	}
+	continent, continentErr := utils.GetContinent(reservation.Location)
+	if continentErr != nil {
+		return false, continentErr
 	}
-	continent := result.Continent
 	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`UPDATE reservations SET state = ?, state_changed_at[?] = ?, is_active = ?, refund = ? WHERE continent = ? AND country = ? AND id = ?`,
+		reservation.State, reservation.State, changedAt, reservation.IsActive, reservation.Refund, continent, reservation.Country, reservation.Id)
+	batch.Query(`UPDATE reservation_by_host SET state = ?, state_changed_at[?] = ?, is_active = ?, refund = ? WHERE host_id = ? AND user_id = ? AND end_date = ? AND id = ?`,
+		reservation.State, reservation.State, changedAt, reservation.IsActive, reservation.Refund, reservation.HostID, reservation.UserID, reservation.EndDate, reservation.Id)
+	batch.Query(`UPDATE reservation_by_accommodation SET state = ?, state_changed_at[?] = ?, is_active = ?, refund = ? WHERE accommodation_id = ? AND user_id = ? AND end_date = ? AND id = ?`,
+		reservation.State, reservation.State, changedAt, reservation.IsActive, reservation.Refund, reservation.AccommodationID, reservation.UserID, reservation.EndDate, reservation.Id)
+	err = rr.executeWithNights(batch, reservation.DateRange, func(batch *gocql.Batch, chunk []string) {
+		for _, night := range chunk {
+			batch.Query(`UPDATE reservation_nights SET state = ? WHERE accommodation_id = ? AND night = ? AND reservation_id = ?`,
+				reservation.State, reservation.AccommodationID, night, reservation.Id)
+		}
+	})
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to update the reservation, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Reservation %v moved from %s to %s", reservation.Id, previous, reservation.State))
+	return true, nil
+}
 
-	batch.Query(`DELETE FROM reservations WHERE continent = ? AND country = ? AND id = ?`, continent, country, id)
-	batch.Query(`DELETE FROM reservation_by_user WHERE user_id = ? AND id = ?`, userID, id)
-	batch.Query(`DELETE FROM reservation_by_host WHERE host_id = ? AND user_id = ? AND end_date = ? AND id = ? `, hostID, userID, endDate, id)
-	batch.Query(`DELETE FROM reservation_by_accommodation WHERE accommodation_id = ? AND user_id = ? AND end_date = ? AND id = ?`, accommodationID, userID, endDate, id)
-	batch.Query(`INSERT INTO deleted_reservations(id,host_id) VALUES(?,?)`, id, hostID)
+// repairStored repairs the reservation as stored in reservation_by_user, which a
+// change found already applied there, and hands it to the caller when it did.
+func (rr *ReservationRepo) repairStored(ctx context.Context, reservation *domain.Reservation) (bool, error) {
+	stored, err := rr.GetReservation(ctx, reservation.UserID, reservation.Id)
+	if err != nil {
+		return false, err
+	}
+	repaired, err := rr.RepairReservation(ctx, stored)
+	if err != nil || !repaired {
+		return false, err
+	}
+	*reservation = *stored
+	return true, nil
+}
 
-	if err := rr.session.ExecuteBatch(batch); err != nil {
+// RepairReservation writes the reservation as stored in reservation_by_user to the
+// other tables and its nights when the write that follows a change of state or
+// dates failed, and releases the claims of the nights a move gave up. It reports
+// whether anything had to be repaired, so a retried change succeeds only once.
+func (rr *ReservationRepo) RepairReservation(ctx context.Context, reservation *domain.Reservation) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.RepairReservation")
+	defer span.End()
+	previous := *reservation
+	var movedFromEndDate string
+	var movedFromDateRange []string
+	err := rr.session.Query(`SELECT moved_from_end_date, moved_from_date_range FROM reservation_by_user WHERE user_id = ? AND id = ?`,
+		reservation.UserID, reservation.Id).Scan(&movedFromEndDate, &movedFromDateRange)
+	if err != nil && err != gocql.ErrNotFound {
 		rr.logger.LogError("reservationsRepo", err.Error())
-		return nil, errors.NewReservationError(500, "Unable to cancel the reservation")
+		return false, errors.NewReservationError(500, "Unable to get reservation, database error")
 	}
-	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Deleted reservation by ID: %v", id))
+	if len(movedFromDateRange) > 0 {
+		previous.EndDate = movedFromEndDate
+		previous.DateRange = movedFromDateRange
+	}
+	behind, err := rr.behind(&previous, reservation)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to get reservation, database error")
+	}
+	if !behind {
+		return false, nil
+	}
+	if err := rr.writeStay(&previous, reservation); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to repair the reservation, database error")
+	}
+	if err := rr.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, nightsNotIn(previous.DateRange, reservation.DateRange)); err != nil {
+		return false, err
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Repaired reservation %v", reservation.Id))
+	return true, nil
+}
 
-	return nil, nil
+// behind reports whether the reservations table or the nights do not hold the
+// reservation yet. Its rows are written together with the first nights and the
+// nights follow in order, with the nights a move gave up last, so the last night
+// of each tells whether all of them were written.
+func (rr *ReservationRepo) behind(previous, reservation *domain.Reservation) (bool, error) {
+	continent, continentErr := utils.GetContinent(reservation.Location)
+	if continentErr != nil {
+		return false, continentErr
+	}
+	var state domain.ReservationState
+	var dateRange []string
+	err := rr.session.Query(`SELECT state, date_range FROM reservations WHERE continent = ? AND country = ? AND id = ?`,
+		continent, reservation.Country, reservation.Id).Scan(&state, &dateRange)
+	if err == gocql.ErrNotFound {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if state != reservation.State || !sameNights(dateRange, reservation.DateRange) {
+		return true, nil
+	}
+	if len(reservation.DateRange) > 0 {
+		err = rr.session.Query(`SELECT state FROM reservation_nights WHERE accommodation_id = ? AND night = ? AND reservation_id = ?`,
+			reservation.AccommodationID, reservation.DateRange[len(reservation.DateRange)-1], reservation.Id).Scan(&state)
+		if err == gocql.ErrNotFound {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if state != reservation.State {
+			return true, nil
+		}
+	}
+	if released := nightsNotIn(previous.DateRange, reservation.DateRange); len(released) > 0 {
+		err = rr.session.Query(`SELECT state FROM reservation_nights WHERE accommodation_id = ? AND night = ? AND reservation_id = ?`,
+			reservation.AccommodationID, released[len(released)-1], reservation.Id).Scan(&state)
+		if err == nil {
+			return true, nil
+		}
+		if err != gocql.ErrNotFound {
+			return false, err
+		}
+	}
+	return false, nil
 }
 
+// ReservationsInDateRange returns the accommodations with a reservation that holds
+// any of the nights. Each accommodation is read with one slice of its nights.
 func (rr *ReservationRepo) ReservationsInDateRange(ctx context.Context, accommodationIDs []string, dateRange []string) ([]string, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReservationsInDateRange")
 	defer span.End()
-	uniqueReservations := make(map[string]struct{})
-	for _, date := range dateRange {
-		for _, accommodationID := range accommodationIDs {
-			query := `SELECT accommodation_id FROM reservation_by_accommodation WHERE accommodation_id = ? AND date_range CONTAINS ?`
-
-			iter := rr.session.Query(query, accommodationID, date).Iter()
-
-			var reservation string
-			for iter.Scan(&reservation) {
-				uniqueReservations[reservation] = struct{}{}
-			}
-			if err := iter.Close(); err != nil {
-				rr.logger.LogError("reservationsRepo", err.Error())
-				return nil, errors.NewReservationError(500, "Unable to retrieve reservations, database error")
-			}
-		}
+	result := make([]string, 0)
+	if len(dateRange) == 0 {
+		return result, nil
 	}
-
-	result := make([]string, 0, len(uniqueReservations))
-	for key := range uniqueReservations {
-		result = append(result, key)
+	for _, accommodationID := range accommodationIDs {
+		reserved, err := rr.hasOpenReservation(ctx, accommodationID, dateRange)
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to retrieve reservations, database error")
+		}
+		if reserved {
+			result = append(result, accommodationID)
+		}
 	}
 	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found reservation by accommodationIDs and dateRange: %v", result))
 
 	return result, nil
 }
 
+// IsAvailable tells whether every night of the date range is in an availability
+// window of the accommodation and neither the host nor an imported calendar
+// blocks it.
 func (rr *ReservationRepo) IsAvailable(ctx context.Context, accommodationID string, dateRange []string) (bool, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.IsAvailable")
 	defer span.End()
-	for _, date := range dateRange {
-		query := `
-	SELECT id
-	FROM free_accommodation 
-	WHERE accommodation_id = ? 
-	AND date_range CONTAINS ?
-	`
-
-		iter := rr.session.Query(query, accommodationID, date).Iter()
-
-		var reservationID string
-		if iter.Scan(&reservationID) {
-			return true, nil
-		}
-
-		if err := iter.Close(); err != nil {
+	if len(dateRange) == 0 {
+		return false, nil
+	}
+	windows, err := rr.availabilityOfNights(ctx, accommodationID, dateRange)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to check availability, database error")
+	}
+	_, _, wanted := nightSlice(dateRange)
+	available := len(windows) == len(wanted)
+	if available {
+		blocked, err := rr.hasBlockedNight(ctx, accommodationID, dateRange)
+		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return false, errors.NewReservationError(500, "Unable to check availability, database error")
 		}
+		available = !blocked
 	}
-	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found out is accommodation available or not by accommodationID and dateRange: %v", false))
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found out is accommodation available or not by accommodationID and dateRange: %v", available))
 
-	return false, nil
+	return available, nil
 }
 
 func (rr *ReservationRepo) CheckAvailabilityForAccommodation(ctx context.Context, accommodationID string) ([]domain.GetAvailabilityForAccommodation, *errors.ReservationError) {
@@ -483,7 +560,7 @@ func (rr *ReservationRepo) CheckAvailabilityForAccommodation(ctx context.Context
 	var result []domain.GetAvailabilityForAccommodation
 
 	query := `
-    SELECT date_range,price,id
+    SELECT date_range,price,currency,rules,id
     FROM free_accommodation 
     WHERE accommodation_id = ? 
     `
@@ -491,11 +568,12 @@ func (rr *ReservationRepo) CheckAvailabilityForAccommodation(ctx context.Context
 	iter := rr.session.Query(query, accommodationID).Iter()
 	var dateRange []string
 	var price int
+	var currency string
 	var id string
 	var avl domain.GetAvailabilityForAccommodation
-	for iter.Scan(&dateRange, &price, &id) {
+	for iter.Scan(&dateRange, &price, &currency, &avl.Rules, &id) {
 		avl.DateRange = dateRange
-		avl.Price = price
+		avl.Price = domain.StoredMoney(price, currency)
 		avl.Id = id
 		result = append(result, avl)
 	}
@@ -512,64 +590,65 @@ func (rr *ReservationRepo) CheckAvailabilityForAccommodation(ctx context.Context
 func (rr *ReservationRepo) IsReserved(ctx context.Context, accommodationID string, dateRange []string) (bool, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.IsReserved")
 	defer span.End()
-
-	for _, date := range dateRange {
-		query := `
-		SELECT id
-		FROM reservation_by_accommodation
-		WHERE accommodation_id = ? 
-		AND date_range CONTAINS ?
-		`
-		iter := rr.session.Query(query, accommodationID, date).Iter()
-
-		var reservationID string
-		if iter.Scan(&reservationID) {
-			return true, nil
-		}
-
-		if err := iter.Close(); err != nil {
-			rr.logger.LogError("reservationsRepo", err.Error())
-			return false, errors.NewReservationError(500, "Unable to check is reserved, database error")
-		}
-
+	if len(dateRange) == 0 {
+		return false, nil
 	}
-	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Checked if is accommodation reserved by accommodationID and dateRanges: %v", false))
-
-	return false, nil
+	reserved, err := rr.hasOpenReservation(ctx, accommodationID, dateRange)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to check is reserved, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Checked if is accommodation reserved by accommodationID and dateRanges: %v", reserved))
+	return reserved, nil
 }
 func (rr *ReservationRepo) GetNumberOfCanceledReservations(ctx context.Context, hostID string) (int, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetNumberOfCanceledReservations")
 	defer span.End()
-	query := `SELECT COUNT(*) FROM deleted_reservations WHERE host_id = ?`
+	query := `SELECT state FROM reservation_by_host WHERE host_id = ?`
 	iter := rr.session.Query(query, hostID).Iter()
 
 	var numberOfCanceled int
-	if iter.Scan(&numberOfCanceled) {
-		return numberOfCanceled, nil
+	var state domain.ReservationState
+	for iter.Scan(&state) {
+		if state == domain.Cancelled {
+			numberOfCanceled++
+		}
+	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return 0, errors.NewReservationError(500, "Failed to get the number of canceled reservations")
 	}
-	return 0, errors.NewReservationError(500, "Failed to get the number of canceled reservations")
+	return numberOfCanceled, nil
 }
 
+// GetTotalReservationsByHost counts the reservations of the host that were booked.
+// Requests the host declined or let expire never were.
 func (rr *ReservationRepo) GetTotalReservationsByHost(ctx context.Context, hostID string) (int, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetTotalReservationsByHost")
 	defer span.End()
-	query := `SELECT COUNT(*) FROM reservation_by_host WHERE host_id = ?`
+	query := `SELECT state FROM reservation_by_host WHERE host_id = ?`
 	iter := rr.session.Query(query, hostID).Iter()
 	var totalReservations int
-	if iter.Scan(&totalReservations) {
-		return totalReservations, nil
+	var state domain.ReservationState
+	for iter.Scan(&state) {
+		if state != domain.Requested && state != domain.Declined && state != domain.Expired {
+			totalReservations++
+		}
 	}
-	return 0, errors.NewReservationError(500, "Failed to get the number of total reservations")
-
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return 0, errors.NewReservationError(500, "Failed to get the number of total reservations")
+	}
+	return totalReservations, nil
 }
+
 func (rr *ReservationRepo) GetReservationsByAccommodationWithEndDate(ctx context.Context, accommodationID, userID string) ([]domain.Reservation, error) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservationsByAccommodationWithEndDate")
 	defer span.End()
-	currentDate := time.Now().Format("2006-01-02")
 	scanner := rr.session.Query(`SELECT id,accommodation_id, user_id, start_date, end_date,username,accommodation_name,location,price,
-	num_of_days,date_range,is_active,country,host_id FROM reservation_by_accommodation
-	 WHERE  accommodation_id = ? AND user_id = ? AND end_date <= ?`,
-		accommodationID, userID, currentDate).Iter().Scanner()
+	num_of_days,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund FROM reservation_by_accommodation
+	 WHERE  accommodation_id = ? AND user_id = ?`,
+		accommodationID, userID).Iter().Scanner()
 
 	var reservations []domain.Reservation
 	for scanner.Next() {
@@ -577,7 +656,8 @@ func (rr *ReservationRepo) GetReservationsByAccommodationWithEndDate(ctx context
 
 		err := scanner.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.UserID, &reservation.StartDate,
 			&reservation.EndDate, &reservation.Username, &reservation.AccommodationName, &reservation.Location, &reservation.Price,
-			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID)
+			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID,
+			&reservation.Guests, &reservation.Party, &reservation.State, &reservation.StateChangedAt, &reservation.Refund)
 		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, err
@@ -598,11 +678,10 @@ func (rr *ReservationRepo) GetReservationsByAccommodationWithEndDate(ctx context
 func (rr *ReservationRepo) GetReservationsByHostWithEndDate(ctx context.Context, hostID, userID string) ([]domain.Reservation, error) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservationsByHostWithEndDate")
 	defer span.End()
-	currentDate := time.Now().Format("2006-01-02")
 	scanner := rr.session.Query(`SELECT id,accommodation_id, user_id, start_date, end_date,username,accommodation_name,location,price,
-	num_of_days,date_range,is_active,country,host_id FROM reservation_by_host
-	 WHERE  host_id = ? AND user_id = ? AND end_date <= ?`,
-		hostID, userID, currentDate).Iter().Scanner()
+	num_of_days,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund FROM reservation_by_host
+	 WHERE  host_id = ? AND user_id = ?`,
+		hostID, userID).Iter().Scanner()
 
 	var reservations []domain.Reservation
 	for scanner.Next() {
@@ -610,7 +689,8 @@ func (rr *ReservationRepo) GetReservationsByHostWithEndDate(ctx context.Context,
 
 		err := scanner.Scan(&reservation.Id, &reservation.AccommodationID, &reservation.UserID, &reservation.StartDate,
 			&reservation.EndDate, &reservation.Username, &reservation.AccommodationName, &reservation.Location, &reservation.Price,
-			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID)
+			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID,
+			&reservation.Guests, &reservation.Party, &reservation.State, &reservation.StateChangedAt, &reservation.Refund)
 		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, err
@@ -628,16 +708,36 @@ func (rr *ReservationRepo) GetReservationsByHostWithEndDate(ctx context.Context,
 
 }
 
-func (rr *ReservationRepo) DeleteAvl(ctx context.Context, accommodationID, id, country string, price int) (*domain.FreeReservation, error) {
+// DeleteAvl removes an availability window. Its avl_by_price copy is found by the
+// price as stored, which is read from free_accommodation.
+func (rr *ReservationRepo) DeleteAvl(ctx context.Context, accommodationID, id, country string) (*domain.FreeReservation, error) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteAvl")
 	defer span.End()
 
+	var price int
+	var dateRange []string
+	err := rr.session.Query(`SELECT price, date_range FROM free_accommodation WHERE accommodation_id = ? AND country = ? AND id = ?`,
+		accommodationID, country, id).Scan(&price, &dateRange)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "Availability not found")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to read availability, database error")
+	}
+
 	batch := rr.session.NewBatch(gocql.LoggedBatch)
 
 	batch.Query(`DELETE FROM free_accommodation WHERE accommodation_id = ? AND country = ? AND id = ?`, accommodationID, country, id)
 	batch.Query(`DELETE FROM avl_by_price WHERE is_active = ? AND price = ? AND id = ?`, true, price, id)
-
-	if err := rr.session.ExecuteBatch(batch); err != nil {
+	availabilityID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		dateRange = nil
+	}
+	err = rr.executeWithNights(batch, dateRange, func(batch *gocql.Batch, chunk []string) {
+		removeAvailabilityNights(batch, accommodationID, availabilityID, chunk)
+	})
+	if err != nil {
 		rr.logger.LogError("reservationsRepo", err.Error())
 		return nil, errors.NewReservationError(500, "Unable to delete availability")
 	}
@@ -646,22 +746,165 @@ func (rr *ReservationRepo) DeleteAvl(ctx context.Context, accommodationID, id, c
 
 }
 
-func (rr *ReservationRepo) GetAccommodationIDsByMaxPrice(ctx context.Context, maxPrice int) ([]string, *errors.ReservationError) {
-	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetAccommodationIDsByMaxPrice")
+// MarkRolledBack records that the creation of the accommodation was rolled back.
+func (rr *ReservationRepo) MarkRolledBack(ctx context.Context, accommodationID string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.MarkRolledBack")
+	defer span.End()
+	err := rr.session.Query(`INSERT INTO rolled_back_accommodations (accommodation_id, rolled_back_at) VALUES(?, ?)`,
+		accommodationID, time.Now()).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to record the rollback, database error")
+	}
+	return nil
+}
+
+// IsRolledBack reports whether the creation of the accommodation was rolled back.
+func (rr *ReservationRepo) IsRolledBack(ctx context.Context, accommodationID string) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.IsRolledBack")
+	defer span.End()
+	var id string
+	err := rr.session.Query(`SELECT accommodation_id FROM rolled_back_accommodations WHERE accommodation_id = ?`, accommodationID).Scan(&id)
+	if err == gocql.ErrNotFound {
+		return false, nil
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to read rollbacks, database error")
+	}
+	return true, nil
+}
+
+// DeleteAvailabilityForAccommodation removes every free_accommodation row of the
+// accommodation together with its avl_by_price copy and its nights.
+func (rr *ReservationRepo) DeleteAvailabilityForAccommodation(ctx context.Context, accommodationID string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteAvailabilityForAccommodation")
+	defer span.End()
+
+	iter := rr.session.Query(`SELECT id, country, price FROM free_accommodation WHERE accommodation_id = ?`, accommodationID).Iter()
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	var id gocql.UUID
+	var country string
+	var price int
+	for iter.Scan(&id, &country, &price) {
+		batch.Query(`DELETE FROM free_accommodation WHERE accommodation_id = ? AND country = ? AND id = ?`, accommodationID, country, id)
+		batch.Query(`DELETE FROM avl_by_price WHERE is_active = ? AND price = ? AND id = ?`, true, price, id)
+	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read availability, database error")
+	}
+	if batch.Size() == 0 {
+		return nil
+	}
+	batch.Query(`DELETE FROM availability_nights WHERE accommodation_id = ?`, accommodationID)
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to delete availability")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Deleted availability of accommodation: %v", accommodationID))
+	return nil
+}
+
+// ArchiveAvailability moves the availability of a host's accommodations into
+// archived_availability, so it can be restored if deleting the host fails.
+func (rr *ReservationRepo) ArchiveAvailability(ctx context.Context, hostID string, accommodationIDs []string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ArchiveAvailability")
+	defer span.End()
+
+	for _, accommodationID := range accommodationIDs {
+		iter := rr.session.Query(`SELECT id, location, price, currency, rules, continent, country, date_range FROM free_accommodation WHERE accommodation_id = ?`,
+			accommodationID).Iter()
+		batch := rr.session.NewBatch(gocql.LoggedBatch)
+		var id gocql.UUID
+		var location, currency, continent, country string
+		var price int
+		var rules domain.StayRules
+		var dateRange []string
+		for iter.Scan(&id, &location, &price, &currency, &rules, &continent, &country, &dateRange) {
+			batch.Query(`INSERT INTO archived_availability (host_id, id, accommodation_id, location, price, currency, rules, continent, country, date_range)
+				VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`, hostID, id, accommodationID, location, price, currency, rules, continent, country, dateRange)
+			batch.Query(`DELETE FROM free_accommodation WHERE accommodation_id = ? AND country = ? AND id = ?`, accommodationID, country, id)
+			batch.Query(`DELETE FROM avl_by_price WHERE is_active = ? AND price = ? AND id = ?`, true, price, id)
+		}
+		if err := iter.Close(); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to read availability, database error")
+		}
+		if batch.Size() == 0 {
+			continue
+		}
+		batch.Query(`DELETE FROM availability_nights WHERE accommodation_id = ?`, accommodationID)
+		if err := rr.session.ExecuteBatch(batch); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to archive availability")
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Archived availability of host: %v", hostID))
+	return nil
+}
+
+// RestoreAvailability puts the archived availability of a host back. Each window
+// leaves the archive only once it is written, so a restore that fails partway can
+// be repeated.
+func (rr *ReservationRepo) RestoreAvailability(ctx context.Context, hostID string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.RestoreAvailability")
+	defer span.End()
+
+	iter := rr.session.Query(`SELECT id, accommodation_id, location, price, currency, rules, continent, country, date_range FROM archived_availability WHERE host_id = ?`,
+		hostID).Iter()
+	var id gocql.UUID
+	var accommodationID, location, currency, continent, country string
+	var price int
+	var rules domain.StayRules
+	var dateRange []string
+	for iter.Scan(&id, &accommodationID, &location, &price, &currency, &rules, &continent, &country, &dateRange) {
+		batch := rr.session.NewBatch(gocql.LoggedBatch)
+		batch.Query(`INSERT INTO free_accommodation (id, accommodation_id, location, price, currency, rules, continent, country, date_range)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)`, id, accommodationID, location, price, currency, rules, continent, country, dateRange)
+		batch.Query(`INSERT INTO avl_by_price (id, accommodation_id, location, price, currency, continent, country, is_active)
+			VALUES(?, ?, ?, ?, ?, ?, ?, ?)`, id, accommodationID, location, price, currency, continent, country, true)
+		err := rr.executeWithNights(batch, dateRange, func(batch *gocql.Batch, chunk []string) {
+			addAvailabilityNights(batch, accommodationID, id, chunk)
+		})
+		if err == nil {
+			err = rr.session.Query(`DELETE FROM archived_availability WHERE host_id = ? AND accommodation_id = ? AND id = ?`, hostID, accommodationID, id).Exec()
+		}
+		if err != nil {
+			iter.Close()
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to restore availability")
+		}
+	}
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read archived availability, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Restored availability of host: %v", hostID))
+	return nil
+}
+
+// GetAvailabilityPricesBelow returns the nightly rate of every availability window
+// stored with a price of at most bound, whatever its currency.
+func (rr *ReservationRepo) GetAvailabilityPricesBelow(ctx context.Context, bound int) ([]domain.AvailabilityPrice, *errors.ReservationError) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetAvailabilityPricesBelow")
 	defer span.End()
 	scanner := rr.session.Query(`
-        SELECT accommodation_id FROM avl_by_price WHERE is_active = ? AND price <= ?
-    `, true, maxPrice).Iter().Scanner()
+        SELECT accommodation_id, price, currency FROM avl_by_price WHERE is_active = ? AND price <= ?
+    `, true, bound).Iter().Scanner()
 
-	var accommodationIDs []string
+	var prices []domain.AvailabilityPrice
 	for scanner.Next() {
-		var accommodationID string
-		err := scanner.Scan(&accommodationID)
+		var availabilityPrice domain.AvailabilityPrice
+		var price int
+		var currency string
+		err := scanner.Scan(&availabilityPrice.AccommodationID, &price, &currency)
 		if err != nil {
 			rr.logger.LogError("reservationsRepo", err.Error())
 			return nil, errors.NewReservationError(500, "Unable to retrive the data")
 		}
-		accommodationIDs = append(accommodationIDs, accommodationID)
+		availabilityPrice.Price = domain.StoredMoney(price, currency)
+		prices = append(prices, availabilityPrice)
 
 	}
 
@@ -669,42 +912,487 @@ func (rr *ReservationRepo) GetAccommodationIDsByMaxPrice(ctx context.Context, ma
 		rr.logger.LogError("reservationsRepo", erro.Error())
 		return nil, errors.NewReservationError(500, "Unable to retrive the data")
 	}
-	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found accommodations by price: %v", accommodationIDs))
-	return accommodationIDs, nil
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found availability prices below: %d", bound))
+	return prices, nil
 }
 
+// AvailabilityNotInDateRange returns the accommodations without availability on
+// any of the nights.
 func (rr *ReservationRepo) AvailabilityNotInDateRange(ctx context.Context, accommodationIDs []string, dateRange []string) ([]string, *errors.ReservationError) {
 	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.AvailabilityNotInDateRange")
 	defer span.End()
-	uniqueAccommodationIDs := make(map[string]struct{})
-
+	result := make([]string, 0)
+	if len(dateRange) == 0 {
+		return result, nil
+	}
 	for _, accommodationID := range accommodationIDs {
-		query := `SELECT accommodation_id FROM free_accommodation WHERE accommodation_id = ? AND date_range CONTAINS ?`
+		windows, err := rr.availabilityOfNights(ctx, accommodationID, dateRange)
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to retrieve availability, database error")
+		}
+		if len(windows) == 0 {
+			result = append(result, accommodationID)
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found availabilities that are not in date range by accommodationIDs and dateRange: %v", result))
+	return result, nil
+}
+
+// PlaceHold keeps the dates of a reservation in progress from being booked by
+// anyone else. The hold expires by itself after ttl.
+func (rr *ReservationRepo) PlaceHold(ctx context.Context, reservationID gocql.UUID, accommodationID, userID string, dateRange []string, ttl time.Duration) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.PlaceHold")
+	defer span.End()
+	err := rr.session.Query(`INSERT INTO reservation_holds (accommodation_id, reservation_id, user_id, date_range, expires_at)
+		VALUES(?, ?, ?, ?, ?) USING TTL ?`,
+		accommodationID, reservationID, userID, dateRange, time.Now().Add(ttl), int(ttl.Seconds())).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to hold the dates")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Placed hold %v on accommodation %v", reservationID, accommodationID))
+	return nil
+}
+
+func (rr *ReservationRepo) ReleaseHold(ctx context.Context, accommodationID string, reservationID gocql.UUID) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReleaseHold")
+	defer span.End()
+	err := rr.session.Query(`DELETE FROM reservation_holds WHERE accommodation_id = ? AND reservation_id = ?`,
+		accommodationID, reservationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to release the hold")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Released hold %v on accommodation %v", reservationID, accommodationID))
+	return nil
+}
 
-		isInDateRange := false
+// ReservationExists reports whether the reservation was already inserted, so a
+// redelivered confirmation does not insert it twice.
+func (rr *ReservationRepo) ReservationExists(ctx context.Context, userID string, id gocql.UUID) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReservationExists")
+	defer span.End()
+	var count int
+	err := rr.session.Query(`SELECT COUNT(*) FROM reservation_by_user WHERE user_id = ? AND id = ?`, userID, id).Scan(&count)
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to check reservation, database error")
+	}
+	return count > 0, nil
+}
 
-		for _, date := range dateRange {
-			iter := rr.session.Query(query, accommodationID, date).Iter()
+// RemoveReservation deletes a reservation whose saga failed after it was inserted.
+// Unlike DeleteById it is not a cancellation, so deleted_reservations is left alone.
+func (rr *ReservationRepo) RemoveReservation(ctx context.Context, reservation *domain.Reservation) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.RemoveReservation")
+	defer span.End()
+	country, err := utils.GetCountry(reservation.Location)
+	if err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	continent, err := utils.GetContinent(reservation.Location)
+	if err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	endDate := reservation.DateRange[len(reservation.DateRange)-1]
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM reservations WHERE continent = ? AND country = ? AND id = ?`, continent, country, reservation.Id)
+	batch.Query(`DELETE FROM reservation_by_user WHERE user_id = ? AND id = ?`, reservation.UserID, reservation.Id)
+	batch.Query(`DELETE FROM reservation_by_host WHERE host_id = ? AND user_id = ? AND end_date = ? AND id = ?`, reservation.HostID, reservation.UserID, endDate, reservation.Id)
+	batch.Query(`DELETE FROM reservation_by_accommodation WHERE accommodation_id = ? AND user_id = ? AND end_date = ? AND id = ?`, reservation.AccommodationID, reservation.UserID, endDate, reservation.Id)
+	if err := rr.executeWithNights(batch, reservation.DateRange, func(batch *gocql.Batch, chunk []string) {
+		removeReservationNights(batch, reservation.AccommodationID, reservation.Id, chunk)
+	}); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to remove the reservation")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Removed reservation: %v", reservation.Id))
+	return nil
+}
 
-			var result string
-			if iter.Scan(&result) {
-				isInDateRange = true
-			}
+// ClaimNights claims every night of a reservation with a lightweight transaction,
+// so two reservations can never hold the same night. The nights are claimed in
+// order and, when one is already claimed by another reservation, the nights
+// claimed so far are released and false is returned. Claims expire after ttl
+// unless ConfirmNights makes them permanent.
+func (rr *ReservationRepo) ClaimNights(ctx context.Context, accommodationID string, reservationID gocql.UUID, nights []string, ttl time.Duration) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ClaimNights")
+	defer span.End()
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	var claimed []string
+	for _, night := range sorted {
+		existing := map[string]interface{}{}
+		applied, err := rr.session.Query(`INSERT INTO night_claims (accommodation_id, night, reservation_id)
+			VALUES(?, ?, ?) IF NOT EXISTS USING TTL ?`,
+			accommodationID, night, reservationID, int(ttl.Seconds())).MapScanCAS(existing)
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			rr.releaseClaimed(ctx, accommodationID, reservationID, claimed)
+			return false, errors.NewReservationError(500, "Unable to claim the dates, database error")
+		}
+		if !applied && existing["reservation_id"] != reservationID {
+			rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Night %s of accommodation %v already claimed", night, accommodationID))
+			rr.releaseClaimed(ctx, accommodationID, reservationID, claimed)
+			return false, nil
+		}
+		claimed = append(claimed, night)
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Claimed %d nights of accommodation %v for %v", len(claimed), accommodationID, reservationID))
+	return true, nil
+}
+
+// ConfirmNights removes the expiry of the claims of a reservation. It returns false
+// when a claim expired before the reservation could be confirmed.
+func (rr *ReservationRepo) ConfirmNights(ctx context.Context, accommodationID string, reservationID gocql.UUID, nights []string) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ConfirmNights")
+	defer span.End()
+	for _, night := range nights {
+		applied, err := rr.session.Query(`UPDATE night_claims USING TTL 0 SET reservation_id = ?
+			WHERE accommodation_id = ? AND night = ? IF reservation_id = ?`,
+			reservationID, accommodationID, night, reservationID).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return false, errors.NewReservationError(500, "Unable to confirm the dates, database error")
+		}
+		if !applied {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// TakeOverNights hands the nights claimed by from over to to, with a new TTL. It
+// returns false when a night is not claimed by from anymore; the nights before it
+// stay handed over.
+func (rr *ReservationRepo) TakeOverNights(ctx context.Context, accommodationID string, from, to gocql.UUID, nights []string, ttl time.Duration) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.TakeOverNights")
+	defer span.End()
+	for _, night := range nights {
+		applied, err := rr.session.Query(`UPDATE night_claims USING TTL ? SET reservation_id = ?
+			WHERE accommodation_id = ? AND night = ? IF reservation_id = ?`,
+			int(ttl.Seconds()), to, accommodationID, night, from).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return false, errors.NewReservationError(500, "Unable to take over the dates, database error")
+		}
+		if !applied {
+			return false, nil
+		}
+	}
+	return true, nil
+}
 
-			if err := iter.Close(); err != nil {
+// ReleaseNights frees the nights claimed by a reservation. Nights claimed by other
+// reservations in the meantime are left alone.
+func (rr *ReservationRepo) ReleaseNights(ctx context.Context, accommodationID string, reservationID gocql.UUID, nights []string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ReleaseNights")
+	defer span.End()
+	for _, night := range nights {
+		_, err := rr.session.Query(`DELETE FROM night_claims WHERE accommodation_id = ? AND night = ? IF reservation_id = ?`,
+			accommodationID, night, reservationID).MapScanCAS(map[string]interface{}{})
+		if err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to release the dates")
+		}
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Released nights of accommodation %v claimed by %v", accommodationID, reservationID))
+	return nil
+}
+
+// releaseClaimed rolls back a partial claim. A night it fails to release still
+// expires with the claim TTL.
+func (rr *ReservationRepo) releaseClaimed(ctx context.Context, accommodationID string, reservationID gocql.UUID, nights []string) {
+	if len(nights) == 0 {
+		return
+	}
+	if err := rr.ReleaseNights(ctx, accommodationID, reservationID, nights); err != nil {
+		rr.logger.LogError("reservationsRepo", fmt.Sprintf("Unable to roll back claimed nights of %v", reservationID))
+	}
+}
+
+// GetReservation returns a reservation of the user.
+func (rr *ReservationRepo) GetReservation(ctx context.Context, userID string, id gocql.UUID) (*domain.Reservation, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservation")
+	defer span.End()
+	var reservation domain.Reservation
+	err := rr.session.Query(`SELECT id,accommodation_id, user_id, start_date, end_date,username,accommodation_name,location,price,
+	num_of_days,date_range,is_active,country,host_id,guests,party,state,state_changed_at,refund FROM reservation_by_user
+	 WHERE user_id = ? AND id = ?`, userID, id).
+		Scan(&reservation.Id, &reservation.AccommodationID, &reservation.UserID, &reservation.StartDate,
+			&reservation.EndDate, &reservation.Username, &reservation.AccommodationName, &reservation.Location, &reservation.Price,
+			&reservation.NumberOfDays, &reservation.DateRange, &reservation.IsActive, &reservation.Country, &reservation.HostID,
+			&reservation.Guests, &reservation.Party, &reservation.State, &reservation.StateChangedAt, &reservation.Refund)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "Reservation not found")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get reservation, database error")
+	}
+	return &reservation, nil
+}
+
+// GetBookingMode returns the booking mode of an accommodation. Accommodations the
+// host never configured are booked instantly.
+func (rr *ReservationRepo) GetBookingMode(ctx context.Context, accommodationID string) (*domain.BookingMode, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetBookingMode")
+	defer span.End()
+	mode := domain.BookingMode{AccommodationID: accommodationID}
+	err := rr.session.Query(`SELECT host_id, request_to_book FROM booking_modes WHERE accommodation_id = ?`, accommodationID).
+		Scan(&mode.HostID, &mode.RequestToBook)
+	if err != nil && err != gocql.ErrNotFound {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get booking mode, database error")
+	}
+	return &mode, nil
+}
+
+func (rr *ReservationRepo) SaveBookingMode(ctx context.Context, mode *domain.BookingMode) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveBookingMode")
+	defer span.End()
+	err := rr.session.Query(`INSERT INTO booking_modes (accommodation_id, host_id, request_to_book) VALUES(?, ?, ?)`,
+		mode.AccommodationID, mode.HostID, mode.RequestToBook).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save booking mode, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Accommodation %v request to book: %v", mode.AccommodationID, mode.RequestToBook))
+	return nil
+}
+
+func (rr *ReservationRepo) InsertRequest(ctx context.Context, request *domain.ReservationRequest) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.InsertRequest")
+	defer span.End()
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`INSERT INTO reservation_requests (host_id, id, user_id, accommodation_id, expires_at)
+		VALUES(?, ?, ?, ?, ?)`,
+		request.HostID, request.Id, request.UserID, request.AccommodationID, request.ExpiresAt)
+	addRequestDeadline(batch, request)
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save reservation request, database error")
+	}
+	return nil
+}
+
+func (rr *ReservationRepo) GetRequest(ctx context.Context, hostID string, id gocql.UUID) (*domain.ReservationRequest, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetRequest")
+	defer span.End()
+	request := domain.ReservationRequest{HostID: hostID, Id: id}
+	err := rr.session.Query(`SELECT user_id, accommodation_id, expires_at FROM reservation_requests WHERE host_id = ? AND id = ?`,
+		hostID, id).Scan(&request.UserID, &request.AccommodationID, &request.ExpiresAt)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "Reservation request not found")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get reservation request, database error")
+	}
+	return &request, nil
+}
+
+// DeleteRequest removes the request together with its deadline. Deleting a request
+// that does not exist is not an error.
+func (rr *ReservationRepo) DeleteRequest(ctx context.Context, hostID string, id gocql.UUID) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteRequest")
+	defer span.End()
+	var expiresAt time.Time
+	err := rr.session.Query(`SELECT expires_at FROM reservation_requests WHERE host_id = ? AND id = ?`,
+		hostID, id).Scan(&expiresAt)
+	if err == gocql.ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to delete reservation request, database error")
+	}
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM reservation_requests WHERE host_id = ? AND id = ?`, hostID, id)
+	batch.Query(`DELETE FROM request_deadlines WHERE day = ? AND expires_at = ? AND id = ?`,
+		deadlineDay(expiresAt), expiresAt, id)
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to delete reservation request, database error")
+	}
+	return nil
+}
+
+// GetExpiredRequests returns the requests the hosts did not answer in time. It
+// reads the deadlines of the days from requestDeadlineLookback ago up to now, so
+// requests left behind while the service was down longer than that are not found.
+func (rr *ReservationRepo) GetExpiredRequests(ctx context.Context, now time.Time) ([]domain.ReservationRequest, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetExpiredRequests")
+	defer span.End()
+	var requests []domain.ReservationRequest
+	for day := now.Add(-requestDeadlineLookback); !day.After(now); day = day.AddDate(0, 0, 1) {
+		scanner := rr.session.Query(`SELECT host_id, id, user_id, accommodation_id, expires_at FROM request_deadlines
+			WHERE day = ? AND expires_at <= ?`, deadlineDay(day), now).Iter().Scanner()
+		for scanner.Next() {
+			var request domain.ReservationRequest
+			if err := scanner.Scan(&request.HostID, &request.Id, &request.UserID, &request.AccommodationID, &request.ExpiresAt); err != nil {
 				rr.logger.LogError("reservationsRepo", err.Error())
-				return nil, errors.NewReservationError(500, "Unable to retrieve availability, database error")
+				return nil, errors.NewReservationError(500, "Unable to get expired requests, database error")
 			}
+			requests = append(requests, request)
 		}
-		if !isInDateRange {
-			uniqueAccommodationIDs[accommodationID] = struct{}{}
+		if err := scanner.Err(); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get expired requests, database error")
 		}
 	}
+	return requests, nil
+}
 
-	result := make([]string, 0, len(uniqueAccommodationIDs))
-	for key := range uniqueAccommodationIDs {
-		result = append(result, key)
+// migrateRequestDeadlines adds the deadlines of the requests stored before
+// request_deadlines existed.
+func (rr *ReservationRepo) migrateRequestDeadlines(ctx context.Context) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.migrateRequestDeadlines")
+	defer span.End()
+	migrated := 0
+	iter := rr.session.Query(`SELECT host_id, id, user_id, accommodation_id, expires_at FROM reservation_requests`).Iter()
+	var request domain.ReservationRequest
+	for iter.Scan(&request.HostID, &request.Id, &request.UserID, &request.AccommodationID, &request.ExpiresAt) {
+		batch := rr.session.NewBatch(gocql.UnloggedBatch)
+		addRequestDeadline(batch, &request)
+		if err := rr.session.ExecuteBatch(batch); err != nil {
+			iter.Close()
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return errors.NewReservationError(500, "Unable to migrate request deadlines")
+		}
+		migrated++
 	}
-	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Found availabilities that are not in date range by accommodationIDs and dateRange: %v", result))
-	return result, nil
+	if err := iter.Close(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to read reservation requests, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Migrated the deadlines of %d reservation requests", migrated))
+	return nil
+}
+
+func addRequestDeadline(batch *gocql.Batch, request *domain.ReservationRequest) {
+	batch.Query(`INSERT INTO request_deadlines (day, expires_at, id, host_id, user_id, accommodation_id)
+		VALUES(?, ?, ?, ?, ?, ?)`,
+		deadlineDay(request.ExpiresAt), request.ExpiresAt, request.Id, request.HostID, request.UserID, request.AccommodationID)
+}
+
+// deadlineDay is the request_deadlines partition of a deadline.
+func deadlineDay(expiresAt time.Time) string {
+	return expiresAt.UTC().Format("2006-01-02")
+}
+
+// GetCancellationPolicy returns the cancellation policy of an accommodation, or the
+// default policy when none was set. Tiers are stored as days before check-in to
+// refund percent.
+func (rr *ReservationRepo) GetCancellationPolicy(ctx context.Context, accommodationID string) (*domain.CancellationPolicy, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetCancellationPolicy")
+	defer span.End()
+	var name string
+	var tiers map[int]int
+	err := rr.session.Query(`SELECT name, tiers FROM cancellation_policies WHERE accommodation_id = ?`, accommodationID).
+		Scan(&name, &tiers)
+	if err == gocql.ErrNotFound {
+		return domain.DefaultCancellationPolicy(accommodationID), nil
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get cancellation policy, database error")
+	}
+	policy := &domain.CancellationPolicy{AccommodationID: accommodationID, Name: name}
+	for days, percent := range tiers {
+		policy.Tiers = append(policy.Tiers, domain.RefundTier{DaysBeforeCheckIn: days, RefundPercent: percent})
+	}
+	policy.Tiers = policy.RefundTiers()
+	return policy, nil
+}
+
+func (rr *ReservationRepo) SaveCancellationPolicy(ctx context.Context, policy *domain.CancellationPolicy) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveCancellationPolicy")
+	defer span.End()
+	tiers := make(map[int]int, len(policy.Tiers))
+	for _, tier := range policy.Tiers {
+		tiers[tier.DaysBeforeCheckIn] = tier.RefundPercent
+	}
+	err := rr.session.Query(`INSERT INTO cancellation_policies (accommodation_id, name, tiers) VALUES(?, ?, ?)`,
+		policy.AccommodationID, policy.Name, tiers).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save cancellation policy, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Accommodation %v has cancellation policy %s", policy.AccommodationID, policy.Name))
+	return nil
+}
+
+// GetPricing returns the pricing of an accommodation. Accommodations without one
+// are paid per accommodation, with no fee or discounts.
+func (rr *ReservationRepo) GetPricing(ctx context.Context, accommodationID string) (*domain.Pricing, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetPricing")
+	defer span.End()
+	pricing := domain.DefaultPricing(accommodationID)
+	var hostID, paying string
+	err := rr.session.Query(`SELECT host_id, paying, cleaning_fee, weekly_discount, monthly_discount FROM pricing WHERE accommodation_id = ?`,
+		accommodationID).Scan(&hostID, &paying, &pricing.CleaningFee, &pricing.WeeklyDiscountPercent, &pricing.MonthlyDiscountPercent)
+	if err != nil && err != gocql.ErrNotFound {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get pricing, database error")
+	}
+	pricing.HostID = hostID
+	if paying != "" {
+		pricing.Paying = paying
+	}
+	return pricing, nil
+}
+
+// SavePaying records how an accommodation is paid, leaving what the host set alone.
+func (rr *ReservationRepo) SavePaying(ctx context.Context, accommodationID, paying string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SavePaying")
+	defer span.End()
+	err := rr.session.Query(`UPDATE pricing SET paying = ? WHERE accommodation_id = ?`, paying, accommodationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save pricing, database error")
+	}
+	return nil
+}
+
+// SaveFees records the cleaning fee and discounts the host set, leaving the paying
+// mode alone.
+func (rr *ReservationRepo) SaveFees(ctx context.Context, pricing *domain.Pricing) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveFees")
+	defer span.End()
+	err := rr.session.Query(`UPDATE pricing SET host_id = ?, cleaning_fee = ?, weekly_discount = ?, monthly_discount = ? WHERE accommodation_id = ?`,
+		pricing.HostID, pricing.CleaningFee, pricing.WeeklyDiscountPercent, pricing.MonthlyDiscountPercent, pricing.AccommodationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save pricing, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Updated pricing of accommodation %v", pricing.AccommodationID))
+	return nil
+}
+
+// PriceWindows returns the availability windows of an accommodation with their
+// nightly rates and stay rules.
+func (rr *ReservationRepo) PriceWindows(ctx context.Context, accommodationID string) ([]domain.DateRangeWithPrice, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.PriceWindows")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT date_range, price, currency, rules FROM free_accommodation WHERE accommodation_id = ?`, accommodationID).
+		Iter().Scanner()
+	var windows []domain.DateRangeWithPrice
+	for scanner.Next() {
+		var window domain.DateRangeWithPrice
+		var price int
+		var currency string
+		if err := scanner.Scan(&window.DateRange, &price, &currency, &window.Rules); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get availability, database error")
+		}
+		window.Price = domain.StoredMoney(price, currency)
+		windows = append(windows, window)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get availability, database error")
+	}
+	return windows, nil
 }
@@ -0,0 +1,112 @@
+package repository
+
+import (
+	"encoding/json"
+	"example/saga/engine"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// SagaStore keeps the sagas orchestrated by reservations-service in Cassandra. The
+// version check of engine.Store is done with lightweight transactions.
+type SagaStore struct {
+	session  *gocql.Session
+	sagaType string
+}
+
+func NewSagaStore(repo *ReservationRepo, sagaType string) engine.Store {
+	return &SagaStore{
+		session:  repo.session,
+		sagaType: sagaType,
+	}
+}
+
+func (s *SagaStore) Save(instance *engine.Instance) error {
+	data, err := json.Marshal(instance)
+	if err != nil {
+		return err
+	}
+	expected := instance.Version
+	var applied bool
+	if expected == 0 {
+		applied, err = s.session.Query(`INSERT INTO sagas (saga_type, id, state, payload, instance, version)
+			VALUES(?, ?, ?, ?, ?, ?) IF NOT EXISTS`,
+			s.sagaType, instance.ID, string(instance.State), instance.Payload, string(data), expected+1).
+			MapScanCAS(map[string]interface{}{})
+	} else {
+		applied, err = s.session.Query(`UPDATE sagas SET state = ?, payload = ?, instance = ?, version = ?
+			WHERE saga_type = ? AND id = ? IF version = ?`,
+			string(instance.State), instance.Payload, string(data), expected+1, s.sagaType, instance.ID, expected).
+			MapScanCAS(map[string]interface{}{})
+	}
+	if err != nil {
+		return err
+	}
+	if !applied {
+		return engine.ErrConflict
+	}
+	instance.Version = expected + 1
+	return nil
+}
+
+func (s *SagaStore) Get(id string) (*engine.Instance, error) {
+	var payload []byte
+	var data string
+	var version int
+	err := s.session.Query(`SELECT payload, instance, version FROM sagas WHERE saga_type = ? AND id = ?`,
+		s.sagaType, id).Consistency(gocql.Quorum).Scan(&payload, &data, &version)
+	if err == gocql.ErrNotFound {
+		return nil, engine.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return decodeInstance(payload, data, version)
+}
+
+func (s *SagaStore) FindActive(sagaType string) ([]*engine.Instance, error) {
+	return s.find(sagaType, func(instance *engine.Instance) bool {
+		return instance.IsActive()
+	})
+}
+
+func (s *SagaStore) FindExpired(sagaType string, now time.Time) ([]*engine.Instance, error) {
+	return s.find(sagaType, func(instance *engine.Instance) bool {
+		return instance.IsActive() && !instance.Deadline.IsZero() && !instance.Deadline.After(now)
+	})
+}
+
+// find reads the whole partition of a saga type. Finished sagas stay in it, which
+// is fine for the number of reservations made here.
+func (s *SagaStore) find(sagaType string, keep func(instance *engine.Instance) bool) ([]*engine.Instance, error) {
+	iter := s.session.Query(`SELECT payload, instance, version FROM sagas WHERE saga_type = ?`, sagaType).Iter()
+	var instances []*engine.Instance
+	var payload []byte
+	var data string
+	var version int
+	for iter.Scan(&payload, &data, &version) {
+		instance, err := decodeInstance(payload, data, version)
+		if err != nil {
+			iter.Close()
+			return nil, err
+		}
+		if keep(instance) {
+			instances = append(instances, instance)
+		}
+	}
+	if err := iter.Close(); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func decodeInstance(payload []byte, data string, version int) (*engine.Instance, error) {
+	var instance engine.Instance
+	if err := json.Unmarshal([]byte(data), &instance); err != nil {
+		return nil, err
+	}
+	instance.Payload = append([]byte(nil), payload...)
+	instance.Version = version
+	return &instance, nil
+}
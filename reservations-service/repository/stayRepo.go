@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func (rr *ReservationRepo) GetArrivalInstructions(ctx context.Context, accommodationID string) (*domain.ArrivalInstructions, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetArrivalInstructions")
+	defer span.End()
+	instructions := domain.ArrivalInstructions{AccommodationID: accommodationID}
+	err := rr.session.Query(`SELECT check_in_time, instructions, access_code, house_rules, notice_hours FROM arrival_instructions WHERE accommodation_id = ?`,
+		accommodationID).Scan(&instructions.CheckInTime, &instructions.Instructions, &instructions.AccessCode, &instructions.HouseRules, &instructions.NoticeHours)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "The accommodation has no arrival instructions")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get arrival instructions, database error")
+	}
+	return &instructions, nil
+}
+
+func (rr *ReservationRepo) SaveArrivalInstructions(ctx context.Context, instructions *domain.ArrivalInstructions) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.SaveArrivalInstructions")
+	defer span.End()
+	err := rr.session.Query(`INSERT INTO arrival_instructions (accommodation_id, check_in_time, instructions, access_code, house_rules, notice_hours)
+		VALUES(?, ?, ?, ?, ?, ?)`,
+		instructions.AccommodationID, instructions.CheckInTime, instructions.Instructions, instructions.AccessCode, instructions.HouseRules,
+		instructions.NoticeHours).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to save arrival instructions, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("Saved arrival instructions of accommodation %v", instructions.AccommodationID))
+	return nil
+}
+
+func (rr *ReservationRepo) DeleteArrivalInstructions(ctx context.Context, accommodationID string) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteArrivalInstructions")
+	defer span.End()
+	err := rr.session.Query(`DELETE FROM arrival_instructions WHERE accommodation_id = ?`, accommodationID).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to delete arrival instructions, database error")
+	}
+	return nil
+}
+
+// GetAllArrivalInstructions returns the arrival instructions of every accommodation
+// that has them. They are one per accommodation at most, so they are scanned
+// across all accommodations.
+func (rr *ReservationRepo) GetAllArrivalInstructions(ctx context.Context) ([]domain.ArrivalInstructions, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetAllArrivalInstructions")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT accommodation_id, check_in_time, instructions, access_code, house_rules, notice_hours FROM arrival_instructions`).
+		Iter().Scanner()
+	var all []domain.ArrivalInstructions
+	for scanner.Next() {
+		var instructions domain.ArrivalInstructions
+		if err := scanner.Scan(&instructions.AccommodationID, &instructions.CheckInTime, &instructions.Instructions, &instructions.AccessCode,
+			&instructions.HouseRules, &instructions.NoticeHours); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get arrival instructions, database error")
+		}
+		all = append(all, instructions)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get arrival instructions, database error")
+	}
+	return all, nil
+}
+
+// ArrivingReservations returns the confirmed reservations of an accommodation
+// whose stay starts from from to to, both included.
+func (rr *ReservationRepo) ArrivingReservations(ctx context.Context, accommodationID, from, to string) ([]domain.Reservation, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ArrivingReservations")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT id, user_id, start_date, end_date, accommodation_name, state FROM reservation_by_accommodation WHERE accommodation_id = ?`,
+		accommodationID).Iter().Scanner()
+	var reservations []domain.Reservation
+	for scanner.Next() {
+		reservation := domain.Reservation{AccommodationID: accommodationID}
+		if err := scanner.Scan(&reservation.Id, &reservation.UserID, &reservation.StartDate, &reservation.EndDate, &reservation.AccommodationName,
+			&reservation.State); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+		}
+		if reservation.State == domain.Confirmed && reservation.StartDate >= from && reservation.StartDate <= to {
+			reservations = append(reservations, reservation)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+	}
+	return reservations, nil
+}
+
+// ClaimArrivalNotice records that the guest of the reservation is sent the arrival
+// instructions and reports whether nobody claimed that before, so each guest is
+// sent them once.
+func (rr *ReservationRepo) ClaimArrivalNotice(ctx context.Context, reservationID gocql.UUID, sentAt time.Time) (bool, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.ClaimArrivalNotice")
+	defer span.End()
+	applied, err := rr.session.Query(`INSERT INTO arrival_notices (reservation_id, sent_at) VALUES(?, ?) IF NOT EXISTS`,
+		reservationID, sentAt).MapScanCAS(map[string]interface{}{})
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return false, errors.NewReservationError(500, "Unable to record arrival notice, database error")
+	}
+	return applied, nil
+}
+
+// GetReservationOfHost returns a reservation of one of the host's accommodations.
+func (rr *ReservationRepo) GetReservationOfHost(ctx context.Context, hostID string, id gocql.UUID) (*domain.Reservation, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetReservationOfHost")
+	defer span.End()
+	var userID string
+	err := rr.session.Query(`SELECT user_id FROM reservation_by_host WHERE host_id = ? AND id = ? ALLOW FILTERING`, hostID, id).Scan(&userID)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "Reservation not found")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get reservation, database error")
+	}
+	return rr.GetReservation(ctx, userID, id)
+}
+
+// GetEndedStays returns the confirmed and checked in reservations whose stay ended
+// on today or earlier. Open stays are few, so they are scanned across all guests.
+func (rr *ReservationRepo) GetEndedStays(ctx context.Context, today string) ([]domain.Reservation, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetEndedStays")
+	defer span.End()
+	type reservationKey struct {
+		userID string
+		id     gocql.UUID
+	}
+	var keys []reservationKey
+	for _, state := range []domain.ReservationState{domain.Confirmed, domain.CheckedIn} {
+		scanner := rr.session.Query(`SELECT user_id, id FROM reservation_by_user WHERE state = ? AND end_date <= ? ALLOW FILTERING`, state, today).Iter().Scanner()
+		for scanner.Next() {
+			var key reservationKey
+			if err := scanner.Scan(&key.userID, &key.id); err != nil {
+				rr.logger.LogError("reservationsRepo", err.Error())
+				return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+			}
+			keys = append(keys, key)
+		}
+		if err := scanner.Err(); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get reservations, database error")
+		}
+	}
+	reservations := make([]domain.Reservation, 0, len(keys))
+	for _, key := range keys {
+		reservation, err := rr.GetReservation(ctx, key.userID, key.id)
+		if err != nil {
+			return nil, err
+		}
+		reservations = append(reservations, *reservation)
+	}
+	return reservations, nil
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+
+	"github.com/gocql/gocql"
+)
+
+const waitlistColumns = `id, user_id, username, accommodation_name, host_id, date_range, guests, state, offer_expires_at`
+
+func scanWaitlistEntry(scan func(dest ...interface{}) error, entry *domain.WaitlistEntry) error {
+	err := scan(&entry.Id, &entry.UserID, &entry.Username, &entry.AccommodationName, &entry.HostID, &entry.DateRange,
+		&entry.Guests, &entry.State, &entry.OfferExpiresAt)
+	if err != nil {
+		return err
+	}
+	entry.JoinedAt = entry.Id.Time()
+	return nil
+}
+
+func (rr *ReservationRepo) InsertWaitlistEntry(ctx context.Context, entry *domain.WaitlistEntry) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.InsertWaitlistEntry")
+	defer span.End()
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`INSERT INTO waitlist (accommodation_id, `+waitlistColumns+`) VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		entry.AccommodationID, entry.Id, entry.UserID, entry.Username, entry.AccommodationName, entry.HostID, entry.DateRange,
+		entry.Guests, entry.State, entry.OfferExpiresAt)
+	batch.Query(`INSERT INTO waitlist_by_user (user_id, id, accommodation_id) VALUES(?, ?, ?)`,
+		entry.UserID, entry.Id, entry.AccommodationID)
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to join waitlist, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("User %v joined the waitlist of accommodation %v", entry.UserID, entry.AccommodationID))
+	return nil
+}
+
+// Waitlist returns the waitlist of an accommodation in the order the guests joined.
+func (rr *ReservationRepo) Waitlist(ctx context.Context, accommodationID string) ([]domain.WaitlistEntry, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.Waitlist")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT `+waitlistColumns+` FROM waitlist WHERE accommodation_id = ?`, accommodationID).Iter().Scanner()
+	var entries []domain.WaitlistEntry
+	for scanner.Next() {
+		entry := domain.WaitlistEntry{AccommodationID: accommodationID}
+		if err := scanWaitlistEntry(scanner.Scan, &entry); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get waitlist, database error")
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get waitlist, database error")
+	}
+	return entries, nil
+}
+
+func (rr *ReservationRepo) GetWaitlistEntry(ctx context.Context, accommodationID string, id gocql.UUID) (*domain.WaitlistEntry, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetWaitlistEntry")
+	defer span.End()
+	entry := domain.WaitlistEntry{AccommodationID: accommodationID}
+	err := scanWaitlistEntry(rr.session.Query(`SELECT `+waitlistColumns+` FROM waitlist WHERE accommodation_id = ? AND id = ?`,
+		accommodationID, id).Scan, &entry)
+	if err == gocql.ErrNotFound {
+		return nil, errors.NewReservationError(404, "Waitlist entry not found")
+	}
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get waitlist entry, database error")
+	}
+	return &entry, nil
+}
+
+// WaitlistOfUser returns the waitlist entries of a guest, latest first.
+func (rr *ReservationRepo) WaitlistOfUser(ctx context.Context, userID string) ([]domain.WaitlistEntry, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.WaitlistOfUser")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT id, accommodation_id FROM waitlist_by_user WHERE user_id = ?`, userID).Iter().Scanner()
+	type entryKey struct {
+		id              gocql.UUID
+		accommodationID string
+	}
+	var keys []entryKey
+	for scanner.Next() {
+		var key entryKey
+		if err := scanner.Scan(&key.id, &key.accommodationID); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get waitlist, database error")
+		}
+		keys = append(keys, key)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get waitlist, database error")
+	}
+	entries := make([]domain.WaitlistEntry, 0, len(keys))
+	for _, key := range keys {
+		entry, err := rr.GetWaitlistEntry(ctx, key.accommodationID, key.id)
+		if err != nil {
+			if reservationErr, ok := err.(*errors.ReservationError); ok && reservationErr.Status == 404 {
+				continue
+			}
+			return nil, err
+		}
+		entries = append(entries, *entry)
+	}
+	return entries, nil
+}
+
+func (rr *ReservationRepo) UpdateWaitlistState(ctx context.Context, entry *domain.WaitlistEntry) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.UpdateWaitlistState")
+	defer span.End()
+	err := rr.session.Query(`UPDATE waitlist SET state = ?, offer_expires_at = ? WHERE accommodation_id = ? AND id = ?`,
+		entry.State, entry.OfferExpiresAt, entry.AccommodationID, entry.Id).Exec()
+	if err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to update waitlist entry, database error")
+	}
+	return nil
+}
+
+func (rr *ReservationRepo) DeleteWaitlistEntry(ctx context.Context, entry *domain.WaitlistEntry) error {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.DeleteWaitlistEntry")
+	defer span.End()
+	batch := rr.session.NewBatch(gocql.LoggedBatch)
+	batch.Query(`DELETE FROM waitlist WHERE accommodation_id = ? AND id = ?`, entry.AccommodationID, entry.Id)
+	batch.Query(`DELETE FROM waitlist_by_user WHERE user_id = ? AND id = ?`, entry.UserID, entry.Id)
+	if err := rr.session.ExecuteBatch(batch); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return errors.NewReservationError(500, "Unable to leave waitlist, database error")
+	}
+	rr.logger.LogInfo("reservationRepo", fmt.Sprintf("User %v left the waitlist of accommodation %v", entry.UserID, entry.AccommodationID))
+	return nil
+}
+
+// GetWaitlistOffers returns the entries the dates are offered to. Open offers are
+// few, so they are scanned across all accommodations.
+func (rr *ReservationRepo) GetWaitlistOffers(ctx context.Context) ([]domain.WaitlistEntry, error) {
+	ctx, span := rr.tracer.Start(ctx, "ReservationRepo.GetWaitlistOffers")
+	defer span.End()
+	scanner := rr.session.Query(`SELECT accommodation_id, id, offer_expires_at FROM waitlist WHERE state = ? ALLOW FILTERING`,
+		domain.Offered).Iter().Scanner()
+	var entries []domain.WaitlistEntry
+	for scanner.Next() {
+		entry := domain.WaitlistEntry{State: domain.Offered}
+		if err := scanner.Scan(&entry.AccommodationID, &entry.Id, &entry.OfferExpiresAt); err != nil {
+			rr.logger.LogError("reservationsRepo", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to get waitlist offers, database error")
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		rr.logger.LogError("reservationsRepo", err.Error())
+		return nil, errors.NewReservationError(500, "Unable to get waitlist offers, database error")
+	}
+	return entries, nil
+}
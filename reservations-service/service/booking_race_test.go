@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reservation-service/calendar"
+	"reservation-service/config"
+	"reservation-service/domain"
+	"reservation-service/exchange"
+	"reservation-service/orchestrator"
+	"reservation-service/repository"
+	"reservation-service/utils"
+	"sync"
+	"testing"
+	"time"
+
+	"example/saga/sagatest"
+
+	"github.com/gocql/gocql"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TestConcurrentReservationsOfTheSameNights books the same nights from many guests
+// at once. Exactly one booking may hold them, and once the saga confirms it the
+// accommodation has one reservation and every claim on the nights is its own, so
+// no refused booking left a claim behind. It needs the Cassandra of a running
+// reservations-service:
+//
+//	CASS_DB=localhost go test ./service -run TestConcurrentReservationsOfTheSameNights
+func TestConcurrentReservationsOfTheSameNights(t *testing.T) {
+	if os.Getenv("CASS_DB") == "" {
+		t.Skip("CASS_DB is not set")
+	}
+	const parallel = 50
+	logger := config.NewLogger(filepath.Join(t.TempDir(), "booking-race.log"))
+	tracer := trace.NewNoopTracerProvider().Tracer("booking-race")
+	repo, err := repository.New(logger, tracer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.CloseSession()
+	migrator, err := repo.Migrator()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing answers the saga commands, so every booking stays held until the
+	// test confirms the one that got the nights.
+	harness := sagatest.NewHarness()
+	reservations, err := orchestrator.NewCreateReservationOrchestrator(
+		harness.CreateReservationCommandPublisher(),
+		harness.CreateReservationReplySubscriber("booking-race"),
+		harness.Store,
+		logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rates, err := exchange.NewStaticProviderFromRates(domain.DefaultCurrency, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewReservationService(repo, utils.NewValidator(), nil, logger, tracer, nil, reservations, time.Hour, time.Hour, rates, calendar.NewSources("", time.Second), nil)
+
+	ctx := context.Background()
+	accommodationID, _ := gocql.RandomUUID()
+	start := time.Now().AddDate(1, 0, 0)
+	nights := []string{
+		start.Format("2006-01-02"),
+		start.AddDate(0, 0, 1).Format("2006-01-02"),
+		start.AddDate(0, 0, 2).Format("2006-01-02"),
+	}
+	location := "Bulevar oslobodjenja 1,Novi Sad,Serbia"
+	_, createErr := s.CreateAvailability(ctx, domain.FreeReservation{
+		AccommodationID: accommodationID.String(),
+		Location:        location,
+		DateRange:       []domain.DateRangeWithPrice{{DateRange: nights, Price: domain.WholeUnits(100, domain.DefaultCurrency)}},
+	})
+	if createErr != nil {
+		t.Fatal(createErr.Message)
+	}
+	defer s.DeleteAvailabilityForAccommodation(ctx, accommodationID.String())
+
+	guest := func(i int) string {
+		return fmt.Sprintf("booking-race-%s-%d", accommodationID, i)
+	}
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		winners []*domain.Reservation
+	)
+	ready := make(chan struct{})
+	for i := 0; i < parallel; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-ready
+			reservation, err := s.CreateReservation(ctx, domain.Reservation{
+				UserID:          guest(i),
+				AccommodationID: accommodationID.String(),
+				Location:        location,
+				Price:           domain.WholeUnits(int64(100*len(nights)), domain.DefaultCurrency),
+				NumberOfDays:    len(nights),
+				DateRange:       nights,
+			})
+			if err != nil {
+				if err.Status >= 500 {
+					t.Errorf("booking of %s failed: %d %s", guest(i), err.Status, err.Message)
+				}
+				return
+			}
+			mu.Lock()
+			winners = append(winners, reservation)
+			mu.Unlock()
+		}(i)
+	}
+	close(ready)
+	wg.Wait()
+	if len(winners) != 1 {
+		for _, winner := range winners {
+			_ = repo.ReleaseHold(ctx, winner.AccommodationID, winner.Id)
+			_ = repo.ReleaseNights(ctx, winner.AccommodationID, winner.Id, winner.DateRange)
+		}
+		t.Fatalf("%d of %d concurrent bookings got the nights, want exactly 1", len(winners), parallel)
+	}
+	winner := winners[0]
+	defer repo.ReleaseNights(ctx, winner.AccommodationID, winner.Id, winner.DateRange)
+
+	if err := s.ConfirmReservation(ctx, toCreateReservationDetails(*winner)); err != nil {
+		t.Fatalf("unable to confirm the booking that got the nights: %s", err.Message)
+	}
+	var stored []domain.Reservation
+	for i := 0; i < parallel; i++ {
+		found, err := repo.GetReservationsByUser(ctx, guest(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		stored = append(stored, found...)
+	}
+	for i := range stored {
+		defer repo.RemoveReservation(ctx, &stored[i])
+	}
+	if len(stored) != 1 || stored[0].Id != winner.Id {
+		t.Fatalf("%d reservations were stored, want only the one of %s", len(stored), winner.UserID)
+	}
+
+	claims := claimsOf(t, accommodationID.String())
+	if len(claims) != len(nights) {
+		t.Fatalf("%d nights are claimed, want %d", len(claims), len(nights))
+	}
+	for night, reservationID := range claims {
+		if reservationID != winner.Id {
+			t.Fatalf("night %s is claimed by %v, not by the reservation %v", night, reservationID, winner.Id)
+		}
+	}
+}
+
+// claimsOf reads the night claims of the accommodation straight from the keyspace.
+func claimsOf(t *testing.T, accommodationID string) map[string]gocql.UUID {
+	t.Helper()
+	cluster := gocql.NewCluster(os.Getenv("CASS_DB"))
+	cluster.Keyspace = "reservation"
+	session, err := cluster.CreateSession()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer session.Close()
+	claims := make(map[string]gocql.UUID)
+	iter := session.Query(`SELECT night, reservation_id FROM night_claims WHERE accommodation_id = ?`, accommodationID).Iter()
+	var night string
+	var reservationID gocql.UUID
+	for iter.Scan(&night, &reservationID) {
+		claims[night] = reservationID
+	}
+	if err := iter.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return claims
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"reservation-service/calendar"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"sort"
+	"time"
+)
+
+// importHorizon bounds how far ahead an imported calendar blocks nights, so an
+// event without a sensible end can not block years of them.
+const importHorizon = 2 * 365
+
+// GetCalendarFeed returns the calendar feed of an accommodation to its host,
+// giving it a token the first time.
+func (s *ReservationService) GetCalendarFeed(ctx context.Context, hostID, accommodationID string) (*domain.CalendarFeed, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetCalendarFeed")
+	defer span.End()
+	feed, err := s.ownCalendarFeed(ctx, hostID, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	if feed.Token != "" {
+		return feed, nil
+	}
+	return s.saveCalendarFeed(ctx, feed, true)
+}
+
+// RotateCalendarToken gives the feed a new token, so the old feed URL stops
+// working for whoever learned it.
+func (s *ReservationService) RotateCalendarToken(ctx context.Context, hostID, accommodationID string) (*domain.CalendarFeed, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.RotateCalendarToken")
+	defer span.End()
+	feed, err := s.ownCalendarFeed(ctx, hostID, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	return s.saveCalendarFeed(ctx, feed, true)
+}
+
+// SetCalendarImport changes the calendar whose events block nights of the
+// accommodation and imports it in the background. An empty location stops
+// importing and frees the nights the last import blocked.
+func (s *ReservationService) SetCalendarImport(ctx context.Context, hostID, accommodationID, importURL string) (*domain.CalendarFeed, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetCalendarImport")
+	defer span.End()
+	if importURL != "" {
+		if err := s.calendars.Validate(importURL); err != nil {
+			return nil, errors.NewReservationError(400, err.Error())
+		}
+	}
+	feed, err := s.ownCalendarFeed(ctx, hostID, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	feed.ImportURL = importURL
+	feed, err = s.saveCalendarFeed(ctx, feed, feed.Token == "")
+	if err != nil {
+		return nil, err
+	}
+	if importURL == "" {
+		if err := s.repo.ReplaceCalendarBlocks(ctx, accommodationID, nil); err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			return nil, errors.NewReservationError(500, err.Error())
+		}
+		return feed, nil
+	}
+	go s.ImportCalendar(context.Background(), *feed)
+	return feed, nil
+}
+
+// ImportCalendarNow imports the calendar of an accommodation in the background
+// without waiting for the next scheduled import.
+func (s *ReservationService) ImportCalendarNow(ctx context.Context, hostID, accommodationID string) (*domain.CalendarFeed, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ImportCalendarNow")
+	defer span.End()
+	feed, err := s.ownCalendarFeed(ctx, hostID, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	if feed.ImportURL == "" {
+		return nil, errors.NewReservationError(400, "The accommodation does not import a calendar")
+	}
+	go s.ImportCalendar(context.Background(), *feed)
+	return feed, nil
+}
+
+// ownCalendarFeed returns the feed of an accommodation if it belongs to hostID.
+func (s *ReservationService) ownCalendarFeed(ctx context.Context, hostID, accommodationID string) (*domain.CalendarFeed, *errors.ReservationError) {
+	if err := s.checkOwner(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	feed, err := s.repo.GetCalendarFeed(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	feed.HostID = hostID
+	return feed, nil
+}
+
+func (s *ReservationService) saveCalendarFeed(ctx context.Context, feed *domain.CalendarFeed, newToken bool) (*domain.CalendarFeed, *errors.ReservationError) {
+	if newToken {
+		token, err := feedToken()
+		if err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			return nil, errors.NewReservationError(500, "Unable to create a calendar token")
+		}
+		feed.Token = token
+	}
+	if err := s.repo.SaveCalendarFeed(ctx, feed); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return feed, nil
+}
+
+func feedToken() (string, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
+
+// ExportCalendar returns the events of the accommodation's calendar feed: its
+// reservations, the nights the host or its imported calendar blocks and the nights
+// between today and its last available night that no availability window covers.
+// Past events are left out. A wrong token is answered like a missing feed.
+func (s *ReservationService) ExportCalendar(ctx context.Context, accommodationID, token string) ([]calendar.Event, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ExportCalendar")
+	defer span.End()
+	feed, err := s.repo.GetCalendarFeed(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	if feed.Token == "" || subtle.ConstantTimeCompare([]byte(feed.Token), []byte(token)) != 1 {
+		return nil, errors.NewReservationError(404, "Calendar not found")
+	}
+	reservations, err := s.repo.OpenReservationsOfAccommodation(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	blocks, err := s.repo.CalendarBlocks(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	hostBlocks, err := s.repo.HostBlocks(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	blocks = append(blocks, hostBlocks...)
+	windows, err := s.repo.PriceWindows(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+
+	today := time.Now().Format("2006-01-02")
+	var events []calendar.Event
+	for _, reservation := range reservations {
+		id := reservation.Id.String()
+		reserved, eventsErr := calendar.Events(sortedNights(reservation.DateRange), func(string) string {
+			return id + "@reservations-service"
+		}, "Reserved")
+		if eventsErr != nil {
+			return nil, errors.NewReservationError(500, eventsErr.Error())
+		}
+		events = append(events, reserved...)
+	}
+
+	taken := make(map[string]bool)
+	for _, reservation := range reservations {
+		for _, night := range reservation.DateRange {
+			taken[night] = true
+		}
+	}
+	var blocked []string
+	for _, block := range blocks {
+		if !taken[block.Night] {
+			taken[block.Night] = true
+			blocked = append(blocked, block.Night)
+		}
+	}
+	available := make(map[string]bool)
+	lastAvailable := ""
+	for _, window := range windows {
+		for _, night := range window.DateRange {
+			available[night] = true
+			if night > lastAvailable {
+				lastAvailable = night
+			}
+		}
+	}
+	for night := today; night <= lastAvailable; night = nextNight(night) {
+		if !available[night] && !taken[night] {
+			blocked = append(blocked, night)
+		}
+	}
+	unavailable, eventsErr := calendar.Events(sortedNights(blocked), func(start string) string {
+		return fmt.Sprintf("blocked-%s-%s@reservations-service", accommodationID, start)
+	}, "Not available")
+	if eventsErr != nil {
+		return nil, errors.NewReservationError(500, eventsErr.Error())
+	}
+	events = append(events, unavailable...)
+
+	upcoming := events[:0]
+	for _, event := range events {
+		if event.End.Format("2006-01-02") > today {
+			upcoming = append(upcoming, event)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].Start.Before(upcoming[j].Start) })
+	return upcoming, nil
+}
+
+// ImportCalendar reads the calendar the feed imports and makes its events, from
+// today up to the import horizon, the nights blocked for the accommodation. The
+// outcome is recorded on the feed. Nights blocked on top of a reservation are kept
+// and logged, since the other platform booked them too.
+func (s *ReservationService) ImportCalendar(ctx context.Context, feed domain.CalendarFeed) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ImportCalendar")
+	defer span.End()
+	importErr := s.importCalendar(ctx, feed)
+	message := ""
+	if importErr != nil {
+		// The host only gets the reason, the error may tell about the service's network.
+		message = calendar.Reason(importErr)
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to import calendar of accommodation %v: %s", feed.AccommodationID, importErr.Error()))
+	}
+	if err := s.repo.SaveImportResult(ctx, feed.AccommodationID, time.Now(), message); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+	}
+}
+
+func (s *ReservationService) importCalendar(ctx context.Context, feed domain.CalendarFeed) error {
+	body, err := s.calendars.Open(ctx, feed.ImportURL)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	events, err := calendar.Parse(body)
+	if err != nil {
+		return &calendar.ImportError{Reason: fmt.Sprintf("The calendar is not valid: %v", err)}
+	}
+	today := time.Now().Format("2006-01-02")
+	horizon := time.Now().AddDate(0, 0, importHorizon).Format("2006-01-02")
+	byNight := make(map[string]domain.CalendarBlock)
+	for _, event := range events {
+		for _, night := range event.Nights() {
+			if night < today || night > horizon {
+				continue
+			}
+			if _, exists := byNight[night]; !exists {
+				byNight[night] = domain.CalendarBlock{Night: night, UID: event.UID, Summary: event.Summary}
+			}
+		}
+	}
+	blocks := make([]domain.CalendarBlock, 0, len(byNight))
+	nights := make([]string, 0, len(byNight))
+	for night, block := range byNight {
+		blocks = append(blocks, block)
+		nights = append(nights, night)
+	}
+	if len(nights) > 0 {
+		reserved, err := s.repo.IsReserved(ctx, feed.AccommodationID, nights)
+		if err != nil {
+			return err
+		}
+		if reserved {
+			s.logger.LogInfo("reservationsService", fmt.Sprintf("Imported calendar of accommodation %v blocks nights it has reservations for", feed.AccommodationID))
+		}
+	}
+	if err := s.repo.ReplaceCalendarBlocks(ctx, feed.AccommodationID, blocks); err != nil {
+		return err
+	}
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Imported %d events blocking %d nights for accommodation %v", len(events), len(blocks), feed.AccommodationID))
+	return nil
+}
+
+// ImportCalendars imports the calendar of every accommodation that has one.
+func (s *ReservationService) ImportCalendars(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ImportCalendars")
+	defer span.End()
+	feeds, err := s.repo.GetCalendarImports(ctx)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	for _, feed := range feeds {
+		s.ImportCalendar(ctx, feed)
+	}
+}
+
+// WatchCalendars imports the calendars every interval until ctx is done.
+func (s *ReservationService) WatchCalendars(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ImportCalendars(ctx)
+		}
+	}
+}
+
+func sortedNights(nights []string) []string {
+	sorted := append([]string(nil), nights...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func nextNight(night string) string {
+	date, err := time.Parse("2006-01-02", night)
+	if err != nil {
+		return "9999-12-31"
+	}
+	return date.AddDate(0, 0, 1).Format("2006-01-02")
+}
@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// calendarChangesShown is how many of the latest calendar changes are listed.
+const calendarChangesShown = 100
+
+// ChangeCalendar blocks, unblocks or prices the nights of a calendar change and
+// records who made it. Nights a reservation holds can be neither blocked nor
+// priced, since the guest already booked them at their old price. Nights held for
+// a reservation not confirmed yet count as reserved. Only the host of the
+// accommodation may change its calendar.
+func (s *ReservationService) ChangeCalendar(ctx context.Context, hostID, accommodationID string, change domain.CalendarChange) (*domain.CalendarChangeRecord, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ChangeCalendar")
+	defer span.End()
+	if err := change.Validate(); err != nil {
+		return nil, errors.NewReservationError(400, err.Error())
+	}
+	nights, datesErr := change.Dates()
+	if datesErr != nil {
+		return nil, errors.NewReservationError(400, datesErr.Error())
+	}
+	if _, err := s.ownCalendarFeed(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	if change.Action != domain.UnblockNights {
+		reserved, err := s.repo.ReservedNights(ctx, accommodationID, nights)
+		if err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			return nil, errors.NewReservationError(500, err.Error())
+		}
+		if len(reserved) > 0 {
+			taken := make([]string, 0, len(reserved))
+			for night := range reserved {
+				taken = append(taken, night)
+			}
+			sort.Strings(taken)
+			return nil, errors.NewReservationError(409, fmt.Sprintf("Nights %s are reserved", strings.Join(taken, ", ")))
+		}
+	}
+
+	var applyErr error
+	switch change.Action {
+	case domain.BlockNights:
+		applyErr = s.repo.BlockNights(ctx, accommodationID, hostID, change.Reason, nights, time.Now())
+	case domain.UnblockNights:
+		applyErr = s.repo.UnblockNights(ctx, accommodationID, nights)
+	case domain.PriceNights:
+		if err := s.checkNightlyCurrency(ctx, accommodationID, change.Price.Currency); err != nil {
+			return nil, err
+		}
+		applyErr = s.repo.RepriceNights(ctx, accommodationID, nights, change.Price)
+	}
+	if applyErr != nil {
+		s.logger.LogError("reservationsService", applyErr.Error())
+		if reservationErr, ok := applyErr.(*errors.ReservationError); ok {
+			return nil, reservationErr
+		}
+		return nil, errors.NewReservationError(500, applyErr.Error())
+	}
+
+	record := domain.CalendarChangeRecord{
+		Id:              gocql.TimeUUID(),
+		AccommodationID: accommodationID,
+		HostID:          hostID,
+		Action:          change.Action,
+		Nights:          nights,
+		Reason:          change.Reason,
+	}
+	record.ChangedAt = record.Id.Time()
+	if change.Action == domain.PriceNights {
+		record.Price = change.Price
+	}
+	if err := s.repo.InsertCalendarChange(ctx, &record); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to record calendar change of accommodation %v by host %v: %s", accommodationID, hostID, err.Error()))
+	}
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Host %v applied %s to %d nights of accommodation %v", hostID, change.Action, len(nights), accommodationID))
+	return &record, nil
+}
+
+// checkNightlyCurrency keeps the nightly rates and the cleaning fee of an
+// accommodation in one currency, which Quote needs.
+func (s *ReservationService) checkNightlyCurrency(ctx context.Context, accommodationID, currency string) *errors.ReservationError {
+	windows, err := s.repo.PriceWindows(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	for _, window := range windows {
+		if window.Price.Currency != currency {
+			return errors.NewReservationError(400, fmt.Sprintf("Nightly rates must be in %s like the other rates", window.Price.Currency))
+		}
+	}
+	pricing, pricingErr := s.GetPricing(ctx, accommodationID)
+	if pricingErr != nil {
+		return pricingErr
+	}
+	if !pricing.CleaningFee.IsZero() && pricing.CleaningFee.Currency != currency {
+		return errors.NewReservationError(400, fmt.Sprintf("Nightly rates must be in %s like the cleaning fee", pricing.CleaningFee.Currency))
+	}
+	return nil
+}
+
+// GetHostCalendar returns the nights from from to to, both included, as the host
+// of the accommodation sees them.
+func (s *ReservationService) GetHostCalendar(ctx context.Context, hostID, accommodationID, from, to string) ([]domain.CalendarNight, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetHostCalendar")
+	defer span.End()
+	nights, datesErr := domain.CalendarChange{From: from, To: to}.Dates()
+	if datesErr != nil {
+		return nil, errors.NewReservationError(400, datesErr.Error())
+	}
+	if _, err := s.ownCalendarFeed(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	windows, err := s.repo.PriceWindows(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	reserved, err := s.repo.ReservedNights(ctx, accommodationID, nights)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	imported, err := s.repo.CalendarBlocks(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	hostBlocks, err := s.repo.HostBlocks(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	blockedBy := make(map[string]string)
+	for _, block := range imported {
+		blockedBy[block.Night] = domain.BlockedByImport
+	}
+	for _, block := range hostBlocks {
+		blockedBy[block.Night] = domain.BlockedByHost
+	}
+	return domain.CalendarNights(nights, windows, blockedBy, reserved), nil
+}
+
+// GetCalendarChanges returns the latest changes made to the calendar of an
+// accommodation, newest first.
+func (s *ReservationService) GetCalendarChanges(ctx context.Context, hostID, accommodationID string) ([]domain.CalendarChangeRecord, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetCalendarChanges")
+	defer span.End()
+	if _, err := s.ownCalendarFeed(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	changes, err := s.repo.GetCalendarChanges(ctx, accommodationID, calendarChangesShown)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return changes, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+)
+
+// partyOf returns the party a guest asked for. Clients that only send a guest
+// count are taken to ask for that many adults.
+func partyOf(party *domain.Party, guests int) domain.Party {
+	if party != nil {
+		return *party
+	}
+	return domain.PartyOf(guests)
+}
+
+// CheckParty tells whether the accommodation takes the party. Without an
+// accommodations client, as in tools run against the database alone, only the
+// party itself is checked.
+func (s *ReservationService) CheckParty(ctx context.Context, accommodationID string, party domain.Party) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CheckParty")
+	defer span.End()
+	limits := &domain.PartyLimits{PetsAllowed: true}
+	if s.accommodations != nil {
+		var err *errors.ReservationError
+		limits, err = s.accommodations.GetPartyLimits(ctx, accommodationID)
+		if err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to get party limits of accommodation %v: %s", accommodationID, err.Message))
+			return err
+		}
+	}
+	if err := party.Check(*limits); err != nil {
+		return errors.NewReservationError(400, fmt.Sprintf("Party not allowed: %s", err.Error()))
+	}
+	return nil
+}
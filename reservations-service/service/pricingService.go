@@ -0,0 +1,134 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"reservation-service/exchange"
+)
+
+func (s *ReservationService) GetPricing(ctx context.Context, accommodationID string) (*domain.Pricing, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetPricing")
+	defer span.End()
+	pricing, err := s.repo.GetPricing(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return pricing, nil
+}
+
+// SetPricing changes the cleaning fee and stay discounts of an accommodation. The
+// paying mode belongs to the accommodation and is kept. Only the host of the
+// accommodation may change its pricing.
+func (s *ReservationService) SetPricing(ctx context.Context, hostID string, changed domain.Pricing) (*domain.Pricing, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetPricing")
+	defer span.End()
+	if err := s.checkOwner(ctx, hostID, changed.AccommodationID); err != nil {
+		return nil, err
+	}
+	pricing, err := s.GetPricing(ctx, changed.AccommodationID)
+	if err != nil {
+		return nil, err
+	}
+	pricing.HostID = hostID
+	pricing.CleaningFee = changed.CleaningFee
+	pricing.WeeklyDiscountPercent = changed.WeeklyDiscountPercent
+	pricing.MonthlyDiscountPercent = changed.MonthlyDiscountPercent
+	if err := pricing.Validate(); err != nil {
+		return nil, errors.NewReservationError(400, err.Error())
+	}
+	if !pricing.CleaningFee.IsZero() {
+		windows, windowsErr := s.repo.PriceWindows(ctx, pricing.AccommodationID)
+		if windowsErr != nil {
+			s.logger.LogError("reservationsService", windowsErr.Error())
+			return nil, errors.NewReservationError(500, windowsErr.Error())
+		}
+		for _, window := range windows {
+			if window.Price.Currency != pricing.CleaningFee.Currency {
+				return nil, errors.NewReservationError(400, fmt.Sprintf("The cleaning fee must be in %s like the nightly rates", window.Price.Currency))
+			}
+		}
+	}
+	if err := s.repo.SaveFees(ctx, pricing); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return pricing, nil
+}
+
+// SetPaying records whether an accommodation is paid per guest or per accommodation.
+func (s *ReservationService) SetPaying(ctx context.Context, accommodationID, paying string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetPaying")
+	defer span.End()
+	pricing := domain.Pricing{AccommodationID: accommodationID, Paying: paying}
+	if err := pricing.Validate(); err != nil {
+		return errors.NewReservationError(400, err.Error())
+	}
+	if err := s.repo.SavePaying(ctx, accommodationID, paying); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+// Quote prices a stay the way a reservation for it will be charged, itemised in the
+// currency the guest asked for.
+func (s *ReservationService) Quote(ctx context.Context, request domain.QuoteRequest) (*domain.Quote, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.Quote")
+	defer span.End()
+	if request.Guests < 0 {
+		return nil, errors.NewReservationError(400, "Number of guests can not be negative")
+	}
+	pricing, err := s.GetPricing(ctx, request.AccommodationID)
+	if err != nil {
+		return nil, err
+	}
+	windows, windowsErr := s.repo.PriceWindows(ctx, request.AccommodationID)
+	if windowsErr != nil {
+		s.logger.LogError("reservationsService", windowsErr.Error())
+		return nil, errors.NewReservationError(500, windowsErr.Error())
+	}
+	quote, quoteErr := pricing.Quote(request.DateRange, request.Guests, windows)
+	if quoteErr != nil {
+		return nil, errors.NewReservationError(400, fmt.Sprintf("Unable to price the stay: %s", quoteErr.Error()))
+	}
+	if request.Currency == "" || request.Currency == quote.Charged.Currency {
+		return quote, nil
+	}
+	converted, convertErr := s.convertQuote(*quote, request.Currency)
+	if convertErr != nil {
+		return nil, errors.NewReservationError(400, fmt.Sprintf("Unable to quote in %s: %s", request.Currency, convertErr.Error()))
+	}
+	return converted, nil
+}
+
+// convertQuote converts every amount of the quote but Charged, which stays what
+// the reservation costs. Each amount is rounded on its own, so the converted lines
+// may be a minor unit off their converted total.
+func (s *ReservationService) convertQuote(quote domain.Quote, currency string) (*domain.Quote, error) {
+	rate, err := s.rates.Rate(quote.Charged.Currency, currency)
+	if err != nil {
+		return nil, err
+	}
+	convert := func(money *domain.Money) {
+		if err == nil {
+			*money, err = exchange.Convert(s.rates, *money, currency)
+		}
+	}
+	quote.Nights = append([]domain.NightlyRate(nil), quote.Nights...)
+	for i := range quote.Nights {
+		convert(&quote.Nights[i].Rate)
+		convert(&quote.Nights[i].Amount)
+	}
+	convert(&quote.Subtotal)
+	convert(&quote.DiscountAmount)
+	convert(&quote.CleaningFee)
+	convert(&quote.Total)
+	if err != nil {
+		return nil, err
+	}
+	quote.ExchangeRate = rate
+	return &quote, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+
+	"github.com/gocql/gocql"
+)
+
+// ModifyReservation moves a confirmed reservation to other nights or changes its
+// party, keeping its ID. The added nights are claimed before anything is
+// written and the nights no longer needed are released only once all four tables
+// hold the new stay, so the guest never loses the old dates to a failed change.
+func (s *ReservationService) ModifyReservation(ctx context.Context, userID, id string, change domain.ReservationChange) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ModifyReservation")
+	defer span.End()
+	reservationID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		return nil, errors.NewReservationError(400, "Invalid reservation id")
+	}
+	if len(change.DateRange) == 0 {
+		return nil, errors.NewReservationError(400, "Date range is empty")
+	}
+	if change.Guests < 0 {
+		return nil, errors.NewReservationError(400, "Number of guests can not be negative")
+	}
+	previous, err := s.getReservation(ctx, userID, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if previous.State != domain.Confirmed {
+		return nil, errors.NewReservationError(409, fmt.Sprintf("A %s reservation can not be changed", previous.State))
+	}
+	// A change whose tables were not all written is finished first, so the
+	// stay is compared with the dates it really holds.
+	if _, repairErr := s.repo.RepairReservation(ctx, previous); repairErr != nil {
+		s.logger.LogError("reservationsService", repairErr.Error())
+		return nil, errors.NewReservationError(500, repairErr.Error())
+	}
+
+	previousParty := partyOf(previous.Party, previous.Guests)
+	party := previousParty
+	if change.Party != nil {
+		party = *change.Party
+	} else if change.Guests > 0 && change.Guests != previous.Guests {
+		party = domain.PartyOf(change.Guests)
+	}
+	if party != previousParty {
+		if err := s.CheckParty(ctx, previous.AccommodationID, party); err != nil {
+			return nil, err
+		}
+	}
+	quote, err := s.Quote(ctx, domain.QuoteRequest{AccommodationID: previous.AccommodationID, DateRange: change.DateRange, Guests: party.Guests()})
+	if err != nil {
+		return nil, err
+	}
+	nights := make([]string, 0, len(quote.Nights))
+	for _, night := range quote.Nights {
+		nights = append(nights, night.Date)
+	}
+	updated := *previous
+	updated.DateRange = nights
+	updated.StartDate = nights[0]
+	updated.EndDate = nights[len(nights)-1]
+	updated.NumberOfDays = len(nights)
+	updated.Price = quote.Charged
+	updated.Guests = quote.Guests
+	updated.Party = &party
+
+	added := without(nights, previous.DateRange)
+	removed := without(previous.DateRange, nights)
+	if len(added) > 0 || len(removed) > 0 {
+		if err := s.CheckStayRules(ctx, previous.AccommodationID, nights); err != nil {
+			return nil, err
+		}
+	}
+	if len(added) > 0 {
+		available, err := s.IsAvailable(ctx, previous.AccommodationID, added)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range")
+		}
+		claimed, claimErr := s.repo.ClaimNights(ctx, previous.AccommodationID, previous.Id, added, holdTTL)
+		if claimErr != nil {
+			return nil, errors.NewReservationError(500, "Unable to hold the dates")
+		}
+		if !claimed {
+			return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range")
+		}
+		confirmed, confirmErr := s.repo.ConfirmNights(ctx, previous.AccommodationID, previous.Id, added)
+		if confirmErr != nil || !confirmed {
+			_ = s.repo.ReleaseNights(ctx, previous.AccommodationID, previous.Id, added)
+			return nil, errors.NewReservationError(500, "Unable to hold the dates")
+		}
+	}
+	applied, updateErr := s.repo.UpdateStay(ctx, previous, &updated)
+	if updateErr != nil || !applied {
+		_ = s.repo.ReleaseNights(ctx, previous.AccommodationID, previous.Id, added)
+		if updateErr != nil {
+			s.logger.LogError("reservationsService", updateErr.Error())
+			return nil, errors.NewReservationError(500, updateErr.Error())
+		}
+		return nil, errors.NewReservationError(409, "Reservation was changed concurrently")
+	}
+	if err := s.repo.ReleaseNights(ctx, previous.AccommodationID, previous.Id, removed); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release old nights of reservation %v", previous.Id))
+	}
+	s.notification.SendReservationModifiedNotification(ctx, updated.HostID,
+		fmt.Sprintf("Reservation for %s changed to %s - %s", updated.AccommodationName, updated.StartDate, updated.EndDate))
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Modified reservation: %v", updated.Id))
+	return &updated, nil
+}
+
+// without returns the nights of a that are not in b.
+func without(a, b []string) []string {
+	excluded := make(map[string]bool, len(b))
+	for _, night := range b {
+		excluded[night] = true
+	}
+	var result []string
+	for _, night := range a {
+		if !excluded[night] {
+			result = append(result, night)
+		}
+	}
+	return result
+}
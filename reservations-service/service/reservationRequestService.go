@@ -0,0 +1,229 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+func (s *ReservationService) GetBookingMode(ctx context.Context, accommodationID string) (*domain.BookingMode, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetBookingMode")
+	defer span.End()
+	mode, err := s.repo.GetBookingMode(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return mode, nil
+}
+
+// SetBookingMode switches an accommodation between instant booking and
+// request-to-book. Only the host of the accommodation may change it.
+func (s *ReservationService) SetBookingMode(ctx context.Context, hostID, accommodationID string, requestToBook bool) (*domain.BookingMode, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetBookingMode")
+	defer span.End()
+	if err := s.checkOwner(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	mode, err := s.GetBookingMode(ctx, accommodationID)
+	if err != nil {
+		return nil, err
+	}
+	mode.HostID = hostID
+	mode.RequestToBook = requestToBook
+	if err := s.repo.SaveBookingMode(ctx, mode); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return mode, nil
+}
+
+// requestReservation stores a reservation the host still has to accept. Its nights
+// were claimed for the request window, so they stay held until the host answers
+// or the request expires.
+func (r ReservationService) requestReservation(ctx context.Context, reservation domain.Reservation) (*domain.Reservation, *errors.ReservationError) {
+	now := time.Now()
+	reservation.State = domain.Requested
+	reservation.StateChangedAt = map[domain.ReservationState]time.Time{domain.Requested: now}
+	reservation.IsActive = true
+	reservation.Refund = nil
+	if _, err := r.repo.InsertReservation(ctx, &reservation); err != nil {
+		r.logger.LogError("reservationsService", err.Error())
+		_ = r.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+		return nil, errors.NewReservationError(500, "Unable to create reservation request")
+	}
+	request := domain.ReservationRequest{
+		Id:              reservation.Id,
+		HostID:          reservation.HostID,
+		UserID:          reservation.UserID,
+		AccommodationID: reservation.AccommodationID,
+		ExpiresAt:       now.Add(r.requestWindow),
+	}
+	if err := r.repo.InsertRequest(ctx, &request); err != nil {
+		r.logger.LogError("reservationsService", err.Error())
+		_ = r.repo.RemoveReservation(ctx, &reservation)
+		_ = r.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+		return nil, errors.NewReservationError(500, "Unable to create reservation request")
+	}
+	r.notification.SendReservationRequestNotification(ctx, reservation.HostID,
+		fmt.Sprintf("New reservation request for %s, answer by %s", reservation.AccommodationName, request.ExpiresAt.Format("2006-01-02 15:04")))
+	r.logger.LogInfo("reservationsService", fmt.Sprintf("Reservation requested: %v", reservation.Id))
+	return &reservation, nil
+}
+
+// GetPendingRequests returns the reservations waiting for the host to answer.
+func (s *ReservationService) GetPendingRequests(ctx context.Context, hostID string) ([]domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetPendingRequests")
+	defer span.End()
+	reservations, err := s.GetReservationsByHost(ctx, hostID)
+	if err != nil {
+		return nil, err
+	}
+	return withState(reservations, domain.Requested), nil
+}
+
+// AcceptRequest confirms a requested reservation. From there on it goes through the
+// same saga as an instant booking, which records the metrics and notifies the host.
+func (s *ReservationService) AcceptRequest(ctx context.Context, hostID, id string) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.AcceptRequest")
+	defer span.End()
+	request, err := s.getRequest(ctx, hostID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !request.ExpiresAt.After(time.Now()) {
+		s.closeRequest(ctx, *request, domain.Expired, "Your reservation request expired")
+		return nil, errors.NewReservationError(409, "The reservation request expired")
+	}
+	reservation, err := s.getReservation(ctx, request.UserID, request.Id)
+	if err != nil {
+		return nil, err
+	}
+	// Confirming makes the claims permanent, so they are released again whenever
+	// the reservation does not end up confirmed; otherwise the nights stay taken
+	// by a reservation nobody holds.
+	confirmed, confirmErr := s.repo.ConfirmNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+	if confirmErr != nil {
+		s.releaseUnlessConfirmed(ctx, *request)
+		return nil, errors.NewReservationError(500, confirmErr.Error())
+	}
+	if !confirmed {
+		s.closeRequest(ctx, *request, domain.Expired, "Your reservation request expired")
+		return nil, errors.NewReservationError(409, "The reservation request expired")
+	}
+	accepted, err := s.applyTransition(ctx, reservation, domain.Confirmed)
+	if err != nil {
+		s.releaseUnlessConfirmed(ctx, *request)
+		return nil, err
+	}
+	reservation = accepted
+	s.bookWaitlistOffers(ctx, *reservation)
+	if err := s.repo.DeleteRequest(ctx, request.HostID, request.Id); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+	}
+	details := toCreateReservationDetails(*reservation)
+	if err := s.orchestrator.Start(ctx, &details); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to start reservation saga for %v: %v", reservation.Id, err))
+	}
+	s.notification.SendReservationRequestNotification(ctx, reservation.UserID,
+		fmt.Sprintf("Your reservation request for %s was accepted", reservation.AccommodationName))
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Reservation request accepted: %v", reservation.Id))
+	return reservation, nil
+}
+
+// releaseUnlessConfirmed frees the nights of a request that could not be
+// accepted. A concurrent accept of the same request may have confirmed it in the
+// meantime, and then the nights are its own.
+func (s *ReservationService) releaseUnlessConfirmed(ctx context.Context, request domain.ReservationRequest) {
+	reservation, err := s.getReservation(ctx, request.UserID, request.Id)
+	if err != nil || reservation.State == domain.Confirmed {
+		return
+	}
+	if err := s.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release nights of request %v", reservation.Id))
+	}
+}
+
+func (s *ReservationService) DeclineRequest(ctx context.Context, hostID, id string) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.DeclineRequest")
+	defer span.End()
+	request, err := s.getRequest(ctx, hostID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.closeRequest(ctx, *request, domain.Declined, "Your reservation request was declined")
+}
+
+// ExpireRequests closes the requests the hosts did not answer in time.
+func (s *ReservationService) ExpireRequests(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ExpireRequests")
+	defer span.End()
+	requests, err := s.repo.GetExpiredRequests(ctx, time.Now())
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	for _, request := range requests {
+		if _, err := s.closeRequest(ctx, request, domain.Expired, "Your reservation request expired"); err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to expire request %v: %s", request.Id, err.Message))
+		}
+	}
+}
+
+// WatchRequests expires unanswered requests every interval until ctx is done.
+func (s *ReservationService) WatchRequests(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireRequests(ctx)
+		}
+	}
+}
+
+func (s *ReservationService) getRequest(ctx context.Context, hostID, id string) (*domain.ReservationRequest, *errors.ReservationError) {
+	requestID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		return nil, errors.NewReservationError(400, "Invalid reservation id")
+	}
+	request, err := s.repo.GetRequest(ctx, hostID, requestID)
+	if err != nil {
+		if reservationErr, ok := err.(*errors.ReservationError); ok {
+			return nil, reservationErr
+		}
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return request, nil
+}
+
+// closeRequest ends a request the host did not accept and frees its nights, and
+// puts a waitlist offer the guest booked with it back in line. The
+// request is deleted even when the reservation already left Requested, for
+// example because the guest cancelled it, since nobody can answer it anymore.
+func (s *ReservationService) closeRequest(ctx context.Context, request domain.ReservationRequest, state domain.ReservationState, message string) (*domain.Reservation, *errors.ReservationError) {
+	reservation, err := s.transition(ctx, request.UserID, request.Id, state)
+	if err != nil && err.Status >= 500 {
+		return nil, err
+	}
+	if deleteErr := s.repo.DeleteRequest(ctx, request.HostID, request.Id); deleteErr != nil {
+		s.logger.LogError("reservationsService", deleteErr.Error())
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release nights of request %v", reservation.Id))
+	}
+	s.notification.SendReservationRequestNotification(ctx, reservation.UserID,
+		fmt.Sprintf("%s: %s", message, reservation.AccommodationName))
+	s.reopenWaitlistOffers(ctx, *reservation)
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Reservation request %v: %s", reservation.Id, state))
+	return reservation, nil
+}
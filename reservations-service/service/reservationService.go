@@ -2,15 +2,21 @@ package service
 
 import (
 	"context"
+	events "example/saga/create_reservation"
 	"fmt"
 	"log"
+	"reservation-service/calendar"
 	"reservation-service/client"
 	"reservation-service/config"
 	"reservation-service/domain"
 	"reservation-service/errors"
+	"reservation-service/exchange"
+	"reservation-service/orchestrator"
 	"reservation-service/repository"
 	"reservation-service/utils"
+	"time"
 
+	"github.com/gocql/gocql"
 	"go.opentelemetry.io/otel/trace"
 )
 
@@ -21,10 +27,46 @@ type ReservationService struct {
 	logger       *config.Logger
 	tracer       trace.Tracer
 	metricClient *client.MetricsClient
+	orchestrator *orchestrator.CreateReservationOrchestrator
+	// requestWindow is how long a host has to answer a reservation request.
+	requestWindow time.Duration
+	// offerWindow is how long a waitlisted guest has to book freed dates.
+	offerWindow time.Duration
+	rates       exchange.Provider
+	calendars   *calendar.Sources
+	// accommodations is nil in tools that run without accommodations-service.
+	accommodations *client.AccommodationsClient
 }
 
-func NewReservationService(repo *repository.ReservationRepo, validator *utils.Validator, notification *client.NotificationClient, logger *config.Logger, tracer trace.Tracer, metricsClient *client.MetricsClient) *ReservationService {
-	return &ReservationService{repo: repo, validator: validator, notification: notification, logger: logger, tracer: tracer, metricClient: metricsClient}
+// holdTTL bounds how long the dates of a reservation in progress stay held. It
+// outlives the saga step timeouts, so a hold only expires by itself when the
+// saga could not release it.
+const holdTTL = 10 * time.Minute
+
+// pendingAccommodation is the status accommodations-service gives accommodations
+// until their creation saga approves them.
+const pendingAccommodation = "Pending"
+
+func NewReservationService(repo *repository.ReservationRepo, validator *utils.Validator, notification *client.NotificationClient, logger *config.Logger, tracer trace.Tracer, metricsClient *client.MetricsClient, orchestrator *orchestrator.CreateReservationOrchestrator, requestWindow, offerWindow time.Duration, rates exchange.Provider, calendars *calendar.Sources, accommodations *client.AccommodationsClient) *ReservationService {
+	return &ReservationService{repo: repo, validator: validator, notification: notification, logger: logger, tracer: tracer, metricClient: metricsClient, orchestrator: orchestrator, requestWindow: requestWindow, offerWindow: offerWindow, rates: rates, calendars: calendars, accommodations: accommodations}
+}
+
+// checkOwner fails with 403 unless the accommodation belongs to hostID, as told
+// by accommodations-service.
+func (s *ReservationService) checkOwner(ctx context.Context, hostID, accommodationID string) *errors.ReservationError {
+	if s.accommodations == nil {
+		return errors.NewReservationError(503, "Unable to check the host of the accommodation")
+	}
+	owner, err := s.accommodations.GetOwner(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to get the host of accommodation %v: %s", accommodationID, err.Message))
+		return err
+	}
+	if owner != hostID {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Host %v does not own accommodation %v", hostID, accommodationID))
+		return errors.NewReservationError(403, "Accommodation does not belong to the host")
+	}
+	return nil
 }
 
 // service/reservationService.go
@@ -39,35 +81,240 @@ func (r ReservationService) CreateReservation(ctx context.Context, reservation d
 			return nil, errors.NewReservationError(400, "Validation failed")
 		}
 	*/
+	if len(reservation.DateRange) == 0 {
+		return nil, errors.NewReservationError(400, "Date range is empty")
+	}
 	available, err := r.IsAvailable(ctx, reservation.AccommodationID, reservation.DateRange)
 	if err != nil {
 		r.logger.LogError("reservationsService", err.Message)
 		return nil, err
 	}
 	if !available {
-		r.logger.LogError("reservationsService", err.Message)
+		r.logger.LogError("reservationsService", "Accommodation not available for the specified date range")
 		return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range")
 	}
 
-	reserved, erro := r.IsReserved(ctx, reservation.AccommodationID, reservation.DateRange)
-	if erro != nil {
-		r.logger.LogError("reservationsService", erro.Message)
-		return nil, erro
+	reserved, err := r.IsReserved(ctx, reservation.AccommodationID, reservation.DateRange)
+	if err != nil {
+		return nil, err
 	}
 	if reserved {
-		r.logger.LogError("reservationsService", erro.Message)
+		r.logger.LogError("reservationsService", "Accommodation already reserved for the specified date range")
 		return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range1")
 	}
-	createdReservation, insertErr := r.repo.InsertReservation(ctx, &reservation)
-	if insertErr != nil {
-		r.logger.LogError("reservationsService", erro.Message)
-		return nil, errors.NewReservationError(500, "Unable to create reservation: "+insertErr.Error())
+	if err := r.CheckStayRules(ctx, reservation.AccommodationID, reservation.DateRange); err != nil {
+		return nil, err
+	}
+	if err := r.checkWaitlistOffers(ctx, reservation); err != nil {
+		return nil, err
+	}
+
+	party := partyOf(reservation.Party, reservation.Guests)
+	if err := r.CheckParty(ctx, reservation.AccommodationID, party); err != nil {
+		return nil, err
+	}
+	reservation.Party = &party
+	reservation.Guests = party.Guests()
+
+	quote, err := r.Quote(ctx, domain.QuoteRequest{AccommodationID: reservation.AccommodationID, DateRange: reservation.DateRange, Guests: reservation.Guests})
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Price != quote.Charged {
+		r.logger.LogError("reservationsService", fmt.Sprintf("Price %s does not match quote %s", reservation.Price, quote.Charged))
+		return nil, errors.NewReservationError(400, fmt.Sprintf("Price does not match the quote of %s", quote.Charged))
+	}
+	reservation.Guests = quote.Guests
+
+	mode, modeErr := r.repo.GetBookingMode(ctx, reservation.AccommodationID)
+	if modeErr != nil {
+		return nil, errors.NewReservationError(500, "Unable to get booking mode")
 	}
-	r.notification.SendReservationCreatedNotification(ctx, reservation.HostID, "Reservation successfully created")
+	claimTTL := holdTTL
+	if mode.RequestToBook {
+		claimTTL = r.requestWindow
+	}
+
+	reservation.Id, _ = gocql.RandomUUID()
+	// Nights offered to the guest from the waitlist are claimed for the offer, so
+	// the reservation takes those claims over. The offer is booked once the
+	// reservation is confirmed, and goes back in line when it falls through.
+	offered := r.takeWaitlistOffers(ctx, reservation, claimTTL)
+	fallThrough := func() {
+		if offered {
+			r.reopenWaitlistOffers(ctx, reservation)
+		}
+	}
+	claimed, claimErr := r.repo.ClaimNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange, claimTTL)
+	if claimErr != nil {
+		fallThrough()
+		return nil, errors.NewReservationError(500, "Unable to hold the dates")
+	}
+	if !claimed {
+		fallThrough()
+		r.logger.LogError("reservationsService", "Accommodation already reserved or held for the specified date range")
+		return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range1")
+	}
+	if mode.RequestToBook {
+		requested, err := r.requestReservation(ctx, reservation)
+		if err != nil {
+			fallThrough()
+		}
+		return requested, err
+	}
+	if err := r.repo.PlaceHold(ctx, reservation.Id, reservation.AccommodationID, reservation.UserID, reservation.DateRange, holdTTL); err != nil {
+		r.logger.LogError("reservationsService", err.Error())
+		_ = r.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+		fallThrough()
+		return nil, errors.NewReservationError(500, "Unable to hold the dates")
+	}
+	details := toCreateReservationDetails(reservation)
+	if err := r.orchestrator.Start(ctx, &details); err != nil {
+		r.logger.LogError("reservationsService", fmt.Sprintf("Unable to start reservation saga for %v: %v", reservation.Id, err))
+		_ = r.repo.ReleaseHold(ctx, reservation.AccommodationID, reservation.Id)
+		_ = r.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+		fallThrough()
+		return nil, errors.NewReservationError(500, "Unable to create reservation")
+	}
+	r.logger.LogInfo("reservationsService", fmt.Sprintf("Dates held for reservation: %v", reservation.Id))
+	return &reservation, nil
+}
 
+// ConfirmReservation turns the hold of a reservation into the reservation itself.
+// It fails when the claims on the nights expired before the saga got here.
+func (r ReservationService) ConfirmReservation(ctx context.Context, details events.CreateReservationDetails) *errors.ReservationError {
+	ctx, span := r.tracer.Start(ctx, "ReservationService.ConfirmReservation")
+	defer span.End()
+	reservation, err := fromCreateReservationDetails(details)
+	if err != nil {
+		return err
+	}
+	exists, existsErr := r.repo.ReservationExists(ctx, reservation.UserID, reservation.Id)
+	if existsErr != nil {
+		return errors.NewReservationError(500, existsErr.Error())
+	}
+	if exists {
+		return nil
+	}
+	confirmed, confirmErr := r.repo.ConfirmNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange)
+	if confirmErr != nil {
+		return errors.NewReservationError(500, confirmErr.Error())
+	}
+	if !confirmed {
+		r.logger.LogError("reservationsService", fmt.Sprintf("Hold of reservation %v expired", reservation.Id))
+		return errors.NewReservationError(409, "The hold on the dates expired")
+	}
+	reservation.Transition(domain.Confirmed, time.Now())
+	createdReservation, insertErr := r.repo.InsertReservation(ctx, reservation)
+	if insertErr != nil {
+		r.logger.LogError("reservationsService", insertErr.Error())
+		return errors.NewReservationError(500, "Unable to create reservation: "+insertErr.Error())
+	}
+	if err := r.repo.ReleaseHold(ctx, reservation.AccommodationID, reservation.Id); err != nil {
+		r.logger.LogError("reservationsService", err.Error())
+	}
+	r.bookWaitlistOffers(ctx, *reservation)
 	r.logger.LogInfo("reservationsService", fmt.Sprintf("Reservation created: %v", createdReservation))
-	r.metricClient.SendReserved(ctx, createdReservation.UserID, createdReservation.AccommodationID)
-	return createdReservation, nil
+	return nil
+}
+
+// CancelReservation undoes ConfirmReservation when a later step of the saga failed.
+func (r ReservationService) CancelReservation(ctx context.Context, details events.CreateReservationDetails) *errors.ReservationError {
+	ctx, span := r.tracer.Start(ctx, "ReservationService.CancelReservation")
+	defer span.End()
+	reservation, err := fromCreateReservationDetails(details)
+	if err != nil {
+		return err
+	}
+	if err := r.repo.RemoveReservation(ctx, reservation); err != nil {
+		r.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+// ReleaseHold frees the dates of a reservation the saga gave up on, and puts a
+// waitlist offer the guest booked with it back in line.
+func (r ReservationService) ReleaseHold(ctx context.Context, details events.CreateReservationDetails) *errors.ReservationError {
+	ctx, span := r.tracer.Start(ctx, "ReservationService.ReleaseHold")
+	defer span.End()
+	reservation, err := fromCreateReservationDetails(details)
+	if err != nil {
+		return err
+	}
+	if err := r.repo.ReleaseHold(ctx, reservation.AccommodationID, reservation.Id); err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	if err := r.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange); err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	r.reopenWaitlistOffers(ctx, *reservation)
+	return nil
+}
+
+func toCreateReservationDetails(reservation domain.Reservation) events.CreateReservationDetails {
+	details := events.CreateReservationDetails{
+		ReservationID:     reservation.Id.String(),
+		UserID:            reservation.UserID,
+		HostID:            reservation.HostID,
+		AccommodationID:   reservation.AccommodationID,
+		Username:          reservation.Username,
+		AccommodationName: reservation.AccommodationName,
+		Location:          reservation.Location,
+		Price:             int(reservation.Price.Amount),
+		Currency:          reservation.Price.Currency,
+		NumberOfDays:      reservation.NumberOfDays,
+		DateRange:         reservation.DateRange,
+		ReservedAt:        time.Now().Format("2006-01-02 15:04"),
+		Guests:            reservation.Guests,
+	}
+	if reservation.Party != nil {
+		details.Adults = reservation.Party.Adults
+		details.Children = reservation.Party.Children
+		details.Infants = reservation.Party.Infants
+		details.Pets = reservation.Party.Pets
+	}
+	return details
+}
+
+func fromCreateReservationDetails(details events.CreateReservationDetails) (*domain.Reservation, *errors.ReservationError) {
+	id, err := gocql.ParseUUID(details.ReservationID)
+	if err != nil {
+		return nil, errors.NewReservationError(400, err.Error())
+	}
+	if len(details.DateRange) == 0 {
+		return nil, errors.NewReservationError(400, "Date range is empty")
+	}
+	reservation := &domain.Reservation{
+		Id:                id,
+		UserID:            details.UserID,
+		HostID:            details.HostID,
+		AccommodationID:   details.AccommodationID,
+		Username:          details.Username,
+		AccommodationName: details.AccommodationName,
+		Location:          details.Location,
+		Price:             domain.StoredMoney(details.Price, details.Currency),
+		NumberOfDays:      details.NumberOfDays,
+		DateRange:         details.DateRange,
+		Guests:            details.Guests,
+		State:             domain.Requested,
+		StateChangedAt:    map[domain.ReservationState]time.Time{domain.Requested: requestedAt(details.ReservedAt)},
+		IsActive:          true,
+	}
+	if details.Adults > 0 {
+		reservation.Party = &domain.Party{Adults: details.Adults, Children: details.Children, Infants: details.Infants, Pets: details.Pets}
+	}
+	return reservation, nil
+}
+
+// requestedAt is when the guest asked for the reservation, or now for sagas
+// started without it.
+func requestedAt(reservedAt string) time.Time {
+	at, err := time.ParseInLocation("2006-01-02 15:04", reservedAt, time.Local)
+	if err != nil {
+		return time.Now()
+	}
+	return at
 }
 
 func (r ReservationService) CreateAvailability(ctx context.Context, reservation domain.FreeReservation) (*domain.FreeReservation, *errors.ReservationError) {
@@ -138,11 +385,35 @@ func (s *ReservationService) ProcessDateRange(ctx context.Context, accommodation
 		uniqueAccommodations[accommodation] = struct{}{}
 	}
 
+	blockedAccommodations, err := s.repo.BlockedInDateRange(ctx, accommodationIDs, dateRange)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, err
+	}
+
+	for _, accommodation := range blockedAccommodations {
+		uniqueAccommodations[accommodation] = struct{}{}
+	}
+
+	var remaining []string
+	for _, accommodationID := range accommodationIDs {
+		if _, excluded := uniqueAccommodations[accommodationID]; !excluded {
+			remaining = append(remaining, accommodationID)
+		}
+	}
+	ruledOut, err := s.stayRulesViolated(ctx, remaining, dateRange)
+	if err != nil {
+		return nil, err
+	}
+	for _, accommodation := range ruledOut {
+		uniqueAccommodations[accommodation] = struct{}{}
+	}
+
 	result := make([]string, 0, len(uniqueAccommodations))
 	for key := range uniqueAccommodations {
 		result = append(result, key)
 	}
-	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found accommodations that have reservations and don't have availability by date range: %v", result))
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found accommodations that have reservations, don't have availability, are blocked by their calendars or don't allow the stay by date range: %v", result))
 	return result, nil
 }
 
@@ -180,17 +451,186 @@ func (s *ReservationService) GetAvailabilityForAccommodation(ctx context.Context
 	return avl, nil
 }
 
-func (s *ReservationService) DeleteReservationById(ctx context.Context, country string, id, userID, hostID, accommodationID, endDate string) (*domain.Reservation, *errors.ReservationError) {
-	ctx, span := s.tracer.Start(ctx, "ReservationService.DeleteReservationById")
+// CancelReservationById moves the reservation to Cancelled and frees its nights,
+// which are offered to the guests waiting for them.
+// The refund follows the cancellation policy of the accommodation and is recorded
+// on the reservation, which is kept so it still counts for the cancellation rate.
+// Cancelling again retries a cancellation whose tables were not all written and
+// keeps the refund it recorded.
+func (s *ReservationService) CancelReservationById(ctx context.Context, id, userID string) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CancelReservationById")
+	defer span.End()
+	reservationID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		return nil, errors.NewReservationError(400, "Invalid reservation id")
+	}
+	reservation, err := s.getReservation(ctx, userID, reservationID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.State == domain.Cancelled && reservation.Refund == nil {
+		return nil, errors.NewReservationError(409, fmt.Sprintf("Reservation is already %s", domain.Cancelled))
+	}
+	if reservation.State != domain.Cancelled {
+		if !reservation.State.CanTransitionTo(domain.Cancelled) {
+			return nil, errors.NewReservationError(409, fmt.Sprintf("Reservation cannot go from %s to %s", reservation.State, domain.Cancelled))
+		}
+		refund, err := s.refundFor(ctx, reservation, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		reservation.Refund = refund
+	}
+	reservation, err = s.applyTransition(ctx, reservation, domain.Cancelled)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.ReleaseNights(ctx, reservation.AccommodationID, reservation.Id, reservation.DateRange); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release nights of canceled reservation %s", id))
+	}
+	if err := s.repo.DeleteRequest(ctx, reservation.HostID, reservation.Id); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to delete request of canceled reservation %s", id))
+	}
+	s.notification.SendReservationCanceledNotification(ctx, reservation.HostID, "Reservation canceled!")
+	s.offerFreedDates(ctx, reservation.AccommodationID)
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Canceled reservation: %v, refund %s of %s", reservation.Id, reservation.Refund.RefundAmount, reservation.Refund.TotalPrice))
+	return reservation, nil
+}
+
+// refundFor computes the refund of cancelling the reservation now. Requests the
+// host has not accepted yet are refunded in full.
+func (s *ReservationService) refundFor(ctx context.Context, reservation *domain.Reservation, cancelledAt time.Time) (*domain.Refund, *errors.ReservationError) {
+	var refund *domain.Refund
+	var refundErr error
+	if reservation.State == domain.Requested {
+		refund, refundErr = domain.FullRefund(reservation, cancelledAt)
+	} else {
+		policy, err := s.GetCancellationPolicy(ctx, reservation.AccommodationID)
+		if err != nil {
+			return nil, err
+		}
+		refund, refundErr = policy.RefundFor(reservation, cancelledAt)
+	}
+	if refundErr != nil {
+		s.logger.LogError("reservationsService", refundErr.Error())
+		return nil, errors.NewReservationError(500, refundErr.Error())
+	}
+	return refund, nil
+}
+
+func (s *ReservationService) GetCancellationPolicy(ctx context.Context, accommodationID string) (*domain.CancellationPolicy, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetCancellationPolicy")
 	defer span.End()
-	deletedReservation, err := s.repo.DeleteById(ctx, country, id, userID, hostID, accommodationID, endDate)
+	policy, err := s.repo.GetCancellationPolicy(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return policy, nil
+}
+
+// SetHostCancellationPolicy stores the policy a host sets for one of their
+// accommodations.
+func (s *ReservationService) SetHostCancellationPolicy(ctx context.Context, hostID string, policy *domain.CancellationPolicy) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetHostCancellationPolicy")
+	defer span.End()
+	if err := s.checkOwner(ctx, hostID, policy.AccommodationID); err != nil {
+		return err
+	}
+	return s.SetCancellationPolicy(ctx, policy)
+}
+
+// SetCancellationPolicy stores the policy without checking who sets it, for the
+// create accommodation saga.
+func (s *ReservationService) SetCancellationPolicy(ctx context.Context, policy *domain.CancellationPolicy) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetCancellationPolicy")
+	defer span.End()
+	if err := policy.Validate(); err != nil {
+		return errors.NewReservationError(400, err.Error())
+	}
+	if err := s.repo.SaveCancellationPolicy(ctx, policy); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+// transition loads a reservation and moves it to next, refusing transitions the
+// state machine does not allow.
+func (s *ReservationService) transition(ctx context.Context, userID string, id gocql.UUID, next domain.ReservationState) (*domain.Reservation, *errors.ReservationError) {
+	found, err := s.getReservation(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return s.applyTransition(ctx, found, next)
+}
+
+func (s *ReservationService) getReservation(ctx context.Context, userID string, id gocql.UUID) (*domain.Reservation, *errors.ReservationError) {
+	found, err := s.repo.GetReservation(ctx, userID, id)
+	if err != nil {
+		if reservationErr, ok := err.(*errors.ReservationError); ok {
+			return nil, reservationErr
+		}
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return found, nil
+}
+
+// applyTransition moves the reservation to next. A reservation already in next is
+// taken for a retry of a transition whose tables were not all written, which
+// succeeds once they are repaired.
+func (s *ReservationService) applyTransition(ctx context.Context, reservation *domain.Reservation, next domain.ReservationState) (*domain.Reservation, *errors.ReservationError) {
+	previous := reservation.State
+	if previous == next {
+		repaired, err := s.repo.RepairReservation(ctx, reservation)
+		if err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			return nil, errors.NewReservationError(500, err.Error())
+		}
+		if !repaired {
+			return nil, errors.NewReservationError(409, fmt.Sprintf("Reservation is already %s", next))
+		}
+		return reservation, nil
+	}
+	if !reservation.Transition(next, time.Now()) {
+		return nil, errors.NewReservationError(409, fmt.Sprintf("Reservation cannot go from %s to %s", previous, next))
+	}
+	applied, err := s.repo.UpdateState(ctx, reservation, previous)
 	if err != nil {
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
 	}
-	s.notification.SendReservationCanceledNotification(ctx, hostID, "Reservation canceled!")
-	s.logger.LogInfo("reservationsService", fmt.Sprintf("Deleted reservations by id: %v", deletedReservation))
-	return deletedReservation, nil
+	if !applied {
+		return nil, errors.NewReservationError(409, "Reservation was changed concurrently")
+	}
+	return reservation, nil
+}
+
+// completed returns the reservations whose stay is completed, counting stays that
+// are over but that CloseStays has not completed yet.
+func completed(reservations []domain.Reservation, now time.Time) []domain.Reservation {
+	var filtered []domain.Reservation
+	for _, reservation := range reservations {
+		if reservation.State == domain.Completed || isOver(reservation, now) {
+			filtered = append(filtered, reservation)
+		}
+	}
+	return filtered
+}
+
+// isOver reports whether a confirmed or checked in stay has ended.
+func isOver(reservation domain.Reservation, now time.Time) bool {
+	return (reservation.State == domain.Confirmed || reservation.State == domain.CheckedIn) && reservation.HasEnded(now)
+}
+
+func withState(reservations []domain.Reservation, state domain.ReservationState) []domain.Reservation {
+	var filtered []domain.Reservation
+	for _, reservation := range reservations {
+		if reservation.State == state {
+			filtered = append(filtered, reservation)
+		}
+	}
+	return filtered
 }
 
 func (s *ReservationService) IsAvailable(ctx context.Context, accommodationID string, dateRange []string) (bool, *errors.ReservationError) {
@@ -251,14 +691,20 @@ func (s *ReservationService) CalculatePercentageCanceled(ctx context.Context, ho
 	}
 	totalReservations, erro := s.getTotalReservationsByHost(ctx, hostID)
 	if erro != nil {
-		s.logger.LogError("reservationsService", err.Error())
+		s.logger.LogError("reservationsService", erro.Error())
 		return 0, errors.NewReservationError(500, "Cannot retrive the total number of reservations")
 	}
-	percentageCanceled := float32(numberOfCanceled)/float32(totalReservations) + float32(numberOfCanceled)*100
+	if totalReservations == 0 {
+		return 0, nil
+	}
+	percentageCanceled := float32(numberOfCanceled) * 100 / float32(totalReservations)
 	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found cancelation percentege: %v", percentageCanceled))
 	return percentageCanceled, nil
 }
 
+// GetReservationsByAccommodationWithEndDate returns the completed stays of the
+// guest at the accommodation, which let the guest rate it. A stay the host checked
+// the guest out of counts before its end date.
 func (s *ReservationService) GetReservationsByAccommodationWithEndDate(ctx context.Context, accommodationID, userID string) ([]domain.Reservation, *errors.ReservationError) {
 	ctx, span := s.tracer.Start(ctx, "ReservationService.GetReservationsByAccommodationWithEndDate")
 	defer span.End()
@@ -267,10 +713,13 @@ func (s *ReservationService) GetReservationsByAccommodationWithEndDate(ctx conte
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
 	}
-	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found expired reservations by accommodationID: %v", reservations))
+	reservations = completed(reservations, time.Now())
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found completed reservations by accommodationID: %v", reservations))
 	return reservations, nil
 }
 
+// GetReservationsByHostWithEndDate returns the completed stays of the guest with
+// the host, which let the guest rate the host.
 func (s *ReservationService) GetReservationsByHostWithEndDate(ctx context.Context, hostID, userID string) ([]domain.Reservation, *errors.ReservationError) {
 	ctx, span := s.tracer.Start(ctx, "ReservationService.GetReservationsByHostWithEndDate")
 	defer span.End()
@@ -279,14 +728,15 @@ func (s *ReservationService) GetReservationsByHostWithEndDate(ctx context.Contex
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
 	}
-	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found expired reservations by hostID: %v", reservations))
+	reservations = completed(reservations, time.Now())
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found completed reservations by hostID: %v", reservations))
 	return reservations, nil
 }
 
-func (s *ReservationService) DeleteAvl(ctx context.Context, accommodationID, id, country string, price int) (*domain.FreeReservation, *errors.ReservationError) {
+func (s *ReservationService) DeleteAvl(ctx context.Context, accommodationID, id, country string) (*domain.FreeReservation, *errors.ReservationError) {
 	ctx, span := s.tracer.Start(ctx, "ReservationService.DeleteAvl")
 	defer span.End()
-	deletedAvl, err := s.repo.DeleteAvl(ctx, accommodationID, id, country, price)
+	deletedAvl, err := s.repo.DeleteAvl(ctx, accommodationID, id, country)
 	if err != nil {
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
@@ -295,11 +745,108 @@ func (s *ReservationService) DeleteAvl(ctx context.Context, accommodationID, id,
 	return deletedAvl, nil
 }
 
-func (s *ReservationService) UpdateAvailability(ctx context.Context, accommodationID, id, country string, price int, reservation *domain.FreeReservation) (*domain.FreeReservation, *errors.ReservationError) {
+func (s *ReservationService) DeleteAvailabilityForAccommodation(ctx context.Context, accommodationID string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.DeleteAvailabilityForAccommodation")
+	defer span.End()
+	err := s.repo.DeleteAvailabilityForAccommodation(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Deleted availability of accommodation: %v", accommodationID))
+	return nil
+}
+
+// RollbackAvailability compensates the create accommodation saga. The availability
+// is removed only while the accommodation is still Pending or already gone; an
+// accommodation approved after its saga timed out keeps it. The rollback is
+// recorded so a redelivered create availability command is refused.
+func (s *ReservationService) RollbackAvailability(ctx context.Context, accommodationID string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.RollbackAvailability")
+	defer span.End()
+	if s.accommodations == nil {
+		return errors.NewReservationError(503, "Accommodations service is not configured")
+	}
+	status, err := s.accommodations.GetStatus(ctx, accommodationID)
+	if err != nil && err.Status != 404 {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to get status of accommodation %v: %s", accommodationID, err.Message))
+		return err
+	}
+	if err == nil && status != pendingAccommodation {
+		s.logger.LogInfo("reservationsService", fmt.Sprintf("Accommodation %v is %s, keeping its availability", accommodationID, status))
+		return nil
+	}
+	if err := s.repo.MarkRolledBack(ctx, accommodationID); err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	return s.DeleteAvailabilityForAccommodation(ctx, accommodationID)
+}
+
+// AvailabilityRolledBack reports whether the creation of the accommodation was
+// rolled back.
+func (s *ReservationService) AvailabilityRolledBack(ctx context.Context, accommodationID string) (bool, *errors.ReservationError) {
+	rolledBack, err := s.repo.IsRolledBack(ctx, accommodationID)
+	if err != nil {
+		return false, errors.NewReservationError(500, err.Error())
+	}
+	return rolledBack, nil
+}
+
+// UserDeleteAllowed reports whether the user has no open reservations left, as
+// guest or as host, so the account may be deleted.
+func (s *ReservationService) UserDeleteAllowed(ctx context.Context, userID, role string) (bool, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.UserDeleteAllowed")
+	defer span.End()
+	var reservations []domain.Reservation
+	var err *errors.ReservationError
+	if role == "Host" {
+		reservations, err = s.GetReservationsByHost(ctx, userID)
+	} else {
+		reservations, err = s.GetReservationsByUser(ctx, userID)
+	}
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	for _, reservation := range reservations {
+		if reservation.State.IsOpen() && !isOver(reservation, now) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func (s *ReservationService) ArchiveAvailability(ctx context.Context, hostID string, accommodationIDs []string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ArchiveAvailability")
+	defer span.End()
+	err := s.repo.ArchiveAvailability(ctx, hostID, accommodationIDs)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+func (s *ReservationService) RestoreAvailability(ctx context.Context, hostID string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.RestoreAvailability")
+	defer span.End()
+	err := s.repo.RestoreAvailability(ctx, hostID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+func (s *ReservationService) UpdateAvailability(ctx context.Context, accommodationID, id, country string, reservation *domain.FreeReservation) (*domain.FreeReservation, *errors.ReservationError) {
 	ctx, span := s.tracer.Start(ctx, "ReservationService.UpdateAvailability")
 	defer span.End()
 	s.logger.LogInfo("reservationService", fmt.Sprintf("Vrednost: %v", reservation))
-	_, err := s.repo.DeleteAvl(ctx, accommodationID, id, country, price)
+	s.validator.ValidateAvailability(reservation)
+	if len(s.validator.GetErrors()) > 0 {
+		return nil, errors.NewReservationError(400, "Validation failed")
+	}
+	_, err := s.repo.DeleteAvl(ctx, accommodationID, id, country)
 	if err != nil {
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
@@ -314,14 +861,55 @@ func (s *ReservationService) UpdateAvailability(ctx context.Context, accommodati
 	return updatedReservation, nil
 }
 
-func (s *ReservationService) GetAccommodationIDsByMaxPrice(ctx context.Context, maxPrice int) ([]string, *errors.ReservationError) {
+// GetAccommodationIDsByMaxPrice finds the accommodations with a nightly rate of at
+// most maxPrice. Rates in other currencies are converted to the currency of
+// maxPrice before they are compared.
+func (s *ReservationService) GetAccommodationIDsByMaxPrice(ctx context.Context, maxPrice domain.Money) ([]string, *errors.ReservationError) {
 	ctx, span := s.tracer.Start(ctx, "ReservationService.GetAccommodationIDsByMaxPrice")
 	defer span.End()
-	accommodations, err := s.repo.GetAccommodationIDsByMaxPrice(ctx, maxPrice)
+	bound, boundErr := s.storedPriceBound(maxPrice)
+	if boundErr != nil {
+		return nil, errors.NewReservationError(400, boundErr.Error())
+	}
+	prices, err := s.repo.GetAvailabilityPricesBelow(ctx, bound)
 	if err != nil {
 		s.logger.LogError("reservationsService", err.Error())
 		return nil, errors.NewReservationError(500, err.Error())
 	}
+	var accommodations []string
+	found := make(map[string]bool)
+	for _, price := range prices {
+		converted, convertErr := exchange.Convert(s.rates, price.Price, maxPrice.Currency)
+		if convertErr != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to compare price of %s: %s", price.AccommodationID, convertErr.Error()))
+			continue
+		}
+		if converted.Amount <= maxPrice.Amount && !found[price.AccommodationID] {
+			found[price.AccommodationID] = true
+			accommodations = append(accommodations, price.AccommodationID)
+		}
+	}
 	s.logger.LogInfo("reservationsService", fmt.Sprintf("Found accommodations by price: %v", accommodations))
 	return accommodations, nil
 }
+
+// storedPriceBound is the highest price, as stored in any currency, that can still
+// be at most maxPrice. It leaves a percent of slack for rounding, the exact check
+// is done after conversion. Prices stored as whole units are always below it.
+func (s *ReservationService) storedPriceBound(maxPrice domain.Money) (int, error) {
+	var bound int64
+	for _, currency := range s.rates.Currencies() {
+		converted, err := exchange.Convert(s.rates, maxPrice, currency)
+		if err != nil {
+			return 0, err
+		}
+		if converted.Amount > bound {
+			bound = converted.Amount
+		}
+	}
+	bound += bound/100 + 1
+	if bound > domain.MaxNightlyRate {
+		bound = domain.MaxNightlyRate
+	}
+	return int(bound), nil
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"reservation-service/domain"
+	"reservation-service/exchange"
+	"testing"
+)
+
+func TestStoredPriceBound(t *testing.T) {
+	rates, err := exchange.NewStaticProviderFromRates("USD", map[string]float64{"EUR": 0.5, "JPY": 150})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &ReservationService{rates: rates}
+	tests := []struct {
+		name     string
+		maxPrice domain.Money
+		want     int
+	}{
+		{name: "zero decimal currency stores the most units", maxPrice: domain.Money{Amount: 10000, Currency: "USD"}, want: 15000 + 150 + 1},
+		{name: "the asked currency stores the most units", maxPrice: domain.Money{Amount: 100, Currency: "JPY"}, want: 100 + 1 + 1},
+		{name: "capped at the largest nightly rate", maxPrice: domain.Money{Amount: domain.MaxNightlyRate, Currency: "USD"}, want: domain.MaxNightlyRate},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			bound, err := s.storedPriceBound(test.maxPrice)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if bound != test.want {
+				t.Fatalf("got %d, want %d", bound, test.want)
+			}
+		})
+	}
+}
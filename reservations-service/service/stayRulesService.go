@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+)
+
+// CheckStayRules tells whether a stay of the given nights keeps the rules of the
+// availability window it checks in on. Nights outside every window are left to the
+// availability checks.
+func (s *ReservationService) CheckStayRules(ctx context.Context, accommodationID string, nights []string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CheckStayRules")
+	defer span.End()
+	windows, err := s.repo.PriceWindows(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return checkStayRules(nights, windows, time.Now())
+}
+
+func checkStayRules(nights []string, windows []domain.DateRangeWithPrice, now time.Time) *errors.ReservationError {
+	rules, found := domain.StayRulesFor(nights, windows)
+	if !found {
+		return nil
+	}
+	if err := rules.Check(nights, now); err != nil {
+		return errors.NewReservationError(400, fmt.Sprintf("Stay not allowed: %s", err.Error()))
+	}
+	return nil
+}
+
+// stayRulesViolated returns the accommodations whose stay rules do not allow a stay
+// of the given nights.
+func (s *ReservationService) stayRulesViolated(ctx context.Context, accommodationIDs []string, nights []string) ([]string, *errors.ReservationError) {
+	var violated []string
+	for _, accommodationID := range accommodationIDs {
+		err := s.CheckStayRules(ctx, accommodationID, nights)
+		if err == nil {
+			continue
+		}
+		if err.Status >= 500 {
+			return nil, err
+		}
+		violated = append(violated, accommodationID)
+	}
+	return violated, nil
+}
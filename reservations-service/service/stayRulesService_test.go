@@ -0,0 +1,53 @@
+package service
+
+import (
+	"reservation-service/domain"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckStayRules(t *testing.T) {
+	// A Sunday.
+	now := time.Date(2026, time.March, 1, 10, 0, 0, 0, time.UTC)
+	windows := []domain.DateRangeWithPrice{
+		{
+			DateRange: []string{"2026-03-06", "2026-03-07", "2026-03-08", "2026-03-09"},
+			Price:     domain.Money{Amount: 10000, Currency: "USD"},
+			Rules:     domain.StayRules{MinNights: 2, CheckInDays: []string{"Friday", "Saturday"}, MinNoticeDays: 5},
+		},
+		{
+			DateRange: []string{"2026-03-10", "2026-03-11"},
+			Price:     domain.Money{Amount: 10000, Currency: "USD"},
+		},
+	}
+	tests := []struct {
+		name   string
+		nights []string
+		err    string
+	}{
+		{name: "keeps the rules", nights: []string{"2026-03-06", "2026-03-07"}},
+		{name: "too short", nights: []string{"2026-03-07"}, err: "Stay not allowed: stays must be at least 2 nights"},
+		{name: "wrong check-in day", nights: []string{"2026-03-08", "2026-03-09"}, err: "Stay not allowed: check-in is only possible on Friday, Saturday"},
+		{name: "rules of a later window do not apply", nights: []string{"2026-03-09", "2026-03-10"}, err: "check-in is only possible"},
+		{name: "window without rules", nights: []string{"2026-03-10"}},
+		{name: "outside every window is left to the availability checks", nights: []string{"2026-03-02"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkStayRules(test.nights, windows, now)
+			if test.err == "" {
+				if err != nil {
+					t.Fatalf("refused: %s", err.Message)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatal("accepted")
+			}
+			if err.Status != 400 || !strings.Contains(err.Message, test.err) {
+				t.Fatalf("refused with %d %q, want 400 %q", err.Status, err.Message, test.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,216 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// GetArrivalInstructions returns the arrival instructions of an accommodation to
+// its host.
+func (s *ReservationService) GetArrivalInstructions(ctx context.Context, hostID, accommodationID string) (*domain.ArrivalInstructions, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetArrivalInstructions")
+	defer span.End()
+	if err := s.checkOwner(ctx, hostID, accommodationID); err != nil {
+		return nil, err
+	}
+	instructions, err := s.repo.GetArrivalInstructions(ctx, accommodationID)
+	if err != nil {
+		if reservationErr, ok := err.(*errors.ReservationError); ok {
+			return nil, reservationErr
+		}
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return instructions, nil
+}
+
+// SetArrivalInstructions replaces the arrival instructions of an accommodation.
+// Guests already sent the previous ones are not sent these.
+func (s *ReservationService) SetArrivalInstructions(ctx context.Context, hostID string, instructions *domain.ArrivalInstructions) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SetArrivalInstructions")
+	defer span.End()
+	if err := instructions.Validate(); err != nil {
+		return errors.NewReservationError(400, err.Error())
+	}
+	if err := s.checkOwner(ctx, hostID, instructions.AccommodationID); err != nil {
+		return err
+	}
+	if err := s.repo.SaveArrivalInstructions(ctx, instructions); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+func (s *ReservationService) DeleteArrivalInstructions(ctx context.Context, hostID, accommodationID string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.DeleteArrivalInstructions")
+	defer span.End()
+	if err := s.checkOwner(ctx, hostID, accommodationID); err != nil {
+		return err
+	}
+	if err := s.repo.DeleteArrivalInstructions(ctx, accommodationID); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	return nil
+}
+
+// CheckIn records that the host let the guest of a confirmed reservation in. Guests
+// can be checked in from the first night of their stay.
+func (s *ReservationService) CheckIn(ctx context.Context, hostID, id string) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CheckIn")
+	defer span.End()
+	reservation, err := s.getHostReservation(ctx, hostID, id)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if reservation.StartDate > now.Format("2006-01-02") {
+		return nil, errors.NewReservationError(409, fmt.Sprintf("The stay starts on %s", reservation.StartDate))
+	}
+	if reservation.HasEnded(now) {
+		return nil, errors.NewReservationError(409, "The stay is over")
+	}
+	reservation, err = s.applyTransition(ctx, reservation, domain.CheckedIn)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.metricClient.SendCheckedIn(ctx, reservation.UserID, reservation.AccommodationID, reservation.Id.String(), now); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to record check-in of reservation %v: %s", reservation.Id, err.Message))
+	}
+	s.notification.SendStayNotification(ctx, reservation.UserID, fmt.Sprintf("Welcome to %s, you are checked in", reservation.AccommodationName))
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Checked in reservation: %v", reservation.Id))
+	return reservation, nil
+}
+
+// CheckOut records that the guest left and completes the reservation, which makes
+// the stay count for rating the host and the accommodation.
+func (s *ReservationService) CheckOut(ctx context.Context, hostID, id string) (*domain.Reservation, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CheckOut")
+	defer span.End()
+	reservation, err := s.getHostReservation(ctx, hostID, id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.State != domain.CheckedIn && reservation.State != domain.Completed {
+		return nil, errors.NewReservationError(409, fmt.Sprintf("Reservation cannot be checked out from %s", reservation.State))
+	}
+	reservation, err = s.applyTransition(ctx, reservation, domain.Completed)
+	if err != nil {
+		return nil, err
+	}
+	s.checkedOut(ctx, reservation, reservation.StateChangedAt[domain.Completed])
+	return reservation, nil
+}
+
+// checkedOut records the check-out of a completed stay and thanks the guest.
+func (s *ReservationService) checkedOut(ctx context.Context, reservation *domain.Reservation, at time.Time) {
+	if err := s.metricClient.SendCheckedOut(ctx, reservation.UserID, reservation.AccommodationID, reservation.Id.String(), at); err != nil {
+		s.logger.LogError("reservationsService", fmt.Sprintf("Unable to record check-out of reservation %v: %s", reservation.Id, err.Message))
+	}
+	s.notification.SendStayNotification(ctx, reservation.UserID,
+		fmt.Sprintf("Thank you for staying at %s, you can now rate your host and the accommodation", reservation.AccommodationName))
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("Checked out reservation: %v", reservation.Id))
+}
+
+func (s *ReservationService) getHostReservation(ctx context.Context, hostID, id string) (*domain.Reservation, *errors.ReservationError) {
+	reservationID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		return nil, errors.NewReservationError(400, "Invalid reservation id")
+	}
+	reservation, err := s.repo.GetReservationOfHost(ctx, hostID, reservationID)
+	if err != nil {
+		if reservationErr, ok := err.(*errors.ReservationError); ok {
+			return nil, reservationErr
+		}
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return reservation, nil
+}
+
+// SendArrivalInstructions sends the guests whose check-in is close enough the
+// arrival instructions of their accommodation, once per reservation. Instructions
+// are sent as late as the start of the stay, so guests who booked within the
+// notice still get them. They go out as a notification, which the notification
+// service also mails to the guest.
+func (s *ReservationService) SendArrivalInstructions(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.SendArrivalInstructions")
+	defer span.End()
+	all, err := s.repo.GetAllArrivalInstructions(ctx)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	for _, instructions := range all {
+		until := now.Add(time.Duration(instructions.NoticeHours)*time.Hour + 24*time.Hour).Format("2006-01-02")
+		arriving, err := s.repo.ArrivingReservations(ctx, instructions.AccommodationID, today, until)
+		if err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			continue
+		}
+		for _, reservation := range arriving {
+			sendAt, err := instructions.SendAt(reservation.StartDate, now.Location())
+			if err != nil || now.Before(sendAt) {
+				continue
+			}
+			claimed, err := s.repo.ClaimArrivalNotice(ctx, reservation.Id, now)
+			if err != nil {
+				s.logger.LogError("reservationsService", err.Error())
+				continue
+			}
+			if !claimed {
+				continue
+			}
+			s.notification.SendStayNotification(ctx, reservation.UserID, instructions.Message(reservation))
+			s.logger.LogInfo("reservationsService", fmt.Sprintf("Sent arrival instructions of reservation %v", reservation.Id))
+		}
+	}
+}
+
+// CloseStays completes the confirmed and checked in reservations whose stay is
+// over, so the state alone tells which stays may be rated. Guests the host did not
+// check out of are checked out. Readers count stays that are over as completed
+// until it runs.
+func (s *ReservationService) CloseStays(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.CloseStays")
+	defer span.End()
+	now := time.Now()
+	ended, err := s.repo.GetEndedStays(ctx, now.Format("2006-01-02"))
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	for i := range ended {
+		reservation := &ended[i]
+		staying := reservation.State == domain.CheckedIn
+		if _, err := s.applyTransition(ctx, reservation, domain.Completed); err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to complete reservation %v: %s", reservation.Id, err.Message))
+			continue
+		}
+		if staying {
+			s.checkedOut(ctx, reservation, now)
+		}
+	}
+}
+
+// WatchStays sends arrival instructions and closes finished stays every interval
+// until ctx is done.
+func (s *ReservationService) WatchStays(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.SendArrivalInstructions(ctx)
+			s.CloseStays(ctx)
+		}
+	}
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"reservation-service/domain"
+	"reservation-service/errors"
+	"sort"
+	"time"
+
+	"github.com/gocql/gocql"
+)
+
+// JoinWaitlist puts a guest on the waitlist for dates of an accommodation that are
+// booked. Dates that are free are booked instead, and dates the host does not offer
+// are never freed.
+func (s *ReservationService) JoinWaitlist(ctx context.Context, entry domain.WaitlistEntry) (*domain.WaitlistEntry, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.JoinWaitlist")
+	defer span.End()
+	if len(entry.DateRange) == 0 {
+		return nil, errors.NewReservationError(400, "Date range is empty")
+	}
+	sort.Strings(entry.DateRange)
+	if entry.DateRange[0] < time.Now().Format("2006-01-02") {
+		return nil, errors.NewReservationError(400, "Date range has already started")
+	}
+	available, err := s.IsAvailable(ctx, entry.AccommodationID, entry.DateRange)
+	if err != nil {
+		return nil, err
+	}
+	if !available {
+		return nil, errors.NewReservationError(400, "Accommodation not available for the specified date range")
+	}
+	if err := s.CheckStayRules(ctx, entry.AccommodationID, entry.DateRange); err != nil {
+		return nil, err
+	}
+	reserved, err := s.IsReserved(ctx, entry.AccommodationID, entry.DateRange)
+	if err != nil {
+		return nil, err
+	}
+	if !reserved {
+		return nil, errors.NewReservationError(409, "The dates are not booked, reserve them instead")
+	}
+	waitlist, waitlistErr := s.repo.Waitlist(ctx, entry.AccommodationID)
+	if waitlistErr != nil {
+		return nil, errors.NewReservationError(500, waitlistErr.Error())
+	}
+	for _, waiting := range waitlist {
+		if waiting.UserID == entry.UserID && (waiting.State == domain.Waiting || waiting.State == domain.Offered) && waiting.Overlaps(entry.DateRange) {
+			return nil, errors.NewReservationError(409, "You already wait for these dates")
+		}
+	}
+	if entry.Guests < 1 {
+		entry.Guests = 1
+	}
+	entry.Id = gocql.TimeUUID()
+	entry.JoinedAt = entry.Id.Time()
+	entry.State = domain.Waiting
+	entry.OfferExpiresAt = time.Time{}
+	if err := s.repo.InsertWaitlistEntry(ctx, &entry); err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	s.logger.LogInfo("reservationsService", fmt.Sprintf("User %v waits for %d nights of accommodation %v", entry.UserID, len(entry.DateRange), entry.AccommodationID))
+	return &entry, nil
+}
+
+// GetWaitlist returns the waitlist entries of a guest, latest first.
+func (s *ReservationService) GetWaitlist(ctx context.Context, userID string) ([]domain.WaitlistEntry, *errors.ReservationError) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.GetWaitlist")
+	defer span.End()
+	entries, err := s.repo.WaitlistOfUser(ctx, userID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return nil, errors.NewReservationError(500, err.Error())
+	}
+	return entries, nil
+}
+
+// LeaveWaitlist removes a guest from the waitlist. Dates offered to the guest are
+// offered to the next in line.
+func (s *ReservationService) LeaveWaitlist(ctx context.Context, userID, accommodationID, id string) *errors.ReservationError {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.LeaveWaitlist")
+	defer span.End()
+	entryID, parseErr := gocql.ParseUUID(id)
+	if parseErr != nil {
+		return errors.NewReservationError(400, "Invalid waitlist entry id")
+	}
+	entry, err := s.repo.GetWaitlistEntry(ctx, accommodationID, entryID)
+	if err != nil {
+		if reservationErr, ok := err.(*errors.ReservationError); ok {
+			return reservationErr
+		}
+		return errors.NewReservationError(500, err.Error())
+	}
+	if entry.UserID != userID {
+		return errors.NewReservationError(404, "Waitlist entry not found")
+	}
+	if err := s.repo.DeleteWaitlistEntry(ctx, entry); err != nil {
+		return errors.NewReservationError(500, err.Error())
+	}
+	if entry.HoldsOffer(time.Now()) {
+		if err := s.repo.ReleaseNights(ctx, accommodationID, entry.Id, entry.DateRange); err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release nights offered to waitlist entry %v", entry.Id))
+		}
+		s.offerFreedDates(ctx, accommodationID)
+	}
+	return nil
+}
+
+// offerFreedDates walks the waitlist of an accommodation in the order the guests
+// joined and offers each guest whose dates are free again a window to book them.
+// The offered nights are claimed for the entry until the offer expires, so nobody
+// else can book them in the meantime, and later guests only get them once the
+// offer expires.
+func (s *ReservationService) offerFreedDates(ctx context.Context, accommodationID string) {
+	waitlist, err := s.repo.Waitlist(ctx, accommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	now := time.Now()
+	today := now.Format("2006-01-02")
+	var offered [][]string
+	for i := range waitlist {
+		entry := &waitlist[i]
+		switch {
+		case entry.HoldsOffer(now):
+			offered = append(offered, entry.DateRange)
+		case entry.State == domain.Offered, entry.State == domain.Waiting && entry.DateRange[0] < today:
+			entry.State = domain.Lapsed
+			if err := s.repo.UpdateWaitlistState(ctx, entry); err != nil {
+				s.logger.LogError("reservationsService", err.Error())
+			}
+		}
+	}
+	for i := range waitlist {
+		entry := &waitlist[i]
+		if entry.State != domain.Waiting || overlapsAny(*entry, offered) {
+			continue
+		}
+		available, availableErr := s.IsAvailable(ctx, accommodationID, entry.DateRange)
+		if availableErr != nil || !available {
+			continue
+		}
+		reserved, reservedErr := s.IsReserved(ctx, accommodationID, entry.DateRange)
+		if reservedErr != nil || reserved {
+			continue
+		}
+		claimed, claimErr := s.repo.ClaimNights(ctx, accommodationID, entry.Id, entry.DateRange, s.offerWindow)
+		if claimErr != nil || !claimed {
+			continue
+		}
+		entry.State = domain.Offered
+		entry.OfferExpiresAt = now.Add(s.offerWindow)
+		if err := s.repo.UpdateWaitlistState(ctx, entry); err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+			_ = s.repo.ReleaseNights(ctx, accommodationID, entry.Id, entry.DateRange)
+			continue
+		}
+		offered = append(offered, entry.DateRange)
+		s.notification.SendWaitlistNotification(ctx, entry.UserID,
+			fmt.Sprintf("%s is free from %s to %s, book it by %s", entry.AccommodationName, entry.DateRange[0], entry.DateRange[len(entry.DateRange)-1],
+				entry.OfferExpiresAt.Format("2006-01-02 15:04")))
+		s.logger.LogInfo("reservationsService", fmt.Sprintf("Offered waitlisted dates of accommodation %v to user %v", accommodationID, entry.UserID))
+	}
+}
+
+func overlapsAny(entry domain.WaitlistEntry, dateRanges [][]string) bool {
+	for _, dateRange := range dateRanges {
+		if entry.Overlaps(dateRange) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWaitlistOffers refuses to book dates offered to another waitlisted guest
+// while the offer is open. The claims of the offer refuse them anyway; this only
+// tells the guest why.
+func (s *ReservationService) checkWaitlistOffers(ctx context.Context, reservation domain.Reservation) *errors.ReservationError {
+	waitlist, err := s.repo.Waitlist(ctx, reservation.AccommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return errors.NewReservationError(500, err.Error())
+	}
+	now := time.Now()
+	for _, entry := range waitlist {
+		if entry.UserID != reservation.UserID && entry.HoldsOffer(now) && entry.Overlaps(reservation.DateRange) {
+			return errors.NewReservationError(409, fmt.Sprintf("The dates are offered to a waitlisted guest until %s", entry.OfferExpiresAt.Format("2006-01-02 15:04")))
+		}
+	}
+	return nil
+}
+
+// takeWaitlistOffers hands the nights offered to the guest over to the
+// reservation, which claims them for ttl from there on. It reports whether the
+// guest held an offer for the dates.
+func (s *ReservationService) takeWaitlistOffers(ctx context.Context, reservation domain.Reservation, ttl time.Duration) bool {
+	waitlist, err := s.repo.Waitlist(ctx, reservation.AccommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return false
+	}
+	now := time.Now()
+	taken := false
+	for _, entry := range waitlist {
+		if entry.UserID != reservation.UserID || !entry.HoldsOffer(now) {
+			continue
+		}
+		nights := entry.Overlap(reservation.DateRange)
+		if len(nights) == 0 {
+			continue
+		}
+		taken = true
+		if _, err := s.repo.TakeOverNights(ctx, reservation.AccommodationID, entry.Id, reservation.Id, nights, ttl); err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to hand the nights offered to waitlist entry %v over", entry.Id))
+		}
+	}
+	return taken
+}
+
+// bookWaitlistOffers marks the offers the guest booked once the reservation is
+// confirmed. The offer may have lapsed while the host answered the request.
+func (s *ReservationService) bookWaitlistOffers(ctx context.Context, reservation domain.Reservation) {
+	waitlist, err := s.repo.Waitlist(ctx, reservation.AccommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	for i := range waitlist {
+		entry := &waitlist[i]
+		if !offeredTo(*entry, reservation) || entry.State == domain.Booked {
+			continue
+		}
+		entry.State = domain.Booked
+		if err := s.repo.UpdateWaitlistState(ctx, entry); err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+		}
+	}
+}
+
+// reopenWaitlistOffers puts the offers the guest tried to book back in line when
+// the reservation falls through, and offers the freed dates again. The guests keep
+// their place, so they get the dates first if they are still free.
+func (s *ReservationService) reopenWaitlistOffers(ctx context.Context, reservation domain.Reservation) {
+	waitlist, err := s.repo.Waitlist(ctx, reservation.AccommodationID)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	for i := range waitlist {
+		entry := &waitlist[i]
+		if !offeredTo(*entry, reservation) {
+			continue
+		}
+		if err := s.repo.ReleaseNights(ctx, entry.AccommodationID, entry.Id, entry.DateRange); err != nil {
+			s.logger.LogError("reservationsService", fmt.Sprintf("Unable to release nights offered to waitlist entry %v", entry.Id))
+		}
+		entry.State = domain.Waiting
+		entry.OfferExpiresAt = time.Time{}
+		if err := s.repo.UpdateWaitlistState(ctx, entry); err != nil {
+			s.logger.LogError("reservationsService", err.Error())
+		}
+	}
+	s.offerFreedDates(ctx, reservation.AccommodationID)
+}
+
+// offeredTo reports whether the entry is an offer of the guest of the reservation
+// for its dates, whether still open, lapsed or booked.
+func offeredTo(entry domain.WaitlistEntry, reservation domain.Reservation) bool {
+	return entry.UserID == reservation.UserID && !entry.OfferExpiresAt.IsZero() &&
+		entry.State != domain.Waiting && entry.Overlaps(reservation.DateRange)
+}
+
+// ExpireWaitlistOffers offers the dates of expired offers to the next guests in
+// line.
+func (s *ReservationService) ExpireWaitlistOffers(ctx context.Context) {
+	ctx, span := s.tracer.Start(ctx, "ReservationService.ExpireWaitlistOffers")
+	defer span.End()
+	offers, err := s.repo.GetWaitlistOffers(ctx)
+	if err != nil {
+		s.logger.LogError("reservationsService", err.Error())
+		return
+	}
+	now := time.Now()
+	expired := make(map[string]bool)
+	for _, offer := range offers {
+		if !offer.HoldsOffer(now) && !expired[offer.AccommodationID] {
+			expired[offer.AccommodationID] = true
+			s.offerFreedDates(ctx, offer.AccommodationID)
+		}
+	}
+}
+
+// WatchWaitlist expires waitlist offers every interval until ctx is done.
+func (s *ReservationService) WatchWaitlist(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.ExpireWaitlistOffers(ctx)
+		}
+	}
+}
@@ -11,6 +11,8 @@ const (
 	EndDate           = "End date must be after start date"
 	Username          = "Username field can't be empty"
 	AccommodationName = "Accommodation name can't be empty"
+	Price             = "Price must be a positive amount in minor units of a three letter ISO currency"
+	Rules             = "Stay rules must not be negative, minimums must not exceed maximums and weekdays must be named like Monday"
 )
 
 var errorMessages = map[string]string{
@@ -18,6 +20,8 @@ var errorMessages = map[string]string{
 	"EndDate":           EndDate,
 	"Username":          Username,
 	"AccommodationName": AccommodationName,
+	"Price":             Price,
+	"Rules":             Rules,
 }
 
 type Validator struct {
@@ -131,6 +135,28 @@ func DateNotSame() ValidationRule {
 	}
 }
 
+func PricesValid() ValidationRule {
+	return func(dateRanges []domain.DateRangeWithPrice) bool {
+		for _, dateRangeWithPrice := range dateRanges {
+			if dateRangeWithPrice.Price.ValidateNightlyRate() != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func StayRulesValid() ValidationRule {
+	return func(dateRanges []domain.DateRangeWithPrice) bool {
+		for _, dateRangeWithPrice := range dateRanges {
+			if dateRangeWithPrice.Rules.Validate() != nil {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 func checkLeft(validPair, newPair []time.Time) bool {
 	if newPair[1].Before(validPair[0]) {
 		return true
@@ -215,6 +241,8 @@ func checkPair(pairs []time.Time) bool {
 */
 func (v *Validator) ValidateAvailability(reservation *domain.FreeReservation) {
 	v.ValidateField("dateRange", reservation.DateRange, DateNotSame())
+	v.ValidateField("Price", reservation.DateRange, PricesValid())
+	v.ValidateField("Rules", reservation.DateRange, StayRulesValid())
 	foundErrors := v.GetErrors()
 	if len(foundErrors) > 0 {
 		for field, message := range foundErrors {
@@ -6,17 +6,51 @@ type SagaCommandType int8
 
 type SagaReplyType int8
 
+// AvailableAccommodationDates prices its nights at Price minor units of Currency.
+// Messages sent before prices had a currency carry whole units of the default
+// currency and no Currency.
 type AvailableAccommodationDates struct { // Pomocna struktura za SendCreateAccommodationAvailability
 	AccommodationId string
 	DateRange       []string
 	Location        string
 	Price           int
+	Currency        string
+	// Rules is nil in messages sent before stays had rules, which leaves the
+	// nights without any.
+	Rules *StayRules
+}
+
+type StayRules struct {
+	MinNights      int
+	MaxNights      int
+	CheckInDays    []string
+	CheckOutDays   []string
+	MinNoticeDays  int
+	MaxHorizonDays int
 }
 
 type SendCreateAccommodationAvailability struct { // ekvivalent sa Order Details
-	AccommodationID string
-	Location        string
-	DateRange       []AvailableAccommodationDates
+	AccommodationID    string
+	Location           string
+	DateRange          []AvailableAccommodationDates
+	CancellationPolicy *CancellationPolicy
+	// Paying is empty in messages sent before it was carried, which leaves the
+	// accommodation paid per accommodation.
+	Paying string
+	// HostID is empty in messages sent before it was carried.
+	HostID string
+}
+
+// CancellationPolicy is nil in messages sent before accommodations had one, which
+// leaves the accommodation with the default policy.
+type CancellationPolicy struct {
+	Name  string
+	Tiers []RefundTier
+}
+
+type RefundTier struct {
+	DaysBeforeCheckIn int
+	RefundPercent     int
 }
 
 type CreateAccommodationCommandType int8
@@ -27,6 +61,9 @@ const (
 	DenyAccommodation
 	UpdateAccommodation
 	RollbackAccommodation
+	// RollbackAvailability removes the availability of an accommodation that is
+	// still Pending when the saga gives up on it.
+	RollbackAvailability
 	UnknownCommand
 )
 
@@ -36,6 +73,8 @@ type CreateAccommodationReplyType int8
 const (
 	AvailabilityCreated CreateAccommodationReplyType = iota
 	AvailabilityNotCreated
+	AccommodationApproved
+	AccommodationNotApproved
 	UnknownReply
 )
 
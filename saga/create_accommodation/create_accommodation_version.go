@@ -0,0 +1,65 @@
+package create_accommodation
+
+import "example/saga/messaging"
+
+// Version 1 of the commands and replies sent Type as the iota value of the enum.
+// Version 2 sends the name of the constant instead.
+const (
+	commandMessageType = "create_accommodation.CreateAccommodationCommand"
+	replyMessageType   = "create_accommodation.CreateAccommodationReply"
+	schemaVersion      = 2
+)
+
+var commandNames = map[CreateAccommodationCommandType]string{
+	CreateAvailability:    "CreateAvailability",
+	DenyAccommodation:     "DenyAccommodation",
+	UpdateAccommodation:   "UpdateAccommodation",
+	RollbackAccommodation: "RollbackAccommodation",
+	RollbackAvailability:  "RollbackAvailability",
+	UnknownCommand:        "UnknownCommand",
+}
+
+var replyNames = map[CreateAccommodationReplyType]string{
+	AvailabilityCreated:      "AvailabilityCreated",
+	AvailabilityNotCreated:   "AvailabilityNotCreated",
+	AccommodationApproved:    "AccommodationApproved",
+	AccommodationNotApproved: "AccommodationNotApproved",
+	UnknownReply:             "UnknownReply",
+}
+
+// commandNamesV1 and replyNamesV1 freeze the iota order used by version 1. They
+// must never change, even if the constants above are reordered.
+var commandNamesV1 = []string{"CreateAvailability", "DenyAccommodation", "UpdateAccommodation", "RollbackAccommodation", "UnknownCommand"}
+
+var replyNamesV1 = []string{"AvailabilityCreated", "AvailabilityNotCreated", "AccommodationApproved", "AccommodationNotApproved", "UnknownReply"}
+
+func init() {
+	messaging.RegisterUpcaster(commandMessageType, 1, messaging.TypeToName(commandNamesV1, "UnknownCommand"))
+	messaging.RegisterUpcaster(replyMessageType, 1, messaging.TypeToName(replyNamesV1, "UnknownReply"))
+}
+
+func (CreateAccommodationCommand) MessageType() string { return commandMessageType }
+
+func (CreateAccommodationCommand) SchemaVersion() int { return schemaVersion }
+
+func (CreateAccommodationReply) MessageType() string { return replyMessageType }
+
+func (CreateAccommodationReply) SchemaVersion() int { return schemaVersion }
+
+func (t CreateAccommodationCommandType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(commandNames, t)
+}
+
+func (t *CreateAccommodationCommandType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(commandNames, text, UnknownCommand)
+	return nil
+}
+
+func (t CreateAccommodationReplyType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(replyNames, t)
+}
+
+func (t *CreateAccommodationReplyType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(replyNames, text, UnknownReply)
+	return nil
+}
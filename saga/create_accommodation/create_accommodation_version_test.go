@@ -0,0 +1,42 @@
+package create_accommodation
+
+import (
+	"example/saga/messaging/messagingtest"
+	"testing"
+)
+
+func TestCommandVersions(t *testing.T) {
+	messagingtest.Versions[CreateAccommodationCommand, CreateAccommodationCommandType]{
+		Message: func(commandType CreateAccommodationCommandType) CreateAccommodationCommand {
+			return CreateAccommodationCommand{Type: commandType}
+		},
+		Type:  func(command CreateAccommodationCommand) CreateAccommodationCommandType { return command.Type },
+		Names: commandNames,
+		Version1: map[int]CreateAccommodationCommandType{
+			0: CreateAvailability,
+			1: DenyAccommodation,
+			2: UpdateAccommodation,
+			3: RollbackAccommodation,
+			4: UnknownCommand,
+			5: UnknownCommand,
+		},
+	}.Run(t)
+}
+
+func TestReplyVersions(t *testing.T) {
+	messagingtest.Versions[CreateAccommodationReply, CreateAccommodationReplyType]{
+		Message: func(replyType CreateAccommodationReplyType) CreateAccommodationReply {
+			return CreateAccommodationReply{Type: replyType}
+		},
+		Type:  func(reply CreateAccommodationReply) CreateAccommodationReplyType { return reply.Type },
+		Names: replyNames,
+		Version1: map[int]CreateAccommodationReplyType{
+			0: AvailabilityCreated,
+			1: AvailabilityNotCreated,
+			2: AccommodationApproved,
+			3: AccommodationNotApproved,
+			4: UnknownReply,
+			5: UnknownReply,
+		},
+	}.Run(t)
+}
@@ -0,0 +1,64 @@
+package create_reservation
+
+// CreateReservationDetails is the reservation a guest asked for. The saga is keyed
+// by ReservationID, generated when the hold on the dates is placed. Price is in
+// minor units of Currency; sagas started before prices had a currency carry whole
+// units of the default currency and no Currency.
+type CreateReservationDetails struct {
+	ReservationID     string
+	UserID            string
+	HostID            string
+	AccommodationID   string
+	Username          string
+	AccommodationName string
+	Location          string
+	Price             int
+	NumberOfDays      int
+	DateRange         []string
+	ReservedAt        string
+	Guests            int
+	Currency          string
+	// Adults, Children, Infants and Pets break Guests down. They are zero in
+	// sagas started before parties were recorded.
+	Adults   int
+	Children int
+	Infants  int
+	Pets     int
+}
+
+type CreateReservationCommandType int8
+
+// komande
+const (
+	ReleaseHold CreateReservationCommandType = iota
+	ConfirmReservation
+	CancelReservation
+	RecordUserReserved
+	NotifyHost
+	UnknownCommand
+	// RetractUserReserved is appended so the commands sent before it keep their values.
+	RetractUserReserved
+)
+
+type CreateReservationReplyType int8
+
+// REPLY
+const (
+	ReservationConfirmed CreateReservationReplyType = iota
+	ReservationNotConfirmed
+	UserReservedRecorded
+	UserReservedNotRecorded
+	HostNotified
+	HostNotNotified
+	UnknownReply
+)
+
+type CreateReservationCommand struct {
+	Type    CreateReservationCommandType
+	Payload CreateReservationDetails
+}
+
+type CreateReservationReply struct {
+	Type    CreateReservationReplyType
+	Payload CreateReservationDetails
+}
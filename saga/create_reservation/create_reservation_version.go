@@ -0,0 +1,79 @@
+package create_reservation
+
+import "example/saga/messaging"
+
+// Version 1 of the commands and replies sent Type as the iota value of the enum.
+// Version 2 sends the name of the constant instead.
+const (
+	commandMessageType = "create_reservation.CreateReservationCommand"
+	replyMessageType   = "create_reservation.CreateReservationReply"
+	schemaVersion      = 2
+)
+
+// Envelopes published before the messages were versioned carry the Go type name
+// and version 1.
+const (
+	legacyCommandMessageType = "CreateReservationCommand"
+	legacyReplyMessageType   = "CreateReservationReply"
+)
+
+var commandNames = map[CreateReservationCommandType]string{
+	ReleaseHold:         "ReleaseHold",
+	ConfirmReservation:  "ConfirmReservation",
+	CancelReservation:   "CancelReservation",
+	RecordUserReserved:  "RecordUserReserved",
+	NotifyHost:          "NotifyHost",
+	UnknownCommand:      "UnknownCommand",
+	RetractUserReserved: "RetractUserReserved",
+}
+
+var replyNames = map[CreateReservationReplyType]string{
+	ReservationConfirmed:    "ReservationConfirmed",
+	ReservationNotConfirmed: "ReservationNotConfirmed",
+	UserReservedRecorded:    "UserReservedRecorded",
+	UserReservedNotRecorded: "UserReservedNotRecorded",
+	HostNotified:            "HostNotified",
+	HostNotNotified:         "HostNotNotified",
+	UnknownReply:            "UnknownReply",
+}
+
+// commandNamesV1 and replyNamesV1 freeze the iota order used by version 1. They
+// must never change, even if the constants above are reordered.
+var commandNamesV1 = []string{"ReleaseHold", "ConfirmReservation", "CancelReservation", "RecordUserReserved", "NotifyHost", "UnknownCommand", "RetractUserReserved"}
+
+var replyNamesV1 = []string{"ReservationConfirmed", "ReservationNotConfirmed", "UserReservedRecorded", "UserReservedNotRecorded", "HostNotified", "HostNotNotified", "UnknownReply"}
+
+func init() {
+	for _, messageType := range []string{commandMessageType, legacyCommandMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(commandNamesV1, "UnknownCommand"))
+	}
+	for _, messageType := range []string{replyMessageType, legacyReplyMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(replyNamesV1, "UnknownReply"))
+	}
+}
+
+func (CreateReservationCommand) MessageType() string { return commandMessageType }
+
+func (CreateReservationCommand) SchemaVersion() int { return schemaVersion }
+
+func (CreateReservationReply) MessageType() string { return replyMessageType }
+
+func (CreateReservationReply) SchemaVersion() int { return schemaVersion }
+
+func (t CreateReservationCommandType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(commandNames, t)
+}
+
+func (t *CreateReservationCommandType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(commandNames, text, UnknownCommand)
+	return nil
+}
+
+func (t CreateReservationReplyType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(replyNames, t)
+}
+
+func (t *CreateReservationReplyType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(replyNames, text, UnknownReply)
+	return nil
+}
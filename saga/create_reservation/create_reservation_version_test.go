@@ -0,0 +1,47 @@
+package create_reservation
+
+import (
+	"example/saga/messaging/messagingtest"
+	"testing"
+)
+
+func TestCommandVersions(t *testing.T) {
+	messagingtest.Versions[CreateReservationCommand, CreateReservationCommandType]{
+		Message: func(commandType CreateReservationCommandType) CreateReservationCommand {
+			return CreateReservationCommand{Type: commandType}
+		},
+		Type:  func(command CreateReservationCommand) CreateReservationCommandType { return command.Type },
+		Names: commandNames,
+		Version1: map[int]CreateReservationCommandType{
+			0: ReleaseHold,
+			1: ConfirmReservation,
+			2: CancelReservation,
+			3: RecordUserReserved,
+			4: NotifyHost,
+			5: UnknownCommand,
+			6: RetractUserReserved,
+			7: UnknownCommand,
+		},
+		Legacy: "CreateReservationCommand",
+	}.Run(t)
+}
+
+func TestReplyVersions(t *testing.T) {
+	messagingtest.Versions[CreateReservationReply, CreateReservationReplyType]{
+		Message: func(replyType CreateReservationReplyType) CreateReservationReply {
+			return CreateReservationReply{Type: replyType}
+		},
+		Type:  func(reply CreateReservationReply) CreateReservationReplyType { return reply.Type },
+		Names: replyNames,
+		Version1: map[int]CreateReservationReplyType{
+			0: ReservationConfirmed,
+			1: ReservationNotConfirmed,
+			2: UserReservedRecorded,
+			3: UserReservedNotRecorded,
+			4: HostNotified,
+			5: HostNotNotified,
+			6: UnknownReply,
+			7: UnknownReply,
+		},
+	}.Run(t)
+}
@@ -0,0 +1,60 @@
+package delete_user
+
+type DeleteUserDetails struct {
+	SagaID           string
+	UserID           string
+	Role             string
+	AccommodationIDs []string
+}
+
+type DeleteUserCommandType int8
+
+// komande
+const (
+	CheckUserReservations DeleteUserCommandType = iota
+	ArchiveUserAuth
+	RestoreUserAuth
+	ArchiveUserAccommodations
+	RestoreUserAccommodations
+	ArchiveUserAvailability
+	RestoreUserAvailability
+	ArchiveUserNotifications
+	RestoreUserNotifications
+	ArchiveUserRatings
+	RestoreUserRatings
+	DeleteUserProfile
+	UnknownCommand
+)
+
+type DeleteUserReplyType int8
+
+// REPLY
+const (
+	UserReservationsChecked DeleteUserReplyType = iota
+	UserHasActiveReservations
+	UserAuthArchived
+	UserAuthNotArchived
+	UserAccommodationsArchived
+	UserAccommodationsNotArchived
+	UserAvailabilityArchived
+	UserAvailabilityNotArchived
+	UserNotificationsArchived
+	UserNotificationsNotArchived
+	UserRatingsArchived
+	UserRatingsNotArchived
+	UserProfileDeleted
+	UserProfileNotDeleted
+	UnknownReply
+)
+
+type DeleteUserCommand struct {
+	Type    DeleteUserCommandType
+	Payload DeleteUserDetails
+}
+
+// DeleteUserReply carries the details back to the orchestrator. Participants may
+// fill in details the later steps need, like the AccommodationIDs of a host.
+type DeleteUserReply struct {
+	Type    DeleteUserReplyType
+	Payload DeleteUserDetails
+}
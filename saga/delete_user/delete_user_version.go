@@ -0,0 +1,93 @@
+package delete_user
+
+import "example/saga/messaging"
+
+// Version 1 of the commands and replies sent Type as the iota value of the enum.
+// Version 2 sends the name of the constant instead.
+const (
+	commandMessageType = "delete_user.DeleteUserCommand"
+	replyMessageType   = "delete_user.DeleteUserReply"
+	schemaVersion      = 2
+)
+
+// Envelopes published before the messages were versioned carry the Go type name
+// and version 1.
+const (
+	legacyCommandMessageType = "DeleteUserCommand"
+	legacyReplyMessageType   = "DeleteUserReply"
+)
+
+var commandNames = map[DeleteUserCommandType]string{
+	CheckUserReservations:     "CheckUserReservations",
+	ArchiveUserAuth:           "ArchiveUserAuth",
+	RestoreUserAuth:           "RestoreUserAuth",
+	ArchiveUserAccommodations: "ArchiveUserAccommodations",
+	RestoreUserAccommodations: "RestoreUserAccommodations",
+	ArchiveUserAvailability:   "ArchiveUserAvailability",
+	RestoreUserAvailability:   "RestoreUserAvailability",
+	ArchiveUserNotifications:  "ArchiveUserNotifications",
+	RestoreUserNotifications:  "RestoreUserNotifications",
+	ArchiveUserRatings:        "ArchiveUserRatings",
+	RestoreUserRatings:        "RestoreUserRatings",
+	DeleteUserProfile:         "DeleteUserProfile",
+	UnknownCommand:            "UnknownCommand",
+}
+
+var replyNames = map[DeleteUserReplyType]string{
+	UserReservationsChecked:       "UserReservationsChecked",
+	UserHasActiveReservations:     "UserHasActiveReservations",
+	UserAuthArchived:              "UserAuthArchived",
+	UserAuthNotArchived:           "UserAuthNotArchived",
+	UserAccommodationsArchived:    "UserAccommodationsArchived",
+	UserAccommodationsNotArchived: "UserAccommodationsNotArchived",
+	UserAvailabilityArchived:      "UserAvailabilityArchived",
+	UserAvailabilityNotArchived:   "UserAvailabilityNotArchived",
+	UserNotificationsArchived:     "UserNotificationsArchived",
+	UserNotificationsNotArchived:  "UserNotificationsNotArchived",
+	UserRatingsArchived:           "UserRatingsArchived",
+	UserRatingsNotArchived:        "UserRatingsNotArchived",
+	UserProfileDeleted:            "UserProfileDeleted",
+	UserProfileNotDeleted:         "UserProfileNotDeleted",
+	UnknownReply:                  "UnknownReply",
+}
+
+// commandNamesV1 and replyNamesV1 freeze the iota order used by version 1. They
+// must never change, even if the constants above are reordered.
+var commandNamesV1 = []string{"CheckUserReservations", "ArchiveUserAuth", "RestoreUserAuth", "ArchiveUserAccommodations", "RestoreUserAccommodations", "ArchiveUserAvailability", "RestoreUserAvailability", "ArchiveUserNotifications", "RestoreUserNotifications", "ArchiveUserRatings", "RestoreUserRatings", "DeleteUserProfile", "UnknownCommand"}
+
+var replyNamesV1 = []string{"UserReservationsChecked", "UserHasActiveReservations", "UserAuthArchived", "UserAuthNotArchived", "UserAccommodationsArchived", "UserAccommodationsNotArchived", "UserAvailabilityArchived", "UserAvailabilityNotArchived", "UserNotificationsArchived", "UserNotificationsNotArchived", "UserRatingsArchived", "UserRatingsNotArchived", "UserProfileDeleted", "UserProfileNotDeleted", "UnknownReply"}
+
+func init() {
+	for _, messageType := range []string{commandMessageType, legacyCommandMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(commandNamesV1, "UnknownCommand"))
+	}
+	for _, messageType := range []string{replyMessageType, legacyReplyMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(replyNamesV1, "UnknownReply"))
+	}
+}
+
+func (DeleteUserCommand) MessageType() string { return commandMessageType }
+
+func (DeleteUserCommand) SchemaVersion() int { return schemaVersion }
+
+func (DeleteUserReply) MessageType() string { return replyMessageType }
+
+func (DeleteUserReply) SchemaVersion() int { return schemaVersion }
+
+func (t DeleteUserCommandType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(commandNames, t)
+}
+
+func (t *DeleteUserCommandType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(commandNames, text, UnknownCommand)
+	return nil
+}
+
+func (t DeleteUserReplyType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(replyNames, t)
+}
+
+func (t *DeleteUserReplyType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(replyNames, text, UnknownReply)
+	return nil
+}
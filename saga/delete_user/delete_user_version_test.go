@@ -0,0 +1,57 @@
+package delete_user
+
+import (
+	"example/saga/messaging/messagingtest"
+	"testing"
+)
+
+func TestCommandVersions(t *testing.T) {
+	messagingtest.Versions[DeleteUserCommand, DeleteUserCommandType]{
+		Message: func(commandType DeleteUserCommandType) DeleteUserCommand { return DeleteUserCommand{Type: commandType} },
+		Type:    func(command DeleteUserCommand) DeleteUserCommandType { return command.Type },
+		Names:   commandNames,
+		Version1: map[int]DeleteUserCommandType{
+			0:  CheckUserReservations,
+			1:  ArchiveUserAuth,
+			2:  RestoreUserAuth,
+			3:  ArchiveUserAccommodations,
+			4:  RestoreUserAccommodations,
+			5:  ArchiveUserAvailability,
+			6:  RestoreUserAvailability,
+			7:  ArchiveUserNotifications,
+			8:  RestoreUserNotifications,
+			9:  ArchiveUserRatings,
+			10: RestoreUserRatings,
+			11: DeleteUserProfile,
+			12: UnknownCommand,
+			13: UnknownCommand,
+		},
+		Legacy: "DeleteUserCommand",
+	}.Run(t)
+}
+
+func TestReplyVersions(t *testing.T) {
+	messagingtest.Versions[DeleteUserReply, DeleteUserReplyType]{
+		Message: func(replyType DeleteUserReplyType) DeleteUserReply { return DeleteUserReply{Type: replyType} },
+		Type:    func(reply DeleteUserReply) DeleteUserReplyType { return reply.Type },
+		Names:   replyNames,
+		Version1: map[int]DeleteUserReplyType{
+			0:  UserReservationsChecked,
+			1:  UserHasActiveReservations,
+			2:  UserAuthArchived,
+			3:  UserAuthNotArchived,
+			4:  UserAccommodationsArchived,
+			5:  UserAccommodationsNotArchived,
+			6:  UserAvailabilityArchived,
+			7:  UserAvailabilityNotArchived,
+			8:  UserNotificationsArchived,
+			9:  UserNotificationsNotArchived,
+			10: UserRatingsArchived,
+			11: UserRatingsNotArchived,
+			12: UserProfileDeleted,
+			13: UserProfileNotDeleted,
+			14: UnknownReply,
+			15: UnknownReply,
+		},
+	}.Run(t)
+}
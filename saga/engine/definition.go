@@ -0,0 +1,20 @@
+package engine
+
+import "time"
+
+// CommandBuilder turns the saga payload into the message published for a step.
+type CommandBuilder func(instance *Instance) (interface{}, error)
+
+// Step is one unit of a saga. A step without Command is a local transaction that
+// already ran before the saga was started and only contributes its Compensation.
+type Step struct {
+	Name         string
+	Command      CommandBuilder
+	Compensation CommandBuilder
+	Timeout      time.Duration
+}
+
+type Definition struct {
+	Name  string
+	Steps []Step
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+type State string
+
+const (
+	Running      State = "Running"
+	Compensating State = "Compensating"
+	Completed    State = "Completed"
+	Compensated  State = "Compensated"
+)
+
+type RecordKind string
+
+const (
+	LocalStepDone    RecordKind = "LocalStepDone"
+	CommandSent      RecordKind = "CommandSent"
+	ReplyReceived    RecordKind = "ReplyReceived"
+	StepTimedOut     RecordKind = "StepTimedOut"
+	CompensationSent RecordKind = "CompensationSent"
+)
+
+var (
+	ErrNotFound        = errors.New("saga instance not found")
+	ErrConflict        = errors.New("saga instance was modified concurrently")
+	ErrUnexpectedReply = errors.New("reply does not match the current saga step")
+)
+
+// StepRecord is a single entry of the saga log kept on the instance.
+type StepRecord struct {
+	Step   string     `bson:"step" json:"step"`
+	Kind   RecordKind `bson:"kind" json:"kind"`
+	Detail string     `bson:"detail" json:"detail"`
+	At     time.Time  `bson:"at" json:"at"`
+}
+
+// Instance is the persisted state of one running saga. CurrentStep points at the
+// step awaiting a reply while Running, and at the number of steps left to undo
+// while Compensating.
+type Instance struct {
+	ID            string       `bson:"_id" json:"id"`
+	SagaType      string       `bson:"sagaType" json:"sagaType"`
+	State         State        `bson:"state" json:"state"`
+	CurrentStep   int          `bson:"currentStep" json:"currentStep"`
+	Payload       []byte       `bson:"payload" json:"-"`
+	History       []StepRecord `bson:"history" json:"history"`
+	Compensations []string     `bson:"compensations" json:"compensations"`
+	FailureReason string       `bson:"failureReason" json:"failureReason"`
+	Deadline      time.Time    `bson:"deadline" json:"deadline"`
+	CreatedAt     time.Time    `bson:"createdAt" json:"createdAt"`
+	UpdatedAt     time.Time    `bson:"updatedAt" json:"updatedAt"`
+	Version       int          `bson:"version" json:"version"`
+
+	// TraceContext holds the W3C trace headers of the request that started the
+	// saga, sent along with every command so participants join its trace.
+	TraceContext map[string]string `bson:"traceContext,omitempty" json:"traceContext,omitempty"`
+}
+
+func (i *Instance) Decode(value interface{}) error {
+	return json.Unmarshal(i.Payload, value)
+}
+
+func (i *Instance) IsActive() bool {
+	return i.State == Running || i.State == Compensating
+}
+
+func (i *Instance) record(step string, kind RecordKind, detail string) {
+	i.History = append(i.History, StepRecord{
+		Step:   step,
+		Kind:   kind,
+		Detail: detail,
+		At:     time.Now(),
+	})
+}
+
+// Store persists saga instances. Save must reject an instance whose Version
+// does not match the stored one with ErrConflict and bump Version on success.
+type Store interface {
+	Save(instance *Instance) error
+	Get(id string) (*Instance, error)
+	FindActive(sagaType string) ([]*Instance, error)
+	FindExpired(sagaType string, now time.Time) ([]*Instance, error)
+}
@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"example/saga/messaging"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// retryDelay is how long a compensating saga waits before a failed compensation
+// command is published again.
+const retryDelay = 10 * time.Second
+
+type Orchestrator struct {
+	definition Definition
+	publisher  messaging.Publisher
+	store      Store
+	mu         sync.Mutex
+}
+
+func NewOrchestrator(definition Definition, publisher messaging.Publisher, store Store) *Orchestrator {
+	return &Orchestrator{
+		definition: definition,
+		publisher:  publisher,
+		store:      store,
+	}
+}
+
+// Start persists a new saga instance and sends the command of its first remote
+// step. Starting a saga that already exists sends its current command again, so
+// a caller may retry Start until it succeeds.
+func (o *Orchestrator) Start(id string, payload interface{}) error {
+	return o.StartTraced(id, payload, nil)
+}
+
+// StartTraced starts the saga like Start, sending traceContext with every
+// command of the saga.
+func (o *Orchestrator) StartTraced(id string, payload interface{}, traceContext map[string]string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	existing, err := o.store.Get(id)
+	if err == nil {
+		if existing.State != Running {
+			return nil
+		}
+		return o.advance(existing)
+	}
+	if err != ErrNotFound {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	instance := &Instance{
+		ID:           id,
+		SagaType:     o.definition.Name,
+		State:        Running,
+		Payload:      data,
+		TraceContext: traceContext,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+	return o.advance(instance)
+}
+
+// StepSucceeded moves the saga past the named step and sends the next command.
+func (o *Orchestrator) StepSucceeded(id, step, reply string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	instance, err := o.awaiting(id, step)
+	if err != nil {
+		return err
+	}
+	instance.record(step, ReplyReceived, reply)
+	instance.CurrentStep++
+	return o.advance(instance)
+}
+
+// StepSucceededWith is StepSucceeded for replies that carry data later steps
+// need: the saga payload is replaced before the next command is built.
+func (o *Orchestrator) StepSucceededWith(id, step, reply string, payload interface{}) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	instance, err := o.awaiting(id, step)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	instance.Payload = data
+	instance.record(step, ReplyReceived, reply)
+	instance.CurrentStep++
+	return o.advance(instance)
+}
+
+// StepFailed records the failure of the named step and compensates every step
+// that completed before it, in reverse order.
+func (o *Orchestrator) StepFailed(id, step, reply string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	instance, err := o.awaiting(id, step)
+	if err != nil {
+		return err
+	}
+	instance.record(step, ReplyReceived, reply)
+	instance.FailureReason = reply
+	instance.State = Compensating
+	return o.compensate(instance)
+}
+
+// Get returns the stored instance so callers can report on saga progress.
+func (o *Orchestrator) Get(id string) (*Instance, error) {
+	return o.store.Get(id)
+}
+
+// Resume picks up every saga left active by a previous run of the service. The
+// current command is sent again, so participants must handle it idempotently.
+func (o *Orchestrator) Resume() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	instances, err := o.store.FindActive(o.definition.Name)
+	if err != nil {
+		return err
+	}
+	for _, instance := range instances {
+		if instance.State == Compensating {
+			err = o.compensate(instance)
+		} else {
+			err = o.advance(instance)
+		}
+		if err != nil {
+			log.Println(fmt.Sprintf("saga %s: unable to resume %s: %v", o.definition.Name, instance.ID, err))
+		}
+	}
+	return nil
+}
+
+// WatchTimeouts fails running steps whose deadline passed and retries pending
+// compensations until ctx is cancelled. A step that timed out is compensated
+// along with the steps before it.
+func (o *Orchestrator) WatchTimeouts(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			o.expire(now)
+		}
+	}
+}
+
+func (o *Orchestrator) expire(now time.Time) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	instances, err := o.store.FindExpired(o.definition.Name, now)
+	if err != nil {
+		log.Println(fmt.Sprintf("saga %s: unable to load expired instances: %v", o.definition.Name, err))
+		return
+	}
+	for _, instance := range instances {
+		if instance.State == Running {
+			step := o.definition.Steps[instance.CurrentStep].Name
+			instance.record(step, StepTimedOut, "no reply before "+instance.Deadline.Format(time.RFC3339))
+			instance.FailureReason = "step " + step + " timed out"
+			instance.State = Compensating
+			// The participant may still apply the command after the deadline, so the
+			// timed-out step is compensated too.
+			instance.CurrentStep++
+		}
+		if err := o.compensate(instance); err != nil {
+			log.Println(fmt.Sprintf("saga %s: unable to compensate %s: %v", o.definition.Name, instance.ID, err))
+		}
+	}
+}
+
+func (o *Orchestrator) awaiting(id, step string) (*Instance, error) {
+	instance, err := o.store.Get(id)
+	if err != nil {
+		return nil, err
+	}
+	if instance.State != Running || o.definition.Steps[instance.CurrentStep].Name != step {
+		return nil, ErrUnexpectedReply
+	}
+	return instance, nil
+}
+
+// advance runs local steps and sends the command of the next remote step, or
+// completes the saga when no steps are left.
+func (o *Orchestrator) advance(instance *Instance) error {
+	for instance.CurrentStep < len(o.definition.Steps) {
+		step := o.definition.Steps[instance.CurrentStep]
+		if step.Command == nil {
+			instance.record(step.Name, LocalStepDone, "")
+			instance.CurrentStep++
+			continue
+		}
+		command, err := step.Command(instance)
+		if err != nil {
+			return err
+		}
+		instance.Deadline = time.Time{}
+		if step.Timeout > 0 {
+			instance.Deadline = time.Now().Add(step.Timeout)
+		}
+		instance.record(step.Name, CommandSent, "")
+		if err := o.save(instance); err != nil {
+			return err
+		}
+		return o.publish(instance, command)
+	}
+	instance.State = Completed
+	instance.Deadline = time.Time{}
+	return o.save(instance)
+}
+
+func (o *Orchestrator) compensate(instance *Instance) error {
+	instance.Deadline = time.Time{}
+	for instance.CurrentStep > 0 {
+		step := o.definition.Steps[instance.CurrentStep-1]
+		if step.Compensation != nil {
+			command, err := step.Compensation(instance)
+			if err != nil {
+				return err
+			}
+			if err := o.publish(instance, command); err != nil {
+				instance.Deadline = time.Now().Add(retryDelay)
+				_ = o.save(instance)
+				return err
+			}
+			instance.record(step.Name, CompensationSent, "")
+			instance.Compensations = append(instance.Compensations, step.Name)
+		}
+		instance.CurrentStep--
+		if err := o.save(instance); err != nil {
+			return err
+		}
+	}
+	instance.State = Compensated
+	return o.save(instance)
+}
+
+// publish sends a saga command correlated with the saga instance, in the trace
+// of the request that started it.
+func (o *Orchestrator) publish(instance *Instance, command interface{}) error {
+	envelope, err := messaging.Wrap(command, messaging.CorrelatedWith(instance.ID), messaging.WithTraceContext(instance.TraceContext))
+	if err != nil {
+		return err
+	}
+	return o.publisher.Publish(envelope)
+}
+
+func (o *Orchestrator) save(instance *Instance) error {
+	instance.UpdatedAt = time.Now()
+	return o.store.Save(instance)
+}
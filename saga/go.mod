@@ -3,10 +3,22 @@ module example/saga
 go 1.21.6
 
 require (
+	github.com/nats-io/nats.go v1.32.0
+	go.mongodb.org/mongo-driver v1.13.1
+)
+
+require (
+	github.com/golang/snappy v0.0.1 // indirect
 	github.com/klauspost/compress v1.17.2 // indirect
-	github.com/nats-io/nats.go v1.32.0 // indirect
+	github.com/montanaflynn/stats v0.0.0-20171201202039-1bf9dbcd8cbe // indirect
 	github.com/nats-io/nkeys v0.4.7 // indirect
 	github.com/nats-io/nuid v1.0.1 // indirect
+	github.com/xdg-go/pbkdf2 v1.0.0 // indirect
+	github.com/xdg-go/scram v1.1.2 // indirect
+	github.com/xdg-go/stringprep v1.0.4 // indirect
+	github.com/youmark/pkcs8 v0.0.0-20181117223130-1be2e3e5546d // indirect
 	golang.org/x/crypto v0.18.0 // indirect
+	golang.org/x/sync v0.0.0-20220722155255-886fb9371eb4 // indirect
 	golang.org/x/sys v0.16.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
 )
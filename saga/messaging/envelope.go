@@ -0,0 +1,140 @@
+package messaging
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// legacySchemaVersion is the version given to messages published before the
+// envelope existed, which arrive as the bare JSON of the message.
+const legacySchemaVersion = 1
+
+// Envelope is what every publisher puts on the wire. CorrelationID ties together
+// all messages of one saga, CausationID is the MessageID of the message that
+// caused this one and TraceContext carries the W3C trace headers across services.
+// Event stores keep the envelope without its payload as the event metadata.
+type Envelope struct {
+	MessageID     string            `json:"messageId"`
+	CorrelationID string            `json:"correlationId,omitempty"`
+	CausationID   string            `json:"causationId,omitempty"`
+	MessageType   string            `json:"messageType"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Timestamp     time.Time         `json:"timestamp"`
+	TraceContext  map[string]string `json:"traceContext,omitempty"`
+	Payload       json.RawMessage   `json:"payload,omitempty"`
+}
+
+// Versioned is implemented by messages whose wire format has changed at least once.
+// Messages that do not implement it are published with their Go type name and
+// schema version 1.
+type Versioned interface {
+	MessageType() string
+	SchemaVersion() int
+}
+
+type Option func(envelope *Envelope)
+
+// CorrelatedWith sets the correlation ID, usually the ID of the saga.
+func CorrelatedWith(correlationID string) Option {
+	return func(envelope *Envelope) {
+		envelope.CorrelationID = correlationID
+	}
+}
+
+// CausedBy marks the message as sent in reaction to cause, keeping its
+// correlation ID and trace context.
+func CausedBy(cause *Envelope) Option {
+	return func(envelope *Envelope) {
+		if cause == nil {
+			return
+		}
+		envelope.CausationID = cause.MessageID
+		envelope.CorrelationID = cause.CorrelationID
+		envelope.TraceContext = cause.TraceContext
+	}
+}
+
+func WithTraceContext(traceContext map[string]string) Option {
+	return func(envelope *Envelope) {
+		envelope.TraceContext = traceContext
+	}
+}
+
+// Wrap puts message in a new envelope. An envelope passed in is returned as it is,
+// so publishers can be handed either.
+func Wrap(message interface{}, options ...Option) (*Envelope, error) {
+	switch m := message.(type) {
+	case *Envelope:
+		return m, nil
+	case Envelope:
+		return &m, nil
+	}
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	messageType, schemaVersion := describe(reflect.TypeOf(message))
+	envelope := &Envelope{
+		MessageID:     NewMessageID(),
+		MessageType:   messageType,
+		SchemaVersion: schemaVersion,
+		Timestamp:     time.Now().UTC(),
+		Payload:       payload,
+	}
+	for _, option := range options {
+		option(envelope)
+	}
+	return envelope, nil
+}
+
+// Unwrap reads an envelope from data. Bare messages published before envelopes were
+// introduced get the type of messageType and the legacy schema version, so the
+// upcasters of that type still apply to them.
+func Unwrap(data []byte, messageType reflect.Type) (*Envelope, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err == nil && envelope.MessageID != "" && len(envelope.Payload) > 0 {
+		return &envelope, nil
+	}
+	name, _ := describe(messageType)
+	return &Envelope{
+		MessageType:   name,
+		SchemaVersion: legacySchemaVersion,
+		Payload:       bytes.TrimSpace(data),
+	}, nil
+}
+
+// Decode upcasts the payload to the current schema version and unmarshals it into value.
+func (e *Envelope) Decode(value interface{}) error {
+	payload, err := Upcast(e.MessageType, e.SchemaVersion, e.Payload)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(payload, value)
+}
+
+func NewMessageID() string {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	id[6] = (id[6] & 0x0f) | 0x40
+	id[8] = (id[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", id[0:4], id[4:6], id[6:8], id[8:10], id[10:])
+}
+
+func describe(messageType reflect.Type) (string, int) {
+	if messageType == nil {
+		return "", legacySchemaVersion
+	}
+	for messageType.Kind() == reflect.Ptr {
+		messageType = messageType.Elem()
+	}
+	if versioned, ok := reflect.New(messageType).Interface().(Versioned); ok {
+		return versioned.MessageType(), versioned.SchemaVersion()
+	}
+	return messageType.Name(), legacySchemaVersion
+}
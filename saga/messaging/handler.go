@@ -0,0 +1,79 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	ErrInvalidHandler = errors.New("handler must be a func taking the message and optionally its *Envelope, optionally returning an error")
+	errorType         = reflect.TypeOf((*error)(nil)).Elem()
+	envelopeType      = reflect.TypeOf((*Envelope)(nil))
+)
+
+// Handler wraps the function passed to Subscriber.Subscribe. The function takes
+// the decoded message by value or by pointer, optionally followed by the
+// *Envelope it arrived in, and may return an error.
+type Handler struct {
+	function     reflect.Value
+	argType      reflect.Type
+	withEnvelope bool
+}
+
+func NewHandler(function interface{}) (*Handler, error) {
+	value := reflect.ValueOf(function)
+	if value.Kind() != reflect.Func {
+		return nil, ErrInvalidHandler
+	}
+	functionType := value.Type()
+	if functionType.NumIn() < 1 || functionType.NumIn() > 2 || functionType.NumOut() > 1 ||
+		(functionType.NumIn() == 2 && functionType.In(1) != envelopeType) ||
+		(functionType.NumOut() == 1 && functionType.Out(0) != errorType) {
+		return nil, ErrInvalidHandler
+	}
+	return &Handler{
+		function:     value,
+		argType:      functionType.In(0),
+		withEnvelope: functionType.NumIn() == 2,
+	}, nil
+}
+
+// Decode unwraps the envelope in data, upcasts its payload and unmarshals it into
+// the type the handler expects. It returns the arguments to pass to Call.
+func (h *Handler) Decode(data []byte) ([]reflect.Value, error) {
+	envelope, err := Unwrap(data, h.argType)
+	if err != nil {
+		return nil, err
+	}
+	var value reflect.Value
+	if h.argType.Kind() == reflect.Ptr {
+		value = reflect.New(h.argType.Elem())
+		err = envelope.Decode(value.Interface())
+	} else {
+		value = reflect.New(h.argType)
+		err = envelope.Decode(value.Interface())
+		value = value.Elem()
+	}
+	if err != nil {
+		return nil, err
+	}
+	if h.withEnvelope {
+		return []reflect.Value{value, reflect.ValueOf(envelope)}, nil
+	}
+	return []reflect.Value{value}, nil
+}
+
+// Call runs the handler and turns a returned error or a panic into an error.
+func (h *Handler) Call(args []reflect.Value) (err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("handler panicked: %v", recovered)
+		}
+	}()
+	results := h.function.Call(args)
+	if len(results) == 1 && !results[0].IsNil() {
+		return results[0].Interface().(error)
+	}
+	return nil
+}
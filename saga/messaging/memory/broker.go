@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"encoding/json"
+	"example/saga/messaging"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// Broker is an in-process replacement for NATS. Messages are wrapped in an envelope
+// and JSON encoded like on the wire and every queue group receives each message once, in publish order.
+// Queues are unbounded, so a handler may publish from inside a delivery without
+// waiting for its own or any other queue to drain.
+type Broker struct {
+	groups   map[string]map[string]*group
+	mu       sync.Mutex
+	inFlight int
+	idle     *sync.Cond
+}
+
+type group struct {
+	handlers []*messaging.Handler
+	next     int
+	pending  [][]byte
+	ready    chan struct{}
+}
+
+func NewBroker() *Broker {
+	broker := &Broker{
+		groups: make(map[string]map[string]*group),
+	}
+	broker.idle = sync.NewCond(&broker.mu)
+	return broker
+}
+
+func (b *Broker) Publisher(subject string) messaging.Publisher {
+	return &Publisher{broker: b, subject: subject}
+}
+
+func (b *Broker) Subscriber(subject, queueGroup string) messaging.Subscriber {
+	return &Subscriber{broker: b, subject: subject, queueGroup: queueGroup}
+}
+
+// Wait blocks until every published message, including the ones published by
+// handlers while it waits, has been handled.
+func (b *Broker) Wait() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for b.inFlight > 0 {
+		b.idle.Wait()
+	}
+}
+
+func (b *Broker) publish(subject string, message interface{}) error {
+	envelope, err := messaging.Wrap(message)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, g := range b.groups[subject] {
+		b.inFlight++
+		g.pending = append(g.pending, data)
+		select {
+		case g.ready <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (b *Broker) subscribe(subject, queueGroup string, handler *messaging.Handler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.groups[subject] == nil {
+		b.groups[subject] = make(map[string]*group)
+	}
+	g, exists := b.groups[subject][queueGroup]
+	if !exists {
+		g = &group{ready: make(chan struct{}, 1)}
+		b.groups[subject][queueGroup] = g
+		go b.deliver(subject, g)
+	}
+	g.handlers = append(g.handlers, handler)
+}
+
+func (b *Broker) deliver(subject string, g *group) {
+	for range g.ready {
+		for {
+			b.mu.Lock()
+			if len(g.pending) == 0 {
+				b.mu.Unlock()
+				break
+			}
+			data := g.pending[0]
+			g.pending = g.pending[1:]
+			handler := g.handlers[g.next%len(g.handlers)]
+			g.next++
+			b.mu.Unlock()
+			b.handle(subject, handler, data)
+		}
+	}
+}
+
+func (b *Broker) handle(subject string, handler *messaging.Handler, data []byte) {
+	defer b.done()
+	args, err := handler.Decode(data)
+	if err == nil {
+		err = handler.Call(args)
+	}
+	if err != nil {
+		log.Println(fmt.Sprintf("memory broker: handler on %s failed: %v", subject, err))
+	}
+}
+
+func (b *Broker) done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inFlight--
+	if b.inFlight == 0 {
+		b.idle.Broadcast()
+	}
+}
+
+type Publisher struct {
+	broker  *Broker
+	subject string
+}
+
+func (p *Publisher) Publish(message interface{}) error {
+	return p.broker.publish(p.subject, message)
+}
+
+type Subscriber struct {
+	broker     *Broker
+	subject    string
+	queueGroup string
+}
+
+func (s *Subscriber) Subscribe(function interface{}) error {
+	handler, err := messaging.NewHandler(function)
+	if err != nil {
+		return err
+	}
+	s.broker.subscribe(s.subject, s.queueGroup, handler)
+	return nil
+}
@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+type ping struct {
+	N int
+}
+
+func settle(t *testing.T, broker *Broker) {
+	t.Helper()
+	done := make(chan struct{})
+	go func() {
+		broker.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("broker did not settle, a delivery is blocked")
+	}
+}
+
+func TestHandlerPublishesToItsOwnQueue(t *testing.T) {
+	broker := NewBroker()
+	publisher := broker.Publisher("ping")
+	const fanOut = 5000
+	var mu sync.Mutex
+	received := 0
+	err := broker.Subscriber("ping", "group").Subscribe(func(message *ping) error {
+		mu.Lock()
+		received++
+		mu.Unlock()
+		if message.N > 0 {
+			return nil
+		}
+		for i := 1; i <= fanOut; i++ {
+			if err := publisher.Publish(ping{N: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := publisher.Publish(ping{}); err != nil {
+		t.Fatal(err)
+	}
+	settle(t, broker)
+
+	if received != fanOut+1 {
+		t.Fatalf("received %d messages, want %d", received, fanOut+1)
+	}
+}
+
+func TestHandlersPublishToEachOther(t *testing.T) {
+	broker := NewBroker()
+	pings := broker.Publisher("ping")
+	pongs := broker.Publisher("pong")
+	const rounds = 2000
+	var last int
+	err := broker.Subscriber("ping", "group").Subscribe(func(message ping) error {
+		if message.N == rounds {
+			last = message.N
+			return nil
+		}
+		return pongs.Publish(ping{N: message.N + 1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = broker.Subscriber("pong", "group").Subscribe(func(message ping) error {
+		return pings.Publish(ping{N: message.N + 1})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := pings.Publish(ping{}); err != nil {
+		t.Fatal(err)
+	}
+	settle(t, broker)
+
+	if last != rounds {
+		t.Fatalf("last ping was %d, want %d", last, rounds)
+	}
+}
+
+func TestEveryQueueGroupReceivesEachMessageInOrder(t *testing.T) {
+	broker := NewBroker()
+	received := map[string][]int{}
+	var mu sync.Mutex
+	for _, queueGroup := range []string{"first", "second"} {
+		queueGroup := queueGroup
+		err := broker.Subscriber("ping", queueGroup).Subscribe(func(message ping) {
+			mu.Lock()
+			defer mu.Unlock()
+			received[queueGroup] = append(received[queueGroup], message.N)
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publisher := broker.Publisher("ping")
+	for i := 0; i < 100; i++ {
+		if err := publisher.Publish(ping{N: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	settle(t, broker)
+
+	for _, queueGroup := range []string{"first", "second"} {
+		if len(received[queueGroup]) != 100 {
+			t.Fatalf("%s received %d messages, want 100", queueGroup, len(received[queueGroup]))
+		}
+		for i, n := range received[queueGroup] {
+			if n != i {
+				t.Fatalf("%s received message %d at position %d", queueGroup, n, i)
+			}
+		}
+	}
+}
@@ -7,3 +7,13 @@ type Publisher interface {
 type Subscriber interface {
 	Subscribe(function interface{}) error
 }
+
+// Reply publishes reply as caused by the command in cause, so it keeps the
+// correlation ID and trace context of the saga.
+func Reply(publisher Publisher, reply interface{}, cause *Envelope) error {
+	envelope, err := Wrap(reply, CausedBy(cause))
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(envelope)
+}
@@ -0,0 +1,53 @@
+package messaging
+
+import (
+	"reflect"
+	"testing"
+)
+
+type ping struct {
+	N int
+}
+
+type recordingPublisher struct {
+	published []interface{}
+}
+
+func (p *recordingPublisher) Publish(message interface{}) error {
+	p.published = append(p.published, message)
+	return nil
+}
+
+func TestReplyIsCausedByTheCommand(t *testing.T) {
+	traceContext := map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"}
+	command, err := Wrap(ping{N: 1}, CorrelatedWith("saga"), WithTraceContext(traceContext))
+	if err != nil {
+		t.Fatal(err)
+	}
+	publisher := &recordingPublisher{}
+
+	if err := Reply(publisher, ping{N: 2}, command); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(publisher.published) != 1 {
+		t.Fatalf("published %d messages, want 1", len(publisher.published))
+	}
+	reply, ok := publisher.published[0].(*Envelope)
+	if !ok {
+		t.Fatalf("published %T, want *Envelope", publisher.published[0])
+	}
+	if reply.CausationID != command.MessageID {
+		t.Fatalf("reply caused by %q, want %q", reply.CausationID, command.MessageID)
+	}
+	if reply.CorrelationID != "saga" {
+		t.Fatalf("reply correlated with %q, want saga", reply.CorrelationID)
+	}
+	if !reflect.DeepEqual(reply.TraceContext, traceContext) {
+		t.Fatalf("reply trace context is %v, want %v", reply.TraceContext, traceContext)
+	}
+	var decoded ping
+	if err := reply.Decode(&decoded); err != nil || decoded.N != 2 {
+		t.Fatalf("reply decoded to %v (%v), want N=2", decoded, err)
+	}
+}
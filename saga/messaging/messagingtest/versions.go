@@ -0,0 +1,95 @@
+// Package messagingtest checks that the commands and replies of a saga keep
+// decoding across schema versions. Each saga package only lists its mapping.
+package messagingtest
+
+import (
+	"encoding/json"
+	"example/saga/messaging"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+// Versions describes how a message M sends its Type T.
+type Versions[M any, T comparable] struct {
+	// Message builds a message of the type.
+	Message func(T) M
+	// Type returns the type of a decoded message.
+	Type func(M) T
+	// Names are the names version 2 sends for the types.
+	Names map[T]string
+	// Version1 maps the iota values version 1 sent to the types they decode as,
+	// including values past the frozen names.
+	Version1 map[int]T
+	// Legacy is the Go type name envelopes carried before the messages were
+	// versioned, or "" when the message has no such envelopes to check.
+	Legacy string
+}
+
+// Run checks that every version 1 value decodes as its type, that every type is
+// sent as its name and decodes back, and that legacy envelopes still decode.
+func (v Versions[M, T]) Run(t *testing.T) {
+	t.Helper()
+	t.Run("version 1 decodes", v.version1Decodes)
+	t.Run("round trip", v.roundTrip)
+	if v.Legacy != "" {
+		t.Run("legacy envelope decodes", v.legacyEnvelopeDecodes)
+	}
+}
+
+func (v Versions[M, T]) version1Decodes(t *testing.T) {
+	for version1, want := range v.Version1 {
+		data := []byte(fmt.Sprintf(`{"Type":%d,"Payload":{}}`, version1))
+		got := v.decode(t, data)
+		if got != want {
+			t.Fatalf("version 1 type %d decoded as %v, want %v", version1, got, want)
+		}
+	}
+}
+
+func (v Versions[M, T]) roundTrip(t *testing.T) {
+	for value, name := range v.Names {
+		envelope, err := messaging.Wrap(v.Message(value))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var sent map[string]json.RawMessage
+		if err := json.Unmarshal(envelope.Payload, &sent); err != nil {
+			t.Fatal(err)
+		}
+		if string(sent["Type"]) != `"`+name+`"` {
+			t.Fatalf("%s sent as %s", name, sent["Type"])
+		}
+		var message M
+		if err := envelope.Decode(&message); err != nil {
+			t.Fatal(err)
+		}
+		if got := v.Type(message); got != value {
+			t.Fatalf("%s decoded as %v", name, got)
+		}
+	}
+}
+
+func (v Versions[M, T]) legacyEnvelopeDecodes(t *testing.T) {
+	want, exists := v.Version1[1]
+	if !exists {
+		t.Fatal("Version1 has no value 1 to send in the legacy envelope")
+	}
+	data := []byte(fmt.Sprintf(`{"messageId":"1","messageType":%q,"schemaVersion":1,"payload":{"Type":1}}`, v.Legacy))
+	if got := v.decode(t, data); got != want {
+		t.Fatalf("decoded %v, want %v", got, want)
+	}
+}
+
+func (v Versions[M, T]) decode(t *testing.T, data []byte) T {
+	t.Helper()
+	var message M
+	envelope, err := messaging.Unwrap(data, reflect.TypeOf(message))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := envelope.Decode(&message); err != nil {
+		t.Fatal(err)
+	}
+	return v.Type(message)
+}
@@ -0,0 +1,52 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// From schema version 2 on, saga messages send the Type of a command or reply as
+// the name of its constant instead of its iota value, so reordering the constants
+// cannot silently change the meaning of stored messages.
+
+// MarshalName returns the name sent for value.
+func MarshalName[T comparable](names map[T]string, value T) ([]byte, error) {
+	name, exists := names[value]
+	if !exists {
+		return nil, fmt.Errorf("no name for %T %v", value, value)
+	}
+	return []byte(name), nil
+}
+
+// UnmarshalName returns the value sent as text, or unknown for a name it does not
+// know, like one added by a newer version of the sender.
+func UnmarshalName[T comparable](names map[T]string, text []byte, unknown T) T {
+	for value, name := range names {
+		if name == string(text) {
+			return value
+		}
+	}
+	return unknown
+}
+
+// TypeToName upcasts version 1 to version 2 by replacing the numeric Type with its
+// name. namesV1 freezes the iota order used by version 1 and must never change;
+// values outside it become unknown.
+func TypeToName(namesV1 []string, unknown string) Upcaster {
+	return func(payload json.RawMessage) (json.RawMessage, error) {
+		var message map[string]json.RawMessage
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return nil, err
+		}
+		var index int
+		if err := json.Unmarshal(message["Type"], &index); err != nil {
+			return nil, err
+		}
+		name := unknown
+		if index >= 0 && index < len(namesV1) {
+			name = namesV1[index]
+		}
+		message["Type"], _ = json.Marshal(name)
+		return json.Marshal(message)
+	}
+}
@@ -0,0 +1,121 @@
+package messaging
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestTypeToName(t *testing.T) {
+	upcast := TypeToName([]string{"First", "Second", "Unknown", "Appended"}, "Unknown")
+	tests := []struct {
+		name    string
+		payload string
+		want    string
+		wantErr bool
+	}{
+		{name: "first value", payload: `{"Type":0,"Payload":{"Id":"a"}}`, want: `{"Payload":{"Id":"a"},"Type":"First"}`},
+		{name: "value after unknown", payload: `{"Type":3}`, want: `{"Type":"Appended"}`},
+		{name: "value past the frozen names", payload: `{"Type":4}`, want: `{"Type":"Unknown"}`},
+		{name: "negative value", payload: `{"Type":-1}`, want: `{"Type":"Unknown"}`},
+		{name: "type already a name", payload: `{"Type":"First"}`, wantErr: true},
+		{name: "no type", payload: `{"Payload":{}}`, wantErr: true},
+		{name: "not an object", payload: `[0]`, wantErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := upcast(json.RawMessage(test.payload))
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("upcast(%s) = %s, want an error", test.payload, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.want {
+				t.Fatalf("upcast(%s) = %s, want %s", test.payload, got, test.want)
+			}
+		})
+	}
+}
+
+type color int8
+
+const (
+	red color = iota
+	green
+	unknownColor
+)
+
+var colorNames = map[color]string{red: "red", green: "green", unknownColor: "unknownColor"}
+
+func TestMarshalName(t *testing.T) {
+	tests := []struct {
+		value   color
+		want    string
+		wantErr bool
+	}{
+		{value: red, want: "red"},
+		{value: green, want: "green"},
+		{value: color(7), wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := MarshalName(colorNames, test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Fatalf("MarshalName(%d) = %s, want an error", test.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Fatalf("MarshalName(%d) = %s, want %s", test.value, got, test.want)
+		}
+	}
+}
+
+func TestUnmarshalName(t *testing.T) {
+	tests := []struct {
+		text string
+		want color
+	}{
+		{text: "red", want: red},
+		{text: "green", want: green},
+		{text: "blue", want: unknownColor},
+		{text: "", want: unknownColor},
+	}
+	for _, test := range tests {
+		if got := UnmarshalName(colorNames, []byte(test.text), unknownColor); got != test.want {
+			t.Fatalf("UnmarshalName(%q) = %d, want %d", test.text, got, test.want)
+		}
+	}
+}
+
+func TestUpcastAppliesEveryVersion(t *testing.T) {
+	RegisterUpcaster("test.Chained", 1, func(payload json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"v":2}`), nil
+	})
+	RegisterUpcaster("test.Chained", 2, func(payload json.RawMessage) (json.RawMessage, error) {
+		return json.RawMessage(`{"v":3}`), nil
+	})
+	tests := []struct {
+		version int
+		want    string
+	}{
+		{version: 1, want: `{"v":3}`},
+		{version: 2, want: `{"v":3}`},
+		{version: 3, want: `{"v":"current"}`},
+	}
+	for _, test := range tests {
+		got, err := Upcast("test.Chained", test.version, json.RawMessage(`{"v":"current"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != test.want {
+			t.Fatalf("Upcast from version %d = %s, want %s", test.version, got, test.want)
+		}
+	}
+}
@@ -2,15 +2,57 @@ package nats
 
 import (
 	"fmt"
+	"log"
+	"sync"
 
 	"github.com/nats-io/nats.go"
 )
 
+// connection is shared by every publisher and subscriber of a service that
+// connects to the same server, so the service holds one connection it can drain
+// on shutdown instead of one per subject.
+type connection struct {
+	conn   *nats.Conn
+	closed chan struct{}
+}
+
+var (
+	connections   = make(map[string]*connection)
+	connectionsMu sync.Mutex
+)
+
 func getConnection(host, port, user, password string) (*nats.Conn, error) {
 	url := fmt.Sprintf("nats://%s:%s@%s:%s", user, password, host, port)
-	connection, err := nats.Connect(url)
+	connectionsMu.Lock()
+	defer connectionsMu.Unlock()
+	if shared, exists := connections[url]; exists && !shared.conn.IsClosed() {
+		return shared.conn, nil
+	}
+	closed := make(chan struct{})
+	conn, err := nats.Connect(url, nats.ClosedHandler(func(*nats.Conn) { close(closed) }))
 	if err != nil {
 		return nil, err
 	}
-	return connection, nil
+	connections[url] = &connection{conn: conn, closed: closed}
+	return conn, nil
+}
+
+// Drain drains the connections of the service and waits until they are closed.
+// Subscriptions stop receiving messages but finish handling the ones they already
+// got, and messages still buffered by publishers are flushed. Publishers and
+// subscribers must not be used afterwards.
+func Drain() {
+	connectionsMu.Lock()
+	draining := connections
+	connections = make(map[string]*connection)
+	connectionsMu.Unlock()
+	for _, shared := range draining {
+		if err := shared.conn.Drain(); err != nil {
+			log.Println(fmt.Sprintf("unable to drain nats connection: %v", err))
+			shared.conn.Close()
+		}
+	}
+	for _, shared := range draining {
+		<-shared.closed
+	}
 }
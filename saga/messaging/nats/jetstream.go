@@ -0,0 +1,77 @@
+package nats
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+const (
+	streamMaxAge    = 7 * 24 * time.Hour
+	maxDeliver      = 5
+	ackWait         = 30 * time.Second
+	deadLetterToken = ".dead"
+)
+
+// redeliveryBackOff is the delay before each redelivery of a message whose
+// handler failed, indexed by the number of deliveries so far.
+var redeliveryBackOff = []time.Duration{
+	1 * time.Second,
+	5 * time.Second,
+	15 * time.Second,
+	30 * time.Second,
+}
+
+// ErrNoSubject is returned for an empty subject, which usually means the
+// environment variable naming it is not set.
+var ErrNoSubject = errors.New("nats subject is empty, check that its environment variable is set")
+
+func streamName(subject string) string {
+	return strings.ToUpper(strings.ReplaceAll(subject, ".", "_"))
+}
+
+func deadLetterSubject(subject string) string {
+	return subject + deadLetterToken
+}
+
+func backOffFor(delivered uint64) time.Duration {
+	if delivered == 0 {
+		return redeliveryBackOff[0]
+	}
+	if int(delivered) > len(redeliveryBackOff) {
+		return redeliveryBackOff[len(redeliveryBackOff)-1]
+	}
+	return redeliveryBackOff[delivered-1]
+}
+
+// getJetStream makes sure a stream holding the subject and its dead-letter
+// subject exists, on the connection the service shares for the server.
+func getJetStream(host, port, user, password, subject string) (nats.JetStreamContext, error) {
+	if subject == "" {
+		return nil, ErrNoSubject
+	}
+	conn, err := getConnection(host, port, user, password)
+	if err != nil {
+		return nil, err
+	}
+	js, err := conn.JetStream()
+	if err != nil {
+		return nil, err
+	}
+	name := streamName(subject)
+	_, err = js.StreamInfo(name)
+	if err == nats.ErrStreamNotFound {
+		_, err = js.AddStream(&nats.StreamConfig{
+			Name:     name,
+			Subjects: []string{subject, deadLetterSubject(subject)},
+			Storage:  nats.FileStorage,
+			MaxAge:   streamMaxAge,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return js, nil
+}
@@ -0,0 +1,45 @@
+package nats
+
+import (
+	"encoding/json"
+	"example/saga/messaging"
+
+	"github.com/nats-io/nats.go"
+)
+
+type JetStreamPublisher struct {
+	js      nats.JetStreamContext
+	subject string
+}
+
+// NewJetStreamPublisher publishes on the connection the service shares for the
+// server, which Drain closes on shutdown.
+func NewJetStreamPublisher(host, port, user, password, subject string) (messaging.Publisher, error) {
+	js, err := getJetStream(host, port, user, password, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &JetStreamPublisher{
+		js:      js,
+		subject: subject,
+	}, nil
+}
+
+// Publish wraps the message in an envelope, unless it already is one, and returns
+// only after the stream acknowledged it, so a nil error means the message is
+// stored even if no subscriber is running.
+func (p *JetStreamPublisher) Publish(message interface{}) error {
+	envelope, err := messaging.Wrap(message)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+	_, err = p.js.Publish(p.subject, data)
+	if err != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package nats
+
+import (
+	"example/saga/messaging"
+	"fmt"
+	"log"
+
+	"github.com/nats-io/nats.go"
+)
+
+type JetStreamSubscriber struct {
+	js      nats.JetStreamContext
+	subject string
+	durable string
+}
+
+// NewJetStreamSubscriber creates a subscriber backed by a durable consumer. Every
+// service instance using the same durable name shares the work, like a queue group.
+// It subscribes on the connection the service shares for the server, which Drain
+// closes on shutdown.
+func NewJetStreamSubscriber(host, port, user, password, subject, durable string) (messaging.Subscriber, error) {
+	js, err := getJetStream(host, port, user, password, subject)
+	if err != nil {
+		return nil, err
+	}
+	return &JetStreamSubscriber{
+		js:      js,
+		subject: subject,
+		durable: durable,
+	}, nil
+}
+
+// Subscribe accepts the same handlers as the core NATS subscriber: a func taking
+// the decoded message by value or by pointer, optionally followed by its
+// *Envelope. A message is acked after the handler returns and redelivered with
+// back-off if it panics or returns a non-nil error. Messages that cannot be
+// decoded, or that keep failing after maxDeliver attempts, go to the dead-letter
+// subject.
+func (s *JetStreamSubscriber) Subscribe(handler interface{}) error {
+	wrapped, err := messaging.NewHandler(handler)
+	if err != nil {
+		return err
+	}
+	_, err = s.js.QueueSubscribe(s.subject, s.durable, func(msg *nats.Msg) {
+		s.dispatch(wrapped, msg)
+	},
+		nats.Durable(s.durable),
+		nats.DeliverAll(),
+		nats.ManualAck(),
+		nats.AckExplicit(),
+		nats.AckWait(ackWait),
+		nats.MaxDeliver(maxDeliver),
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (s *JetStreamSubscriber) dispatch(handler *messaging.Handler, msg *nats.Msg) {
+	args, err := handler.Decode(msg.Data)
+	if err != nil {
+		s.deadLetter(msg, "undecodable message: "+err.Error())
+		return
+	}
+	if err := handler.Call(args); err != nil {
+		var delivered uint64
+		if metadata, metaErr := msg.Metadata(); metaErr == nil {
+			delivered = metadata.NumDelivered
+		}
+		if delivered >= maxDeliver {
+			s.deadLetter(msg, fmt.Sprintf("failed after %d deliveries: %v", delivered, err))
+			return
+		}
+		_ = msg.NakWithDelay(backOffFor(delivered))
+		return
+	}
+	_ = msg.Ack()
+}
+
+func (s *JetStreamSubscriber) deadLetter(msg *nats.Msg, reason string) {
+	dead := nats.NewMsg(deadLetterSubject(s.subject))
+	dead.Data = msg.Data
+	dead.Header.Set("Dead-Letter-Reason", reason)
+	dead.Header.Set("Dead-Letter-Consumer", s.durable)
+	if _, err := s.js.PublishMsg(dead); err != nil {
+		log.Println(fmt.Sprintf("unable to dead-letter message on %s: %v", s.subject, err))
+		_ = msg.Nak()
+		return
+	}
+	_ = msg.Term()
+}
@@ -23,7 +23,11 @@ func NewNATSPublisher(host, port, user, password, subject string) (messaging.Pub
 }
 
 func (p *Publisher) Publish(message interface{}) error {
-	err := p.conn.Publish(p.subject, message)
+	envelope, err := messaging.Wrap(message)
+	if err != nil {
+		return err
+	}
+	err = p.conn.Publish(p.subject, envelope)
 	if err != nil {
 		return err
 	}
@@ -2,6 +2,9 @@ package nats
 
 import (
 	"example/saga/messaging"
+	"fmt"
+	"log"
+
 	"github.com/nats-io/nats.go"
 )
 
@@ -28,7 +31,19 @@ func NewNATSSubscriber(host, port, user, password, subject, queueGroup string) (
 }
 
 func (s *Subscriber) Subscribe(handler interface{}) error {
-	_, err := s.conn.QueueSubscribe(s.subject, s.queueGroup, handler)
+	wrapped, err := messaging.NewHandler(handler)
+	if err != nil {
+		return err
+	}
+	_, err = s.conn.QueueSubscribe(s.subject, s.queueGroup, func(msg *nats.Msg) {
+		args, err := wrapped.Decode(msg.Data)
+		if err == nil {
+			err = wrapped.Call(args)
+		}
+		if err != nil {
+			log.Println(fmt.Sprintf("unable to handle message on %s: %v", s.subject, err))
+		}
+	})
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,47 @@
+package messaging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Upcaster rewrites the payload of a message from one schema version to the next.
+type Upcaster func(payload json.RawMessage) (json.RawMessage, error)
+
+var (
+	upcasters   = make(map[string]map[int]Upcaster)
+	upcastersMu sync.RWMutex
+)
+
+// RegisterUpcaster registers the upcaster turning fromVersion of messageType into
+// fromVersion+1. Message packages register theirs in init, so every consumer that
+// imports the message type can read its older versions.
+func RegisterUpcaster(messageType string, fromVersion int, upcaster Upcaster) {
+	upcastersMu.Lock()
+	defer upcastersMu.Unlock()
+	if upcasters[messageType] == nil {
+		upcasters[messageType] = make(map[int]Upcaster)
+	}
+	upcasters[messageType][fromVersion] = upcaster
+}
+
+// Upcast applies the registered upcasters in order, starting at version, until no
+// upcaster is left for the version reached.
+func Upcast(messageType string, version int, payload json.RawMessage) (json.RawMessage, error) {
+	upcastersMu.RLock()
+	chain := upcasters[messageType]
+	upcastersMu.RUnlock()
+	for {
+		upcaster, exists := chain[version]
+		if !exists {
+			return payload, nil
+		}
+		upcasted, err := upcaster(payload)
+		if err != nil {
+			return nil, fmt.Errorf("unable to upcast %s from version %d: %v", messageType, version, err)
+		}
+		payload = upcasted
+		version++
+	}
+}
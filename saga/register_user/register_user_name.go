@@ -0,0 +1,52 @@
+package register_user
+
+// RegisterUserDetails is what the services need to set up a new user. The saga is
+// keyed by UserID, the id auth-service gave the credentials.
+type RegisterUserDetails struct {
+	UserID    string
+	FirstName string
+	LastName  string
+	Email     string
+	Residence string
+	Role      string
+	Username  string
+	Age       int
+}
+
+type RegisterUserCommandType int8
+
+// komande
+const (
+	RollbackUserCredentials RegisterUserCommandType = iota
+	CreateUserProfile
+	RemoveUserProfile
+	CreateUserInbox
+	RemoveUserInbox
+	CompleteRegistration
+	UnknownCommand
+	// ReopenRegistration is appended so the commands sent before it keep their values.
+	ReopenRegistration
+)
+
+type RegisterUserReplyType int8
+
+// REPLY
+const (
+	UserProfileCreated RegisterUserReplyType = iota
+	UserProfileNotCreated
+	UserInboxCreated
+	UserInboxNotCreated
+	RegistrationCompleted
+	RegistrationNotCompleted
+	UnknownReply
+)
+
+type RegisterUserCommand struct {
+	Type    RegisterUserCommandType
+	Payload RegisterUserDetails
+}
+
+type RegisterUserReply struct {
+	Type    RegisterUserReplyType
+	Payload RegisterUserDetails
+}
@@ -0,0 +1,80 @@
+package register_user
+
+import "example/saga/messaging"
+
+// Version 1 of the commands and replies sent Type as the iota value of the enum.
+// Version 2 sends the name of the constant instead.
+const (
+	commandMessageType = "register_user.RegisterUserCommand"
+	replyMessageType   = "register_user.RegisterUserReply"
+	schemaVersion      = 2
+)
+
+// Envelopes published before the messages were versioned carry the Go type name
+// and version 1.
+const (
+	legacyCommandMessageType = "RegisterUserCommand"
+	legacyReplyMessageType   = "RegisterUserReply"
+)
+
+var commandNames = map[RegisterUserCommandType]string{
+	RollbackUserCredentials: "RollbackUserCredentials",
+	CreateUserProfile:       "CreateUserProfile",
+	RemoveUserProfile:       "RemoveUserProfile",
+	CreateUserInbox:         "CreateUserInbox",
+	RemoveUserInbox:         "RemoveUserInbox",
+	CompleteRegistration:    "CompleteRegistration",
+	UnknownCommand:          "UnknownCommand",
+	ReopenRegistration:      "ReopenRegistration",
+}
+
+var replyNames = map[RegisterUserReplyType]string{
+	UserProfileCreated:       "UserProfileCreated",
+	UserProfileNotCreated:    "UserProfileNotCreated",
+	UserInboxCreated:         "UserInboxCreated",
+	UserInboxNotCreated:      "UserInboxNotCreated",
+	RegistrationCompleted:    "RegistrationCompleted",
+	RegistrationNotCompleted: "RegistrationNotCompleted",
+	UnknownReply:             "UnknownReply",
+}
+
+// commandNamesV1 and replyNamesV1 freeze the iota order used by version 1. They
+// must never change, even if the constants above are reordered.
+var commandNamesV1 = []string{"RollbackUserCredentials", "CreateUserProfile", "RemoveUserProfile", "CreateUserInbox", "RemoveUserInbox", "CompleteRegistration", "UnknownCommand", "ReopenRegistration"}
+
+var replyNamesV1 = []string{"UserProfileCreated", "UserProfileNotCreated", "UserInboxCreated", "UserInboxNotCreated", "RegistrationCompleted", "RegistrationNotCompleted", "UnknownReply"}
+
+func init() {
+	for _, messageType := range []string{commandMessageType, legacyCommandMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(commandNamesV1, "UnknownCommand"))
+	}
+	for _, messageType := range []string{replyMessageType, legacyReplyMessageType} {
+		messaging.RegisterUpcaster(messageType, 1, messaging.TypeToName(replyNamesV1, "UnknownReply"))
+	}
+}
+
+func (RegisterUserCommand) MessageType() string { return commandMessageType }
+
+func (RegisterUserCommand) SchemaVersion() int { return schemaVersion }
+
+func (RegisterUserReply) MessageType() string { return replyMessageType }
+
+func (RegisterUserReply) SchemaVersion() int { return schemaVersion }
+
+func (t RegisterUserCommandType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(commandNames, t)
+}
+
+func (t *RegisterUserCommandType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(commandNames, text, UnknownCommand)
+	return nil
+}
+
+func (t RegisterUserReplyType) MarshalText() ([]byte, error) {
+	return messaging.MarshalName(replyNames, t)
+}
+
+func (t *RegisterUserReplyType) UnmarshalText(text []byte) error {
+	*t = messaging.UnmarshalName(replyNames, text, UnknownReply)
+	return nil
+}
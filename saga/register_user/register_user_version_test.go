@@ -0,0 +1,46 @@
+package register_user
+
+import (
+	"example/saga/messaging/messagingtest"
+	"testing"
+)
+
+func TestCommandVersions(t *testing.T) {
+	messagingtest.Versions[RegisterUserCommand, RegisterUserCommandType]{
+		Message: func(commandType RegisterUserCommandType) RegisterUserCommand {
+			return RegisterUserCommand{Type: commandType}
+		},
+		Type:  func(command RegisterUserCommand) RegisterUserCommandType { return command.Type },
+		Names: commandNames,
+		Version1: map[int]RegisterUserCommandType{
+			0: RollbackUserCredentials,
+			1: CreateUserProfile,
+			2: RemoveUserProfile,
+			3: CreateUserInbox,
+			4: RemoveUserInbox,
+			5: CompleteRegistration,
+			6: UnknownCommand,
+			7: ReopenRegistration,
+			8: UnknownCommand,
+		},
+		Legacy: "RegisterUserCommand",
+	}.Run(t)
+}
+
+func TestReplyVersions(t *testing.T) {
+	messagingtest.Versions[RegisterUserReply, RegisterUserReplyType]{
+		Message: func(replyType RegisterUserReplyType) RegisterUserReply { return RegisterUserReply{Type: replyType} },
+		Type:    func(reply RegisterUserReply) RegisterUserReplyType { return reply.Type },
+		Names:   replyNames,
+		Version1: map[int]RegisterUserReplyType{
+			0: UserProfileCreated,
+			1: UserProfileNotCreated,
+			2: UserInboxCreated,
+			3: UserInboxNotCreated,
+			4: RegistrationCompleted,
+			5: RegistrationNotCompleted,
+			6: UnknownReply,
+			7: UnknownReply,
+		},
+	}.Run(t)
+}
@@ -0,0 +1,208 @@
+package sagatest
+
+import (
+	events "example/saga/create_accommodation"
+	"example/saga/messaging"
+	"sync"
+)
+
+const (
+	CreateAccommodationCommandSubject = "accommodation.create.command"
+	CreateAccommodationReplySubject   = "accommodation.create.reply"
+)
+
+func (h *Harness) CreateAccommodationCommandPublisher() messaging.Publisher {
+	return h.Broker.Publisher(CreateAccommodationCommandSubject)
+}
+
+func (h *Harness) CreateAccommodationCommandSubscriber(queueGroup string) messaging.Subscriber {
+	return h.Broker.Subscriber(CreateAccommodationCommandSubject, queueGroup)
+}
+
+func (h *Harness) CreateAccommodationReplyPublisher() messaging.Publisher {
+	return h.Broker.Publisher(CreateAccommodationReplySubject)
+}
+
+func (h *Harness) CreateAccommodationReplySubscriber(queueGroup string) messaging.Subscriber {
+	return h.Broker.Subscriber(CreateAccommodationReplySubject, queueGroup)
+}
+
+// FakeReservations stands in for reservations-service: it creates availability for
+// every accommodation except the ones marked with FailFor, and never answers for
+// the ones marked with IgnoreFor. Availability it rolled back is never created
+// again.
+type FakeReservations struct {
+	replies    messaging.Publisher
+	failing    map[string]bool
+	ignored    map[string]bool
+	created    map[string]bool
+	rolledBack map[string]bool
+	status     func(accommodationID string) string
+	received   []events.CreateAccommodationCommandType
+	mu         sync.Mutex
+}
+
+func (h *Harness) FakeReservations() (*FakeReservations, error) {
+	fake := &FakeReservations{
+		replies:    h.CreateAccommodationReplyPublisher(),
+		failing:    make(map[string]bool),
+		ignored:    make(map[string]bool),
+		created:    make(map[string]bool),
+		rolledBack: make(map[string]bool),
+	}
+	err := h.CreateAccommodationCommandSubscriber("reservations-service").Subscribe(fake.handle)
+	if err != nil {
+		return nil, err
+	}
+	return fake, nil
+}
+
+func (f *FakeReservations) FailFor(accommodationID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing[accommodationID] = true
+}
+
+// IgnoreFor creates the availability of accommodationID without replying, like a
+// participant whose reply got lost.
+func (f *FakeReservations) IgnoreFor(accommodationID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ignored[accommodationID] = true
+}
+
+// CheckStatusWith makes the fake roll back availability only while status reports
+// the accommodation Pending, like reservations-service asking accommodations-service.
+func (f *FakeReservations) CheckStatusWith(status func(accommodationID string) string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status = status
+}
+
+func (f *FakeReservations) HasAvailability(accommodationID string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.created[accommodationID]
+}
+
+func (f *FakeReservations) Received() []events.CreateAccommodationCommandType {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]events.CreateAccommodationCommandType(nil), f.received...)
+}
+
+func (f *FakeReservations) handle(command *events.CreateAccommodationCommand) {
+	f.mu.Lock()
+	f.received = append(f.received, command.Type)
+	id := command.Payload.AccommodationID
+	reply := events.CreateAccommodationReply{Type: events.UnknownReply, Payload: command.Payload}
+	switch command.Type {
+	case events.CreateAvailability:
+		if f.failing[id] || f.rolledBack[id] {
+			reply.Type = events.AvailabilityNotCreated
+		} else {
+			f.created[id] = true
+			reply.Type = events.AvailabilityCreated
+		}
+	case events.RollbackAvailability:
+		if f.status == nil || f.status(id) == "Pending" {
+			delete(f.created, id)
+			f.rolledBack[id] = true
+		}
+	}
+	ignored := f.ignored[id]
+	f.mu.Unlock()
+	if reply.Type != events.UnknownReply && !ignored {
+		_ = f.replies.Publish(reply)
+	}
+}
+
+// FakeAccommodations stands in for the command handler of accommodations-service:
+// it approves accommodations and records the ones that were rolled back. It
+// approves the ones marked with IgnoreFor without answering.
+type FakeAccommodations struct {
+	replies  messaging.Publisher
+	statuses map[string]string
+	ignored  map[string]bool
+	mu       sync.Mutex
+}
+
+func (h *Harness) FakeAccommodations() (*FakeAccommodations, error) {
+	fake := &FakeAccommodations{
+		replies:  h.CreateAccommodationReplyPublisher(),
+		statuses: make(map[string]string),
+		ignored:  make(map[string]bool),
+	}
+	err := h.CreateAccommodationCommandSubscriber("accommodations-service").Subscribe(fake.handle)
+	if err != nil {
+		return nil, err
+	}
+	return fake, nil
+}
+
+// IgnoreFor approves accommodationID without replying, like a participant whose
+// reply got lost.
+func (f *FakeAccommodations) IgnoreFor(accommodationID string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ignored[accommodationID] = true
+}
+
+// Status returns "Approved", "RolledBack" or "Denied" once the saga acted on the
+// accommodation, and "Pending" before that.
+func (f *FakeAccommodations) Status(accommodationID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	status, exists := f.statuses[accommodationID]
+	if !exists {
+		return "Pending"
+	}
+	return status
+}
+
+func (f *FakeAccommodations) handle(command *events.CreateAccommodationCommand) {
+	id := command.Payload.AccommodationID
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch command.Type {
+	case events.UpdateAccommodation:
+		f.statuses[id] = "Approved"
+		if f.ignored[id] {
+			return
+		}
+		_ = f.replies.Publish(events.CreateAccommodationReply{Type: events.AccommodationApproved, Payload: command.Payload})
+	case events.RollbackAccommodation:
+		if f.statuses[id] != "Approved" {
+			f.statuses[id] = "RolledBack"
+		}
+	case events.DenyAccommodation:
+		f.statuses[id] = "Denied"
+	}
+}
+
+// ReplyRecorder collects replies so a command handler can be checked in isolation.
+type ReplyRecorder struct {
+	replies []events.CreateAccommodationReply
+	mu      sync.Mutex
+}
+
+func (h *Harness) RecordCreateAccommodationReplies() (*ReplyRecorder, error) {
+	recorder := &ReplyRecorder{}
+	err := h.CreateAccommodationReplySubscriber("reply-recorder").Subscribe(recorder.handle)
+	if err != nil {
+		return nil, err
+	}
+	return recorder, nil
+}
+
+func (r *ReplyRecorder) Replies() []events.CreateAccommodationReply {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]events.CreateAccommodationReply(nil), r.replies...)
+}
+
+func (r *ReplyRecorder) handle(reply *events.CreateAccommodationReply) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.replies = append(r.replies, *reply)
+}
@@ -0,0 +1,126 @@
+package sagatest
+
+import (
+	events "example/saga/create_reservation"
+	"example/saga/messaging"
+	"sync"
+)
+
+const (
+	CreateReservationCommandSubject = "reservation.create.command"
+	CreateReservationReplySubject   = "reservation.create.reply"
+)
+
+func (h *Harness) CreateReservationCommandPublisher() messaging.Publisher {
+	return h.Broker.Publisher(CreateReservationCommandSubject)
+}
+
+func (h *Harness) CreateReservationCommandSubscriber(queueGroup string) messaging.Subscriber {
+	return h.Broker.Subscriber(CreateReservationCommandSubject, queueGroup)
+}
+
+func (h *Harness) CreateReservationReplyPublisher() messaging.Publisher {
+	return h.Broker.Publisher(CreateReservationReplySubject)
+}
+
+func (h *Harness) CreateReservationReplySubscriber(queueGroup string) messaging.Subscriber {
+	return h.Broker.Subscriber(CreateReservationReplySubject, queueGroup)
+}
+
+// reservationParticipants maps every queue group taking part in the create
+// reservation saga to the commands it handles.
+var reservationParticipants = map[string][]events.CreateReservationCommandType{
+	"reservations-service":  {events.ConfirmReservation, events.CancelReservation, events.ReleaseHold},
+	"metrics-command":       {events.RecordUserReserved, events.RetractUserReserved},
+	"notifications-service": {events.NotifyHost},
+}
+
+// reservationAnswers holds the success and failure reply of every command that is
+// answered. Compensations are not.
+var reservationAnswers = map[events.CreateReservationCommandType][2]events.CreateReservationReplyType{
+	events.ConfirmReservation: {events.ReservationConfirmed, events.ReservationNotConfirmed},
+	events.RecordUserReserved: {events.UserReservedRecorded, events.UserReservedNotRecorded},
+	events.NotifyHost:         {events.HostNotified, events.HostNotNotified},
+}
+
+// FakeReservationParticipants stands in for reservations-service, metrics-command
+// and notifications-service. Every command succeeds except the ones marked with
+// FailOn, and the ones marked with IgnoreOn are never answered.
+type FakeReservationParticipants struct {
+	replies  messaging.Publisher
+	failing  map[events.CreateReservationCommandType]bool
+	ignored  map[events.CreateReservationCommandType]bool
+	received []events.CreateReservationCommandType
+	traces   []map[string]string
+	mu       sync.Mutex
+}
+
+func (h *Harness) FakeReservationParticipants() (*FakeReservationParticipants, error) {
+	fake := &FakeReservationParticipants{
+		replies: h.CreateReservationReplyPublisher(),
+		failing: make(map[events.CreateReservationCommandType]bool),
+		ignored: make(map[events.CreateReservationCommandType]bool),
+	}
+	for queueGroup, handled := range reservationParticipants {
+		handled := handled
+		err := h.CreateReservationCommandSubscriber(queueGroup).Subscribe(func(command *events.CreateReservationCommand, envelope *messaging.Envelope) {
+			for _, commandType := range handled {
+				if commandType == command.Type {
+					fake.handle(command, envelope)
+				}
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return fake, nil
+}
+
+func (f *FakeReservationParticipants) FailOn(commandType events.CreateReservationCommandType) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failing[commandType] = true
+}
+
+// IgnoreOn makes the participant apply commandType without replying, like a
+// participant whose reply got lost.
+func (f *FakeReservationParticipants) IgnoreOn(commandType events.CreateReservationCommandType) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.ignored[commandType] = true
+}
+
+// Received returns every command sent so far, compensations included, in the
+// order they were handled.
+func (f *FakeReservationParticipants) Received() []events.CreateReservationCommandType {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]events.CreateReservationCommandType(nil), f.received...)
+}
+
+// TraceContexts returns the trace context of every command sent so far, in the
+// order they were handled.
+func (f *FakeReservationParticipants) TraceContexts() []map[string]string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]map[string]string(nil), f.traces...)
+}
+
+func (f *FakeReservationParticipants) handle(command *events.CreateReservationCommand, envelope *messaging.Envelope) {
+	f.mu.Lock()
+	f.received = append(f.received, command.Type)
+	f.traces = append(f.traces, envelope.TraceContext)
+	failing := f.failing[command.Type]
+	ignored := f.ignored[command.Type]
+	f.mu.Unlock()
+	answer, answered := reservationAnswers[command.Type]
+	if !answered || ignored {
+		return
+	}
+	reply := events.CreateReservationReply{Type: answer[0], Payload: command.Payload}
+	if failing {
+		reply.Type = answer[1]
+	}
+	_ = messaging.Reply(f.replies, reply, envelope)
+}
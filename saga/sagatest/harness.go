@@ -0,0 +1,58 @@
+// Package sagatest runs sagas in process, against an in-memory broker and store,
+// so orchestrators and command handlers can be exercised without NATS or a database.
+package sagatest
+
+import (
+	"example/saga/engine"
+	"example/saga/messaging/memory"
+	"example/saga/store"
+	"fmt"
+	"time"
+)
+
+type Harness struct {
+	Broker *memory.Broker
+	Store  engine.Store
+}
+
+func NewHarness() *Harness {
+	return &Harness{
+		Broker: memory.NewBroker(),
+		Store:  store.NewMemoryStore(),
+	}
+}
+
+// Settle waits until every message sent so far, and every message sent in
+// reaction to them, has been handled.
+func (h *Harness) Settle() {
+	h.Broker.Wait()
+}
+
+func (h *Harness) State(id string) (engine.State, error) {
+	instance, err := h.Store.Get(id)
+	if err != nil {
+		return "", err
+	}
+	return instance.State, nil
+}
+
+// WaitFor settles the harness until the saga reaches state, for sagas moved on by
+// their timeouts rather than by replies. It gives up after timeout.
+func (h *Harness) WaitFor(id string, state engine.State, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		h.Settle()
+		current, err := h.State(id)
+		if err != nil {
+			return err
+		}
+		if current == state {
+			h.Settle()
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("saga %s is still %s after %v", id, current, timeout)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"example/saga/engine"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps instances in process memory. It loses every saga on restart
+// and is meant for tests and local runs only.
+type MemoryStore struct {
+	instances map[string]engine.Instance
+	mu        sync.Mutex
+}
+
+func NewMemoryStore() engine.Store {
+	return &MemoryStore{
+		instances: make(map[string]engine.Instance),
+	}
+}
+
+func (s *MemoryStore) Save(instance *engine.Instance) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.instances[instance.ID]
+	if (exists && stored.Version != instance.Version) || (!exists && instance.Version != 0) {
+		return engine.ErrConflict
+	}
+	instance.Version++
+	s.instances[instance.ID] = copyInstance(instance)
+	return nil
+}
+
+func (s *MemoryStore) Get(id string) (*engine.Instance, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored, exists := s.instances[id]
+	if !exists {
+		return nil, engine.ErrNotFound
+	}
+	instance := copyInstance(&stored)
+	return &instance, nil
+}
+
+func (s *MemoryStore) FindActive(sagaType string) ([]*engine.Instance, error) {
+	return s.filter(func(instance *engine.Instance) bool {
+		return instance.SagaType == sagaType && instance.IsActive()
+	}), nil
+}
+
+func (s *MemoryStore) FindExpired(sagaType string, now time.Time) ([]*engine.Instance, error) {
+	return s.filter(func(instance *engine.Instance) bool {
+		return instance.SagaType == sagaType && instance.IsActive() &&
+			!instance.Deadline.IsZero() && !instance.Deadline.After(now)
+	}), nil
+}
+
+func (s *MemoryStore) filter(match func(instance *engine.Instance) bool) []*engine.Instance {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var instances []*engine.Instance
+	for _, stored := range s.instances {
+		if match(&stored) {
+			instance := copyInstance(&stored)
+			instances = append(instances, &instance)
+		}
+	}
+	return instances
+}
+
+func copyInstance(instance *engine.Instance) engine.Instance {
+	copied := *instance
+	copied.Payload = append([]byte(nil), instance.Payload...)
+	copied.History = append([]engine.StepRecord(nil), instance.History...)
+	copied.Compensations = append([]string(nil), instance.Compensations...)
+	return copied
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"example/saga/engine"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const queryTimeout = 5 * time.Second
+
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+func NewMongoStore(cli *mongo.Client, database, collection string) engine.Store {
+	return &MongoStore{
+		collection: cli.Database(database).Collection(collection),
+	}
+}
+
+func (s *MongoStore) Save(instance *engine.Instance) error {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	expected := instance.Version
+	instance.Version++
+	if expected == 0 {
+		_, err := s.collection.InsertOne(ctx, instance)
+		if mongo.IsDuplicateKeyError(err) {
+			instance.Version = expected
+			return engine.ErrConflict
+		}
+		if err != nil {
+			instance.Version = expected
+		}
+		return err
+	}
+	result, err := s.collection.ReplaceOne(ctx, bson.M{"_id": instance.ID, "version": expected}, instance)
+	if err != nil {
+		instance.Version = expected
+		return err
+	}
+	if result.MatchedCount == 0 {
+		instance.Version = expected
+		return engine.ErrConflict
+	}
+	return nil
+}
+
+func (s *MongoStore) Get(id string) (*engine.Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	var instance engine.Instance
+	err := s.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&instance)
+	if err == mongo.ErrNoDocuments {
+		return nil, engine.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &instance, nil
+}
+
+func (s *MongoStore) FindActive(sagaType string) ([]*engine.Instance, error) {
+	return s.find(bson.M{
+		"sagaType": sagaType,
+		"state":    bson.M{"$in": bson.A{engine.Running, engine.Compensating}},
+	})
+}
+
+func (s *MongoStore) FindExpired(sagaType string, now time.Time) ([]*engine.Instance, error) {
+	return s.find(bson.M{
+		"sagaType": sagaType,
+		"state":    bson.M{"$in": bson.A{engine.Running, engine.Compensating}},
+		"deadline": bson.M{"$gt": time.Time{}, "$lte": now},
+	})
+}
+
+func (s *MongoStore) find(filter bson.M) ([]*engine.Instance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), queryTimeout)
+	defer cancel()
+	cursor, err := s.collection.Find(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+	var instances []*engine.Instance
+	if err := cursor.All(ctx, &instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
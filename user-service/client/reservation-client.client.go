@@ -117,19 +117,6 @@ func (rc ReservationClient) getCancelRate(ctx context.Context, id string) (*floa
 	return nil, errors.NewError(baseResp.Error, baseResp.Status)
 }
 
-func (rc ReservationClient) UserDeleteAllowed(ctx context.Context, id, role string) *errors.ErrorStruct {
-	ctx, span := rc.tracer.Start(ctx, "ReservationClient.UserDeleteAllowed")
-	defer span.End()
-	list, err := rc.getReservationsForUser(ctx, id, role)
-	if err != nil {
-		return err
-	}
-	if len(list) != 0 {
-		return errors.NewError(fmt.Sprintf("User by id: %v has open reservations", id), 401)
-	}
-	return nil
-}
-
 // requirements lowered for easier examples
 func (rc ReservationClient) CheckDistinguished(ctx context.Context, id string) (bool, *errors.ErrorStruct) {
 	ctx, span := rc.tracer.Start(ctx, "ReservationClient.CheckDistinguished")
@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"context"
+	events "example/saga/delete_user"
+	saga "example/saga/messaging"
+	"fmt"
+	"user-service/config"
+	"user-service/service"
+)
+
+// DeleteUserCommandHandler is the user-service participant of the delete user
+// saga: it deletes the profile once every other service archived the user.
+type DeleteUserCommandHandler struct {
+	userService       *service.UserService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewDeleteUserCommandHandler(userService *service.UserService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*DeleteUserCommandHandler, error) {
+	o := &DeleteUserCommandHandler{
+		userService:       userService,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError(source, "Unable to subscribe to delete user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *DeleteUserCommandHandler) handle(command *events.DeleteUserCommand, envelope *saga.Envelope) error {
+	if command.Type != events.DeleteUserProfile {
+		return nil
+	}
+	details := command.Payload
+	reply := events.DeleteUserReply{Type: events.UserProfileDeleted, Payload: details}
+	if err := handler.userService.DeleteUserProfile(context.Background(), details.UserID); err != nil {
+		handler.logger.LogError(source, fmt.Sprintf("Unable to delete profile of user %s: %s", details.UserID, err.GetErrorMessage()))
+		reply.Type = events.UserProfileNotDeleted
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"context"
+	saga "example/saga/messaging"
+	events "example/saga/register_user"
+	"fmt"
+	"user-service/config"
+	"user-service/service"
+)
+
+// RegisterUserCommandHandler is the user-service participant of the register user
+// saga: it creates the profile of a new user and removes it when the saga fails.
+type RegisterUserCommandHandler struct {
+	userService       *service.UserService
+	replyPublisher    saga.Publisher
+	commandSubscriber saga.Subscriber
+	logger            *config.Logger
+}
+
+func NewRegisterUserCommandHandler(userService *service.UserService, publisher saga.Publisher, subscriber saga.Subscriber, logger *config.Logger) (*RegisterUserCommandHandler, error) {
+	o := &RegisterUserCommandHandler{
+		userService:       userService,
+		replyPublisher:    publisher,
+		commandSubscriber: subscriber,
+		logger:            logger,
+	}
+	err := o.commandSubscriber.Subscribe(o.handle)
+	if err != nil {
+		logger.LogError(source, "Unable to subscribe to register user commands")
+		return nil, err
+	}
+	return o, nil
+}
+
+func (handler *RegisterUserCommandHandler) handle(command *events.RegisterUserCommand, envelope *saga.Envelope) error {
+	details := command.Payload
+	reply := events.RegisterUserReply{Payload: details}
+	switch command.Type {
+	case events.CreateUserProfile:
+		reply.Type = events.UserProfileCreated
+		if err := handler.userService.CreateUserProfile(context.Background(), details); err != nil {
+			if err.GetErrorStatus() >= 500 {
+				// Likely transient, let the command be redelivered.
+				return fmt.Errorf("%s", err.GetErrorMessage())
+			}
+			handler.logger.LogError(source, fmt.Sprintf("Unable to create profile of user %s: %s", details.UserID, err.GetErrorMessage()))
+			reply.Type = events.UserProfileNotCreated
+		}
+	case events.RemoveUserProfile:
+		if err := handler.userService.DeleteUserProfile(context.Background(), details.UserID); err != nil {
+			return fmt.Errorf("%s", err.GetErrorMessage())
+		}
+		return nil
+	default:
+		return nil
+	}
+	return saga.Reply(handler.replyPublisher, reply, envelope)
+}
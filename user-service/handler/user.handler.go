@@ -3,6 +3,7 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"go.opentelemetry.io/otel/trace"
 	"log"
 	"net/http"
@@ -175,10 +176,11 @@ func (u UserHandler) DeleteHandler(rw http.ResponseWriter, h *http.Request) {
 		utils.WriteErrorResponse("Not authorized", 401, "api/delete", rw)
 		return
 	}
-	err := u.UserService.DeleteUser(ctx, role.(string), id)
+	sagaID, err := u.UserService.DeleteUser(ctx, role.(string), id)
 	if err != nil {
 		utils.WriteErrorResponse(err.GetErrorMessage(), err.GetErrorStatus(), "api/delete", rw)
 		return
 	}
-	utils.WriteResp(id, 200, rw)
+	u.logger.LogInfo(source, fmt.Sprintf("Deletion of user %v accepted as saga %v", id, sagaID))
+	utils.WriteResp(id, 202, rw)
 }
@@ -2,6 +2,8 @@ package main
 
 import (
 	"context"
+	"example/saga/messaging/nats"
+	"example/saga/store"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 
@@ -19,6 +21,7 @@ import (
 	"user-service/config"
 	"user-service/handler"
 	"user-service/middleware"
+	"user-service/orchestrator"
 	"user-service/repository"
 	"user-service/service"
 	"user-service/tracing"
@@ -45,10 +48,6 @@ func main() {
 
 	reservationsServiceHost := os.Getenv("RESERVATIONS_SERVICE_HOST")
 	reservationsServicePort := os.Getenv("RESERVATIONS_SERVICE_PORT")
-	authServiceHost := os.Getenv("AUTH_SERVICE_HOST")
-	authServicePort := os.Getenv("AUTH_SERVICE_PORT")
-	accServiceHost := os.Getenv("ACCOMMODATION_SERVICE_HOST")
-	accServicePort := os.Getenv("ACCOMMODATION_SERVICE_PORT")
 
 	//clients
 
@@ -60,34 +59,6 @@ func main() {
 		},
 	}
 
-	customAuthServiceClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			MaxIdleConnsPerHost: 10,
-			MaxConnsPerHost:     10,
-		},
-	}
-
-	customAccServiceClient := &http.Client{
-		Transport: &http.Transport{
-			MaxIdleConns:        10,
-			MaxIdleConnsPerHost: 10,
-			MaxConnsPerHost:     10,
-		},
-	}
-
-	authServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
-		gobreaker.Settings{
-			Name:        "auth-service",
-			MaxRequests: 1,
-			Timeout:     10 * time.Second,
-			Interval:    0,
-			OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-				log.Printf("Circuit Breaker %v: %v -> %v", name, from, to)
-			},
-		},
-	)
-
 	reservationsServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
 		gobreaker.Settings{
 			Name:        "reservations-service",
@@ -99,21 +70,8 @@ func main() {
 			},
 		},
 	)
-	accommodationServiceCircuitBreaker := gobreaker.NewCircuitBreaker(
-		gobreaker.Settings{
-			Name:        "accommodation-service",
-			MaxRequests: 1,
-			Timeout:     30 * time.Second,
-			Interval:    0,
-			OnStateChange: func(name string, from gobreaker.State, to gobreaker.State) {
-				log.Printf("Circuit Breaker %v: %v -> %v", name, from, to)
-			},
-		},
-	)
 
 	reservationsClient := client.NewReservationClient(reservationsServiceHost, reservationsServicePort, customReservationsServiceClient, reservationsServiceCircuitBreaker, tracer)
-	authClient := client.NewAuthClient(authServiceHost, authServicePort, customAuthServiceClient, authServiceCircuitBreaker, tracer)
-	accClient := client.NewAccClient(accServiceHost, accServicePort, customAccServiceClient, accommodationServiceCircuitBreaker, tracer)
 
 	// service
 
@@ -123,9 +81,93 @@ func main() {
 	}
 	userRepo := repository.NewUserRepository(mongoService.GetCli(), logger, tracer)
 	validator := utils.NewValidator()
-	userService := service.NewUserService(userRepo, validator, reservationsClient, authClient, accClient, logger, tracer)
+
+	// saga
+
+	commandPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	replySubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+		"user-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaStore := store.NewMongoStore(mongoService.GetCli(), "user-service", "sagas")
+	deleteUserOrchestrator, err := orchestrator.NewDeleteUserOrchestrator(commandPublisher, replySubscriber, sagaStore, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	userService := service.NewUserService(userRepo, validator, reservationsClient, deleteUserOrchestrator, logger, tracer)
 	profileHandler := handler.NewUserHandler(userService, logger, tracer)
 
+	replyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	commandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("DELETE_USER_COMMAND_SUBJECT"),
+		"user-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewDeleteUserCommandHandler(userService, replyPublisher, commandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserReplyPublisher, err := nats.NewJetStreamPublisher(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_REPLY_SUBJECT"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registerUserCommandSubscriber, err := nats.NewJetStreamSubscriber(
+		os.Getenv("NATS_HOST"),
+		os.Getenv("NATS_PORT"),
+		os.Getenv("NATS_USER"),
+		os.Getenv("NATS_PASS"),
+		os.Getenv("REGISTER_USER_COMMAND_SUBJECT"),
+		"user-service")
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = handler.NewRegisterUserCommandHandler(userService, registerUserReplyPublisher, registerUserCommandSubscriber, logger)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sagaContext, stopSagas := context.WithCancel(context.Background())
+	defer stopSagas()
+	err = deleteUserOrchestrator.Resume(sagaContext)
+	if err != nil {
+		log.Println(err)
+	}
+
 	// router
 
 	router := mux.NewRouter()
@@ -181,6 +223,7 @@ func main() {
 			"error":  err.Error(),
 		})
 	}
+	nats.Drain()
 	logger.LogInfo(source, "Server shut down")
 
 }
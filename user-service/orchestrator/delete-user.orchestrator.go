@@ -0,0 +1,174 @@
+package orchestrator
+
+import (
+	"context"
+	events "example/saga/delete_user"
+	"example/saga/engine"
+	saga "example/saga/messaging"
+	"fmt"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+	"user-service/config"
+)
+
+const (
+	deleteUserSaga            = "delete-user"
+	checkReservationsStep     = "check-reservations"
+	archiveAuthStep           = "archive-auth"
+	archiveAccommodationsStep = "archive-accommodations"
+	archiveAvailabilityStep   = "archive-availability"
+	archiveNotificationsStep  = "archive-notifications"
+	archiveRatingsStep        = "archive-ratings"
+	deleteProfileStep         = "delete-profile"
+	stepTimeout               = 30 * time.Second
+	timeoutCheckInterval      = 5 * time.Second
+	source                    = "delete-user-orchestrator"
+)
+
+type DeleteUserOrchestrator struct {
+	sagas           *engine.Orchestrator
+	replySubscriber saga.Subscriber
+	logger          *config.Logger
+}
+
+func NewDeleteUserOrchestrator(publisher saga.Publisher, replySubscriber saga.Subscriber, store engine.Store, logger *config.Logger) (*DeleteUserOrchestrator, error) {
+	orchestrator := &DeleteUserOrchestrator{
+		sagas:           engine.NewOrchestrator(deleteUserDefinition(), publisher, store),
+		replySubscriber: replySubscriber,
+		logger:          logger,
+	}
+	err := orchestrator.replySubscriber.Subscribe(orchestrator.handle)
+	if err != nil {
+		logger.LogError(source, "Unable to subscribe to delete user replies")
+		return nil, err
+	}
+	return orchestrator, nil
+}
+
+// deleteUserDefinition describes the saga: after reservations-service confirms the
+// user has no open reservations, every service archives what it holds on the user,
+// and the profile is deleted last. A failed step restores what was archived.
+func deleteUserDefinition() engine.Definition {
+	return engine.Definition{
+		Name: deleteUserSaga,
+		Steps: []engine.Step{
+			{
+				Name:    checkReservationsStep,
+				Command: commandOf(events.CheckUserReservations),
+				Timeout: stepTimeout,
+			},
+			{
+				Name:         archiveAuthStep,
+				Command:      commandOf(events.ArchiveUserAuth),
+				Compensation: commandOf(events.RestoreUserAuth),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         archiveAccommodationsStep,
+				Command:      commandOf(events.ArchiveUserAccommodations),
+				Compensation: commandOf(events.RestoreUserAccommodations),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         archiveAvailabilityStep,
+				Command:      commandOf(events.ArchiveUserAvailability),
+				Compensation: commandOf(events.RestoreUserAvailability),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         archiveNotificationsStep,
+				Command:      commandOf(events.ArchiveUserNotifications),
+				Compensation: commandOf(events.RestoreUserNotifications),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:         archiveRatingsStep,
+				Command:      commandOf(events.ArchiveUserRatings),
+				Compensation: commandOf(events.RestoreUserRatings),
+				Timeout:      stepTimeout,
+			},
+			{
+				Name:    deleteProfileStep,
+				Command: commandOf(events.DeleteUserProfile),
+				Timeout: stepTimeout,
+			},
+		},
+	}
+}
+
+func commandOf(commandType events.DeleteUserCommandType) engine.CommandBuilder {
+	return func(instance *engine.Instance) (interface{}, error) {
+		var details events.DeleteUserDetails
+		if err := instance.Decode(&details); err != nil {
+			return nil, err
+		}
+		return &events.DeleteUserCommand{
+			Type:    commandType,
+			Payload: details,
+		}, nil
+	}
+}
+
+func (duo *DeleteUserOrchestrator) Start(ctx context.Context, details *events.DeleteUserDetails) error {
+	duo.logger.LogInfo(source, fmt.Sprintf("Starting delete user saga %s for user %s", details.SagaID, details.UserID))
+	traceContext := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, traceContext)
+	return duo.sagas.StartTraced(details.SagaID, details, traceContext)
+}
+
+func (duo *DeleteUserOrchestrator) Status(sagaID string) (*engine.Instance, error) {
+	return duo.sagas.Get(sagaID)
+}
+
+// Resume restarts sagas left in flight by a previous run and starts watching step timeouts.
+func (duo *DeleteUserOrchestrator) Resume(ctx context.Context) error {
+	err := duo.sagas.Resume()
+	if err != nil {
+		duo.logger.LogError(source, "Unable to resume sagas: "+err.Error())
+		return err
+	}
+	go duo.sagas.WatchTimeouts(ctx, timeoutCheckInterval)
+	return nil
+}
+
+func (duo *DeleteUserOrchestrator) handle(reply *events.DeleteUserReply) {
+	id := reply.Payload.SagaID
+	var err error
+	switch reply.Type {
+	case events.UserReservationsChecked:
+		err = duo.sagas.StepSucceeded(id, checkReservationsStep, "UserReservationsChecked")
+	case events.UserHasActiveReservations:
+		err = duo.sagas.StepFailed(id, checkReservationsStep, "UserHasActiveReservations")
+	case events.UserAuthArchived:
+		err = duo.sagas.StepSucceeded(id, archiveAuthStep, "UserAuthArchived")
+	case events.UserAuthNotArchived:
+		err = duo.sagas.StepFailed(id, archiveAuthStep, "UserAuthNotArchived")
+	case events.UserAccommodationsArchived:
+		// The reply lists the archived accommodations, the later steps act on them.
+		err = duo.sagas.StepSucceededWith(id, archiveAccommodationsStep, "UserAccommodationsArchived", reply.Payload)
+	case events.UserAccommodationsNotArchived:
+		err = duo.sagas.StepFailed(id, archiveAccommodationsStep, "UserAccommodationsNotArchived")
+	case events.UserAvailabilityArchived:
+		err = duo.sagas.StepSucceeded(id, archiveAvailabilityStep, "UserAvailabilityArchived")
+	case events.UserAvailabilityNotArchived:
+		err = duo.sagas.StepFailed(id, archiveAvailabilityStep, "UserAvailabilityNotArchived")
+	case events.UserNotificationsArchived:
+		err = duo.sagas.StepSucceeded(id, archiveNotificationsStep, "UserNotificationsArchived")
+	case events.UserNotificationsNotArchived:
+		err = duo.sagas.StepFailed(id, archiveNotificationsStep, "UserNotificationsNotArchived")
+	case events.UserRatingsArchived:
+		err = duo.sagas.StepSucceeded(id, archiveRatingsStep, "UserRatingsArchived")
+	case events.UserRatingsNotArchived:
+		err = duo.sagas.StepFailed(id, archiveRatingsStep, "UserRatingsNotArchived")
+	case events.UserProfileDeleted:
+		err = duo.sagas.StepSucceeded(id, deleteProfileStep, "UserProfileDeleted")
+	case events.UserProfileNotDeleted:
+		err = duo.sagas.StepFailed(id, deleteProfileStep, "UserProfileNotDeleted")
+	default:
+		return
+	}
+	if err != nil {
+		duo.logger.LogError(source, fmt.Sprintf("Unable to handle reply for delete user saga %s: %v", id, err))
+	}
+}
@@ -2,6 +2,8 @@ package service
 
 import (
 	"context"
+	events "example/saga/delete_user"
+	registerEvents "example/saga/register_user"
 	"fmt"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.opentelemetry.io/otel/trace"
@@ -10,6 +12,7 @@ import (
 	"user-service/client"
 	"user-service/domain"
 	"user-service/errors"
+	"user-service/orchestrator"
 	"user-service/repository"
 	"user-service/utils"
 )
@@ -18,8 +21,7 @@ type UserService struct {
 	userRepository    *repository.UserRepository
 	validator         *utils.Validator
 	reservationClient *client.ReservationClient
-	authClient        *client.AuthClient
-	accClient         *client.AccClient
+	orchestrator      *orchestrator.DeleteUserOrchestrator
 	logger            *config.Logger
 	tracer            trace.Tracer
 }
@@ -29,16 +31,14 @@ const source = "user-service"
 func NewUserService(userRepo *repository.UserRepository,
 	validator *utils.Validator,
 	reservationClient *client.ReservationClient,
-	authClient *client.AuthClient,
-	accClient *client.AccClient,
+	orchestrator *orchestrator.DeleteUserOrchestrator,
 	logger *config.Logger,
 	tracer trace.Tracer) *UserService {
 	return &UserService{
 		userRepository:    userRepo,
 		validator:         validator,
 		reservationClient: reservationClient,
-		authClient:        authClient,
-		accClient:         accClient,
+		orchestrator:      orchestrator,
 		logger:            logger,
 		tracer:            tracer,
 	}
@@ -82,6 +82,28 @@ func (u *UserService) CreateUser(ctx context.Context, createUser domain.CreateUs
 	return newUser, nil
 }
 
+// CreateUserProfile creates the profile for the register user saga. A profile that
+// already exists counts as created, so a redelivered command is harmless.
+func (u *UserService) CreateUserProfile(ctx context.Context, details registerEvents.RegisterUserDetails) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.CreateUserProfile")
+	defer span.End()
+	if existing, err := u.userRepository.GetUserById(ctx, details.UserID); err == nil && existing != nil {
+		u.logger.LogInfo(source, fmt.Sprintf("Profile of user %v already exists", details.UserID))
+		return nil
+	}
+	_, err := u.CreateUser(ctx, domain.CreateUser{
+		ID:        details.UserID,
+		FirstName: details.FirstName,
+		LastName:  details.LastName,
+		Email:     details.Email,
+		Residence: details.Residence,
+		Role:      details.Role,
+		Username:  details.Username,
+		Age:       details.Age,
+	})
+	return err
+}
+
 func (u *UserService) UpdateUser(ctx context.Context, updateUser domain.CreateUser) (*domain.User, *errors.ErrorStruct) {
 	ctx, span := u.tracer.Start(ctx, "UserService.UpdateUser")
 	defer span.End()
@@ -214,39 +236,37 @@ func (u *UserService) isDistinguished(ctx context.Context, id string, rating flo
 	return false, nil
 }
 
-func (u *UserService) DeleteUser(ctx context.Context, role string, id string) *errors.ErrorStruct {
+// DeleteUser starts the delete user saga and returns its id. The account is gone
+// once the saga completes; if any service fails, everything is restored.
+func (u *UserService) DeleteUser(ctx context.Context, role string, id string) (string, *errors.ErrorStruct) {
 	ctx, span := u.tracer.Start(ctx, "UserService.DeleteUser")
 	defer span.End()
-	err := u.reservationClient.UserDeleteAllowed(ctx, id, role)
-	if err != nil {
-		message := err.GetErrorMessage()
-		u.logger.LogError(source, message)
-		return err
-	}
-	if role == "Host" {
-		err := u.accClient.DeleteUserAccommodations(ctx, id)
-		if err != nil {
-			message := err.GetErrorMessage()
-			u.logger.LogError(source, message)
-			return err
-		}
-	}
 	if role != "Guest" && role != "Host" {
-		err2 := errors.NewError(fmt.Sprintf("User by id: %v not allowed by role: %s", id, role), 401)
-		u.logger.LogError(source, err2.GetErrorMessage())
-		return err2
+		err := errors.NewError(fmt.Sprintf("User by id: %v not allowed by role: %s", id, role), 401)
+		u.logger.LogError(source, err.GetErrorMessage())
+		return "", err
 	}
-	newErr := u.authClient.DeleteUserAuth(ctx, id)
-	if newErr != nil {
-		message := newErr.GetErrorMessage()
-		u.logger.LogError(source, message)
-		return newErr
+	details := events.DeleteUserDetails{
+		SagaID: primitive.NewObjectID().Hex(),
+		UserID: id,
+		Role:   role,
 	}
-	err3 := u.userRepository.DeleteUser(ctx, id)
-	if err3 != nil {
-		message := err3.GetErrorMessage()
-		u.logger.LogError(source, message)
-		return err3
+	if err := u.orchestrator.Start(ctx, &details); err != nil {
+		u.logger.LogError(source, fmt.Sprintf("Unable to start delete saga for user %v: %v", id, err))
+		return "", errors.NewError("Service is not responding correctly", 500)
+	}
+	u.logger.LogInfo(source, fmt.Sprintf("Delete saga %v started for user %v", details.SagaID, id))
+	return details.SagaID, nil
+}
+
+// DeleteUserProfile removes the profile itself, the last step of the delete user saga.
+func (u *UserService) DeleteUserProfile(ctx context.Context, id string) *errors.ErrorStruct {
+	ctx, span := u.tracer.Start(ctx, "UserService.DeleteUserProfile")
+	defer span.End()
+	err := u.userRepository.DeleteUser(ctx, id)
+	if err != nil {
+		u.logger.LogError(source, err.GetErrorMessage())
+		return err
 	}
 	u.logger.LogInfo(source, fmt.Sprintf("User by id: %v deleted", id))
 	return nil